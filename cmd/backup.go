@@ -4,12 +4,24 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/shivaji17/notionbackup/src/resolver"
 	"github.com/spf13/cobra"
 )
 
 var pageUUIDs []string
 var databaseUUIDs []string
+var pageTitles []string
+var databaseTitles []string
+var titleMatch string
 var backupWorkspace bool
+var concurrency int
+var skipAssets bool
+var backupComments bool
+var excludePageUUIDs []string
+var excludeDatabaseUUIDs []string
+var excludeTitleRegex string
+var maxDepth int
+var excludeBlockTypes []string
 
 // backupCmd represents the backup command
 var backupCmd = &cobra.Command{
@@ -28,22 +40,66 @@ func init() {
 		false, "backup whole workspace")
 
 	backupCmd.PersistentFlags().StringArrayVar(&pageUUIDs, "page",
-		make([]string, 0), "Page UUIDs for which backup needs to be taken")
+		make([]string, 0), "Page UUIDs or URLs for which backup needs to be "+
+			"taken")
 
 	backupCmd.PersistentFlags().StringArrayVar(&databaseUUIDs, "database",
-		make([]string, 0), "Database UUIDs for which backup needs to be taken")
+		make([]string, 0), "Database UUIDs or URLs for which backup needs to "+
+			"be taken")
+
+	backupCmd.PersistentFlags().StringArrayVar(&pageTitles, "page-title",
+		make([]string, 0), "titles of the Pages for which backup needs to be "+
+			"taken. Title must match exactly one Page")
+	backupCmd.PersistentFlags().StringArrayVar(&databaseTitles,
+		"database-title", make([]string, 0), "titles of the Databases for "+
+			"which backup needs to be taken. Title must match exactly one "+
+			"Database")
+	backupCmd.PersistentFlags().StringVar(&titleMatch, "title-match",
+		string(resolver.EXACT_MATCH), "mode in which --page-title and "+
+			"--database-title are matched, one of exact, prefix or regex")
+
+	backupCmd.PersistentFlags().IntVar(&concurrency, "concurrency", 1,
+		"Number of workers fetching Pages, Databases and Blocks in parallel. "+
+			"All workers share the request rate set by --rate-limit")
+
+	backupCmd.PersistentFlags().BoolVar(&skipAssets, "skip-assets", false,
+		"do not download the files hosted by Notion, like images and page "+
+			"covers. Their URLs expire an hour after the backup is taken")
+	backupCmd.PersistentFlags().BoolVar(&backupComments, "comments", false,
+		"backup the comments of pages and blocks. Comments are fetched with a "+
+			"request per page and block and need the read comments capability")
+
+	backupCmd.PersistentFlags().StringArrayVar(&excludePageUUIDs,
+		"exclude-page", make([]string, 0), "Page UUIDs excluded from the "+
+			"backup along with their children")
+	backupCmd.PersistentFlags().StringArrayVar(&excludeDatabaseUUIDs,
+		"exclude-database", make([]string, 0), "Database UUIDs excluded from "+
+			"the backup along with their pages")
+	backupCmd.PersistentFlags().StringVar(&excludeTitleRegex,
+		"exclude-title-regex", "", "exclude the Pages and Databases whose "+
+			"title matches the regular expression along with their children")
+	backupCmd.PersistentFlags().IntVar(&maxDepth, "max-depth", 0,
+		"maximum depth of nested Pages and Databases, the Pages and Databases "+
+			"backed up directly being at depth 1. Zero means no limit")
+	backupCmd.PersistentFlags().StringArrayVar(&excludeBlockTypes,
+		"exclude-block-type", make([]string, 0), "types of the Blocks "+
+			"excluded from the backup, e.g. embed or link_preview")
+
+	addMetricsFlags(backupCmd)
 }
 
 func validateMutuallyExclusiveFlags() {
-	if len(pageUUIDs) == 0 && len(databaseUUIDs) == 0 && !backupWorkspace {
+	hasTargets := len(pageUUIDs) != 0 || len(databaseUUIDs) != 0 ||
+		len(pageTitles) != 0 || len(databaseTitles) != 0
+	if !hasTargets && !backupWorkspace {
 		fmt.Fprintf(os.Stderr, "Please provide --workspace flag to backup whole "+
-			"workspace or Page and/or Database UUIDs to backup.\n")
+			"workspace or Page and/or Database UUIDs or titles to backup.\n")
 		os.Exit(1)
 	}
 
-	if (len(pageUUIDs) != 0 || len(databaseUUIDs) != 0) && backupWorkspace {
+	if hasTargets && backupWorkspace {
 		fmt.Fprintf(os.Stderr, "Flag --workspace is mutually exclusive with flag "+
-			"--page and --database.\n")
+			"--page, --database, --page-title and --database-title.\n")
 		os.Exit(1)
 	}
 }
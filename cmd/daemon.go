@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/daemon"
+	"github.com/spf13/cobra"
+)
+
+var scheduleFile string
+
+// daemonCmd represents the daemon command
+var daemonCmd = &cobra.Command{
+	Use:   "daemon",
+	Short: "Run the backup jobs of a schedule",
+	Long: "Run the backup jobs of the YAML schedule file on their cron " +
+		"schedule until SIGTERM or SIGINT is received. Each snapshot is written " +
+		"in a directory named with its start time under the destination of the " +
+		"job and the retention policy of the job is applied after each " +
+		"successful run. Retention is only supported for directory " +
+		"destinations, snapshots under an S3 prefix are never removed by the " +
+		"daemon. A job is not run again while its previous run is " +
+		"still running. State of the jobs, including the last snapshot from " +
+		"which incremental snapshots are taken, is kept in the state directory " +
+		"of the schedule.",
+	Example: `  notionbackup daemon --schedule schedule.yaml
+
+  # schedule.yaml
+  state_dir: /var/lib/notionbackup
+  jobs:
+    - name: workspace
+      schedule: "0 2 * * *"
+      incremental: true
+      destination:
+        dir: /backups/workspace
+      retention:
+        keep_daily: 7
+        keep_weekly: 4
+        keep_monthly: 12
+    - name: wiki
+      schedule: "@hourly"
+      pages: [05034203-2870-4bc8-b1f9-22c0ae6e56ba]
+      # Retention cannot be set for S3 destination
+      destination:
+        s3:
+          bucket: notion-backups
+          prefix: wiki`,
+	RunE: RunDaemon,
+
+	// Failed daemon is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(daemonCmd)
+
+	daemonCmd.Flags().StringVar(&scheduleFile, "schedule", "",
+		"YAML file containing the schedule of the backup jobs")
+	daemonCmd.MarkFlagFilename("schedule", "yaml", "yml")
+	daemonCmd.MarkFlagRequired("schedule")
+
+	addMetricsFlags(daemonCmd)
+}
+
+func RunDaemon(cmd *cobra.Command, args []string) error {
+
+	validateNonEmptyNotionToken()
+
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	schedule, err := daemon.ReadSchedule(scheduleFile)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read the schedule")
+		return err
+	}
+
+	baseConfig := &config.Config{
+		Token:             notionToken,
+		MaxRetries:        maxRetries,
+		RequestsPerSecond: requestsPerSecond,
+		S3Config:          getS3Config(),
+		PassphraseFile:    passphraseFile,
+		KeyFile:           keyFile,
+	}
+
+	// Running jobs are cancelled on SIGTERM so that their partial snapshots are
+	// cleaned up before exiting
+	ctx, stop := signal.NotifyContext(log.WithContext(context.Background()),
+		syscall.SIGTERM, os.Interrupt)
+	defer stop()
+
+	m, err := startMetrics(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start metrics")
+		return err
+	}
+
+	return daemon.GetDaemon(schedule, baseConfig,
+		daemon.WithMetrics(m, metricsTextfile)).Run(ctx)
+}
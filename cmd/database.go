@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/dbexport"
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+
+// databaseCmd represents the database command
+var databaseCmd = &cobra.Command{
+	Use:   "database",
+	Short: "Export the Databases as CSV or JSON Lines files",
+	Long: "Export every Database of the backup as a CSV or JSON Lines file " +
+		"with one row per Page of the Database and one column per property.",
+	RunE: ExportDatabase,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	exportCmd.AddCommand(databaseCmd)
+
+	databaseCmd.Flags().StringVar(&exportFormat, "format", string(dbexport.CSV),
+		"format of the exported files. (Formats: csv, jsonl)")
+}
+
+func ExportDatabase(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type:   config.DATABASE_EXPORT,
+		MetadataFilePath: exportMetadataFilePath,
+		OutputDir:        exportOutputDir,
+		ExportFormat:     exportFormat,
+		S3Config:         getS3Config(),
+		PassphraseFile:   passphraseFile,
+		KeyFile:          keyFile,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx, config.InitializeExport)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/spf13/cobra"
+)
+
+var diffReportFormat string
+var textDiff bool
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <old backup> <new backup>",
+	Short: "Compare two snapshots of the backup",
+	Long: "Report the Pages, Databases and Blocks added, removed, moved to a " +
+		"different parent or changed in content between two snapshots. Objects " +
+		"are matched by their Notion ID. Snapshots are metadata files, archive " +
+		"paths or S3 URLs (s3://bucket/prefix) of the backups.",
+	Args: cobra.ExactArgs(2),
+	RunE: Diff,
+
+	// Failed comparison is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().StringVar(&diffReportFormat, "report-format",
+		config.TABLE_REPORT_FORMAT, "format of the report (Formats: "+
+			config.TABLE_REPORT_FORMAT+", "+config.JSON_REPORT_FORMAT+")")
+	diffCmd.Flags().BoolVar(&textDiff, "text", false,
+		"also report the word level difference of the text of changed blocks")
+}
+
+func Diff(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type:      config.DIFF,
+		OldMetadataFilePath: args[0],
+		MetadataFilePath:    args[1],
+		ReportFormat:        diffReportFormat,
+		TextDiff:            textDiff,
+		S3Config:            getS3Config(),
+		PassphraseFile:      passphraseFile,
+		KeyFile:             keyFile,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx)
+}
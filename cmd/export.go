@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var exportMetadataFilePath string
+var exportOutputDir string
+
+// exportCmd represents the export command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Export the backup data to other formats",
+	Long: "Export the Pages, Databases and Blocks of the backup to other " +
+		"formats. Export works offline and does not need Notion secret token.",
+}
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.PersistentFlags().StringVarP(&exportMetadataFilePath, "file-path",
+		"f", "", "metadata file, archive path or S3 URL (s3://bucket/prefix) "+
+			"of the backup")
+	exportCmd.MarkPersistentFlagRequired("file-path")
+	exportCmd.PersistentFlags().StringVarP(&exportOutputDir, "out", "o", "",
+		"directory to write exported data to")
+	exportCmd.MarkPersistentFlagRequired("out")
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/spf13/cobra"
+)
+
+var gcDir string
+var gcDryRun bool
+
+// gcCmd represents the gc command
+var gcCmd = &cobra.Command{
+	Use:   "gc",
+	Short: "Delete objects not referenced by any snapshot of the object pool",
+	Long: "Delete the objects of the object pool created by 'backup local " +
+		"--dedup' which are not referenced by any snapshot in its snapshots " +
+		"directory. Remove the directories of unwanted snapshots before running " +
+		"gc. gc must not run while a backup is being taken in the same directory.",
+	RunE: CollectGarbage,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(gcCmd)
+
+	gcCmd.Flags().StringVarP(&gcDir, "dir", "d", "",
+		"directory of the object pool")
+	gcCmd.MarkFlagDirname("dir")
+	gcCmd.MarkFlagRequired("dir")
+	gcCmd.Flags().BoolVar(&gcDryRun, "dry-run", false,
+		"only print the hashes of unreferenced objects without deleting them")
+}
+
+func CollectGarbage(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type: config.GC,
+		Dir:            gcDir,
+		DryRun:         gcDryRun,
+		PassphraseFile: passphraseFile,
+		KeyFile:        keyFile,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx)
+}
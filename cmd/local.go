@@ -6,18 +6,26 @@ import (
 	"os"
 
 	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/shivaji17/notionbackup/src/utils"
 	"github.com/spf13/cobra"
 )
 
 var dir string
 var createDir bool
+var incrementalFrom string
+var archiveFormat string
+var dedup bool
 
 // localCmd represents the local command
 var localCmd = &cobra.Command{
 	Use:   "local",
 	Short: "backup to local machine",
 	RunE:  TakeLocalBackup,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
 }
 
 func init() {
@@ -30,6 +38,17 @@ func init() {
 	localCmd.MarkFlagRequired("dir")
 	localCmd.Flags().BoolVar(&createDir, "create-dir", false,
 		"Create directory if not exists")
+	localCmd.Flags().StringVar(&incrementalFrom, "incremental-from", "",
+		"metadata file, archive path or S3 URL of the previous backup. Pages "+
+			"and Databases not edited since the previous backup are reused from it")
+	localCmd.Flags().StringVar(&archiveFormat, "archive", "",
+		"write backup data and metadata to a single archive file instead of "+
+			"directories. (Formats: tar.gz, zip)")
+	localCmd.Flags().BoolVar(&dedup, "dedup", false,
+		"store objects in a content addressed object pool shared by all "+
+			"snapshots taken in the directory. Identical objects are stored only "+
+			"once and metadata of each snapshot is written in snapshots directory. "+
+			"Not supported with encryption")
 }
 
 func TakeLocalBackup(cmd *cobra.Command, args []string) error {
@@ -47,16 +66,46 @@ func TakeLocalBackup(cmd *cobra.Command, args []string) error {
 	databaseUUIDs = utils.GetUniqueValues(databaseUUIDs)
 
 	cfg := &config.Config{
-		Token:          notionToken,
-		Operation_Type: config.BACKUP,
-		PageUUIDs:      pageUUIDs,
-		DatabaseUUIDs:  databaseUUIDs,
-		Dir:            dir,
-		Create_Dir:     createDir,
+		Token:                notionToken,
+		Operation_Type:       config.BACKUP,
+		PageUUIDs:            pageUUIDs,
+		DatabaseUUIDs:        databaseUUIDs,
+		PageTitles:           pageTitles,
+		DatabaseTitles:       databaseTitles,
+		TitleMatch:           titleMatch,
+		Dir:                  dir,
+		Create_Dir:           createDir,
+		MaxRetries:           maxRetries,
+		RequestsPerSecond:    requestsPerSecond,
+		Concurrency:          concurrency,
+		SkipAssets:           skipAssets,
+		BackupComments:       backupComments,
+		ExcludePageUUIDs:     excludePageUUIDs,
+		ExcludeDatabaseUUIDs: excludeDatabaseUUIDs,
+		ExcludeTitleRegex:    excludeTitleRegex,
+		MaxDepth:             maxDepth,
+		ExcludeBlockTypes:    excludeBlockTypes,
+		IncrementalFrom:      incrementalFrom,
+		ArchiveFormat:        archiveFormat,
+		S3Config:             getS3Config(),
+		PassphraseFile:       passphraseFile,
+		KeyFile:              keyFile,
+		Dedup:                dedup,
 	}
 
-	ctx := log.WithContext(context.Background())
+	ctx, cancel := context.WithCancel(log.WithContext(context.Background()))
+	defer cancel()
+
+	m, err := startMetrics(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start metrics")
+		return err
+	}
 
-	cfg.Execute(ctx, config.InitializeBackup)
-	return nil
+	ctx, finishRun := recordRun(ctx, m, metrics.BACKUP)
+	ctx, stopProgress := trackProgress(ctx, progress.BACKUP)
+	err = cfg.Execute(ctx, config.InitializeBackup)
+	stopProgress()
+	finishRun(err)
+	return err
 }
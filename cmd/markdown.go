@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/spf13/cobra"
+)
+
+// markdownCmd represents the markdown command
+var markdownCmd = &cobra.Command{
+	Use:   "markdown",
+	Short: "Export the Pages as markdown files",
+	Long: "Export every Page and Database of the backup as a markdown file. " +
+		"Files are arranged in directories matching the hierarchy of the Pages.",
+	RunE: ExportMarkdown,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	exportCmd.AddCommand(markdownCmd)
+}
+
+func ExportMarkdown(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type:   config.MARKDOWN_EXPORT,
+		MetadataFilePath: exportMetadataFilePath,
+		OutputDir:        exportOutputDir,
+		S3Config:         getS3Config(),
+		PassphraseFile:   passphraseFile,
+		KeyFile:          keyFile,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx, config.InitializeExport)
+}
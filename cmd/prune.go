@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/spf13/cobra"
+)
+
+var pruneDir string
+var keepDaily int
+var keepWeekly int
+var keepMonthly int
+var pruneDryRun bool
+
+// pruneCmd represents the prune command
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove the snapshots not kept by the retention policy",
+	Long: "Apply grandfather-father-son retention to the snapshots in the " +
+		"directory and remove the rest. Snapshots are the sub directories " +
+		"having a metadata file or an archive, the archives in the directory " +
+		"or the snapshots of the object pool created by 'backup local --dedup'. " +
+		"Newest snapshot of each of the last --keep-daily days, --keep-weekly " +
+		"weeks and --keep-monthly months is kept along with the snapshots from " +
+		"which kept snapshots were taken incrementally. Run gc after pruning an " +
+		"object pool.",
+	RunE: Prune,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	rootCmd.AddCommand(pruneCmd)
+
+	pruneCmd.Flags().StringVarP(&pruneDir, "dir", "d", "",
+		"directory containing the snapshots")
+	pruneCmd.MarkFlagDirname("dir")
+	pruneCmd.MarkFlagRequired("dir")
+	pruneCmd.Flags().IntVar(&keepDaily, "keep-daily", 0,
+		"number of days for which newest snapshot of the day is kept")
+	pruneCmd.Flags().IntVar(&keepWeekly, "keep-weekly", 0,
+		"number of weeks for which newest snapshot of the week is kept")
+	pruneCmd.Flags().IntVar(&keepMonthly, "keep-monthly", 0,
+		"number of months for which newest snapshot of the month is kept")
+	pruneCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false,
+		"only print the snapshots which would be removed without removing them")
+}
+
+func Prune(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type: config.PRUNE,
+		Dir:            pruneDir,
+		KeepDaily:      keepDaily,
+		KeepWeekly:     keepWeekly,
+		KeepMonthly:    keepMonthly,
+		DryRun:         pruneDryRun,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx)
+}
@@ -6,11 +6,23 @@ import (
 	"os"
 
 	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/spf13/cobra"
 )
 
 var metadataFilePath string
 var restoreToPageUUID string
+var journalPath string
+var resume bool
+var restoreDryRun bool
+var reportFormat string
+var subtreeNodeUuid string
+var subtreeNotionId string
+var subtreeTitle string
+var missingLinkPolicy string
+var assetBucketURL string
+var assetFallbackURL string
 
 // restoreCmd represents the restore command
 var restoreCmd = &cobra.Command{
@@ -19,6 +31,9 @@ var restoreCmd = &cobra.Command{
 	Long: "Restore the data back to Notion with all Pages, Databases and " +
 		"Blocks maintaining the hierarchy of all the objects.",
 	RunE: Restore,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
 }
 
 func init() {
@@ -26,28 +41,88 @@ func init() {
 
 	// Here you will define your flags and configuration settings.
 	restoreCmd.Flags().StringVarP(&metadataFilePath, "file-path", "f", "",
-		"metadata file path")
+		"metadata file, archive path or S3 URL (s3://bucket/prefix) of the "+
+			"backup")
 	restoreCmd.Flags().StringVarP(&restoreToPageUUID, "page", "p", "",
 		"page uuid to which all data needs to be restored")
+	restoreCmd.Flags().StringVar(&journalPath, "journal", "",
+		"file recording the progress of the restore (Default: restore-<page "+
+			"uuid>[-<node uuid>].journal next to the metadata file)")
+	restoreCmd.Flags().BoolVar(&resume, "resume", false,
+		"resume the failed restore from its journal without creating the "+
+			"already restored objects again")
+	restoreCmd.Flags().BoolVar(&restoreDryRun, "dry-run", false,
+		"only report the objects and API calls the restore would create without "+
+			"sending anything to Notion")
+	restoreCmd.Flags().StringVar(&reportFormat, "report-format",
+		config.TABLE_REPORT_FORMAT, "format of the dry run report (Formats: "+
+			config.TABLE_REPORT_FORMAT+", "+config.JSON_REPORT_FORMAT+")")
+	restoreCmd.Flags().StringVar(&subtreeNodeUuid, "node", "",
+		"restore only the page or database with given node uuid and its children")
+	restoreCmd.Flags().StringVar(&subtreeNotionId, "notion-id", "",
+		"restore only the page or database with given Notion ID and its children")
+	restoreCmd.Flags().StringVar(&subtreeTitle, "title", "",
+		"restore only the page or database with given title and its children")
+	restoreCmd.Flags().StringVar(&missingLinkPolicy, "missing-links",
+		string(importer.KEEP_MISSING_LINKS), "policy for the links to the "+
+			"objects which are not part of the backup (Policies: "+
+			string(importer.KEEP_MISSING_LINKS)+", "+
+			string(importer.TEXT_MISSING_LINKS)+")")
+	restoreCmd.Flags().StringVar(&assetBucketURL, "asset-bucket", "",
+		"S3 URL (s3://bucket/prefix) of the bucket to which the assets of the "+
+			"backup are uploaded. Files hosted by Notion are restored as external "+
+			"files pointing to the uploaded assets. Connection parameters are same "+
+			"as of the S3 backup")
+	restoreCmd.Flags().StringVar(&assetFallbackURL, "asset-fallback-url", "",
+		"http or https URL restored in place of the files hosted by Notion "+
+			"which cannot be uploaded")
+}
+
+// Helper function to get the config of the restore from the flags
+func getRestoreConfig() *config.Config {
+	return &config.Config{
+		Token:             notionToken,
+		Operation_Type:    config.RESTORE,
+		MetadataFilePath:  metadataFilePath,
+		RestoreToPageUUID: restoreToPageUUID,
+		JournalPath:       journalPath,
+		Resume:            resume,
+		DryRun:            restoreDryRun,
+		ReportFormat:      reportFormat,
+		SubtreeNodeUuid:   subtreeNodeUuid,
+		SubtreeNotionId:   subtreeNotionId,
+		SubtreeTitle:      subtreeTitle,
+		MissingLinkPolicy: missingLinkPolicy,
+		AssetBucketURL:    assetBucketURL,
+		AssetFallbackURL:  assetFallbackURL,
+		MaxRetries:        maxRetries,
+		RequestsPerSecond: requestsPerSecond,
+		S3Config:          getS3Config(),
+		PassphraseFile:    passphraseFile,
+		KeyFile:           keyFile,
+	}
 }
 
 func Restore(cmd *cobra.Command, args []string) error {
-	validateNonEmptyNotionToken()
+	if !restoreDryRun {
+		validateNonEmptyNotionToken()
+	}
 
 	log, err := getLogger()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
 		return err
 	}
-	cfg := &config.Config{
-		Token:             notionToken,
-		Operation_Type:    config.RESTORE,
-		MetadataFilePath:  metadataFilePath,
-		RestoreToPageUUID: restoreToPageUUID,
-	}
+	cfg := getRestoreConfig()
 
 	ctx := log.WithContext(context.Background())
 
-	cfg.Execute(ctx, config.InitializeRestore)
-	return nil
+	// Dry run only prints the report of the restore
+	if !restoreDryRun {
+		var stopProgress func()
+		ctx, stopProgress = trackProgress(ctx, progress.RESTORE)
+		defer stopProgress()
+	}
+
+	return cfg.Execute(ctx, config.InitializeRestore)
 }
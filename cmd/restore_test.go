@@ -0,0 +1,19 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRestoreFlags(t *testing.T) {
+	err := restoreCmd.ParseFlags([]string{
+		"--asset-bucket", "s3://assets/notion",
+		"--asset-fallback-url", "https://example.com/missing.png",
+	})
+	assert.Nil(t, err)
+
+	cfg := getRestoreConfig()
+	assert.Equal(t, "s3://assets/notion", cfg.AssetBucketURL)
+	assert.Equal(t, "https://example.com/missing.png", cfg.AssetFallbackURL)
+}
@@ -1,19 +1,41 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"golang.org/x/term"
 )
 
+const (
+	ENV_PREFIX = "NTN_"
+
+	CONFIG_DIR_NAME  = "notionbackup"
+	CONFIG_FILE_NAME = "config"
+)
+
 var notionToken string
 var logLevel string
+var maxRetries int
+var requestsPerSecond float64
+var passphraseFile string
+var keyFile string
+var metricsListen string
+var metricsTextfile string
+var configFile string
+var profile string
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -22,6 +44,7 @@ var rootCmd = &cobra.Command{
 	Long: "Notion Backup is a tool to take backup of whole Notion workspace or " +
 		"a specific set of Pages or Databases and restore them back to different " +
 		"or same Noion workspace.",
+	PersistentPreRunE: loadConfigFile,
 }
 
 // Execute adds all child commands to the root command and sets flags
@@ -47,6 +70,28 @@ func init() {
 			"as environment variable 'NTN_TOKEN'.")
 	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info",
 		"Level of logging. (Log levels: info, debug, trace)")
+	rootCmd.PersistentFlags().IntVar(&maxRetries, "max-retries",
+		notionclient.DEFAULT_MAX_RETRIES, "Maximum number of retries for Notion "+
+			"API requests failed with transient errors. Set 0 to disable retries")
+	rootCmd.PersistentFlags().Float64Var(&requestsPerSecond, "rate-limit",
+		notionclient.DEFAULT_REQUESTS_PER_SECOND, "Maximum number of requests per "+
+			"second sent to Notion API. Set 0 to disable rate limiting")
+	rootCmd.PersistentFlags().StringVar(&passphraseFile, "passphrase-file", "",
+		"file containing the passphrase with which backup is encrypted. Backup "+
+			"is encrypted with AES-256-GCM using key derived with argon2id")
+	rootCmd.PersistentFlags().StringVar(&keyFile, "key-file", "",
+		"file containing the 256 bit key (raw or hex encoded) with which backup "+
+			"is encrypted. Mutually exclusive with --passphrase-file")
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "",
+		"YAML, TOML or JSON file setting the flags not provided on the command "+
+			"line or as NTN_* environment variables. Flags are set with their "+
+			"names, either at the top level or under the names of the commands, "+
+			"e.g. backup.local.dir (Default: "+CONFIG_FILE_NAME+".yaml or "+
+			CONFIG_FILE_NAME+".toml in the notionbackup directory of the user "+
+			"config directory, if it exists)")
+	rootCmd.PersistentFlags().StringVar(&profile, "profile", "",
+		"profile of the config file to use. Flags set under profiles.<profile> "+
+			"override the flags set anywhere else in the config file")
 }
 
 // initConfig reads in config file and ENV variables if set.
@@ -55,12 +100,149 @@ func initConfig() {
 	viper.AutomaticEnv()
 }
 
+// Helper function to read the config file at the given path, or the default
+// config file if path is empty. Configs are returned in the order of their
+// precedence: flags of the profile first, then the flags of the whole file.
+// No config is returned if the default config file does not exist
+func readConfigFile(configFilePath string,
+	profileName string) ([]*viper.Viper, error) {
+	fileConfig := viper.New()
+	if configFilePath != "" {
+		fileConfig.SetConfigFile(configFilePath)
+	} else {
+		userConfigDir, err := os.UserConfigDir()
+		if err != nil {
+			return nil, nil
+		}
+		fileConfig.SetConfigName(CONFIG_FILE_NAME)
+		fileConfig.AddConfigPath(filepath.Join(userConfigDir, CONFIG_DIR_NAME))
+	}
+
+	err := fileConfig.ReadInConfig()
+	if _, ok := err.(viper.ConfigFileNotFoundError); ok {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't read config file")
+	}
+
+	if profileName == "" {
+		profileName = fileConfig.GetString("profile")
+	}
+	if profileName == "" {
+		return []*viper.Viper{fileConfig}, nil
+	}
+
+	profileConfig := fileConfig.Sub("profiles." + profileName)
+	if profileConfig == nil {
+		return nil, fmt.Errorf("profile %s not found in config file %s",
+			profileName, fileConfig.ConfigFileUsed())
+	}
+
+	return []*viper.Viper{profileConfig, fileConfig}, nil
+}
+
+// Helper function to get the keys with which the flag of the command can be
+// set in the config file, the most specific key first. For instance, --dir of
+// backup local is set with backup.local.dir, backup.dir or dir
+func getConfigFileKeys(cmd *cobra.Command, flagName string) []string {
+	commandNames := []string{}
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		commandNames = append([]string{c.Name()}, commandNames...)
+	}
+
+	keys := []string{}
+	for i := len(commandNames); i >= 0; i-- {
+		keys = append(keys, strings.Join(append(commandNames[:i:i], flagName),
+			"."))
+	}
+
+	return keys
+}
+
+// Helper function to get the value of the flag from the environment variable
+// or from the configs, in that order. Any key of the profile takes precedence
+// over the keys of the whole file
+func getFlagValue(cmd *cobra.Command, configs []*viper.Viper,
+	flagName string) (interface{}, bool) {
+	envName := ENV_PREFIX + strings.ToUpper(strings.ReplaceAll(flagName, "-",
+		"_"))
+	if value, found := os.LookupEnv(envName); found {
+		return value, true
+	}
+
+	keys := getConfigFileKeys(cmd, flagName)
+	for _, config := range configs {
+		for _, key := range keys {
+			if config.IsSet(key) {
+				return config.Get(key), true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// Set the flags of the command not provided on the command line from the
+// environment variables and the config file, so that the precedence is flags >
+// env > profile of config file > config file
+func applyConfigFile(cmd *cobra.Command, configFilePath string,
+	profileName string) error {
+	configs, err := readConfigFile(configFilePath, profileName)
+	if err != nil {
+		return err
+	}
+
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Name == "config" ||
+			flag.Name == "profile" {
+			return
+		}
+
+		value, found := getFlagValue(cmd, configs, flag.Name)
+		if !found {
+			return
+		}
+
+		// Lists set the flags provided more than once like --page
+		values, ok := value.([]interface{})
+		if !ok {
+			values = []interface{}{value}
+		}
+
+		for _, v := range values {
+			err = cmd.Flags().Set(flag.Name, fmt.Sprint(v))
+			if err != nil {
+				err = errors.Wrapf(err, "Couldn't set flag --%s", flag.Name)
+				return
+			}
+		}
+	})
+
+	return err
+}
+
+// Load the config file set by --config or NTN_CONFIG with the profile set by
+// --profile or NTN_PROFILE
+func loadConfigFile(cmd *cobra.Command, args []string) error {
+	if configFile == "" {
+		configFile = os.Getenv(ENV_PREFIX + "CONFIG")
+	}
+
+	if profile == "" {
+		profile = os.Getenv(ENV_PREFIX + "PROFILE")
+	}
+
+	return applyConfigFile(cmd, configFile, profile)
+}
+
 func validateNonEmptyNotionToken() {
 	if notionToken == "" {
 		notionToken = viper.GetString("token")
 		if notionToken == "" {
 			fmt.Fprintf(os.Stderr, "Please provide Notion secret token with "+
-				"--token flag or export it as an environment variable 'NTN_TOKEN'.\n")
+				"--token flag, export it as an environment variable 'NTN_TOKEN' or "+
+				"set it in the config file.\n")
 			os.Exit(1)
 		}
 	}
@@ -86,6 +268,8 @@ func getLogger() (zerolog.Logger, error) {
 
 	if !term.IsTerminal(int(out.Fd())) {
 		writer.NoColor = true
+	} else {
+		writer.Out = progress.GetClearLineWriter(out)
 	}
 	log := zerolog.New(writer).
 		Hook(timeHook{}).
@@ -93,3 +277,65 @@ func getLogger() (zerolog.Logger, error) {
 
 	return log, nil
 }
+
+// Track the progress of the operation with the tracker associated with the
+// context. Progress is reported to stderr until the returned function is called
+func trackProgress(ctx context.Context,
+	operation string) (context.Context, func()) {
+	tracker := progress.GetTracker(operation)
+	reporter := progress.StartReporter(tracker, os.Stderr)
+	return tracker.WithContext(ctx), reporter.Stop
+}
+
+// Helper function to add the flags exposing the metrics of the runs of the
+// command
+func addMetricsFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&metricsListen, "metrics-listen", "",
+		"address on which Prometheus metrics are served at /metrics while the "+
+			"command runs, e.g. :9090")
+	cmd.PersistentFlags().StringVar(&metricsTextfile, "metrics-textfile", "",
+		"file to which Prometheus metrics are written after each run, for the "+
+			"textfile collector of node exporter")
+}
+
+// Create the metrics of the process and serve them on the address set by
+// --metrics-listen until the context is cancelled
+func startMetrics(ctx context.Context) (*metrics.Metrics, error) {
+	m := metrics.GetMetrics()
+	if metricsListen == "" {
+		return m, nil
+	}
+
+	err := m.Serve(ctx, metricsListen)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Couldn't serve metrics on %s",
+			metricsListen)
+	}
+
+	return m, nil
+}
+
+// Helper function to write the metrics to the file set by --metrics-textfile
+func writeMetricsTextfile(ctx context.Context, m *metrics.Metrics) {
+	if metricsTextfile == "" {
+		return
+	}
+
+	err := m.WriteTextfile(metricsTextfile)
+	if err != nil {
+		zerolog.Ctx(ctx).Warn().Err(err).Msg(
+			"Failed to write the metrics textfile")
+	}
+}
+
+// Record the run of the operation with the recorder associated with the
+// context. Returned function records the outcome of the run and writes the
+// metrics textfile
+func recordRun(ctx context.Context, m *metrics.Metrics,
+	operation string) (context.Context, func(error)) {
+	recorder := m.GetRecorder(operation, "")
+	return recorder.WithContext(ctx), func(err error) {
+		recorder.Finish(err)
+		writeMetricsTextfile(ctx, m)
+	}
+}
@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to get the local command under backup command with its own
+// flags, so that the flags of the actual commands are not modified
+func getTestCommand() *cobra.Command {
+	rootCmd := &cobra.Command{Use: "notionbackup"}
+	backupCmd := &cobra.Command{Use: "backup"}
+	localCmd := &cobra.Command{Use: "local"}
+	rootCmd.AddCommand(backupCmd)
+	backupCmd.AddCommand(localCmd)
+
+	localCmd.Flags().String("dir", "", "")
+	localCmd.Flags().StringSlice("page", []string{}, "")
+	localCmd.Flags().Int("max-depth", 0, "")
+	return localCmd
+}
+
+func writeConfigFile(t *testing.T, dir string, content string) string {
+	path := filepath.Join(dir, CONFIG_FILE_NAME+".yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestApplyConfigFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		args     []string
+		env      map[string]string
+		profile  string
+		dir      string
+		pages    []string
+		maxDepth int
+		isError  bool
+	}{
+		{
+			name:     "Flags at the top level",
+			content:  "dir: top\nmax-depth: 2\n",
+			dir:      "top",
+			maxDepth: 2,
+		},
+		{
+			name: "Command keys override top level",
+			content: "dir: top\nmax-depth: 2\nbackup:\n  max-depth: 3\n" +
+				"  local:\n    dir: local\n",
+			dir:      "local",
+			maxDepth: 3,
+		},
+		{
+			name:    "List of values",
+			content: "page: [first, second]\n",
+			pages:   []string{"first", "second"},
+		},
+		{
+			name: "Profile overrides command keys",
+			content: "backup:\n  local:\n    dir: local\n    max-depth: 2\n" +
+				"profiles:\n  work:\n    dir: work\n",
+			profile:  "work",
+			dir:      "work",
+			maxDepth: 2,
+		},
+		{
+			name: "Command keys of profile",
+			content: "dir: top\nprofiles:\n  work:\n    dir: work\n" +
+				"    backup:\n      local:\n        dir: work-local\n",
+			profile: "work",
+			dir:     "work-local",
+		},
+		{
+			name: "Profile set in config file",
+			content: "profile: work\ndir: top\nprofiles:\n  work:\n" +
+				"    dir: work\n",
+			dir: "work",
+		},
+		{
+			name:    "Profile not found",
+			content: "dir: top\nprofiles:\n  work:\n    dir: work\n",
+			profile: "home",
+			isError: true,
+		},
+		{
+			name:    "Command line flags override config file",
+			content: "dir: top\nprofiles:\n  work:\n    dir: work\n",
+			args:    []string{"--dir", "cli"},
+			profile: "work",
+			dir:     "cli",
+		},
+		{
+			name:    "Environment variables override config file",
+			content: "dir: top\nmax-depth: 2\n",
+			env: map[string]string{"NTN_DIR": "env",
+				"NTN_MAX_DEPTH": "4"},
+			dir:      "env",
+			maxDepth: 4,
+		},
+		{
+			name:    "Invalid value",
+			content: "max-depth: deep\n",
+			isError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for name, value := range test.env {
+				os.Setenv(name, value)
+				defer os.Unsetenv(name)
+			}
+
+			cmd := getTestCommand()
+			err := cmd.ParseFlags(test.args)
+			assert.Nil(t, err)
+
+			configFilePath := writeConfigFile(t, t.TempDir(), test.content)
+			err = applyConfigFile(cmd, configFilePath, test.profile)
+			if test.isError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+
+			dir, _ := cmd.Flags().GetString("dir")
+			assert.Equal(t, test.dir, dir)
+			pages, _ := cmd.Flags().GetStringSlice("page")
+			assert.Equal(t, len(test.pages), len(pages))
+			if len(test.pages) != 0 {
+				assert.Equal(t, test.pages, pages)
+			}
+			maxDepth, _ := cmd.Flags().GetInt("max-depth")
+			assert.Equal(t, test.maxDepth, maxDepth)
+		})
+	}
+
+	t.Run("Config file does not exist", func(t *testing.T) {
+		err := applyConfigFile(getTestCommand(),
+			filepath.Join(t.TempDir(), "config.yaml"), "")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Default config file", func(t *testing.T) {
+		configHome := t.TempDir()
+		oldConfigHome, found := os.LookupEnv("XDG_CONFIG_HOME")
+		os.Setenv("XDG_CONFIG_HOME", configHome)
+		defer func() {
+			if found {
+				os.Setenv("XDG_CONFIG_HOME", oldConfigHome)
+			} else {
+				os.Unsetenv("XDG_CONFIG_HOME")
+			}
+		}()
+
+		// Missing default config file is ignored
+		cmd := getTestCommand()
+		err := applyConfigFile(cmd, "", "")
+		assert.Nil(t, err)
+
+		configDir := filepath.Join(configHome, CONFIG_DIR_NAME)
+		err = os.MkdirAll(configDir, 0700)
+		assert.Nil(t, err)
+		writeConfigFile(t, configDir, "dir: default\n")
+
+		err = applyConfigFile(cmd, "", "")
+		assert.Nil(t, err)
+		dir, _ := cmd.Flags().GetString("dir")
+		assert.Equal(t, "default", dir)
+	})
+}
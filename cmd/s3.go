@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/progress"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var s3Bucket string
+var s3Prefix string
+var s3Endpoint string
+var s3Region string
+var s3AccessKey string
+var s3SecretKey string
+var s3Insecure bool
+
+// s3Cmd represents the s3 command
+var s3Cmd = &cobra.Command{
+	Use:   "s3",
+	Short: "backup to S3 compatible object storage",
+	Long: "Backup to a bucket of S3 compatible object storage like AWS S3, " +
+		"MinIO or Ceph. Backup can be restored with s3://bucket/prefix as the " +
+		"file path.",
+	RunE: TakeS3Backup,
+
+	// Failed run is described by the logs
+	SilenceUsage: true,
+}
+
+func init() {
+	backupCmd.AddCommand(s3Cmd)
+
+	s3Cmd.Flags().StringVar(&s3Bucket, "bucket", "",
+		"bucket to write backup data to")
+	s3Cmd.MarkFlagRequired("bucket")
+	s3Cmd.Flags().StringVar(&s3Prefix, "prefix", "",
+		"prefix of the keys of all objects of the backup")
+	s3Cmd.Flags().StringVar(&incrementalFrom, "incremental-from", "",
+		"metadata file, archive path or S3 URL of the previous backup. Pages "+
+			"and Databases not edited since the previous backup are reused from it")
+
+	// Connection flags are used by restore and export to read backups from S3
+	// URLs as well
+	rootCmd.PersistentFlags().StringVar(&s3Endpoint, "s3-endpoint", "",
+		"endpoint of S3 compatible object storage. Plain HTTP is used for "+
+			"endpoint with http:// scheme (Default: "+rw.DEFAULT_S3_ENDPOINT+")")
+	rootCmd.PersistentFlags().StringVar(&s3Region, "s3-region", "",
+		"region of the bucket")
+	rootCmd.PersistentFlags().StringVar(&s3AccessKey, "s3-access-key", "",
+		"access key of S3 compatible object storage. Alternatively, one can set "+
+			"it as environment variable 'NTN_S3_ACCESS_KEY' or 'AWS_ACCESS_KEY_ID'")
+	rootCmd.PersistentFlags().StringVar(&s3SecretKey, "s3-secret-key", "",
+		"secret key of S3 compatible object storage. Alternatively, one can set "+
+			"it as environment variable 'NTN_S3_SECRET_KEY' or "+
+			"'AWS_SECRET_ACCESS_KEY'")
+	rootCmd.PersistentFlags().BoolVar(&s3Insecure, "s3-insecure", false,
+		"use plain HTTP to connect to S3 compatible object storage")
+}
+
+// Get the configuration to connect to S3 compatible object storage. Keys not
+// provided with flags are read from environment variables
+func getS3Config() *rw.S3Config {
+	if s3AccessKey == "" {
+		s3AccessKey = viper.GetString("s3_access_key")
+	}
+
+	if s3SecretKey == "" {
+		s3SecretKey = viper.GetString("s3_secret_key")
+	}
+
+	return &rw.S3Config{
+		Endpoint:  s3Endpoint,
+		Region:    s3Region,
+		Bucket:    s3Bucket,
+		Prefix:    s3Prefix,
+		AccessKey: s3AccessKey,
+		SecretKey: s3SecretKey,
+		Insecure:  s3Insecure,
+	}
+}
+
+func TakeS3Backup(cmd *cobra.Command, args []string) error {
+
+	validateNonEmptyNotionToken()
+	validateMutuallyExclusiveFlags()
+
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	pageUUIDs = utils.GetUniqueValues(pageUUIDs)
+	databaseUUIDs = utils.GetUniqueValues(databaseUUIDs)
+
+	cfg := &config.Config{
+		Token:                notionToken,
+		Operation_Type:       config.BACKUP,
+		PageUUIDs:            pageUUIDs,
+		DatabaseUUIDs:        databaseUUIDs,
+		PageTitles:           pageTitles,
+		DatabaseTitles:       databaseTitles,
+		TitleMatch:           titleMatch,
+		MaxRetries:           maxRetries,
+		RequestsPerSecond:    requestsPerSecond,
+		Concurrency:          concurrency,
+		SkipAssets:           skipAssets,
+		BackupComments:       backupComments,
+		ExcludePageUUIDs:     excludePageUUIDs,
+		ExcludeDatabaseUUIDs: excludeDatabaseUUIDs,
+		ExcludeTitleRegex:    excludeTitleRegex,
+		MaxDepth:             maxDepth,
+		ExcludeBlockTypes:    excludeBlockTypes,
+		IncrementalFrom:      incrementalFrom,
+		S3Config:             getS3Config(),
+		PassphraseFile:       passphraseFile,
+		KeyFile:              keyFile,
+	}
+
+	ctx, cancel := context.WithCancel(log.WithContext(context.Background()))
+	defer cancel()
+
+	m, err := startMetrics(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to start metrics")
+		return err
+	}
+
+	ctx, finishRun := recordRun(ctx, m, metrics.BACKUP)
+	ctx, stopProgress := trackProgress(ctx, progress.BACKUP)
+	err = cfg.Execute(ctx, config.InitializeBackup)
+	stopProgress()
+	finishRun(err)
+	return err
+}
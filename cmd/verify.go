@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/spf13/cobra"
+)
+
+var reportFile string
+
+// verifyCmd represents the verify command
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verify the integrity of the backup",
+	Long: "Verify that every object of the backup can be read, matches its " +
+		"recorded checksum and the hierarchy of the objects is complete. Report " +
+		"is written as JSON and the command exits with non-zero status if any " +
+		"check fails.",
+	RunE: Verify,
+
+	// Failed verification is described by the report and the logs
+	SilenceUsage:  true,
+	SilenceErrors: true,
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+
+	verifyCmd.Flags().StringVarP(&metadataFilePath, "file-path", "f", "",
+		"metadata file, archive path or S3 URL (s3://bucket/prefix) of the "+
+			"backup")
+	verifyCmd.MarkFlagRequired("file-path")
+	verifyCmd.Flags().StringVar(&reportFile, "report-file", "",
+		"file to write the JSON report to (Default: stdout)")
+}
+
+func Verify(cmd *cobra.Command, args []string) error {
+	log, err := getLogger()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize logger: %v\n", err)
+		return err
+	}
+
+	cfg := &config.Config{
+		Operation_Type:   config.VERIFY,
+		MetadataFilePath: metadataFilePath,
+		ReportFile:       reportFile,
+		S3Config:         getS3Config(),
+		PassphraseFile:   passphraseFile,
+		KeyFile:          keyFile,
+	}
+
+	ctx := log.WithContext(context.Background())
+
+	return cfg.Execute(ctx)
+}
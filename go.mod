@@ -5,11 +5,18 @@ go 1.16
 require (
 	github.com/google/uuid v1.6.0
 	github.com/jomei/notionapi v1.12.1
+	github.com/minio/minio-go/v7 v7.0.19
 	github.com/pkg/errors v0.9.1
+	github.com/prometheus/client_golang v1.12.2
+	github.com/robfig/cron/v3 v3.0.1
 	github.com/rs/zerolog v1.33.0
 	github.com/spf13/cobra v1.9.1
+	github.com/spf13/pflag v1.0.6
 	github.com/spf13/viper v1.16.0
 	github.com/stretchr/testify v1.10.0
+	golang.org/x/crypto v0.9.0
 	golang.org/x/term v0.29.0
+	golang.org/x/time v0.3.0
 	google.golang.org/protobuf v1.34.1
+	gopkg.in/yaml.v3 v3.0.1
 )
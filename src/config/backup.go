@@ -0,0 +1,354 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/resolver"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree/builder"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+func (c *Config) isS3Backup() bool {
+	return c.S3Config != nil && c.S3Config.Bucket != ""
+}
+
+// Helper function to parse the IDs of the objects given either as IDs or as
+// URLs copied from Notion app
+func parseNotionIds(objectType string, idList []string) ([]string, error) {
+	result := make([]string, 0, len(idList))
+	for _, idOrUrl := range idList {
+		id, err := utils.ParseNotionId(idOrUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s UUID or URL: %s", objectType,
+				idOrUrl)
+		}
+		result = append(result, id)
+	}
+	return result, nil
+}
+
+// Helper function to set the tree and ReaderWriter of the previous snapshot in
+// the tree builder request for incremental backup
+func setPreviousSnapshot(ctx context.Context, c *Config,
+	treeBuilderReq *builder.TreeBuilderRequest) error {
+	previousRW, metadataObj, err := getReaderWriterForMetadata(ctx, c,
+		c.IncrementalFrom)
+	if err != nil {
+		return err
+	}
+
+	previousTree, err := builder.GetMetaDataTreeBuilder(ctx,
+		metadataObj).BuildTree(ctx)
+	if err != nil {
+		return err
+	}
+
+	treeBuilderReq.PreviousTree = previousTree
+	treeBuilderReq.PreviousReaderWriter = previousRW
+	c.parentSnapshotId = metadataObj.SnapshotId
+	return nil
+}
+
+// Helper function to get the path of the parent snapshot relative to the
+// directory of the metadata file of the new snapshot, so that the snapshots
+// remain linked when they are moved together. Snapshots of the object pool
+// are all stored one level below its snapshots directory. S3 URLs are
+// recorded as is
+func (c *Config) getParentSnapshotPath() string {
+	if c.isS3Backup() || rw.IsS3URL(c.IncrementalFrom) {
+		return c.IncrementalFrom
+	}
+
+	metadataDirPath := c.Dir
+	if c.Dedup {
+		metadataDirPath = filepath.Join(c.Dir, rw.SNAPSHOTS_DIR_NAME,
+			rw.SNAPSHOT_NAME_FORMAT)
+	}
+
+	relPath, err := filepath.Rel(metadataDirPath, c.IncrementalFrom)
+	if err != nil {
+		return c.IncrementalFrom
+	}
+	return relPath
+}
+
+// Helper function to wrap the ReaderWriter of the config to encrypt the backup
+// if passphrase file or key file is provided. Key of the previous snapshot is
+// reused if it is encrypted, so that its objects can be reused as is
+func setEncryption(c *Config, previousRW rw.ReaderWriter) error {
+	keySource, err := getKeySource(c)
+	if err != nil || keySource == nil {
+		return err
+	}
+
+	var key *rw.EncryptionKey
+	if encryptedRW, ok := previousRW.(*rw.EncryptedReaderWriter); ok {
+		key = encryptedRW.GetEncryptionKey()
+	} else {
+		key, err = keySource.NewEncryptionKey()
+		if err != nil {
+			return err
+		}
+	}
+
+	c.ReaderWriter, err = rw.GetEncryptedReaderWriter(c.ReaderWriter, key)
+	return err
+}
+
+// Helper function to add the IDs of the Pages and Databases matching the names
+// to the IDs of the objects backed up
+func (c *Config) resolveTitles(ctx context.Context) error {
+	titleResolver := resolver.GetResolver(c.NotionClient,
+		resolver.MatchMode(c.TitleMatch))
+	for _, title := range c.PageTitles {
+		id, err := titleResolver.ResolvePage(ctx, title)
+		if err != nil {
+			return err
+		}
+		c.PageUUIDs = append(c.PageUUIDs, id)
+	}
+
+	for _, title := range c.DatabaseTitles {
+		id, err := titleResolver.ResolveDatabase(ctx, title)
+		if err != nil {
+			return err
+		}
+		c.DatabaseUUIDs = append(c.DatabaseUUIDs, id)
+	}
+
+	return nil
+}
+
+func InitializeBackup(ctx context.Context, c *Config) {
+	log := zerolog.Ctx(ctx)
+	var err error
+	if c.isS3Backup() {
+		c.ReaderWriter, err = rw.GetS3ReaderWriter(ctx, c.S3Config)
+	} else if c.Dedup {
+		c.ReaderWriter, err = rw.GetContentAddressedReaderWriter(ctx, c.Dir,
+			c.Create_Dir)
+	} else if c.ArchiveFormat != "" {
+		c.ReaderWriter, err = rw.GetArchiveReaderWriter(ctx, c.Dir, c.Create_Dir,
+			c.ArchiveFormat)
+	} else {
+		c.ReaderWriter, err = rw.GetFileReaderWriter(ctx, c.Dir, c.Create_Dir)
+	}
+	if err != nil {
+		log.Panic().Err(err).Msg("Failed to create ReaderWriter instance")
+	}
+
+	c.NotionClient = getNotionClient(ctx, c)
+
+	err = c.resolveTitles(ctx)
+	if err != nil {
+		log.Panic().Err(err).Msg("Failed to resolve the Page and Database names")
+	}
+
+	treeBuilderReq := &builder.TreeBuilderRequest{
+		PageIdList:     c.PageUUIDs,
+		DatabaseIdList: c.DatabaseUUIDs,
+		Concurrency:    c.Concurrency,
+		DownloadAssets: !c.SkipAssets,
+		BackupComments: c.BackupComments,
+		Filter:         c.filter,
+	}
+
+	if c.IncrementalFrom != "" {
+		err = setPreviousSnapshot(ctx, c, treeBuilderReq)
+		if err != nil {
+			log.Panic().Err(err).Msg("Failed to read the previous snapshot")
+		}
+	}
+
+	err = setEncryption(c, treeBuilderReq.PreviousReaderWriter)
+	if err != nil {
+		log.Panic().Err(err).Msg("Failed to initialize encryption")
+	}
+
+	c.TreeBuilder = builder.GetExportTreebuilder(ctx, c.NotionClient,
+		c.ReaderWriter, treeBuilderReq)
+}
+
+func (c *Config) validateBackupConfig() error {
+	if c.Token == "" {
+		return fmt.Errorf("notion secret token not provided")
+	}
+
+	var err error
+	if c.Dedup && c.ArchiveFormat != "" {
+		return fmt.Errorf("archive format is not supported for deduplicated " +
+			"backup")
+	}
+
+	// Encrypted objects differ on every write because of the random nonce, so
+	// identical objects would never be deduplicated
+	if c.Dedup && (c.PassphraseFile != "" || c.KeyFile != "") {
+		return fmt.Errorf("encryption is not supported for deduplicated backup")
+	}
+
+	if c.isS3Backup() {
+		if c.ArchiveFormat != "" || c.Dedup {
+			return fmt.Errorf("archive format and deduplication are not " +
+				"supported for S3 backup")
+		}
+	} else {
+		if c.Dir == "" {
+			c.Dir = "./"
+		}
+
+		c.Dir, err = filepath.Abs(c.Dir)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.ArchiveFormat != "" && c.ArchiveFormat != rw.TAR_GZ_FORMAT &&
+		c.ArchiveFormat != rw.ZIP_FORMAT {
+		return fmt.Errorf("unsupported archive format: %s", c.ArchiveFormat)
+	}
+
+	if c.IncrementalFrom != "" {
+		c.IncrementalFrom, err = getAbsBackupPath(c.IncrementalFrom)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.validateKeyFiles()
+	if err != nil {
+		return err
+	}
+
+	c.PageUUIDs, err = parseNotionIds("Page", c.PageUUIDs)
+	if err != nil {
+		return err
+	}
+
+	c.DatabaseUUIDs, err = parseNotionIds("Database", c.DatabaseUUIDs)
+	if err != nil {
+		return err
+	}
+
+	if c.TitleMatch == "" {
+		c.TitleMatch = string(resolver.EXACT_MATCH)
+	}
+
+	if !resolver.MatchMode(c.TitleMatch).IsValid() {
+		return fmt.Errorf("unsupported title match mode: %s", c.TitleMatch)
+	}
+
+	return c.validateFilter()
+}
+
+// Helper function to validate the filters of the backup and compile them to
+// the filter of the tree builder
+func (c *Config) validateFilter() error {
+	var err error
+	c.ExcludePageUUIDs, err = parseNotionIds("Page", c.ExcludePageUUIDs)
+	if err != nil {
+		return err
+	}
+
+	c.ExcludeDatabaseUUIDs, err = parseNotionIds("Database",
+		c.ExcludeDatabaseUUIDs)
+	if err != nil {
+		return err
+	}
+
+	if c.MaxDepth < 0 {
+		return fmt.Errorf("max depth cannot be negative: %d", c.MaxDepth)
+	}
+
+	for _, blockType := range c.ExcludeBlockTypes {
+		if !builder.IsKnownBlockType(blockType) {
+			return fmt.Errorf("unknown block type: %s", blockType)
+		}
+	}
+
+	c.filter = &builder.Filter{
+		ExcludePageIds:     c.ExcludePageUUIDs,
+		ExcludeDatabaseIds: c.ExcludeDatabaseUUIDs,
+		MaxDepth:           c.MaxDepth,
+		ExcludeBlockTypes:  c.ExcludeBlockTypes,
+	}
+
+	if c.ExcludeTitleRegex != "" {
+		c.filter.ExcludeTitleRegex, err = regexp.Compile(c.ExcludeTitleRegex)
+		if err != nil {
+			return fmt.Errorf("invalid title regex: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func (c *Config) executeBackup(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	// Snapshot is identified by the time at which backup started, so that the
+	// nightly backup belongs to the day it was started on
+	snapshotId := uuid.NewString()
+	metadataOpts := []exporter.MetadataOption{
+		exporter.WithSnapshot(snapshotId, time.Now().UTC()),
+	}
+
+	// Summary is written once the outcome of the run is known, failed runs
+	// included
+	recorder := metrics.Ctx(ctx)
+	recorder.SetSnapshotId(snapshotId)
+	recorder.OnFinish(func(summary *metrics.Summary) {
+		err := rw.WriteSummary(ctx, c.ReaderWriter, summary)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to write the summary of the run")
+		}
+	})
+
+	tree, err := c.TreeBuilder.BuildTree(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build the notion object tree")
+		return err
+	}
+
+	if c.IncrementalFrom != "" {
+		metadataOpts = append(metadataOpts,
+			exporter.WithParentSnapshot(c.getParentSnapshotPath(),
+				c.parentSnapshotId))
+	}
+
+	if !c.filter.IsEmpty() {
+		metadataOpts = append(metadataOpts,
+			exporter.WithFilter(c.filter.ToProto()))
+	}
+
+	log.Info().Msg("Creating metadata of the exported data")
+	err = exporter.ExportTree(ctx, c.ReaderWriter, tree, metadataOpts...)
+	if err != nil {
+		log.Error().Err(err).Msg(
+			"Failed to create the metadata of the exported data. Cleaning up...")
+
+		err2 := c.ReaderWriter.CleanUp(ctx)
+		if err2 != nil {
+			log.Warn().Err(err2).Msg(
+				"Failed to cleanup the exported data. Manual cleanup may be required")
+		} else {
+			log.Info().Msg("Cleanup successful")
+		}
+
+		return err
+	}
+
+	log.Info().Msg("Backup successful")
+	return nil
+}
+
+// Backup is written to S3 if the bucket is provided. S3Config without bucket
+// is only used to connect to S3 for reading backups from S3 URLs
@@ -0,0 +1,351 @@
+package config_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInitializeBackup(t *testing.T) {
+	t.Run("Valid export data path", func(t *testing.T) {
+		cfg := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			Dir:            TESTDATAPATH,
+			Create_Dir:     false,
+		}
+
+		config.InitializeBackup(context.Background(), cfg)
+
+		assert.NotNil(t, cfg.NotionClient)
+		assert.NotNil(t, cfg.ReaderWriter)
+		assert.NotNil(t, cfg.TreeBuilder)
+	})
+
+	t.Run("Directory does not exist", func(t *testing.T) {
+		defer func() {
+			r := recover()
+			assert.NotNilf(t, r, "Panic Recovering")
+		}()
+		cfg := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			Dir:            NON_EXISTING_DIR,
+			Create_Dir:     false,
+		}
+
+		config.InitializeBackup(context.Background(), cfg)
+	})
+}
+
+func TestExecuteBackup(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty token", func(t *testing.T) {
+		config := &config.Config{
+			Token:          "",
+			Operation_Type: config.BACKUP,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: invalid page UUID", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.BACKUP,
+			Token:          MOCKED_TOKEN,
+			Dir:            "",
+			PageUUIDs:      []string{"05034203-2870-4bc8-b1f9-22c0ae6e56b"},
+		}
+
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: invalid database UUID", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.BACKUP,
+			Token:          MOCKED_TOKEN,
+			Dir:            "",
+			DatabaseUUIDs:  []string{"05034203-2870-4bc8-b1f9-22c0ae6e56b"},
+		}
+
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: archive format for S3", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.BACKUP,
+			Token:          MOCKED_TOKEN,
+			ArchiveFormat:  rw.ZIP_FORMAT,
+			S3Config:       &rw.S3Config{Bucket: "bucket"},
+		}
+
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: encryption for dedup", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.BACKUP,
+			Token:          MOCKED_TOKEN,
+			Dir:            TESTDATAPATH,
+			Dedup:          true,
+			PassphraseFile: "passphrase",
+		}
+
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+		assert.Contains(err.Error(), "encryption")
+	})
+
+	t.Run("Invalid config: unsupported title match", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.BACKUP,
+			Token:          MOCKED_TOKEN,
+			PageTitles:     []string{"Notes"},
+			TitleMatch:     "fuzzy",
+		}
+
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Page and Database URLs", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedRW.On("WriteMetaData", context.Background(), mock.Anything).
+			Return(nil)
+		mockedRW.On("GetStorageConfig", context.Background()).Return(
+			&metadata.StorageConfig{}, nil)
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{
+				RootNode: node.CreateRootNode(),
+			}, nil)
+
+		cfg := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			PageUUIDs: []string{"https://www.notion.so/workspace/Notes-" +
+				"0503420328704bc8b1f922c0ae6e56ba"},
+			DatabaseUUIDs: []string{"https://www.notion.so/workspace/" +
+				"5ed2d97a510a4756b113cc28c7a30fd7?v=9cd00ee963e54dadb0aad76f2ecc36d1"},
+			Dir: TESTDATAPATH,
+		}
+
+		ctx := context.Background()
+		err := cfg.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.Nil(err)
+		assert.Equal([]string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba"},
+			cfg.PageUUIDs)
+		assert.Equal([]string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7"},
+			cfg.DatabaseUUIDs)
+	})
+
+	t.Run("Invalid config: invalid filters", func(t *testing.T) {
+		for _, c := range []*config.Config{
+			{ExcludePageUUIDs: []string{"05034203-2870-4bc8-b1f9-22c0ae6e56b"}},
+			{ExcludeDatabaseUUIDs: []string{"invalid"}},
+			{ExcludeTitleRegex: "(Archive"},
+			{MaxDepth: -1},
+			{ExcludeBlockTypes: []string{"link-preview"}},
+		} {
+			c.Operation_Type = config.BACKUP
+			c.Token = MOCKED_TOKEN
+			err := c.Execute(context.Background())
+			assert.NotNil(err)
+		}
+	})
+
+	t.Run("Filter recorded in metadata", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedRW.On("WriteMetaData", context.Background(), mock.MatchedBy(
+			func(metadataObj *metadata.MetaData) bool {
+				return metadataObj.Filter != nil &&
+					metadataObj.Filter.ExcludeTitleRegex == "^Archive" &&
+					metadataObj.Filter.MaxDepth == 2
+			})).Return(nil)
+		mockedRW.On("GetStorageConfig", context.Background()).Return(
+			&metadata.StorageConfig{}, nil)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{
+				RootNode: node.CreateRootNode(),
+			}, nil)
+
+		config := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.BACKUP,
+			Dir:               TESTDATAPATH,
+			ExcludeTitleRegex: "^Archive",
+			MaxDepth:          2,
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.Nil(err)
+	})
+
+	t.Run("Error while building tree", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			nil, errGeneric)
+
+		config := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			PageUUIDs: []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba",
+				"53d18605-7779-4700-b16d-662a332283a1"},
+			DatabaseUUIDs: []string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7",
+				"9cd00ee9-63e5-4dad-b0aa-d76f2ecc36d1"},
+			Dir:        TESTDATAPATH,
+			Create_Dir: false,
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.NotNil(err)
+	})
+
+	t.Run("Error while writing metadata", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{
+				RootNode: node.CreateRootNode(),
+			}, nil)
+
+		mockedRW.On("GetStorageConfig", context.Background()).Return(
+			&metadata.StorageConfig{}, nil)
+		mockedRW.On("WriteMetaData", context.Background(), mock.Anything).Return(
+			errGeneric)
+
+		mockedRW.On("CleanUp", context.Background()).Return(nil)
+
+		config := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			PageUUIDs: []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba",
+				"53d18605-7779-4700-b16d-662a332283a1"},
+			DatabaseUUIDs: []string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7",
+				"9cd00ee9-63e5-4dad-b0aa-d76f2ecc36d1"},
+			Dir:        TESTDATAPATH,
+			Create_Dir: false,
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.NotNil(err)
+	})
+
+	t.Run("Error while cleanup", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{
+				RootNode: node.CreateRootNode(),
+			}, nil)
+
+		mockedRW.On("GetStorageConfig", context.Background()).Return(
+			&metadata.StorageConfig{}, nil)
+
+		mockedRW.On("WriteMetaData", context.Background(), mock.Anything).Return(
+			errGeneric)
+
+		mockedRW.On("CleanUp", context.Background()).Return(errGeneric)
+
+		config := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			PageUUIDs: []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba",
+				"53d18605-7779-4700-b16d-662a332283a1"},
+			DatabaseUUIDs: []string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7",
+				"9cd00ee9-63e5-4dad-b0aa-d76f2ecc36d1"},
+			Dir:        TESTDATAPATH,
+			Create_Dir: false,
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.NotNil(err)
+	})
+
+	t.Run("Valid config", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedRW.On("WriteMetaData", context.Background(), mock.MatchedBy(
+			func(metadataObj *metadata.MetaData) bool {
+				return metadataObj.SnapshotId != "" &&
+					metadataObj.CreatedAt != nil
+			})).Return(nil)
+		mockedRW.On("GetStorageConfig", context.Background()).Return(
+			&metadata.StorageConfig{}, nil)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{
+				RootNode: node.CreateRootNode(),
+			}, nil)
+
+		config := &config.Config{
+			Token:          MOCKED_TOKEN,
+			Operation_Type: config.BACKUP,
+			PageUUIDs: []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba",
+				"53d18605-7779-4700-b16d-662a332283a1"},
+			DatabaseUUIDs: []string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7",
+				"9cd00ee9-63e5-4dad-b0aa-d76f2ecc36d1"},
+			Dir:        TESTDATAPATH,
+			Create_Dir: false,
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.Nil(err)
+	})
+}
@@ -9,7 +9,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/jomei/notionapi"
 	"github.com/rs/zerolog"
-	"github.com/shivaji17/notionbackup/src/exporter"
 	"github.com/shivaji17/notionbackup/src/importer"
 	"github.com/shivaji17/notionbackup/src/logging"
 	"github.com/shivaji17/notionbackup/src/metadata"
@@ -25,55 +24,154 @@ const (
 	UNKNOWN OperationType = "UNKNOWN"
 	BACKUP  OperationType = "BACKUP"
 	RESTORE OperationType = "RESTORE"
+
+	MARKDOWN_EXPORT OperationType = "MARKDOWN_EXPORT"
+	DATABASE_EXPORT OperationType = "DATABASE_EXPORT"
+	GC              OperationType = "GC"
+	VERIFY          OperationType = "VERIFY"
+	DIFF            OperationType = "DIFF"
+	PRUNE           OperationType = "PRUNE"
+
+	REPORT_FILE_PERM = 0644
+
+	JOURNAL_FILE_NAME_FORMAT         = "restore-%s.journal"
+	SUBTREE_JOURNAL_FILE_NAME_FORMAT = "restore-%s-%s.journal"
+
+	TABLE_REPORT_FORMAT = "table"
+	JSON_REPORT_FORMAT  = "json"
 )
 
 type ConfigOption func(context.Context, *Config)
 
-func InitializeBackup(ctx context.Context, c *Config) {
-	log := zerolog.Ctx(ctx)
-	var err error
-	c.ReaderWriter, err = rw.GetFileReaderWriter(ctx, c.Dir, c.Create_Dir)
+// Helper function to create NotionClient which limits the rate of requests
+// sent to Notion API and retries the requests failed with transient errors
+func getNotionClient(ctx context.Context,
+	c *Config) notionclient.NotionClient {
+	apiClient := notionclient.GetNotionApiClient(ctx, notionapi.Token(c.Token),
+		notionapi.NewClient, notionclient.WithRetryableTransport(nil))
+
+	return notionclient.GetRetryingNotionClient(ctx, apiClient,
+		&notionclient.RetryConfig{
+			MaxRetries:        c.MaxRetries,
+			RequestsPerSecond: c.RequestsPerSecond,
+		})
+}
+
+// Helper function to read the metadata file
+func readMetadata(metadataFilePath string) (*metadata.MetaData, error) {
+	dat, err := os.ReadFile(metadataFilePath)
+	if err != nil {
+		return nil, err
+	}
+
+	metadataObj := &metadata.MetaData{}
+	err = proto.Unmarshal(dat, metadataObj)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to create ReaderWriter instance")
+		return nil, err
 	}
 
-	c.NotionClient = notionclient.GetNotionApiClient(ctx,
-		notionapi.Token(c.Token), notionapi.NewClient)
+	return metadataObj, nil
+}
 
-	treeBuilderReq := &builder.TreeBuilderRequest{
-		PageIdList:     c.PageUUIDs,
-		DatabaseIdList: c.DatabaseUUIDs,
+// Helper function to get the source of the encryption key. nil is returned if
+// neither passphrase file nor key file is provided
+func getKeySource(c *Config) (*rw.KeySource, error) {
+	if c.PassphraseFile != "" {
+		return rw.GetKeySourceFromPassphraseFile(c.PassphraseFile)
 	}
 
-	c.TreeBuilder = builder.GetExportTreebuilder(ctx, c.NotionClient,
-		c.ReaderWriter, treeBuilderReq)
+	if c.KeyFile != "" {
+		return rw.GetKeySourceFromKeyFile(c.KeyFile)
+	}
+
+	return nil, nil
 }
 
-func InitializeRestore(ctx context.Context, c *Config) {
-	log := zerolog.Ctx(ctx)
+// Helper function to read the metadata of the backup and create the
+// ReaderWriter to read the objects of the backup. Encrypted backups are
+// decrypted transparently with the passphrase or key file of the config
+func getReaderWriterForMetadata(ctx context.Context, c *Config,
+	metadataFilePath string) (rw.ReaderWriter, *metadata.MetaData, error) {
+	rwClient, metadataObj, err := openBackup(ctx, c, metadataFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	dat, err := os.ReadFile(c.MetadataFilePath)
+	if metadataObj.GetEncryptionConfig() == nil {
+		return rwClient, metadataObj, nil
+	}
+
+	keySource, err := getKeySource(c)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to create ReaderWriter instance")
+		return nil, nil, err
 	}
 
-	metadataObj := &metadata.MetaData{}
-	err = proto.Unmarshal(dat, metadataObj)
+	if keySource == nil {
+		return nil, nil, fmt.Errorf("backup is encrypted, passphrase file or " +
+			"key file not provided")
+	}
+
+	key, err := keySource.GetEncryptionKey(metadataObj.GetEncryptionConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadataObj, err = rw.DecryptMetaData(key, metadataObj)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to read metadata file data")
+		return nil, nil, err
+	}
+
+	rwClient, err = rw.GetEncryptedReaderWriter(rwClient, key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rwClient, metadataObj, nil
+}
+
+// Helper function to read the metadata of the backup as stored and create the
+// ReaderWriter to read the objects of the backup. Backup is either a metadata
+// file, an archive containing the metadata file or an S3 URL of the prefix
+// under which backup is stored
+func openBackup(ctx context.Context, c *Config,
+	metadataFilePath string) (rw.ReaderWriter, *metadata.MetaData, error) {
+	if rw.IsS3URL(metadataFilePath) {
+		bucket, prefix, err := rw.ParseS3URL(metadataFilePath)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		s3Config := &rw.S3Config{}
+		if c.S3Config != nil {
+			*s3Config = *c.S3Config
+		}
+		s3Config.Bucket = bucket
+		s3Config.Prefix = prefix
+		return rw.GetS3ReaderWriterForMetadata(ctx, s3Config)
 	}
 
-	c.ReaderWriter, err = rw.GetFileReaderWriterForMetadata(ctx,
-		c.MetadataFilePath, metadataObj)
+	if rw.GetArchiveFormat(metadataFilePath) != "" {
+		return rw.GetArchiveReaderWriterForArchive(ctx, metadataFilePath)
+	}
 
+	metadataObj, err := readMetadata(metadataFilePath)
 	if err != nil {
-		log.Panic().Err(err).Msg("Failed to create ReaderWriter instance")
+		return nil, nil, err
 	}
 
-	c.NotionClient = notionclient.GetNotionApiClient(ctx,
-		notionapi.Token(c.Token), notionapi.NewClient)
+	var rwClient rw.ReaderWriter
+	if metadataObj.GetStorageConfig().GetContentAddressed() != nil {
+		rwClient, err = rw.GetContentAddressedReaderWriterForMetadata(ctx,
+			metadataFilePath, metadataObj)
+	} else {
+		rwClient, err = rw.GetFileReaderWriterForMetadata(ctx, metadataFilePath,
+			metadataObj)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
 
-	c.TreeBuilder = builder.GetMetaDataTreeBuilder(ctx, metadataObj)
+	return rwClient, metadataObj, nil
 }
 
 type Config struct {
@@ -88,6 +186,69 @@ type Config struct {
 	TreeBuilder       builder.TreeBuilder
 	MetadataFilePath  string
 	RestoreToPageUUID string
+	MaxRetries        int
+	RequestsPerSecond float64
+	Concurrency       int
+	IncrementalFrom   string
+	ArchiveFormat     string
+	OutputDir         string
+	ExportFormat      string
+	S3Config          *rw.S3Config
+	PassphraseFile    string
+	KeyFile           string
+	Dedup             bool
+	DryRun            bool
+	ReportFile        string
+	JournalPath       string
+	Resume            bool
+	ReportFormat      string
+	SubtreeNodeUuid   string
+	SubtreeNotionId   string
+	SubtreeTitle      string
+	MissingLinkPolicy string
+	SkipAssets        bool
+	BackupComments    bool
+	AssetBucketURL    string
+	AssetFallbackURL  string
+	AssetUploader     importer.AssetUploader
+
+	// Snapshot compared with the snapshot of MetadataFilePath by diff
+	OldMetadataFilePath string
+	TextDiff            bool
+
+	// Retention policy with which snapshots in Dir are pruned
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+
+	// Names of the Pages and Databases backed up, resolved to their IDs through
+	// search. Titles are matched with the names in TitleMatch mode
+	PageTitles     []string
+	DatabaseTitles []string
+	TitleMatch     string
+
+	// Filters of the objects included in the backup
+	ExcludePageUUIDs     []string
+	ExcludeDatabaseUUIDs []string
+	ExcludeTitleRegex    string
+	MaxDepth             int
+	ExcludeBlockTypes    []string
+
+	// ID of the snapshot of IncrementalFrom, set while reading it
+	parentSnapshotId string
+
+	// Filter of the backup, set while validating the backup config
+	filter *builder.Filter
+}
+
+// Helper function to get the absolute path of the backup. S3 URLs are
+// returned as is
+func getAbsBackupPath(backupPath string) (string, error) {
+	if rw.IsS3URL(backupPath) {
+		return backupPath, nil
+	}
+
+	return filepath.Abs(backupPath)
 }
 
 func validateUUIDs(objectType string, uuidList []string) error {
@@ -99,109 +260,101 @@ func validateUUIDs(objectType string, uuidList []string) error {
 	return nil
 }
 
-func (c *Config) validateBackupConfig() error {
-	if c.Token == "" {
-		return fmt.Errorf("notion secret token not provided")
-	}
-
-	if c.Dir == "" {
-		c.Dir = "./"
+func (c *Config) validateKeyFiles() error {
+	if c.PassphraseFile != "" && c.KeyFile != "" {
+		return fmt.Errorf("passphrase file and key file are mutually exclusive")
 	}
+	return nil
+}
 
-	dir, err := filepath.Abs(c.Dir)
-	if err != nil {
-		return err
+// Helper function to validate the format of the report, defaulting to table
+func (c *Config) validateReportFormat() error {
+	if c.ReportFormat == "" {
+		c.ReportFormat = TABLE_REPORT_FORMAT
 	}
-	c.Dir = dir
 
-	err = validateUUIDs("Page", c.PageUUIDs)
-	if err != nil {
-		return err
-	}
-
-	err = validateUUIDs("Database", c.DatabaseUUIDs)
-	if err != nil {
-		return err
+	if c.ReportFormat != TABLE_REPORT_FORMAT &&
+		c.ReportFormat != JSON_REPORT_FORMAT {
+		return fmt.Errorf("unsupported report format: %s", c.ReportFormat)
 	}
 
 	return nil
 }
 
-func (c *Config) executeBackup(ctx context.Context) error {
+func (c *Config) execute(ctx context.Context, opts ...ConfigOption) error {
 	log := zerolog.Ctx(ctx)
+	if c.Operation_Type == BACKUP {
+		err := c.validateBackupConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
+		}
 
-	tree, err := c.TreeBuilder.BuildTree(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to build the notion object tree")
-		return err
-	}
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
 
-	log.Info().Msg("Creating metadata of the exported data")
-	err = exporter.ExportTree(ctx, c.ReaderWriter, tree)
-	if err != nil {
-		log.Error().Err(err).Msg(
-			"Failed to create the metadata of the exported data. Cleaning up...")
+		log.Info().Msg("Starting backup operation")
 
-		err2 := c.ReaderWriter.CleanUp(ctx)
-		if err2 != nil {
-			log.Warn().Err(err2).Msg(
-				"Failed to cleanup the exported data. Manual cleanup may be required")
-		} else {
-			log.Info().Msg("Cleanup successful")
+		return c.executeBackup(ctx)
+	} else if c.Operation_Type == RESTORE {
+		err := c.validateRestoreConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
 		}
 
-		return err
-	}
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
 
-	log.Info().Msg("Backup successful")
-	return nil
-}
+		log.Info().Msg("Starting restore operation")
 
-func (c *Config) validateRestoreConfig() error {
-	if c.Token == "" {
-		return fmt.Errorf("notion secret token not provided")
-	}
+		return c.executeRestore(ctx)
+	} else if c.Operation_Type == MARKDOWN_EXPORT {
+		err := c.validateExportConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
+		}
 
-	metadataFilePath, err := filepath.Abs(c.MetadataFilePath)
-	if err != nil {
-		return err
-	}
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
 
-	err = validateUUIDs("Page", []string{c.RestoreToPageUUID})
-	if err != nil {
-		return err
-	}
+		log.Info().Msg("Starting markdown export operation")
 
-	c.MetadataFilePath = metadataFilePath
-	return nil
-}
+		return c.executeMarkdownExport(ctx)
+	} else if c.Operation_Type == DATABASE_EXPORT {
+		err := c.validateDatabaseExportConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
+		}
 
-func (c *Config) executeRestore(ctx context.Context) error {
-	log := zerolog.Ctx(ctx)
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
 
-	tree, err := c.TreeBuilder.BuildTree(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to build the notion object tree")
-		return err
-	}
+		log.Info().Msg("Starting database export operation")
 
-	log.Info().Msg("Starting data import...")
-	importerObj := importer.GetImporter(c.ReaderWriter, c.NotionClient,
-		c.RestoreToPageUUID, tree)
-	err = importerObj.ImportObjects(ctx)
-	if err != nil {
-		log.Error().Err(err).Msg("Failed to import data to Notion")
-		return err
-	}
+		return c.executeDatabaseExport(ctx)
+	} else if c.Operation_Type == GC {
+		err := c.validateGCConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
+		}
 
-	log.Info().Msg("Restore successful")
-	return nil
-}
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
 
-func (c *Config) execute(ctx context.Context, opts ...ConfigOption) error {
-	log := zerolog.Ctx(ctx)
-	if c.Operation_Type == BACKUP {
-		err := c.validateBackupConfig()
+		log.Info().Msg("Starting garbage collection operation")
+
+		return c.executeGC(ctx)
+	} else if c.Operation_Type == VERIFY {
+		err := c.validateVerifyConfig()
 		if err != nil {
 			log.Error().Err(err).Msg(logging.ValidationErr)
 			return err
@@ -211,11 +364,11 @@ func (c *Config) execute(ctx context.Context, opts ...ConfigOption) error {
 			opt(ctx, c)
 		}
 
-		log.Info().Msg("Starting backup operation")
+		log.Info().Msg("Starting verify operation")
 
-		return c.executeBackup(ctx)
-	} else if c.Operation_Type == RESTORE {
-		err := c.validateRestoreConfig()
+		return c.executeVerify(ctx)
+	} else if c.Operation_Type == DIFF {
+		err := c.validateDiffConfig()
 		if err != nil {
 			log.Error().Err(err).Msg(logging.ValidationErr)
 			return err
@@ -225,9 +378,23 @@ func (c *Config) execute(ctx context.Context, opts ...ConfigOption) error {
 			opt(ctx, c)
 		}
 
-		log.Info().Msg("Starting restore operation")
+		log.Info().Msg("Starting diff operation")
 
-		return c.executeRestore(ctx)
+		return c.executeDiff(ctx)
+	} else if c.Operation_Type == PRUNE {
+		err := c.validatePruneConfig()
+		if err != nil {
+			log.Error().Err(err).Msg(logging.ValidationErr)
+			return err
+		}
+
+		for _, opt := range opts {
+			opt(ctx, c)
+		}
+
+		log.Info().Msg("Starting prune operation")
+
+		return c.executePrune(ctx)
 	}
 
 	err := fmt.Errorf("unknown operation type provided: %s", c.Operation_Type)
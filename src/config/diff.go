@@ -0,0 +1,101 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/differ"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/builder"
+)
+
+func (c *Config) validateDiffConfig() error {
+	if c.OldMetadataFilePath == "" || c.MetadataFilePath == "" {
+		return fmt.Errorf("metadata file paths of both snapshots not provided")
+	}
+
+	oldMetadataFilePath, err := getAbsBackupPath(c.OldMetadataFilePath)
+	if err != nil {
+		return err
+	}
+	c.OldMetadataFilePath = oldMetadataFilePath
+
+	metadataFilePath, err := getAbsBackupPath(c.MetadataFilePath)
+	if err != nil {
+		return err
+	}
+	c.MetadataFilePath = metadataFilePath
+
+	err = c.validateReportFormat()
+	if err != nil {
+		return err
+	}
+
+	return c.validateKeyFiles()
+}
+
+// Helper function to read the snapshot and build its tree for diff
+func (c *Config) readSnapshot(ctx context.Context,
+	metadataFilePath string) (rw.ReaderWriter, *tree.Tree, error) {
+	rwClient, metadataObj, err := getReaderWriterForMetadata(ctx, c,
+		metadataFilePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tree, err := builder.GetMetaDataTreeBuilder(ctx, metadataObj).BuildTree(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rwClient, tree, nil
+}
+
+// Compare the old snapshot with the new snapshot and print the changes
+func (c *Config) executeDiff(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	oldRW, oldTree, err := c.readSnapshot(ctx, c.OldMetadataFilePath)
+	if err != nil {
+		log.Error().Err(err).Str(logging.MetaDataFilePath,
+			c.OldMetadataFilePath).Msg("Failed to read the snapshot")
+		return err
+	}
+
+	newRW, newTree, err := c.readSnapshot(ctx, c.MetadataFilePath)
+	if err != nil {
+		log.Error().Err(err).Str(logging.MetaDataFilePath,
+			c.MetadataFilePath).Msg("Failed to read the snapshot")
+		return err
+	}
+
+	opts := []differ.DifferOption{}
+	if c.TextDiff {
+		opts = append(opts, differ.WithTextDiff())
+	}
+
+	report, err := differ.GetDiffer(oldRW, oldTree, newRW, newTree, opts...).
+		Diff(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to compare the snapshots")
+		return err
+	}
+	report.OldMetadataFilePath = c.OldMetadataFilePath
+	report.NewMetadataFilePath = c.MetadataFilePath
+
+	if c.ReportFormat == JSON_REPORT_FORMAT {
+		dataBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(dataBytes, '\n'))
+		return err
+	}
+
+	return report.WriteTable(os.Stdout)
+}
@@ -0,0 +1,69 @@
+package config_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteDiff(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty file path", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type:   config.DIFF,
+			MetadataFilePath: METADATA_FILEPATH,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: unsupported report format", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type:      config.DIFF,
+			OldMetadataFilePath: METADATA_FILEPATH,
+			MetadataFilePath:    METADATA_FILEPATH,
+			ReportFormat:        "xml",
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Valid snapshots", func(t *testing.T) {
+		ctx := context.Background()
+		dirs := []string{}
+		for _, title := range []string{"old", "new"} {
+			dir := t.TempDir()
+			writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+			assert.Nil(err)
+
+			pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID("page_" + title),
+			}, writer)
+			assert.Nil(err)
+			rootNode := node.CreateRootNode()
+			rootNode.AddChild(pageNode)
+			err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+			assert.Nil(err)
+			dirs = append(dirs, dir)
+		}
+
+		config := &config.Config{
+			Operation_Type:      config.DIFF,
+			OldMetadataFilePath: filepath.Join(dirs[0], rw.METADATA_FILE_NAME),
+			MetadataFilePath:    filepath.Join(dirs[1], rw.METADATA_FILE_NAME),
+			ReportFormat:        config.JSON_REPORT_FORMAT,
+		}
+		err := config.Execute(ctx)
+		assert.Nil(err)
+	})
+}
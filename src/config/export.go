@@ -0,0 +1,110 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/dbexport"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/markdown"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/tree/builder"
+)
+
+// Initialize the ReaderWriter and TreeBuilder to read the backup offline
+func InitializeExport(ctx context.Context, c *Config) {
+	log := zerolog.Ctx(ctx)
+
+	var metadataObj *metadata.MetaData
+	var err error
+	c.ReaderWriter, metadataObj, err = getReaderWriterForMetadata(ctx, c,
+		c.MetadataFilePath)
+	if err != nil {
+		log.Panic().Err(err).Msg("Failed to read the backup")
+	}
+
+	c.TreeBuilder = builder.GetMetaDataTreeBuilder(ctx, metadataObj)
+}
+
+func (c *Config) validateExportConfig() error {
+	if c.MetadataFilePath == "" {
+		return fmt.Errorf("metadata file path not provided")
+	}
+
+	metadataFilePath, err := getAbsBackupPath(c.MetadataFilePath)
+	if err != nil {
+		return err
+	}
+	c.MetadataFilePath = metadataFilePath
+
+	if c.OutputDir == "" {
+		return fmt.Errorf("output directory not provided")
+	}
+
+	outputDir, err := filepath.Abs(c.OutputDir)
+	if err != nil {
+		return err
+	}
+	c.OutputDir = outputDir
+
+	return c.validateKeyFiles()
+}
+
+func (c *Config) validateDatabaseExportConfig() error {
+	err := c.validateExportConfig()
+	if err != nil {
+		return err
+	}
+
+	if !dbexport.Format(c.ExportFormat).IsValid() {
+		return fmt.Errorf("unsupported export format: %s", c.ExportFormat)
+	}
+
+	return nil
+}
+
+func (c *Config) executeMarkdownExport(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	tree, err := c.TreeBuilder.BuildTree(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build the notion object tree")
+		return err
+	}
+
+	log.Info().Str(logging.ExportPath, c.OutputDir).Msg(
+		"Exporting pages as markdown files")
+	err = markdown.GetMarkdownExporter(c.ReaderWriter, tree, c.OutputDir).
+		Export(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to export markdown files")
+		return err
+	}
+
+	log.Info().Msg("Markdown export successful")
+	return nil
+}
+
+func (c *Config) executeDatabaseExport(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	tree, err := c.TreeBuilder.BuildTree(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build the notion object tree")
+		return err
+	}
+
+	log.Info().Str(logging.ExportPath, c.OutputDir).Msg(
+		"Exporting databases as " + c.ExportFormat + " files")
+	err = dbexport.GetDatabaseExporter(c.ReaderWriter, tree, c.OutputDir,
+		dbexport.Format(c.ExportFormat)).Export(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to export databases")
+		return err
+	}
+
+	log.Info().Msg("Database export successful")
+	return nil
+}
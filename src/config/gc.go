@@ -0,0 +1,100 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+func (c *Config) validateGCConfig() error {
+	if c.Dir == "" {
+		return fmt.Errorf("object pool directory not provided")
+	}
+
+	dir, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return err
+	}
+	c.Dir = dir
+
+	err = utils.CheckIfDirExists(c.Dir)
+	if err != nil {
+		return err
+	}
+
+	return c.validateKeyFiles()
+}
+
+// Delete the objects of the object pool not referenced by any snapshot of the
+// pool. Nothing is deleted if any of the snapshots cannot be read
+func (c *Config) executeGC(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	// Pool is locked before listing the snapshots, so that the objects of a
+	// snapshot written meanwhile are never removed
+	unlock, err := rw.LockObjectPool(c.Dir)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to lock the object pool")
+		return err
+	}
+	defer func() {
+		err := unlock()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to unlock the object pool")
+		}
+	}()
+
+	snapshotPaths, err := rw.GetSnapshotMetadataPaths(c.Dir)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list the snapshots")
+		return err
+	}
+
+	referencedHashes := make(map[string]bool)
+	for _, snapshotPath := range snapshotPaths {
+		_, metadataObj, err := getReaderWriterForMetadata(ctx, c, snapshotPath)
+		if err != nil {
+			log.Error().Err(err).Str(logging.MetaDataFilePath, snapshotPath).Msg(
+				"Failed to read the snapshot")
+			return err
+		}
+
+		for _, notionObject := range metadataObj.NotionObjectMap {
+			if notionObject.StorageIdentifier != "" {
+				referencedHashes[notionObject.StorageIdentifier] = true
+			}
+		}
+
+		for _, asset := range metadataObj.AssetMap {
+			referencedHashes[asset.StorageIdentifier] = true
+		}
+	}
+
+	log.Info().Msgf("%d snapshots reference %d objects", len(snapshotPaths),
+		len(referencedHashes))
+
+	removedHashes, err := rw.CollectGarbage(ctx, c.Dir, referencedHashes,
+		c.DryRun)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to remove unreferenced objects")
+		return err
+	}
+
+	if c.DryRun {
+		for _, hash := range removedHashes {
+			fmt.Println(hash)
+		}
+		log.Info().Msgf("%d unreferenced objects would be removed",
+			len(removedHashes))
+		return nil
+	}
+
+	log.Info().Msgf("Garbage collection successful. %d unreferenced objects "+
+		"removed", len(removedHashes))
+	return nil
+}
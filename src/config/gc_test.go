@@ -0,0 +1,50 @@
+package config_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteGC(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty directory", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.GC,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Valid config", func(t *testing.T) {
+		ctx := context.Background()
+		poolDir := t.TempDir()
+		writer, err := rw.GetContentAddressedReaderWriter(ctx, poolDir, false)
+		assert.Nil(err)
+
+		pageId, err := writer.WritePage(ctx, &notionapi.Page{ID: "page_id"})
+		assert.Nil(err)
+		storageConfig, err := writer.GetStorageConfig(ctx)
+		assert.Nil(err)
+		err = writer.WriteMetaData(ctx, &metadata.MetaData{
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(err)
+
+		config := &config.Config{
+			Operation_Type: config.GC,
+			Dir:            poolDir,
+		}
+		err = config.Execute(ctx)
+		assert.Nil(err)
+		assert.NoFileExists(filepath.Join(poolDir, rw.OBJECTS_DIR_NAME,
+			pageId.String()[:rw.OBJECT_SUB_DIR_LENGTH], pageId.String()))
+	})
+}
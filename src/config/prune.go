@@ -0,0 +1,181 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/retention"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+func (c *Config) getRetentionPolicy() *retention.Policy {
+	return &retention.Policy{
+		KeepDaily:   c.KeepDaily,
+		KeepWeekly:  c.KeepWeekly,
+		KeepMonthly: c.KeepMonthly,
+	}
+}
+
+func (c *Config) validatePruneConfig() error {
+	if c.Dir == "" {
+		return fmt.Errorf("snapshots directory not provided")
+	}
+
+	dir, err := filepath.Abs(c.Dir)
+	if err != nil {
+		return err
+	}
+	c.Dir = dir
+
+	err = utils.CheckIfDirExists(c.Dir)
+	if err != nil {
+		return err
+	}
+
+	if c.KeepDaily < 0 || c.KeepWeekly < 0 || c.KeepMonthly < 0 {
+		return fmt.Errorf("number of snapshots to keep cannot be negative")
+	}
+
+	if c.getRetentionPolicy().IsEmpty() {
+		return fmt.Errorf("retention policy does not keep any snapshot")
+	}
+
+	return nil
+}
+
+// Helper function to get the path of the parent snapshot recorded in the
+// metadata of the snapshot. Relative path is resolved against the directory of
+// the metadata file, or of the archive, of the snapshot
+func resolveParentSnapshotPath(snapshotPath string,
+	parentSnapshotPath string) string {
+	if parentSnapshotPath == "" || rw.IsS3URL(parentSnapshotPath) ||
+		filepath.IsAbs(parentSnapshotPath) {
+		return parentSnapshotPath
+	}
+
+	return filepath.Join(filepath.Dir(snapshotPath), parentSnapshotPath)
+}
+
+// Helper function to read the identity of the snapshot from its metadata file
+// or archive. Metadata is not decrypted as identity of encrypted snapshots is
+// stored in plain text. Archives in the sub directories of the root directory
+// are removed along with their directory
+func getRetentionSnapshot(ctx context.Context, rootDir string,
+	snapshotPath string) (*retention.Snapshot, error) {
+	log := zerolog.Ctx(ctx).With().Str(logging.MetaDataFilePath,
+		snapshotPath).Logger()
+
+	var metadataObj *metadata.MetaData
+	var err error
+	snapshot := &retention.Snapshot{MetadataFilePath: snapshotPath}
+	if rw.GetArchiveFormat(snapshotPath) != "" {
+		metadataObj, err = rw.ReadArchiveMetaData(snapshotPath)
+		snapshot.Path = snapshotPath
+		if filepath.Dir(snapshotPath) != rootDir {
+			snapshot.Path = filepath.Dir(snapshotPath)
+		}
+	} else {
+		metadataObj, err = readMetadata(snapshotPath)
+		snapshot.Path = filepath.Dir(snapshotPath)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot.SnapshotId = metadataObj.SnapshotId
+	snapshot.ParentSnapshotId = metadataObj.ParentSnapshotId
+	snapshot.ParentSnapshotPath = resolveParentSnapshotPath(snapshotPath,
+		metadataObj.ParentSnapshotPath)
+	if metadataObj.CreatedAt != nil {
+		snapshot.CreatedAt = metadataObj.CreatedAt.AsTime()
+		return snapshot, nil
+	}
+
+	info, err := os.Stat(snapshotPath)
+	if err != nil {
+		return nil, err
+	}
+	snapshot.CreatedAt = info.ModTime()
+
+	log.Warn().Msg("Snapshot taken by older version does not record its " +
+		"creation time. Modification time of the snapshot is used instead")
+	if metadataObj.GetEncryptionConfig() != nil {
+		log.Warn().Msg("Parent of the encrypted snapshot taken by older " +
+			"version cannot be found. It may be removed even if this snapshot " +
+			"is kept")
+	}
+
+	return snapshot, nil
+}
+
+// Remove the snapshots in the directory which are not kept by the retention
+// policy. Nothing is removed if any of the snapshots cannot be read
+func (c *Config) executePrune(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	snapshotPaths, err := rw.GetSnapshotPaths(c.Dir)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to list the snapshots")
+		return err
+	}
+
+	snapshots := make([]*retention.Snapshot, 0, len(snapshotPaths))
+	for _, snapshotPath := range snapshotPaths {
+		snapshot, err := getRetentionSnapshot(ctx, c.Dir, snapshotPath)
+		if err != nil {
+			log.Error().Err(err).Str(logging.MetaDataFilePath, snapshotPath).Msg(
+				"Failed to read the snapshot")
+			return err
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].CreatedAt.Before(snapshots[j].CreatedAt)
+	})
+	c.getRetentionPolicy().Apply(snapshots)
+
+	err = retention.WriteTable(os.Stdout, snapshots)
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, snapshot := range snapshots {
+		if snapshot.Keep {
+			continue
+		}
+
+		if !c.DryRun {
+			log.Debug().Msgf("Removing snapshot %s", snapshot.Path)
+			err = os.RemoveAll(snapshot.Path)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to remove snapshot %s",
+					snapshot.Path)
+				return err
+			}
+		}
+		removed++
+	}
+
+	if c.DryRun {
+		log.Info().Msgf("%d of %d snapshots would be removed", removed,
+			len(snapshots))
+		return nil
+	}
+
+	log.Info().Msgf("Prune successful. %d of %d snapshots removed", removed,
+		len(snapshots))
+	if removed > 0 && rw.IsObjectPool(c.Dir) {
+		log.Info().Msg("Run gc to delete the objects of the removed snapshots " +
+			"from the object pool")
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecutePrune(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty retention policy", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.PRUNE,
+			Dir:            t.TempDir(),
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Valid config", func(t *testing.T) {
+		ctx := context.Background()
+		rootDir := t.TempDir()
+
+		// Snapshot of 2022-05-03 is taken incrementally from the snapshot of
+		// 2022-05-01
+		writeSnapshot := func(name string, createdAt time.Time,
+			opts ...exporter.MetadataOption) string {
+			writer, err := rw.GetFileReaderWriter(ctx,
+				filepath.Join(rootDir, name), true)
+			assert.Nil(err)
+			opts = append(opts, exporter.WithSnapshot(name, createdAt))
+			err = exporter.ExportTree(ctx, writer,
+				&tree.Tree{RootNode: node.CreateRootNode()}, opts...)
+			assert.Nil(err)
+			return filepath.Join(rootDir, name, rw.METADATA_FILE_NAME)
+		}
+
+		firstPath := writeSnapshot("2022-05-01",
+			time.Date(2022, 5, 1, 12, 0, 0, 0, time.UTC))
+		writeSnapshot("2022-05-02", time.Date(2022, 5, 2, 12, 0, 0, 0, time.UTC))
+		writeSnapshot("2022-05-03", time.Date(2022, 5, 3, 12, 0, 0, 0, time.UTC),
+			exporter.WithParentSnapshot(firstPath, "2022-05-01"))
+
+		cfg := &config.Config{
+			Operation_Type: config.PRUNE,
+			Dir:            rootDir,
+			KeepDaily:      1,
+			DryRun:         true,
+		}
+		err := cfg.Execute(ctx)
+		assert.Nil(err)
+		assert.DirExists(filepath.Join(rootDir, "2022-05-02"))
+
+		cfg.DryRun = false
+		err = cfg.Execute(ctx)
+		assert.Nil(err)
+		assert.DirExists(filepath.Join(rootDir, "2022-05-01"))
+		assert.NoDirExists(filepath.Join(rootDir, "2022-05-02"))
+		assert.DirExists(filepath.Join(rootDir, "2022-05-03"))
+	})
+
+	t.Run("Parent recorded relative to moved snapshot", func(t *testing.T) {
+		ctx := context.Background()
+		rootDir := t.TempDir()
+
+		// Snapshots are taken in another directory and moved to the root
+		// directory. Parent is only recorded with its relative path
+		oldRootDir := t.TempDir()
+		for day := 1; day <= 3; day++ {
+			name := fmt.Sprintf("2022-05-0%d", day)
+			writer, err := rw.GetFileReaderWriter(ctx,
+				filepath.Join(oldRootDir, name), true)
+			assert.Nil(err)
+			opts := []exporter.MetadataOption{exporter.WithSnapshot("",
+				time.Date(2022, 5, day, 12, 0, 0, 0, time.UTC))}
+			if day == 3 {
+				opts = append(opts, exporter.WithParentSnapshot(filepath.Join("..",
+					"2022-05-01", rw.METADATA_FILE_NAME), ""))
+			}
+			err = exporter.ExportTree(ctx, writer,
+				&tree.Tree{RootNode: node.CreateRootNode()}, opts...)
+			assert.Nil(err)
+
+			err = os.Rename(filepath.Join(oldRootDir, name),
+				filepath.Join(rootDir, name))
+			assert.Nil(err)
+		}
+
+		cfg := &config.Config{
+			Operation_Type: config.PRUNE,
+			Dir:            rootDir,
+			KeepDaily:      1,
+		}
+		err := cfg.Execute(ctx)
+		assert.Nil(err)
+		assert.DirExists(filepath.Join(rootDir, "2022-05-01"))
+		assert.NoDirExists(filepath.Join(rootDir, "2022-05-02"))
+		assert.DirExists(filepath.Join(rootDir, "2022-05-03"))
+	})
+}
@@ -0,0 +1,315 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/builder"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+)
+
+func InitializeRestore(ctx context.Context, c *Config) {
+	log := zerolog.Ctx(ctx)
+
+	var metadataObj *metadata.MetaData
+	var err error
+	c.ReaderWriter, metadataObj, err = getReaderWriterForMetadata(ctx, c,
+		c.MetadataFilePath)
+	if err != nil {
+		log.Panic().Err(err).Msg("Failed to read the backup")
+	}
+
+	c.NotionClient = getNotionClient(ctx, c)
+
+	// Nothing is uploaded during dry run
+	if c.AssetBucketURL != "" && !c.DryRun {
+		c.AssetUploader, err = getAssetUploader(ctx, c)
+		if err != nil {
+			log.Panic().Err(err).Msg("Failed to connect to the asset bucket")
+		}
+	}
+
+	c.TreeBuilder = builder.GetMetaDataTreeBuilder(ctx, metadataObj)
+}
+
+// Helper function to create the uploader of the assets to the bucket of the
+// S3 URL. Connection parameters are same as of the backup on S3
+func getAssetUploader(ctx context.Context,
+	c *Config) (importer.AssetUploader, error) {
+	bucket, prefix, err := rw.ParseS3URL(c.AssetBucketURL)
+	if err != nil {
+		return nil, err
+	}
+
+	s3Config := &rw.S3Config{}
+	if c.S3Config != nil {
+		*s3Config = *c.S3Config
+	}
+	s3Config.Bucket = bucket
+	s3Config.Prefix = prefix
+
+	return rw.GetS3AssetUploader(ctx, s3Config)
+}
+
+// Token and page to restore to are optional for dry run as nothing is sent to
+// Notion
+func (c *Config) validateRestoreConfig() error {
+	if c.Token == "" && !c.DryRun {
+		return fmt.Errorf("notion secret token not provided")
+	}
+
+	metadataFilePath, err := getAbsBackupPath(c.MetadataFilePath)
+	if err != nil {
+		return err
+	}
+
+	if c.RestoreToPageUUID != "" || !c.DryRun {
+		err = validateUUIDs("Page", []string{c.RestoreToPageUUID})
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.validateKeyFiles()
+	if err != nil {
+		return err
+	}
+
+	c.MetadataFilePath = metadataFilePath
+
+	selected := 0
+	for _, selector := range []string{c.SubtreeNodeUuid, c.SubtreeNotionId,
+		c.SubtreeTitle} {
+		if selector != "" {
+			selected++
+		}
+	}
+
+	if selected > 1 {
+		return fmt.Errorf("only one of node, notion id and title can be " +
+			"selected")
+	}
+
+	if c.MissingLinkPolicy == "" {
+		c.MissingLinkPolicy = string(importer.KEEP_MISSING_LINKS)
+	}
+
+	if c.MissingLinkPolicy != string(importer.KEEP_MISSING_LINKS) &&
+		c.MissingLinkPolicy != string(importer.TEXT_MISSING_LINKS) {
+		return fmt.Errorf("unsupported missing link policy: %s",
+			c.MissingLinkPolicy)
+	}
+
+	if c.SubtreeNodeUuid != "" {
+		_, err = uuid.Parse(c.SubtreeNodeUuid)
+		if err != nil {
+			return fmt.Errorf("invalid node uuid %s: %v", c.SubtreeNodeUuid, err)
+		}
+	}
+
+	if c.AssetBucketURL != "" {
+		_, _, err = rw.ParseS3URL(c.AssetBucketURL)
+		if err != nil {
+			return err
+		}
+	}
+
+	if c.AssetFallbackURL != "" {
+		fallbackURL, err := url.Parse(c.AssetFallbackURL)
+		if err != nil {
+			return err
+		}
+
+		if fallbackURL.Scheme != "http" && fallbackURL.Scheme != "https" {
+			return fmt.Errorf("asset fallback URL must be an http or https URL: "+
+				"%s", c.AssetFallbackURL)
+		}
+	}
+
+	if c.DryRun {
+		return c.validateReportFormat()
+	}
+
+	// Default journal path depends on the selected subtree, so it is set once
+	// the tree is built
+	if c.JournalPath == "" {
+		return nil
+	}
+
+	journalPath, err := filepath.Abs(c.JournalPath)
+	if err != nil {
+		return err
+	}
+	c.JournalPath = journalPath
+	return nil
+}
+
+// Helper function to get the path of the restore journal. Journal is kept
+// next to the metadata file or archive of the backup, or in the current
+// directory for backups on S3. Restore of a subtree has its own journal
+func getDefaultJournalPath(metadataFilePath string, restoreToPageUUID string,
+	subtreeNode *node.Node) string {
+	journalFileName := fmt.Sprintf(JOURNAL_FILE_NAME_FORMAT, restoreToPageUUID)
+	if subtreeNode != nil {
+		journalFileName = fmt.Sprintf(SUBTREE_JOURNAL_FILE_NAME_FORMAT,
+			restoreToPageUUID, subtreeNode.GetID().String())
+	}
+
+	if rw.IsS3URL(metadataFilePath) {
+		return journalFileName
+	}
+
+	return filepath.Join(filepath.Dir(metadataFilePath), journalFileName)
+}
+
+func (c *Config) getSubtreeSelector() *importer.SubtreeSelector {
+	return &importer.SubtreeSelector{
+		NodeUuid:       c.SubtreeNodeUuid,
+		NotionObjectId: c.SubtreeNotionId,
+		Title:          c.SubtreeTitle,
+	}
+}
+
+// Helper function to find the node of the subtree to restore and report the
+// objects it depends on which are not restored with it. nil node is returned
+// if the whole backup is restored
+func (c *Config) selectSubtree(ctx context.Context, tree *tree.Tree) (
+	*node.Node, []importer.ExternalDependency, error) {
+	log := zerolog.Ctx(ctx)
+	selector := c.getSubtreeSelector()
+	if selector.IsEmpty() {
+		return nil, nil, nil
+	}
+
+	subtreeNode, err := importer.SelectSubtree(ctx, c.ReaderWriter, tree,
+		selector)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to select the subtree to restore")
+		return nil, nil, err
+	}
+
+	log.Info().Str(logging.NodeID, subtreeNode.GetID().String()).Msgf(
+		"Restoring %s %s and its children", subtreeNode.GetNodeType(),
+		subtreeNode.GetNotionObjectId())
+
+	dependencies, err := importer.FindExternalDependencies(ctx, c.ReaderWriter,
+		subtreeNode)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to find dependencies of the subtree")
+		return nil, nil, err
+	}
+
+	for _, dependency := range dependencies {
+		log.Warn().Str(logging.NodeID, dependency.NodeUuid).Msgf(
+			"%s %s of %s refers to %s which is not restored", dependency.Kind,
+			dependency.Property, dependency.NotionObjectId, dependency.TargetId)
+	}
+
+	return subtreeNode, dependencies, nil
+}
+
+// Walk the tree with RecordingNotionClient and print the plan of the restore
+func (c *Config) executeDryRunRestore(ctx context.Context,
+	tree *tree.Tree) error {
+	log := zerolog.Ctx(ctx)
+
+	subtreeNode, dependencies, err := c.selectSubtree(ctx, tree)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msg("Starting dry run of the data import...")
+	importerObj := importer.GetImporter(c.ReaderWriter, c.NotionClient,
+		c.RestoreToPageUUID, tree, importer.WithDryRun(),
+		importer.WithSubtree(subtreeNode), importer.WithMissingLinkPolicy(
+			importer.MissingLinkPolicy(c.MissingLinkPolicy)),
+		importer.WithAssetFallbackUrl(c.AssetFallbackURL))
+	err = importerObj.ImportObjects(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to walk the backup")
+		return err
+	}
+
+	report := importerObj.GetDryRunReport()
+	report.ExternalDependencies = dependencies
+	if c.ReportFormat == JSON_REPORT_FORMAT {
+		dataBytes, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = os.Stdout.Write(append(dataBytes, '\n'))
+		return err
+	}
+
+	return report.WriteTable(os.Stdout)
+}
+
+func (c *Config) executeRestore(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	tree, err := c.TreeBuilder.BuildTree(ctx)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to build the notion object tree")
+		return err
+	}
+
+	if c.DryRun {
+		return c.executeDryRunRestore(ctx, tree)
+	}
+
+	subtreeNode, _, err := c.selectSubtree(ctx, tree)
+	if err != nil {
+		return err
+	}
+
+	if c.JournalPath == "" {
+		c.JournalPath = getDefaultJournalPath(c.MetadataFilePath,
+			c.RestoreToPageUUID, subtreeNode)
+	}
+
+	journal, err := importer.OpenJournal(c.JournalPath, c.RestoreToPageUUID,
+		c.Resume)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to open the restore journal")
+		return err
+	}
+
+	if journal.GetCreatedCount() > 0 {
+		log.Info().Msgf("Resuming restore. %d objects already restored",
+			journal.GetCreatedCount())
+	}
+
+	log.Info().Msg("Starting data import...")
+	importerObj := importer.GetImporter(c.ReaderWriter, c.NotionClient,
+		c.RestoreToPageUUID, tree, importer.WithJournal(journal),
+		importer.WithSubtree(subtreeNode), importer.WithMissingLinkPolicy(
+			importer.MissingLinkPolicy(c.MissingLinkPolicy)),
+		importer.WithAssetUploader(c.AssetUploader),
+		importer.WithAssetFallbackUrl(c.AssetFallbackURL))
+	err = importerObj.ImportObjects(ctx)
+	if err != nil {
+		journal.Close()
+		log.Error().Err(err).Msgf("Failed to import data to Notion. Run restore "+
+			"again with --resume to continue from %s", c.JournalPath)
+		return err
+	}
+
+	err = journal.Remove()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to remove the restore journal")
+	}
+
+	log.Info().Msg("Restore successful")
+	return nil
+}
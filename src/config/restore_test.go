@@ -0,0 +1,856 @@
+package config_test
+
+import (
+	"context"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Uploader recording the uploaded assets instead of uploading them
+type fakeAssetUploader struct {
+	uploaded map[string][]byte
+}
+
+func (u *fakeAssetUploader) UploadAsset(ctx context.Context,
+	asset *metadata.Asset, dataBytes []byte) (string, error) {
+	u.uploaded[asset.Checksum] = dataBytes
+	return "https://assets.example.com/" + asset.Checksum + "/" +
+		asset.FileName, nil
+}
+
+func getAssignAssetUploaderFunc(ctx context.Context,
+	uploader *fakeAssetUploader) config.ConfigOption {
+	return func(ctx context.Context, c *config.Config) {
+		c.AssetUploader = uploader
+	}
+}
+
+func TestInitializeRestore(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         *config.Config
+		shouldPanic bool
+	}{
+		{
+			name: "All fields are valid",
+			cfg: &config.Config{
+				Token:             MOCKED_TOKEN,
+				Operation_Type:    config.RESTORE,
+				MetadataFilePath:  METADATA_FILEPATH,
+				RestoreToPageUUID: uuid.NewString(),
+			},
+			shouldPanic: false,
+		},
+		{
+			name: "Metadata file does not exists",
+			cfg: &config.Config{
+				Token:            MOCKED_TOKEN,
+				Operation_Type:   config.RESTORE,
+				MetadataFilePath: INVALID_FILE_PATH,
+			},
+			shouldPanic: true,
+		},
+		{
+			name: "Invalid metadata file data",
+			cfg: &config.Config{
+				Token:            MOCKED_TOKEN,
+				Operation_Type:   config.RESTORE,
+				MetadataFilePath: INVALID_METADATA_FILE_CONTENT,
+			},
+			shouldPanic: true,
+		},
+	}
+
+	for _, test := range tests {
+		if test.shouldPanic {
+			t.Run(test.name, func(t *testing.T) {
+				defer func() {
+					r := recover()
+					assert.NotNilf(t, r, "Panic Recovering")
+				}()
+
+				config.InitializeRestore(context.Background(), test.cfg)
+			})
+		} else {
+			t.Run(test.name, func(t *testing.T) {
+				config.InitializeRestore(context.Background(), test.cfg)
+				assert.NotNil(t, test.cfg.NotionClient)
+				assert.NotNil(t, test.cfg.ReaderWriter)
+				assert.NotNil(t, test.cfg.TreeBuilder)
+			})
+		}
+	}
+}
+
+func TestExecuteRestore(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty token", func(t *testing.T) {
+		config := &config.Config{
+			Token:          "",
+			Operation_Type: config.RESTORE,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid config: invalid asset URLs", func(t *testing.T) {
+		tests := []struct {
+			name             string
+			assetBucketURL   string
+			assetFallbackURL string
+		}{
+			{name: "Bucket URL without S3 scheme",
+				assetBucketURL: "https://assets/notion"},
+			{name: "Fallback URL without http scheme",
+				assetFallbackURL: "ftp://example.com/missing.png"},
+		}
+
+		for _, test := range tests {
+			t.Run(test.name, func(t *testing.T) {
+				cfg := &config.Config{
+					Token:             MOCKED_TOKEN,
+					Operation_Type:    config.RESTORE,
+					MetadataFilePath:  TESTDATAPATH,
+					RestoreToPageUUID: uuid.NewString(),
+					AssetBucketURL:    test.assetBucketURL,
+					AssetFallbackURL:  test.assetFallbackURL,
+				}
+
+				err := cfg.Execute(context.Background())
+				assert.NotNil(err)
+			})
+		}
+	})
+
+	t.Run("Error while building tree", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			nil, errGeneric)
+
+		config := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  METADATA_FILEPATH,
+			RestoreToPageUUID: uuid.NewString(),
+		}
+
+		ctx := context.Background()
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.NotNil(err)
+	})
+
+	t.Run("Error while importing objects", func(t *testing.T) {
+		ctx := context.Background()
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedRW.On("WritePage", ctx, mock.Anything).Return(
+			rw.DataIdentifier(uuid.NewString()), nil)
+		mockedRW.On("ReadPage", ctx, mock.Anything).Return(nil, errGeneric)
+
+		pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{}, mockedRW)
+		assert.NotNil(pageNode)
+		assert.Nil(err)
+		rootNode := node.CreateRootNode()
+		assert.NotNil(rootNode)
+
+		rootNode.AddChild(pageNode)
+
+		mockedTreeBuilder.On("BuildTree", context.Background()).Return(
+			&tree.Tree{RootNode: rootNode}, nil)
+
+		config := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  METADATA_FILEPATH,
+			RestoreToPageUUID: uuid.NewString(),
+			JournalPath:       filepath.Join(t.TempDir(), "restore.journal"),
+		}
+
+		err = config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.NotNil(err)
+	})
+
+	t.Run("Valid config", func(t *testing.T) {
+		ctx := context.Background()
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+
+		mockedTreeBuilder.On("BuildTree", ctx).Return(
+			&tree.Tree{RootNode: node.CreateRootNode()}, nil)
+
+		config := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  METADATA_FILEPATH,
+			RestoreToPageUUID: uuid.NewString(),
+			JournalPath:       filepath.Join(t.TempDir(), "restore.journal"),
+		}
+
+		err := config.Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+
+		assert.Nil(err)
+	})
+
+	t.Run("Resume failed restore", func(t *testing.T) {
+		ctx := context.Background()
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedTreeBuilder := mocks.NewTreeBuilder(t)
+		journalPath := filepath.Join(t.TempDir(), "restore.journal")
+		restoreToPageUUID := uuid.NewString()
+
+		rootNode := node.CreateRootNode()
+		for _, pageId := range []string{"page_1", "page_2"} {
+			page := &notionapi.Page{ID: notionapi.ObjectID(pageId)}
+			mockedRW.On("WritePage", ctx, page).Return(rw.DataIdentifier(pageId),
+				nil)
+			mockedRW.On("ReadPage", ctx, rw.DataIdentifier(pageId)).Return(page,
+				nil)
+
+			pageNode, err := node.CreatePageNode(ctx, page, mockedRW)
+			assert.Nil(err)
+			rootNode.AddChild(pageNode)
+		}
+
+		mockedTreeBuilder.On("BuildTree", ctx).Return(
+			&tree.Tree{RootNode: rootNode}, nil)
+
+		getConfig := func(resume bool) *config.Config {
+			return &config.Config{
+				Token:             MOCKED_TOKEN,
+				Operation_Type:    config.RESTORE,
+				MetadataFilePath:  METADATA_FILEPATH,
+				RestoreToPageUUID: restoreToPageUUID,
+				JournalPath:       journalPath,
+				Resume:            resume,
+			}
+		}
+
+		// First attempt fails after restoring the first page
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+			&notionapi.Page{ID: "new_page_1"}, nil).Once()
+		mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+			nil, errGeneric).Once()
+
+		err := getConfig(false).Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+		assert.NotNil(err)
+		assert.FileExists(journalPath)
+
+		// Restore without resume does not repeat the first attempt
+		err = getConfig(false).Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mocks.NewNotionClient(t)),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+		assert.NotNil(err)
+
+		// Resumed restore only creates the second page
+		mockedNotionClient = mocks.NewNotionClient(t)
+		mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+			&notionapi.Page{ID: "new_page_2"}, nil).Once()
+
+		err = getConfig(true).Execute(ctx,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignMockedRWFunc(ctx, mockedRW),
+			getAssignMockedTreeBuilderFunc(ctx, mockedTreeBuilder))
+		assert.Nil(err)
+		assert.NoFileExists(journalPath)
+	})
+
+	t.Run("Invalid config: multiple subtree selectors",
+		func(t *testing.T) {
+			cfg := &config.Config{
+				Token:             MOCKED_TOKEN,
+				Operation_Type:    config.RESTORE,
+				MetadataFilePath:  METADATA_FILEPATH,
+				RestoreToPageUUID: uuid.NewString(),
+				SubtreeNotionId:   "page_id",
+				SubtreeTitle:      "Tasks",
+			}
+			err := cfg.Execute(context.Background())
+			assert.NotNil(err)
+		})
+	t.Run("Subtree", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		rootNode := node.CreateRootNode()
+		for _, pageId := range []string{"page_1", "page_2"} {
+			pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID(pageId),
+				Properties: notionapi.Properties{
+					"Related": &notionapi.RelationProperty{
+						Type:     notionapi.PropertyTypeRelation,
+						Relation: []notionapi.Relation{{ID: "page_1"}},
+					},
+				},
+			}, writer)
+			assert.Nil(err)
+			rootNode.AddChild(pageNode)
+		}
+
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		restoreToPageUUID := uuid.NewString()
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedNotionClient.On("CreatePage", ctx, mock.MatchedBy(
+			func(req *notionapi.PageCreateRequest) bool {
+				return req.Parent.PageID == notionapi.PageID(restoreToPageUUID)
+			})).Return(&notionapi.Page{ID: "new_page_2"}, nil).Once()
+
+		cfg := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: restoreToPageUUID,
+			SubtreeNotionId:   "PAGE_2",
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient))
+		assert.Nil(err)
+
+		matches, err := filepath.Glob(filepath.Join(dir, "*.journal"))
+		assert.Nil(err)
+		assert.Empty(matches)
+
+		cfg = &config.Config{
+			Operation_Type:   config.RESTORE,
+			MetadataFilePath: filepath.Join(dir, rw.METADATA_FILE_NAME),
+			DryRun:           true,
+			SubtreeNotionId:  "unknown_page",
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore)
+		assert.NotNil(err)
+	})
+
+	t.Run("Remap relations", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		// Tasks database relates to Projects database and to a database which
+		// is not part of the backup
+		rootNode := node.CreateRootNode()
+		for _, ids := range [][]string{{"tasks_db", "task", "project"},
+			{"projects_db", "project", "task"}} {
+			databaseNode, err := node.CreateDatabaseNode(ctx, &notionapi.Database{
+				Object: notionapi.ObjectTypeDatabase,
+				ID:     notionapi.ObjectID(ids[0]),
+				Properties: notionapi.PropertyConfigs{
+					"Name": &notionapi.TitlePropertyConfig{
+						Type: notionapi.PropertyConfigTypeTitle,
+					},
+					"Related": &notionapi.RelationPropertyConfig{
+						Type: notionapi.PropertyConfigTypeRelation,
+						Relation: notionapi.RelationConfig{
+							DatabaseID: "projects_db",
+						},
+					},
+					"Other": &notionapi.RelationPropertyConfig{
+						Type: notionapi.PropertyConfigTypeRelation,
+						Relation: notionapi.RelationConfig{
+							DatabaseID: "other_db",
+						},
+					},
+				},
+			}, writer)
+			assert.Nil(err)
+			rootNode.AddChild(databaseNode)
+
+			pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID(ids[1]),
+				Parent: notionapi.Parent{
+					Type:       notionapi.ParentTypeDatabaseID,
+					DatabaseID: notionapi.DatabaseID(ids[0]),
+				},
+				Properties: notionapi.Properties{
+					"Related": &notionapi.RelationProperty{
+						Type:     notionapi.PropertyTypeRelation,
+						Relation: []notionapi.Relation{{ID: "project"}},
+					},
+				},
+			}, writer)
+			assert.Nil(err)
+			databaseNode.AddChild(pageNode)
+		}
+
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		mockedNotionClient := mocks.NewNotionClient(t)
+		for _, id := range []string{"tasks_db", "projects_db"} {
+			mockedNotionClient.On("CreateDatabase", ctx, mock.MatchedBy(
+				func(req *notionapi.DatabaseCreateRequest) bool {
+					return len(req.Properties) == 1
+				})).Return(&notionapi.Database{ID: notionapi.ObjectID(
+				"new_" + id)}, nil).Once()
+		}
+		for _, id := range []string{"task", "project"} {
+			mockedNotionClient.On("CreatePage", ctx, mock.MatchedBy(
+				func(req *notionapi.PageCreateRequest) bool {
+					return len(req.Properties) == 0
+				})).Return(&notionapi.Page{ID: notionapi.ObjectID("new_" + id)},
+				nil).Once()
+		}
+
+		for _, id := range []string{"new_tasks_db", "new_projects_db"} {
+			mockedNotionClient.On("UpdateDatabase", ctx,
+				notionclient.DatabaseID(id), mock.MatchedBy(
+					func(req *notionapi.DatabaseUpdateRequest) bool {
+						dataBytes, _ := json.Marshal(req.Properties)
+						return len(req.Properties) == 1 && strings.Contains(
+							string(dataBytes), `"database_id":"new_projects_db"`)
+					})).Return(&notionapi.Database{}, nil).Once()
+		}
+		for _, id := range []string{"new_task", "new_project"} {
+			mockedNotionClient.On("UpdatePage", ctx, notionclient.PageID(id),
+				&notionapi.PageUpdateRequest{
+					Properties: notionapi.Properties{
+						"Related": &notionapi.RelationProperty{
+							Type:     notionapi.PropertyTypeRelation,
+							Relation: []notionapi.Relation{{ID: "new_project"}},
+						},
+					},
+				}).Return(&notionapi.Page{}, nil).Once()
+		}
+
+		cfg := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: uuid.NewString(),
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient))
+		assert.Nil(err)
+	})
+
+	t.Run("Rewrite links", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		pageIds := []string{uuid.NewString(), uuid.NewString()}
+		newPageIds := []string{uuid.NewString(), uuid.NewString()}
+		missingPageId := uuid.NewString()
+		mention := func(pageId string) []notionapi.RichText {
+			return []notionapi.RichText{{
+				Type: "mention",
+				Mention: &notionapi.Mention{
+					Type: notionapi.MentionTypePage,
+					Page: &notionapi.PageMention{ID: notionapi.ObjectID(pageId)},
+				},
+				PlainText: "Linked page",
+			}}
+		}
+		createBlockNode := func(block notionapi.Block) *node.Node {
+			blockNode, err := node.CreateBlockNode(ctx, block, writer)
+			assert.Nil(err)
+			return blockNode
+		}
+
+		pageNodes := []*node.Node{}
+		for _, pageId := range pageIds {
+			pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID(pageId),
+				Parent: notionapi.Parent{
+					Type:   notionapi.ParentTypePageID,
+					PageID: notionapi.PageID(pageIds[0]),
+				},
+			}, writer)
+			assert.Nil(err)
+			pageNodes = append(pageNodes, pageNode)
+		}
+
+		// First page mentions the second page which is created after it
+		pageNodes[0].AddChild(createBlockNode(&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+			Paragraph: notionapi.Paragraph{RichText: mention(pageIds[1])},
+		}))
+		childPageNode := createBlockNode(&notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(pageIds[1]),
+				Type:   notionapi.BlockTypeChildPage,
+			},
+		})
+		childPageNode.AddChild(pageNodes[1])
+		pageNodes[0].AddChild(childPageNode)
+
+		// Second page links to the first page and to a page which is not
+		// backed up
+		pageNodes[1].AddChild(createBlockNode(&notionapi.LinkToPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeLinkToPage,
+			},
+			LinkToPage: notionapi.LinkToPage{
+				Type:   notionapi.BlockTypeLinkToPage,
+				PageID: notionapi.PageID(pageIds[0]),
+			},
+		}))
+		pageNodes[1].AddChild(createBlockNode(&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+			Paragraph: notionapi.Paragraph{RichText: mention(missingPageId)},
+		}))
+
+		rootNode := node.CreateRootNode()
+		rootNode.AddChild(pageNodes[0])
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		mockedNotionClient := mocks.NewNotionClient(t)
+		for _, newPageId := range newPageIds {
+			mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+				&notionapi.Page{ID: notionapi.ObjectID(newPageId)}, nil).Once()
+		}
+
+		getResponse := func(count int) *notionapi.AppendBlockChildrenResponse {
+			rsp := &notionapi.AppendBlockChildrenResponse{}
+			for i := 0; i < count; i++ {
+				rsp.Results = append(rsp.Results, &notionapi.BasicBlock{
+					ID: notionapi.BlockID(uuid.NewString()),
+				})
+			}
+			return rsp
+		}
+		matchRequest := func(contains string, notContains string) interface{} {
+			return mock.MatchedBy(
+				func(req *notionapi.AppendBlockChildrenRequest) bool {
+					dataBytes, _ := json.Marshal(req)
+					return strings.Contains(string(dataBytes), contains) &&
+						!strings.Contains(string(dataBytes), notContains)
+				})
+		}
+
+		// Mention of the second page is restored as text until the page is
+		// created
+		mockedNotionClient.On("AppendBlocksToBlock", ctx,
+			notionclient.BlockID(newPageIds[0]), matchRequest("Linked page",
+				pageIds[1])).Return(getResponse(1), nil).Once()
+		mockedNotionClient.On("AppendBlocksToBlock", ctx,
+			notionclient.BlockID(newPageIds[1]), matchRequest(newPageIds[0],
+				pageIds[0])).Return(getResponse(2), nil).Once()
+		mockedNotionClient.On("UpdateBlock", ctx, mock.Anything, mock.MatchedBy(
+			func(req *notionapi.BlockUpdateRequest) bool {
+				return req.Paragraph != nil && req.Paragraph.RichText[0].Mention.
+					Page.ID == notionapi.ObjectID(newPageIds[1])
+			})).Return(&notionapi.ParagraphBlock{}, nil).Once()
+
+		cfg := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: uuid.NewString(),
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient))
+		assert.Nil(err)
+	})
+
+	t.Run("Restore assets", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		fileUrl := "https://files.notion.so/space/cover.png"
+		dataBytes := []byte("cover image")
+		identifier, err := rw.WriteAsset(ctx, writer, dataBytes)
+		assert.Nil(err)
+		checksum := rw.GetChecksum(dataBytes)
+		assets := map[string]*metadata.Asset{
+			fileUrl: {
+				StorageIdentifier: identifier.String(),
+				FileName:          "cover.png",
+				ContentType:       "image/png",
+				Checksum:          checksum,
+			},
+		}
+
+		pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     notionapi.ObjectID(uuid.NewString()),
+			Cover: &notionapi.Image{
+				Type: notionapi.FileTypeFile,
+				File: &notionapi.FileObject{URL: fileUrl + "?X-Amz-Signature=1"},
+			},
+		}, writer)
+		assert.Nil(err)
+
+		// Image which could not be downloaded while taking the backup
+		blockNode, err := node.CreateBlockNode(ctx, &notionapi.ImageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeImage,
+			},
+			Image: notionapi.Image{
+				Type: notionapi.FileTypeFile,
+				File: &notionapi.FileObject{
+					URL: "https://files.notion.so/space/expired.png?X-Amz=2",
+				},
+			},
+		}, writer)
+		assert.Nil(err)
+		pageNode.AddChild(blockNode)
+
+		rootNode := node.CreateRootNode()
+		rootNode.AddChild(pageNode)
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{
+			RootNode: rootNode,
+			Assets:   assets,
+		})
+		assert.Nil(err)
+
+		newPageId := uuid.NewString()
+		fallbackUrl := "https://example.com/missing.png"
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedNotionClient.On("CreatePage", ctx, mock.MatchedBy(
+			func(req *notionapi.PageCreateRequest) bool {
+				return req.Cover.Type == notionapi.FileTypeExternal &&
+					req.Cover.External.URL == "https://assets.example.com/"+
+						checksum+"/cover.png"
+			})).Return(&notionapi.Page{ID: notionapi.ObjectID(newPageId)},
+			nil).Once()
+		mockedNotionClient.On("AppendBlocksToBlock", ctx,
+			notionclient.BlockID(newPageId), mock.MatchedBy(
+				func(req *notionapi.AppendBlockChildrenRequest) bool {
+					image, ok := req.Children[0].(*notionapi.ImageBlock)
+					return ok && image.Image.Type == notionapi.FileTypeExternal &&
+						image.Image.External.URL == fallbackUrl &&
+						image.Image.File == nil
+				})).Return(&notionapi.AppendBlockChildrenResponse{
+			Results: []notionapi.Block{&notionapi.BasicBlock{
+				ID: notionapi.BlockID(uuid.NewString()),
+			}},
+		}, nil).Once()
+
+		uploader := &fakeAssetUploader{uploaded: make(map[string][]byte)}
+		cfg := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: uuid.NewString(),
+			AssetFallbackURL:  fallbackUrl,
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient),
+			getAssignAssetUploaderFunc(ctx, uploader))
+		assert.Nil(err)
+		assert.Equal(map[string][]byte{checksum: dataBytes}, uploader.uploaded)
+
+		cfg = &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: uuid.NewString(),
+			AssetFallbackURL:  "ftp://example.com/missing.png",
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore)
+		assert.NotNil(err)
+	})
+
+	t.Run("Restore comments", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		createdTime, err := time.Parse(time.RFC3339, "2022-05-01T10:30:00Z")
+		assert.Nil(err)
+		getComment := func(discussionId notionapi.DiscussionID,
+			content string) *notionapi.Comment {
+			return &notionapi.Comment{
+				Object:       notionapi.ObjectTypeComment,
+				ID:           notionapi.ObjectID(uuid.NewString()),
+				DiscussionID: discussionId,
+				CreatedTime:  createdTime,
+				CreatedBy:    notionapi.User{ID: "author"},
+				RichText: []notionapi.RichText{{
+					Type:      notionapi.ObjectTypeText,
+					Text:      &notionapi.Text{Content: content},
+					PlainText: content,
+				}},
+			}
+		}
+
+		pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     notionapi.ObjectID(uuid.NewString()),
+		}, writer)
+		assert.Nil(err)
+		pageDiscussion := notionapi.DiscussionID(uuid.NewString())
+		for _, content := range []string{"first", "reply"} {
+			commentNode, err := node.CreateCommentNode(ctx,
+				getComment(pageDiscussion, content), writer)
+			assert.Nil(err)
+			pageNode.AddChild(commentNode)
+		}
+
+		blockNode, err := node.CreateBlockNode(ctx, &notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+		}, writer)
+		assert.Nil(err)
+		commentNode, err := node.CreateCommentNode(ctx,
+			getComment(notionapi.DiscussionID(uuid.NewString()), "block"), writer)
+		assert.Nil(err)
+		blockNode.AddChild(commentNode)
+		pageNode.AddChild(blockNode)
+
+		rootNode := node.CreateRootNode()
+		rootNode.AddChild(pageNode)
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		newPageId := notionapi.PageID(uuid.NewString())
+		newDiscussion := notionapi.DiscussionID(uuid.NewString())
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+			&notionapi.Page{ID: notionapi.ObjectID(newPageId)}, nil).Once()
+		mockedNotionClient.On("AppendBlocksToBlock", ctx,
+			notionclient.BlockID(newPageId), mock.MatchedBy(
+				func(req *notionapi.AppendBlockChildrenRequest) bool {
+					return len(req.Children) == 1
+				})).Return(&notionapi.AppendBlockChildrenResponse{
+			Results: []notionapi.Block{&notionapi.BasicBlock{
+				ID: notionapi.BlockID(uuid.NewString()),
+			}},
+		}, nil).Once()
+		mockedNotionClient.On("CreateComment", ctx, mock.MatchedBy(
+			func(req *notionapi.CommentCreateRequest) bool {
+				return req.Parent.PageID == newPageId &&
+					req.RichText[0].Text.Content ==
+						"[Commented by user author at 2022-05-01 10:30 UTC] " &&
+					req.RichText[1].Text.Content == "first"
+			})).Return(&notionapi.Comment{DiscussionID: newDiscussion}, nil).Once()
+		mockedNotionClient.On("CreateComment", ctx, mock.MatchedBy(
+			func(req *notionapi.CommentCreateRequest) bool {
+				return req.Parent.PageID == "" &&
+					req.DiscussionID == newDiscussion &&
+					req.RichText[1].Text.Content == "reply"
+			})).Return(&notionapi.Comment{DiscussionID: newDiscussion}, nil).Once()
+		mockedNotionClient.On("CreateComment", ctx, mock.MatchedBy(
+			func(req *notionapi.CommentCreateRequest) bool {
+				return req.Parent.PageID == newPageId &&
+					req.RichText[0].Text.Content == "[Commented on a block by user "+
+						"author at 2022-05-01 10:30 UTC] " &&
+					req.RichText[1].Text.Content == "block"
+			})).Return(&notionapi.Comment{
+			DiscussionID: notionapi.DiscussionID(uuid.NewString()),
+		}, nil).Once()
+
+		cfg := &config.Config{
+			Token:             MOCKED_TOKEN,
+			Operation_Type:    config.RESTORE,
+			MetadataFilePath:  filepath.Join(dir, rw.METADATA_FILE_NAME),
+			RestoreToPageUUID: uuid.NewString(),
+		}
+		err = cfg.Execute(ctx, config.InitializeRestore,
+			getAssignMockedNotionClientFunc(ctx, mockedNotionClient))
+		assert.Nil(err)
+	})
+
+	t.Run("Dry run", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     "page_id",
+		}, writer)
+		assert.Nil(err)
+		for _, blockType := range []notionapi.BlockType{
+			notionapi.BlockTypeParagraph, notionapi.BlockTypeUnsupported} {
+			blockNode, err := node.CreateBlockNode(ctx, &notionapi.ParagraphBlock{
+				BasicBlock: notionapi.BasicBlock{
+					Object: notionapi.ObjectTypeBlock,
+					ID:     notionapi.BlockID(uuid.NewString()),
+					Type:   blockType,
+				},
+			}, writer)
+			assert.Nil(err)
+			pageNode.AddChild(blockNode)
+		}
+
+		rootNode := node.CreateRootNode()
+		rootNode.AddChild(pageNode)
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		for _, reportFormat := range []string{config.TABLE_REPORT_FORMAT,
+			config.JSON_REPORT_FORMAT} {
+			cfg := &config.Config{
+				Operation_Type:   config.RESTORE,
+				MetadataFilePath: filepath.Join(dir, rw.METADATA_FILE_NAME),
+				DryRun:           true,
+				ReportFormat:     reportFormat,
+			}
+			err = cfg.Execute(ctx, config.InitializeRestore)
+			assert.Nil(err)
+		}
+
+		matches, err := filepath.Glob(filepath.Join(dir, "*.journal"))
+		assert.Nil(err)
+		assert.Empty(matches)
+	})
+}
@@ -0,0 +1,80 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/verifier"
+)
+
+func (c *Config) validateVerifyConfig() error {
+	if c.MetadataFilePath == "" {
+		return fmt.Errorf("metadata file path not provided")
+	}
+
+	metadataFilePath, err := getAbsBackupPath(c.MetadataFilePath)
+	if err != nil {
+		return err
+	}
+	c.MetadataFilePath = metadataFilePath
+
+	return c.validateKeyFiles()
+}
+
+// Helper function to write the verification report as JSON to the report file
+// or to stdout if report file is not provided
+func writeReport(reportFile string, report *verifier.Report) error {
+	dataBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	dataBytes = append(dataBytes, '\n')
+
+	if reportFile == "" {
+		_, err = os.Stdout.Write(dataBytes)
+		return err
+	}
+
+	return os.WriteFile(reportFile, dataBytes, REPORT_FILE_PERM)
+}
+
+// Verify the backup and write the report. Error is returned if any of the
+// checks fails
+func (c *Config) executeVerify(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	rwClient, metadataObj, err := getReaderWriterForMetadata(ctx, c,
+		c.MetadataFilePath)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read the backup")
+		return err
+	}
+
+	report := verifier.GetVerifier(rwClient, metadataObj).Verify(ctx)
+	report.MetadataFilePath = c.MetadataFilePath
+
+	err = writeReport(c.ReportFile, report)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to write the verification report")
+		return err
+	}
+
+	if !report.Valid {
+		err = fmt.Errorf("backup verification failed with %d issues",
+			len(report.Issues))
+		log.Error().Err(err).Msg("Backup is not valid")
+		return err
+	}
+
+	if report.MissingChecksums > 0 {
+		log.Warn().Msgf("%d objects do not have checksums and are only verified "+
+			"by reading them", report.MissingChecksums)
+	}
+
+	log.Info().Msgf("Backup verification successful. %d objects and %d "+
+		"assets verified", report.VerifiedObjects, report.VerifiedAssets)
+	return nil
+}
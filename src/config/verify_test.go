@@ -0,0 +1,64 @@
+package config_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteVerify(t *testing.T) {
+	assert := assert.New(t)
+
+	t.Run("Invalid config: empty file path", func(t *testing.T) {
+		config := &config.Config{
+			Operation_Type: config.VERIFY,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+	})
+
+	t.Run("Invalid backup", func(t *testing.T) {
+		reportFile := filepath.Join(t.TempDir(), "report.json")
+		config := &config.Config{
+			Operation_Type:   config.VERIFY,
+			MetadataFilePath: METADATA_FILEPATH,
+			ReportFile:       reportFile,
+		}
+		err := config.Execute(context.Background())
+		assert.NotNil(err)
+		assert.FileExists(reportFile)
+	})
+
+	t.Run("Valid backup", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(err)
+
+		pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     "page_id",
+		}, writer)
+		assert.Nil(err)
+		rootNode := node.CreateRootNode()
+		rootNode.AddChild(pageNode)
+		err = exporter.ExportTree(ctx, writer, &tree.Tree{RootNode: rootNode})
+		assert.Nil(err)
+
+		config := &config.Config{
+			Operation_Type:   config.VERIFY,
+			MetadataFilePath: filepath.Join(dir, rw.METADATA_FILE_NAME),
+			ReportFile:       filepath.Join(dir, "report.json"),
+		}
+		err = config.Execute(ctx)
+		assert.Nil(err)
+	})
+}
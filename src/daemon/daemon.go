@@ -0,0 +1,353 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+var ErrJobRunning = errors.New("previous run of the job is still running")
+
+// Daemon runs the backup jobs of the schedule until its context is cancelled
+type Daemon struct {
+	schedule   *Schedule
+	baseConfig *config.Config
+	backupOpts []config.ConfigOption
+
+	// Metrics of the runs are recorded only if set. Metrics are written to the
+	// textfile after each run if its path is set
+	metrics         *metrics.Metrics
+	metricsTextfile string
+
+	// Jobs are not run again while the previous run is still running
+	running map[string]*int32
+}
+
+type DaemonOption func(*Daemon)
+
+// Options with which the config of each backup is executed. Backup is
+// initialized with config.InitializeBackup by default
+func WithBackupOptions(opts ...config.ConfigOption) DaemonOption {
+	return func(d *Daemon) {
+		d.backupOpts = opts
+	}
+}
+
+// Metrics with which the runs of the jobs are recorded. Metrics are written to
+// the textfile for the textfile collector of node exporter after each run, if
+// its path is not empty
+func WithMetrics(m *metrics.Metrics, textfilePath string) DaemonOption {
+	return func(d *Daemon) {
+		d.metrics = m
+		d.metricsTextfile = textfilePath
+	}
+}
+
+// Create the daemon running the jobs of the schedule. Notion token, request
+// limits, S3 credentials and key files are taken from the base config
+func GetDaemon(schedule *Schedule, baseConfig *config.Config,
+	opts ...DaemonOption) *Daemon {
+	d := &Daemon{
+		schedule:   schedule,
+		baseConfig: baseConfig,
+		backupOpts: []config.ConfigOption{config.InitializeBackup},
+		running:    make(map[string]*int32),
+	}
+
+	for _, job := range schedule.Jobs {
+		d.running[job.Name] = new(int32)
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Helper function to execute the config. Config initialization reports
+// failures by panicking, which must not stop the daemon
+func execute(ctx context.Context, c *config.Config,
+	opts ...config.ConfigOption) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+
+	return c.Execute(ctx, opts...)
+}
+
+// Helper function to create the directory of the snapshot named with its start
+// time. Suffix is added to the name if the directory already exists
+func createSnapshotDir(dir string, name string) (string, error) {
+	err := utils.CreateDirectory(dir)
+	if err != nil {
+		return "", err
+	}
+
+	snapshotDir := filepath.Join(dir, name)
+	for i := 2; ; i++ {
+		err = os.Mkdir(snapshotDir, os.ModePerm)
+		if !os.IsExist(err) {
+			break
+		}
+		snapshotDir = filepath.Join(dir, fmt.Sprintf("%s-%d", name, i))
+	}
+
+	return snapshotDir, err
+}
+
+// Helper function to get the S3 configuration of the job. Connection settings
+// not provided by the job are taken from the base config
+func (d *Daemon) getS3Config(job *Job, name string) *rw.S3Config {
+	s3Config := &rw.S3Config{}
+	if d.baseConfig.S3Config != nil {
+		*s3Config = *d.baseConfig.S3Config
+	}
+
+	s3Config.Bucket = ""
+	s3Config.Prefix = ""
+	if !job.isS3Job() {
+		return s3Config
+	}
+
+	destination := job.Destination.S3
+	s3Config.Bucket = destination.Bucket
+	s3Config.Prefix = path.Join(destination.Prefix, name)
+	if destination.Endpoint != "" {
+		s3Config.Endpoint = destination.Endpoint
+	}
+	if destination.Region != "" {
+		s3Config.Region = destination.Region
+	}
+
+	return s3Config
+}
+
+// Helper function to take the snapshot of the job. Metadata file path, archive
+// path or S3 URL of the snapshot is returned
+func (d *Daemon) backup(ctx context.Context, job *Job,
+	state *JobState) (string, error) {
+	log := zerolog.Ctx(ctx)
+	name := time.Now().UTC().Format(rw.SNAPSHOT_NAME_FORMAT)
+
+	c := *d.baseConfig
+	c.Operation_Type = config.BACKUP
+	c.PageUUIDs = job.PageUUIDs
+	c.DatabaseUUIDs = job.DatabaseUUIDs
+	c.PageTitles = job.PageTitles
+	c.DatabaseTitles = job.DatabaseTitles
+	c.TitleMatch = job.TitleMatch
+	c.Concurrency = job.Concurrency
+	c.SkipAssets = job.SkipAssets
+	c.BackupComments = job.BackupComments
+	c.ExcludePageUUIDs = job.ExcludePageUUIDs
+	c.ExcludeDatabaseUUIDs = job.ExcludeDatabaseUUIDs
+	c.ExcludeTitleRegex = job.ExcludeTitleRegex
+	c.MaxDepth = job.MaxDepth
+	c.ExcludeBlockTypes = job.ExcludeBlockTypes
+	c.S3Config = d.getS3Config(job, name)
+
+	if job.Incremental {
+		c.IncrementalFrom = state.LastSnapshot
+	}
+
+	if c.IncrementalFrom != "" && !rw.IsS3URL(c.IncrementalFrom) {
+		if _, err := os.Stat(c.IncrementalFrom); err != nil {
+			log.Warn().Err(err).Msg("Last snapshot cannot be found. Taking full " +
+				"backup instead")
+			c.IncrementalFrom = ""
+		}
+	}
+
+	var snapshotPath string
+	if job.isS3Job() {
+		snapshotPath = fmt.Sprintf("%s://%s/%s", rw.S3_URL_SCHEME,
+			c.S3Config.Bucket, c.S3Config.Prefix)
+	} else if job.Destination.Dedup {
+		// Object pool names the snapshots with their start time by itself
+		c.Dir = job.Destination.Dir
+		c.Create_Dir = true
+		c.Dedup = true
+	} else {
+		snapshotDir, err := createSnapshotDir(job.Destination.Dir, name)
+		if err != nil {
+			return "", err
+		}
+
+		c.Dir = snapshotDir
+		c.ArchiveFormat = job.Destination.Archive
+		snapshotPath = filepath.Join(snapshotDir, rw.METADATA_FILE_NAME)
+		if c.ArchiveFormat != "" {
+			snapshotPath = filepath.Join(snapshotDir,
+				rw.ARCHIVE_FILE_NAME+"."+c.ArchiveFormat)
+		}
+	}
+
+	err := execute(ctx, &c, d.backupOpts...)
+	if err != nil {
+		if !job.isS3Job() && !job.Destination.Dedup {
+			err2 := os.RemoveAll(c.Dir)
+			if err2 != nil {
+				log.Warn().Err(err2).Msgf("Failed to remove the directory of the "+
+					"failed snapshot %s", c.Dir)
+			}
+		}
+		return "", err
+	}
+
+	if job.Destination.Dedup {
+		snapshotPaths, err := rw.GetSnapshotMetadataPaths(c.Dir)
+		if err != nil {
+			return "", err
+		}
+
+		if len(snapshotPaths) == 0 {
+			return "", fmt.Errorf("snapshot not found in object pool %s", c.Dir)
+		}
+		snapshotPath = snapshotPaths[len(snapshotPaths)-1]
+	}
+
+	return snapshotPath, nil
+}
+
+// Helper function to apply the retention policy of the job to its snapshots.
+// Objects of the removed snapshots are deleted from the object pool as well
+func (d *Daemon) prune(ctx context.Context, job *Job) error {
+	c := &config.Config{
+		Operation_Type: config.PRUNE,
+		Dir:            job.Destination.Dir,
+		KeepDaily:      job.Retention.KeepDaily,
+		KeepWeekly:     job.Retention.KeepWeekly,
+		KeepMonthly:    job.Retention.KeepMonthly,
+	}
+
+	err := execute(ctx, c)
+	if err != nil || !job.Destination.Dedup {
+		return err
+	}
+
+	c = &config.Config{
+		Operation_Type: config.GC,
+		Dir:            job.Destination.Dir,
+		PassphraseFile: d.baseConfig.PassphraseFile,
+		KeyFile:        d.baseConfig.KeyFile,
+	}
+
+	return execute(ctx, c)
+}
+
+// Run the job once, unless its previous run is still running. State of the job
+// is updated with the result of the run
+func (d *Daemon) RunJob(ctx context.Context, job *Job) error {
+	log := zerolog.Ctx(ctx).With().Str(logging.JobName, job.Name).Logger()
+	ctx = log.WithContext(ctx)
+
+	running := d.running[job.Name]
+	if !atomic.CompareAndSwapInt32(running, 0, 1) {
+		log.Warn().Msg("Previous run of the job is still running. Skipping " +
+			"this run")
+		return ErrJobRunning
+	}
+	defer atomic.StoreInt32(running, 0)
+
+	state, err := ReadJobState(d.schedule.StateDir, job.Name)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read the state of the job")
+		return err
+	}
+
+	log.Info().Msg("Starting job")
+	startTime := time.Now().UTC()
+	state.LastRun = &startTime
+
+	recorder := d.metrics.GetRecorder(metrics.BACKUP, job.Name)
+	snapshotPath, err := d.backup(recorder.WithContext(ctx), job, state)
+	recorder.Finish(err)
+	if d.metrics != nil && d.metricsTextfile != "" {
+		err2 := d.metrics.WriteTextfile(d.metricsTextfile)
+		if err2 != nil {
+			log.Warn().Err(err2).Msg("Failed to write the metrics textfile")
+		}
+	}
+
+	if err != nil {
+		log.Error().Err(err).Msg("Job failed")
+		state.LastError = err.Error()
+	} else {
+		log.Info().Str(logging.MetaDataFilePath, snapshotPath).Msg(
+			"Snapshot taken")
+		state.LastSuccess = &startTime
+		state.LastSnapshot = snapshotPath
+		state.LastError = ""
+
+		if !job.Retention.isEmpty() {
+			err = d.prune(ctx, job)
+			if err != nil {
+				log.Error().Err(err).Msg("Failed to prune the snapshots")
+				state.LastError = err.Error()
+			}
+		}
+	}
+
+	err2 := writeJobState(d.schedule.StateDir, job.Name, state)
+	if err2 != nil {
+		log.Error().Err(err2).Msg("Failed to write the state of the job")
+		if err == nil {
+			err = err2
+		}
+	}
+
+	if err == nil {
+		log.Info().Msg("Job successful")
+	}
+	return err
+}
+
+// Run the jobs on their schedule until the context is cancelled. Cancelling the
+// context cancels the running jobs and waits for them to return
+func (d *Daemon) Run(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+
+	scheduler := cron.New()
+	jobNames := make(map[cron.EntryID]string)
+	for _, job := range d.schedule.Jobs {
+		job := job
+		schedule, err := cron.ParseStandard(job.Schedule)
+		if err != nil {
+			return err
+		}
+
+		id := scheduler.Schedule(schedule, cron.FuncJob(func() {
+			d.RunJob(ctx, job)
+		}))
+		jobNames[id] = job.Name
+	}
+
+	scheduler.Start()
+	log.Info().Msgf("Daemon started with %d jobs", len(d.schedule.Jobs))
+	for _, entry := range scheduler.Entries() {
+		log.Info().Str(logging.JobName, jobNames[entry.ID]).Msgf(
+			"Next run at %s", entry.Next.Format(time.RFC3339))
+	}
+
+	<-ctx.Done()
+	log.Info().Msg("Stopping daemon. Waiting for running jobs to return")
+	<-scheduler.Stop().Done()
+	log.Info().Msg("Daemon stopped")
+	return nil
+}
@@ -0,0 +1,191 @@
+package daemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/config"
+	"github.com/shivaji17/notionbackup/src/daemon"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// Helper function to initialize the backup with the mocked tree builder and
+// the ReaderWriter writing to the snapshot directory
+func getInitializeBackupFunc(
+	treeBuilder *mocks.TreeBuilder) config.ConfigOption {
+	return func(ctx context.Context, c *config.Config) {
+		var err error
+		c.ReaderWriter, err = rw.GetFileReaderWriter(ctx, c.Dir, false)
+		if err != nil {
+			panic(err)
+		}
+		c.TreeBuilder = treeBuilder
+	}
+}
+
+func getSchedule(t *testing.T) *daemon.Schedule {
+	rootDir := t.TempDir()
+	return &daemon.Schedule{
+		StateDir: filepath.Join(rootDir, "state"),
+		Jobs: []*daemon.Job{
+			{
+				Name:        "job",
+				Schedule:    "@daily",
+				Destination: daemon.Destination{Dir: filepath.Join(rootDir, "job")},
+				Retention:   daemon.Retention{KeepDaily: 1},
+			},
+		},
+	}
+}
+
+func TestRunJob(t *testing.T) {
+	t.Run("Retention and state", func(t *testing.T) {
+		ctx := context.Background()
+		schedule := getSchedule(t)
+		job := schedule.Jobs[0]
+
+		treeBuilder := mocks.NewTreeBuilder(t)
+		treeBuilder.On("BuildTree", mock.Anything).Return(
+			&tree.Tree{RootNode: node.CreateRootNode()}, nil)
+
+		d := daemon.GetDaemon(schedule, &config.Config{Token: "token"},
+			daemon.WithBackupOptions(getInitializeBackupFunc(treeBuilder)))
+
+		err := d.RunJob(ctx, job)
+		assert.Nil(t, err)
+		state, err := daemon.ReadJobState(schedule.StateDir, job.Name)
+		assert.Nil(t, err)
+		firstSnapshot := state.LastSnapshot
+		assert.FileExists(t, firstSnapshot)
+
+		// Both snapshots are taken on the same day, so only the newest is kept
+		err = d.RunJob(ctx, job)
+		assert.Nil(t, err)
+		state, err = daemon.ReadJobState(schedule.StateDir, job.Name)
+		assert.Nil(t, err)
+		assert.NotEqual(t, firstSnapshot, state.LastSnapshot)
+		assert.FileExists(t, state.LastSnapshot)
+		assert.NoFileExists(t, firstSnapshot)
+		assert.NotNil(t, state.LastSuccess)
+		assert.Empty(t, state.LastError)
+
+		snapshotPaths, err := rw.GetSnapshotPaths(job.Destination.Dir)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{state.LastSnapshot}, snapshotPaths)
+	})
+
+	t.Run("Metrics and summary", func(t *testing.T) {
+		ctx := context.Background()
+		schedule := getSchedule(t)
+		job := schedule.Jobs[0]
+
+		treeBuilder := mocks.NewTreeBuilder(t)
+		treeBuilder.On("BuildTree", mock.Anything).Return(
+			&tree.Tree{RootNode: node.CreateRootNode()}, nil)
+
+		textfilePath := filepath.Join(t.TempDir(), "notionbackup.prom")
+		d := daemon.GetDaemon(schedule, &config.Config{Token: "token"},
+			daemon.WithBackupOptions(getInitializeBackupFunc(treeBuilder)),
+			daemon.WithMetrics(metrics.GetMetrics(), textfilePath))
+
+		err := d.RunJob(ctx, job)
+		assert.Nil(t, err)
+
+		dataBytes, err := ioutil.ReadFile(textfilePath)
+		assert.Nil(t, err)
+		assert.Contains(t, string(dataBytes), `notionbackup_runs_total{`+
+			`job="job",operation="backup",outcome="success"} 1`)
+
+		state, err := daemon.ReadJobState(schedule.StateDir, job.Name)
+		assert.Nil(t, err)
+		dataBytes, err = ioutil.ReadFile(filepath.Join(
+			filepath.Dir(state.LastSnapshot), rw.SUMMARY_FILE_NAME))
+		assert.Nil(t, err)
+		summary := &metrics.Summary{}
+		err = json.Unmarshal(dataBytes, summary)
+		assert.Nil(t, err)
+		assert.Equal(t, "job", summary.Job)
+		assert.Equal(t, metrics.SUCCESS_OUTCOME, summary.Outcome)
+		assert.NotEmpty(t, summary.SnapshotId)
+	})
+
+	t.Run("Failed backup", func(t *testing.T) {
+		ctx := context.Background()
+		schedule := getSchedule(t)
+		job := schedule.Jobs[0]
+		job.PageUUIDs = []string{"invalid"}
+
+		d := daemon.GetDaemon(schedule, &config.Config{Token: "token"})
+		err := d.RunJob(ctx, job)
+		assert.NotNil(t, err)
+
+		state, err := daemon.ReadJobState(schedule.StateDir, job.Name)
+		assert.Nil(t, err)
+		assert.NotNil(t, state.LastRun)
+		assert.Nil(t, state.LastSuccess)
+		assert.NotEmpty(t, state.LastError)
+
+		// Directory of the failed snapshot is removed
+		snapshotPaths, err := rw.GetSnapshotPaths(job.Destination.Dir)
+		assert.Nil(t, err)
+		assert.Empty(t, snapshotPaths)
+	})
+
+	t.Run("Overlapping runs", func(t *testing.T) {
+		ctx := context.Background()
+		schedule := getSchedule(t)
+		job := schedule.Jobs[0]
+
+		started := make(chan bool)
+		release := make(chan bool)
+		treeBuilder := mocks.NewTreeBuilder(t)
+		treeBuilder.On("BuildTree", mock.Anything).Run(
+			func(args mock.Arguments) {
+				started <- true
+				<-release
+			}).Return(&tree.Tree{RootNode: node.CreateRootNode()}, nil).Once()
+
+		d := daemon.GetDaemon(schedule, &config.Config{Token: "token"},
+			daemon.WithBackupOptions(getInitializeBackupFunc(treeBuilder)))
+
+		errs := make(chan error)
+		go func() {
+			errs <- d.RunJob(ctx, job)
+		}()
+
+		<-started
+		err := d.RunJob(ctx, job)
+		assert.Equal(t, daemon.ErrJobRunning, err)
+
+		close(release)
+		assert.Nil(t, <-errs)
+	})
+}
+
+func TestRun(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := daemon.GetDaemon(getSchedule(t), &config.Config{Token: "token"})
+
+	errs := make(chan error)
+	go func() {
+		errs <- d.Run(ctx)
+	}()
+
+	cancel()
+	select {
+	case err := <-errs:
+		assert.Nil(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("daemon did not stop after the context was cancelled")
+	}
+}
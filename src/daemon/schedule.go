@@ -0,0 +1,221 @@
+package daemon
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// Job names are used as file names of the job state
+var jobNameRegex = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Schedule of the backup jobs run by the daemon
+type Schedule struct {
+	// Directory in which the state of each job is stored across restarts
+	StateDir string `yaml:"state_dir"`
+	Jobs     []*Job `yaml:"jobs"`
+}
+
+// Backup job run on the schedule given by the cron expression
+type Job struct {
+	Name string `yaml:"name"`
+
+	// Standard cron expression with 5 fields or descriptors like @daily.
+	// Time zone can be set with CRON_TZ= prefix
+	Schedule string `yaml:"schedule"`
+
+	// Pages and Databases backed up by the job, either by ID, URL or title.
+	// All the Pages and Databases shared with the integration are backed up if
+	// none is provided
+	PageUUIDs      []string `yaml:"pages"`
+	DatabaseUUIDs  []string `yaml:"databases"`
+	PageTitles     []string `yaml:"page_titles"`
+	DatabaseTitles []string `yaml:"database_titles"`
+	TitleMatch     string   `yaml:"title_match"`
+
+	// Pages, Databases and Blocks excluded from the backup
+	ExcludePageUUIDs     []string `yaml:"exclude_pages"`
+	ExcludeDatabaseUUIDs []string `yaml:"exclude_databases"`
+	ExcludeTitleRegex    string   `yaml:"exclude_title_regex"`
+	MaxDepth             int      `yaml:"max_depth"`
+	ExcludeBlockTypes    []string `yaml:"exclude_block_types"`
+
+	Destination Destination `yaml:"destination"`
+	Retention   Retention   `yaml:"retention"`
+
+	// Take each snapshot incrementally from the last successful snapshot
+	Incremental    bool `yaml:"incremental"`
+	Concurrency    int  `yaml:"concurrency"`
+	SkipAssets     bool `yaml:"skip_assets"`
+	BackupComments bool `yaml:"comments"`
+}
+
+// Destination of the snapshots of the job. Each snapshot is written in a
+// directory named with its start time either under Dir or under the prefix of
+// the S3 bucket
+type Destination struct {
+	Dir     string `yaml:"dir"`
+	Archive string `yaml:"archive"`
+	Dedup   bool   `yaml:"dedup"`
+
+	S3 *S3Destination `yaml:"s3"`
+}
+
+type S3Destination struct {
+	Bucket   string `yaml:"bucket"`
+	Prefix   string `yaml:"prefix"`
+	Endpoint string `yaml:"endpoint"`
+	Region   string `yaml:"region"`
+}
+
+// Retention policy applied to the snapshots of the job after each successful
+// run. Only snapshots in a directory can be pruned, so retention cannot be set
+// for S3 destination
+type Retention struct {
+	KeepDaily   int `yaml:"keep_daily"`
+	KeepWeekly  int `yaml:"keep_weekly"`
+	KeepMonthly int `yaml:"keep_monthly"`
+}
+
+func (r *Retention) isEmpty() bool {
+	return r.KeepDaily == 0 && r.KeepWeekly == 0 && r.KeepMonthly == 0
+}
+
+func (j *Job) isS3Job() bool {
+	return j.Destination.S3 != nil
+}
+
+// Helper function to get the key identifying the destination of the job.
+// Snapshots, retention and state of the jobs with the same destination would
+// clash, so no two jobs can have the same key
+func (j *Job) getDestinationKey() string {
+	if j.isS3Job() {
+		s3 := j.Destination.S3
+		return fmt.Sprintf("s3://%s/%s/%s", s3.Endpoint, s3.Bucket,
+			strings.Trim(s3.Prefix, "/"))
+	}
+
+	return j.Destination.Dir
+}
+
+func (j *Job) validate() error {
+	if !jobNameRegex.MatchString(j.Name) {
+		return fmt.Errorf("invalid job name: '%s'. Only letters, digits, '_' "+
+			"and '-' are allowed", j.Name)
+	}
+
+	_, err := cron.ParseStandard(j.Schedule)
+	if err != nil {
+		return fmt.Errorf("invalid schedule of job %s: %v", j.Name, err)
+	}
+
+	if j.isS3Job() {
+		if j.Destination.Dir != "" {
+			return fmt.Errorf("job %s has both directory and S3 destination",
+				j.Name)
+		}
+
+		if j.Destination.S3.Bucket == "" {
+			return fmt.Errorf("bucket of job %s not provided", j.Name)
+		}
+
+		if !j.Retention.isEmpty() {
+			return fmt.Errorf("retention is only supported for directory "+
+				"destination, snapshots of job %s under S3 prefix have to be "+
+				"removed outside of the daemon", j.Name)
+		}
+	} else if j.Destination.Dir == "" {
+		return fmt.Errorf("destination of job %s not provided", j.Name)
+	}
+
+	if j.Retention.KeepDaily < 0 || j.Retention.KeepWeekly < 0 ||
+		j.Retention.KeepMonthly < 0 {
+		return fmt.Errorf("number of snapshots to keep cannot be negative for "+
+			"job %s", j.Name)
+	}
+
+	return nil
+}
+
+// Helper function to validate the schedule and make the paths of the schedule
+// absolute
+func (s *Schedule) validate() error {
+	if s.StateDir == "" {
+		return fmt.Errorf("state directory not provided")
+	}
+
+	var err error
+	s.StateDir, err = filepath.Abs(s.StateDir)
+	if err != nil {
+		return err
+	}
+
+	if len(s.Jobs) == 0 {
+		return fmt.Errorf("schedule does not have any job")
+	}
+
+	names := make(map[string]bool)
+	destinations := make(map[string]string)
+	for _, job := range s.Jobs {
+		err = job.validate()
+		if err != nil {
+			return err
+		}
+
+		if names[job.Name] {
+			return fmt.Errorf("duplicate job name: %s", job.Name)
+		}
+		names[job.Name] = true
+
+		if job.Destination.Dir != "" {
+			job.Destination.Dir, err = filepath.Abs(job.Destination.Dir)
+			if err != nil {
+				return err
+			}
+		}
+
+		destination := job.getDestinationKey()
+		if otherJob, found := destinations[destination]; found {
+			return fmt.Errorf("jobs %s and %s have the same destination",
+				otherJob, job.Name)
+		}
+		destinations[destination] = job.Name
+
+		if job.Concurrency <= 0 {
+			job.Concurrency = 1
+		}
+	}
+
+	return nil
+}
+
+// Read and validate the YAML schedule file. Unknown fields are rejected so
+// that misspelled settings are not silently ignored
+func ReadSchedule(scheduleFilePath string) (*Schedule, error) {
+	file, err := os.Open(scheduleFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	decoder := yaml.NewDecoder(file)
+	decoder.KnownFields(true)
+
+	schedule := &Schedule{}
+	err = decoder.Decode(schedule)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schedule file: %v", err)
+	}
+
+	err = schedule.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
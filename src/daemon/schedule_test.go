@@ -0,0 +1,156 @@
+package daemon_test
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/shivaji17/notionbackup/src/daemon"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeScheduleFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "schedule.yaml")
+	err := ioutil.WriteFile(path, []byte(content), 0600)
+	assert.Nil(t, err)
+	return path
+}
+
+func TestReadSchedule(t *testing.T) {
+	t.Run("Valid schedule", func(t *testing.T) {
+		schedule, err := daemon.ReadSchedule(writeScheduleFile(t, `
+state_dir: state
+jobs:
+  - name: workspace
+    schedule: "0 2 * * *"
+    incremental: true
+    destination:
+      dir: backups
+      archive: tar.gz
+    retention:
+      keep_daily: 7
+  - name: wiki
+    schedule: "CRON_TZ=Asia/Kolkata @hourly"
+    pages: [05034203-2870-4bc8-b1f9-22c0ae6e56ba]
+    concurrency: 4
+    destination:
+      s3:
+        bucket: notion-backups
+        prefix: wiki
+`))
+		assert.Nil(t, err)
+		assert.True(t, filepath.IsAbs(schedule.StateDir))
+		assert.Len(t, schedule.Jobs, 2)
+
+		job := schedule.Jobs[0]
+		assert.True(t, filepath.IsAbs(job.Destination.Dir))
+		assert.Equal(t, "tar.gz", job.Destination.Archive)
+		assert.Equal(t, 7, job.Retention.KeepDaily)
+		assert.Equal(t, 1, job.Concurrency)
+
+		job = schedule.Jobs[1]
+		assert.Equal(t, []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba"},
+			job.PageUUIDs)
+		assert.Equal(t, "notion-backups", job.Destination.S3.Bucket)
+		assert.Equal(t, 4, job.Concurrency)
+	})
+
+	invalidSchedules := map[string]string{
+		"Missing state directory": `
+jobs:
+  - name: job
+    schedule: "@daily"
+    destination:
+      dir: backups
+`,
+		"Invalid cron expression": `
+state_dir: state
+jobs:
+  - name: job
+    schedule: "0 25 * * *"
+    destination:
+      dir: backups
+`,
+		"Duplicate job names": `
+state_dir: state
+jobs:
+  - name: job
+    schedule: "@daily"
+    destination:
+      dir: backups
+  - name: job
+    schedule: "@weekly"
+    destination:
+      dir: other
+`,
+		"Duplicate destination directories": `
+state_dir: state
+jobs:
+  - name: daily
+    schedule: "@daily"
+    destination:
+      dir: backups
+  - name: weekly
+    schedule: "@weekly"
+    destination:
+      dir: ./backups/
+`,
+		"Duplicate S3 destinations": `
+state_dir: state
+jobs:
+  - name: daily
+    schedule: "@daily"
+    destination:
+      s3:
+        bucket: notion-backups
+        prefix: wiki
+  - name: weekly
+    schedule: "@weekly"
+    destination:
+      s3:
+        bucket: notion-backups
+        prefix: /wiki/
+`,
+		"Invalid job name": `
+state_dir: state
+jobs:
+  - name: ../job
+    schedule: "@daily"
+    destination:
+      dir: backups
+`,
+		"Missing destination": `
+state_dir: state
+jobs:
+  - name: job
+    schedule: "@daily"
+`,
+		"Retention with S3 destination": `
+state_dir: state
+jobs:
+  - name: job
+    schedule: "@daily"
+    destination:
+      s3:
+        bucket: notion-backups
+    retention:
+      keep_daily: 7
+`,
+		"Unknown field": `
+state_dir: state
+jobs:
+  - name: job
+    schedule: "@daily"
+    destination:
+      directory: backups
+`,
+	}
+
+	for name, content := range invalidSchedules {
+		content := content
+		t.Run(name, func(t *testing.T) {
+			_, err := daemon.ReadSchedule(writeScheduleFile(t, content))
+			assert.NotNil(t, err)
+		})
+	}
+}
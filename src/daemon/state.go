@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+const (
+	STATE_FILE_EXTENSION = ".json"
+	STATE_FILE_PERM      = 0600
+)
+
+// State of the job persisted across the restarts of the daemon
+type JobState struct {
+	LastRun     *time.Time `json:"last_run,omitempty"`
+	LastSuccess *time.Time `json:"last_success,omitempty"`
+
+	// Metadata file path, archive path or S3 URL of the last successful
+	// snapshot. Incremental snapshots are taken from it
+	LastSnapshot string `json:"last_snapshot,omitempty"`
+	LastError    string `json:"last_error,omitempty"`
+}
+
+func getStateFilePath(stateDir string, jobName string) string {
+	return filepath.Join(stateDir, jobName+STATE_FILE_EXTENSION)
+}
+
+// Read the state of the job. Empty state is returned if the job has never run
+func ReadJobState(stateDir string, jobName string) (*JobState, error) {
+	dataBytes, err := ioutil.ReadFile(getStateFilePath(stateDir, jobName))
+	if os.IsNotExist(err) {
+		return &JobState{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state := &JobState{}
+	err = json.Unmarshal(dataBytes, state)
+	if err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+// Write the state of the job. State is written to a temporary file which is
+// then renamed, so that the state is not lost if the daemon is killed while
+// writing it
+func writeJobState(stateDir string, jobName string, state *JobState) error {
+	err := utils.CreateDirectory(stateDir)
+	if err != nil {
+		return err
+	}
+
+	dataBytes, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	stateFilePath := getStateFilePath(stateDir, jobName)
+	tempFilePath := stateFilePath + ".tmp"
+	err = ioutil.WriteFile(tempFilePath, dataBytes, STATE_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tempFilePath, stateFilePath)
+}
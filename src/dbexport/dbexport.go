@@ -0,0 +1,256 @@
+package dbexport
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	JSONL Format = "jsonl"
+
+	EXPORT_FILE_PERM = 0644
+)
+
+// Check if the format is supported
+func (f Format) IsValid() bool {
+	return f == CSV || f == JSONL
+}
+
+// Row of the database exported in JSON Lines format
+type jsonRow struct {
+	ID         string                 `json:"id"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// DatabaseExporter exports every database of the tree as a file with one row
+// per page of the database
+type DatabaseExporter struct {
+	rwClient  rw.ReaderWriter
+	treeObj   *tree.Tree
+	outDir    string
+	format    Format
+	usedNames map[string]bool
+}
+
+func GetDatabaseExporter(rwClient rw.ReaderWriter, treeObj *tree.Tree,
+	outDir string, format Format) *DatabaseExporter {
+	return &DatabaseExporter{
+		rwClient:  rwClient,
+		treeObj:   treeObj,
+		outDir:    outDir,
+		format:    format,
+		usedNames: make(map[string]bool),
+	}
+}
+
+// Get the names of the properties of database schema in the order of columns.
+// Title property is the first column and rest of the properties are sorted by
+// name as Notion API does not preserve the order of properties
+func GetColumnNames(database *notionapi.Database) []string {
+	columns := make([]string, 0, len(database.Properties))
+	var titleColumn string
+	for name, config := range database.Properties {
+		if config.GetType() == notionapi.PropertyConfigTypeTitle {
+			titleColumn = name
+			continue
+		}
+		columns = append(columns, name)
+	}
+
+	sort.Strings(columns)
+	if titleColumn != "" {
+		columns = append([]string{titleColumn}, columns...)
+	}
+
+	return columns
+}
+
+// Helper function to get the file name not used by any other exported
+// database. Names are compared case insensitively as few filesystems are case
+// insensitive
+func (e *DatabaseExporter) getUniqueFileName(title string) string {
+	name := utils.SanitizeFileName(title)
+	fileName := name + "." + string(e.format)
+	for i := 2; e.usedNames[strings.ToLower(fileName)]; i++ {
+		fileName = fmt.Sprintf("%s (%d).%s", name, i, e.format)
+	}
+
+	e.usedNames[strings.ToLower(fileName)] = true
+	return fileName
+}
+
+// Helper function to read all the pages of the database node
+func (e *DatabaseExporter) readPages(ctx context.Context,
+	databaseNode *node.Node) ([]*notionapi.Page, error) {
+	pages := make([]*notionapi.Page, 0)
+	iter := iterator.GetChildIterator(databaseNode)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if childNode.GetNodeType() != node.PAGE {
+			continue
+		}
+
+		page, err := e.rwClient.ReadPage(ctx, childNode.GetStorageIdentifier())
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str(logging.PageUUID,
+				childNode.GetNotionObjectId()).Msg("Failed to read page")
+			return nil, err
+		}
+
+		pages = append(pages, page)
+	}
+
+	return pages, nil
+}
+
+// Write the pages as CSV with a header row of column names
+func writeCSV(writer io.Writer, columns []string,
+	pages []*notionapi.Page) error {
+	csvWriter := csv.NewWriter(writer)
+	err := csvWriter.Write(columns)
+	if err != nil {
+		return err
+	}
+
+	for _, page := range pages {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			if property, found := page.Properties[column]; found {
+				record[i] = FormatValue(GetPropertyValue(property))
+			}
+		}
+
+		err = csvWriter.Write(record)
+		if err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Write the pages as JSON Lines, one JSON object per page
+func writeJSONL(writer io.Writer, columns []string,
+	pages []*notionapi.Page) error {
+	encoder := json.NewEncoder(writer)
+	for _, page := range pages {
+		row := jsonRow{
+			ID:         page.ID.String(),
+			Properties: make(map[string]interface{}),
+		}
+
+		for _, column := range columns {
+			var value interface{}
+			if property, found := page.Properties[column]; found {
+				value = GetPropertyValue(property)
+			}
+			row.Properties[column] = value
+		}
+
+		err := encoder.Encode(row)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Export the database node with all of its pages
+func (e *DatabaseExporter) exportDatabase(ctx context.Context,
+	databaseNode *node.Node) error {
+	database, err := e.rwClient.ReadDatabase(ctx,
+		databaseNode.GetStorageIdentifier())
+	if err != nil {
+		return err
+	}
+
+	pages, err := e.readPages(ctx, databaseNode)
+	if err != nil {
+		return err
+	}
+
+	filePath := filepath.Join(e.outDir,
+		e.getUniqueFileName(utils.GetDatabaseTitle(database)))
+	file, err := os.OpenFile(filePath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		EXPORT_FILE_PERM)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	columns := GetColumnNames(database)
+	if e.format == JSONL {
+		err = writeJSONL(file, columns, pages)
+	} else {
+		err = writeCSV(file, columns, pages)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Debug().Str(logging.ExportPath, filePath).Msg(
+		"Database exported")
+	return file.Close()
+}
+
+// Export all the databases of the tree
+func (e *DatabaseExporter) Export(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+	if !e.format.IsValid() {
+		return fmt.Errorf("unsupported export format: %s", e.format)
+	}
+
+	err := utils.CreateDirectory(e.outDir)
+	if err != nil {
+		log.Error().Err(err).Str(logging.ExportPath, e.outDir).Msg(
+			"Failed to create output directory")
+		return err
+	}
+
+	iter := iterator.GetTreeIterator(e.treeObj.RootNode)
+	for {
+		nodeObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if nodeObj.GetNodeType() != node.DATABASE {
+			continue
+		}
+
+		err = e.exportDatabase(ctx, nodeObj)
+		if err != nil {
+			log.Error().Err(err).Str(logging.DatabaseUUID,
+				nodeObj.GetNotionObjectId()).Msg("Failed to export database")
+			return err
+		}
+	}
+
+	return nil
+}
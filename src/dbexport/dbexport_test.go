@@ -0,0 +1,297 @@
+package dbexport_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/dbexport"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func getDate(t *testing.T, value string) *notionapi.Date {
+	date := &notionapi.Date{}
+	err := date.UnmarshalText([]byte(value))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return date
+}
+
+func getRichText(content string) []notionapi.RichText {
+	return []notionapi.RichText{
+		{
+			Type:      notionapi.ObjectTypeText,
+			Text:      &notionapi.Text{Content: content},
+			PlainText: content,
+		},
+	}
+}
+
+func TestGetPropertyValue(t *testing.T) {
+	tests := []struct {
+		name          string
+		property      notionapi.Property
+		expectedValue interface{}
+		expectedText  string
+	}{
+		{
+			name:          "Title",
+			property:      &notionapi.TitleProperty{Title: getRichText("Task")},
+			expectedValue: "Task",
+			expectedText:  "Task",
+		},
+		{
+			name:          "Number",
+			property:      &notionapi.NumberProperty{Number: 42.5},
+			expectedValue: 42.5,
+			expectedText:  "42.5",
+		},
+		{
+			name: "Select",
+			property: &notionapi.SelectProperty{
+				Select: notionapi.Option{Name: "High"},
+			},
+			expectedValue: "High",
+			expectedText:  "High",
+		},
+		{
+			name:          "Empty select",
+			property:      &notionapi.SelectProperty{},
+			expectedValue: nil,
+			expectedText:  "",
+		},
+		{
+			name: "Multi select",
+			property: &notionapi.MultiSelectProperty{
+				MultiSelect: []notionapi.Option{{Name: "a"}, {Name: "b"}},
+			},
+			expectedValue: []interface{}{"a", "b"},
+			expectedText:  "a, b",
+		},
+		{
+			name: "Date range",
+			property: &notionapi.DateProperty{
+				Date: &notionapi.DateObject{
+					Start: getDate(t, "2022-05-01"),
+					End:   getDate(t, "2022-05-03T10:00:00+05:30"),
+				},
+			},
+			expectedValue: dbexport.DateValue{
+				Start: "2022-05-01",
+				End:   "2022-05-03T10:00:00+05:30",
+			},
+			expectedText: "2022-05-01/2022-05-03T10:00:00+05:30",
+		},
+		{
+			name:          "Empty date",
+			property:      &notionapi.DateProperty{},
+			expectedValue: nil,
+			expectedText:  "",
+		},
+		{
+			name: "Relation",
+			property: &notionapi.RelationProperty{
+				Relation: []notionapi.Relation{{ID: "id1"}, {ID: "id2"}},
+			},
+			expectedValue: []interface{}{"id1", "id2"},
+			expectedText:  "id1, id2",
+		},
+		{
+			name: "People",
+			property: &notionapi.PeopleProperty{
+				People: []notionapi.User{{ID: "user1", Name: "Some Name"},
+					{ID: "user2"}},
+			},
+			expectedValue: []interface{}{
+				dbexport.UserValue{ID: "user1", Name: "Some Name"},
+				dbexport.UserValue{ID: "user2"},
+			},
+			expectedText: "Some Name, user2",
+		},
+		{
+			name: "Boolean formula",
+			property: &notionapi.FormulaProperty{
+				Formula: notionapi.Formula{
+					Type:    notionapi.FormulaTypeBoolean,
+					Boolean: true,
+				},
+			},
+			expectedValue: true,
+			expectedText:  "true",
+		},
+		{
+			name: "Array rollup",
+			property: &notionapi.RollupProperty{
+				Rollup: notionapi.Rollup{
+					Type: notionapi.RollupTypeArray,
+					Array: notionapi.PropertyArray{
+						&notionapi.NumberProperty{Number: 1},
+						&notionapi.NumberProperty{Number: 2},
+					},
+				},
+			},
+			expectedValue: []interface{}{float64(1), float64(2)},
+			expectedText:  "1, 2",
+		},
+		{
+			name: "Files",
+			property: &notionapi.FilesProperty{
+				Files: []notionapi.File{
+					{
+						Name:     "file.pdf",
+						External: &notionapi.FileObject{URL: "https://some.url"},
+					},
+				},
+			},
+			expectedValue: []interface{}{
+				dbexport.FileValue{Name: "file.pdf", URL: "https://some.url"},
+			},
+			expectedText: "https://some.url",
+		},
+		{
+			name: "Created time",
+			property: &notionapi.CreatedTimeProperty{
+				CreatedTime: time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC),
+			},
+			expectedValue: "2022-05-01T10:30:00Z",
+			expectedText:  "2022-05-01T10:30:00Z",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			value := dbexport.GetPropertyValue(test.property)
+			assert.Equal(t, test.expectedValue, value)
+			assert.Equal(t, test.expectedText, dbexport.FormatValue(value))
+		})
+	}
+}
+
+// Create tree with single database having two pages
+func createDatabaseTree(t *testing.T, mockedRW *mocks.ReaderWriter) *tree.Tree {
+	rootNode := node.CreateRootNode()
+	database := &notionapi.Database{
+		ID:    notionapi.ObjectID(uuid.NewString()),
+		Title: getRichText("Tasks"),
+		Properties: notionapi.PropertyConfigs{
+			"Tags": &notionapi.MultiSelectPropertyConfig{
+				Type: notionapi.PropertyConfigTypeMultiSelect,
+			},
+			"Name": &notionapi.TitlePropertyConfig{
+				Type: notionapi.PropertyConfigTypeTitle,
+			},
+			"Done": &notionapi.CheckboxPropertyConfig{
+				Type: notionapi.PropertyConfigTypeCheckbox,
+			},
+		},
+	}
+
+	databaseNode, err := node.CreateNode(&metadata.NotionObject{
+		Uuid:              uuid.NewString(),
+		Type:              metadata.NotionObjectType_DATABASE,
+		StorageIdentifier: "database",
+		NotionObjectId:    database.ID.String(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rootNode.AddChild(databaseNode)
+	mockedRW.On("ReadDatabase", context.Background(),
+		rw.DataIdentifier("database")).Return(database, nil)
+
+	pages := []*notionapi.Page{
+		{
+			ID: "page1",
+			Properties: notionapi.Properties{
+				"Name": &notionapi.TitleProperty{Title: getRichText("First, task")},
+				"Tags": &notionapi.MultiSelectProperty{
+					MultiSelect: []notionapi.Option{{Name: "a"}, {Name: "b"}},
+				},
+				"Done": &notionapi.CheckboxProperty{Checkbox: true},
+			},
+		},
+		{
+			ID: "page2",
+			Properties: notionapi.Properties{
+				"Name": &notionapi.TitleProperty{Title: getRichText("Second")},
+			},
+		},
+	}
+
+	for _, page := range pages {
+		pageNode, err := node.CreateNode(&metadata.NotionObject{
+			Uuid:              uuid.NewString(),
+			Type:              metadata.NotionObjectType_PAGE,
+			StorageIdentifier: page.ID.String(),
+			NotionObjectId:    page.ID.String(),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		databaseNode.AddChild(pageNode)
+		mockedRW.On("ReadPage", context.Background(),
+			rw.DataIdentifier(page.ID.String())).Return(page, nil)
+	}
+
+	return &tree.Tree{RootNode: rootNode}
+}
+
+func TestDatabaseExport(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          dbexport.Format
+		fileName        string
+		expectedContent string
+		wantErr         bool
+	}{
+		{
+			name:     "Export as CSV",
+			format:   dbexport.CSV,
+			fileName: "Tasks.csv",
+			expectedContent: "Name,Done,Tags\n" +
+				"\"First, task\",true,\"a, b\"\n" +
+				"Second,,\n",
+		},
+		{
+			name:     "Export as JSON Lines",
+			format:   dbexport.JSONL,
+			fileName: "Tasks.jsonl",
+			expectedContent: `{"id":"page1","properties":{"Done":true,` +
+				`"Name":"First, task","Tags":["a","b"]}}` + "\n" +
+				`{"id":"page2","properties":{"Done":null,"Name":"Second",` +
+				`"Tags":null}}` + "\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockedRW := mocks.NewReaderWriter(t)
+			outDir := t.TempDir()
+			err := dbexport.GetDatabaseExporter(mockedRW,
+				createDatabaseTree(t, mockedRW), outDir, test.format).
+				Export(context.Background())
+			assert.Nil(t, err)
+
+			content, err := os.ReadFile(filepath.Join(outDir, test.fileName))
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedContent, string(content))
+		})
+	}
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		err := dbexport.GetDatabaseExporter(mocks.NewReaderWriter(t),
+			&tree.Tree{RootNode: node.CreateRootNode()}, t.TempDir(), "xml").
+			Export(context.Background())
+		assert.NotNil(t, err)
+	})
+}
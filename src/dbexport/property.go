@@ -0,0 +1,234 @@
+package dbexport
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+const (
+	DATE_FORMAT     = "2006-01-02"
+	VALUE_SEPARATOR = ", "
+)
+
+// Date or date range value of a property
+type DateValue struct {
+	Start string `json:"start"`
+	End   string `json:"end,omitempty"`
+}
+
+// Date ranges are formatted as ISO 8601 time interval
+func (d DateValue) String() string {
+	if d.End == "" {
+		return d.Start
+	}
+
+	return d.Start + "/" + d.End
+}
+
+// User value of people, created by and last edited by properties
+type UserValue struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+func (u UserValue) String() string {
+	if u.Name == "" {
+		return u.ID
+	}
+
+	return u.Name
+}
+
+// File value of files property
+type FileValue struct {
+	Name string `json:"name,omitempty"`
+	URL  string `json:"url"`
+}
+
+func (f FileValue) String() string {
+	if f.URL == "" {
+		return f.Name
+	}
+
+	return f.URL
+}
+
+// Helper function to format the date. Notion does not differentiate dates and
+// datetimes in API, so datetimes at midnight UTC are considered as dates
+func formatDate(date *notionapi.Date) string {
+	if date == nil {
+		return ""
+	}
+
+	t := time.Time(*date)
+	if t.Equal(t.UTC().Truncate(24 * time.Hour)) {
+		return t.UTC().Format(DATE_FORMAT)
+	}
+
+	return t.Format(time.RFC3339)
+}
+
+// Helper function to get the value of date object. nil is returned for empty
+// date
+func getDateValue(date *notionapi.DateObject) interface{} {
+	if date == nil || date.Start == nil {
+		return nil
+	}
+
+	return DateValue{
+		Start: formatDate(date.Start),
+		End:   formatDate(date.End),
+	}
+}
+
+func getUserValue(user notionapi.User) UserValue {
+	return UserValue{
+		ID:   user.ID.String(),
+		Name: user.Name,
+	}
+}
+
+// Helper function to get the value of string property. nil is returned for
+// empty string as Notion does not differentiate empty and unset values
+func getStringValue(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
+
+func getFormulaValue(formula notionapi.Formula) interface{} {
+	switch formula.Type {
+	case notionapi.FormulaTypeString:
+		return getStringValue(formula.String)
+	case notionapi.FormulaTypeNumber:
+		return formula.Number
+	case notionapi.FormulaTypeBoolean:
+		return formula.Boolean
+	case notionapi.FormulaTypeDate:
+		return getDateValue(formula.Date)
+	}
+
+	return nil
+}
+
+func getRollupValue(rollup notionapi.Rollup) interface{} {
+	switch rollup.Type {
+	case notionapi.RollupTypeNumber:
+		return rollup.Number
+	case notionapi.RollupTypeDate:
+		return getDateValue(rollup.Date)
+	case notionapi.RollupTypeArray:
+		values := make([]interface{}, 0, len(rollup.Array))
+		for _, property := range rollup.Array {
+			values = append(values, GetPropertyValue(property))
+		}
+		return values
+	}
+
+	return nil
+}
+
+// Get the value of the property keeping its type. Values are one of nil,
+// string, float64, bool, DateValue, UserValue, FileValue or list of these
+func GetPropertyValue(property notionapi.Property) interface{} {
+	switch p := property.(type) {
+	case *notionapi.TitleProperty:
+		return utils.GetPlainText(p.Title)
+	case *notionapi.RichTextProperty:
+		return utils.GetPlainText(p.RichText)
+	case *notionapi.TextProperty:
+		return utils.GetPlainText(p.Text)
+	case *notionapi.NumberProperty:
+		return p.Number
+	case *notionapi.SelectProperty:
+		return getStringValue(p.Select.Name)
+	case *notionapi.StatusProperty:
+		return getStringValue(p.Status.Name)
+	case *notionapi.MultiSelectProperty:
+		values := make([]interface{}, 0, len(p.MultiSelect))
+		for _, option := range p.MultiSelect {
+			values = append(values, option.Name)
+		}
+		return values
+	case *notionapi.DateProperty:
+		return getDateValue(p.Date)
+	case *notionapi.FormulaProperty:
+		return getFormulaValue(p.Formula)
+	case *notionapi.RelationProperty:
+		values := make([]interface{}, 0, len(p.Relation))
+		for _, relation := range p.Relation {
+			values = append(values, relation.ID.String())
+		}
+		return values
+	case *notionapi.RollupProperty:
+		return getRollupValue(p.Rollup)
+	case *notionapi.PeopleProperty:
+		values := make([]interface{}, 0, len(p.People))
+		for _, user := range p.People {
+			values = append(values, getUserValue(user))
+		}
+		return values
+	case *notionapi.FilesProperty:
+		values := make([]interface{}, 0, len(p.Files))
+		for _, file := range p.Files {
+			var url string
+			if file.File != nil {
+				url = file.File.URL
+			} else if file.External != nil {
+				url = file.External.URL
+			}
+			values = append(values, FileValue{Name: file.Name, URL: url})
+		}
+		return values
+	case *notionapi.CheckboxProperty:
+		return p.Checkbox
+	case *notionapi.URLProperty:
+		return getStringValue(p.URL)
+	case *notionapi.EmailProperty:
+		return getStringValue(p.Email)
+	case *notionapi.PhoneNumberProperty:
+		return getStringValue(p.PhoneNumber)
+	case *notionapi.CreatedTimeProperty:
+		return p.CreatedTime.Format(time.RFC3339)
+	case *notionapi.LastEditedTimeProperty:
+		return p.LastEditedTime.Format(time.RFC3339)
+	case *notionapi.CreatedByProperty:
+		return getUserValue(p.CreatedBy)
+	case *notionapi.LastEditedByProperty:
+		return getUserValue(p.LastEditedBy)
+	}
+
+	return nil
+}
+
+// Format the value returned by GetPropertyValue as a flat string. Items of
+// list are separated by VALUE_SEPARATOR
+func FormatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, FormatValue(item))
+		}
+		return strings.Join(values, VALUE_SEPARATOR)
+	case fmt.Stringer:
+		return v.String()
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,402 @@
+package differ
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+type ChangeType string
+
+const (
+	ADDED   ChangeType = "added"
+	REMOVED ChangeType = "removed"
+	MOVED   ChangeType = "moved"
+	CHANGED ChangeType = "changed"
+)
+
+// Difference of an object between the snapshots. Object which is moved and
+// changed is reported once for each of them
+type Change struct {
+	Type           ChangeType `json:"type"`
+	ObjectType     string     `json:"object_type"`
+	NotionObjectId string     `json:"notion_object_id"`
+
+	// Title of the page or database and type of the block
+	Title       string     `json:"title,omitempty"`
+	OldParentId string     `json:"old_parent_id,omitempty"`
+	NewParentId string     `json:"new_parent_id,omitempty"`
+	TextDiff    []TextEdit `json:"text_diff,omitempty"`
+}
+
+// Result of the comparison of the snapshots
+type Report struct {
+	OldMetadataFilePath string   `json:"old_metadata_file_path"`
+	NewMetadataFilePath string   `json:"new_metadata_file_path"`
+	Added               int      `json:"added"`
+	Removed             int      `json:"removed"`
+	Moved               int      `json:"moved"`
+	Changed             int      `json:"changed"`
+	Unchanged           int      `json:"unchanged"`
+	Changes             []Change `json:"changes"`
+}
+
+// Snapshot of the backup being compared
+type snapshot struct {
+	rwClient rw.ReaderWriter
+	tree     *tree.Tree
+	nodes    []*node.Node
+	nodeMap  map[string]*node.Node
+}
+
+// Differ compares two snapshots of the backup matching the objects by their
+// notion object ID, as UUIDs of the nodes differ in every snapshot
+type Differ struct {
+	oldSnapshot *snapshot
+	newSnapshot *snapshot
+	textDiff    bool
+	report      *Report
+}
+
+type DifferOption func(*Differ)
+
+// Report the word level difference of the rich text of the changed blocks
+func WithTextDiff() DifferOption {
+	return func(d *Differ) {
+		d.textDiff = true
+	}
+}
+
+func GetDiffer(oldRW rw.ReaderWriter, oldTree *tree.Tree, newRW rw.ReaderWriter,
+	newTree *tree.Tree, opts ...DifferOption) *Differ {
+	d := &Differ{
+		oldSnapshot: &snapshot{rwClient: oldRW, tree: oldTree},
+		newSnapshot: &snapshot{rwClient: newRW, tree: newTree},
+	}
+
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Check if the node is the child_page or child_database block linking the
+// page or database to its parent. Link blocks are compared as part of their
+// page or database
+func isLinkBlock(nodeObj *node.Node) bool {
+	childNode := nodeObj.GetChildNode()
+	return nodeObj.GetNodeType() == node.BLOCK && childNode != nil &&
+		childNode.GetNotionObjectId() == nodeObj.GetNotionObjectId() &&
+		(childNode.GetNodeType() == node.PAGE ||
+			childNode.GetNodeType() == node.DATABASE)
+}
+
+// Helper function to get the key with which node is matched in the other
+// snapshot. Child page block and the page itself have same notion object ID
+func getKey(nodeObj *node.Node) string {
+	return string(nodeObj.GetNodeType()) + ":" + nodeObj.GetNotionObjectId()
+}
+
+// Helper function to get the notion object ID of the parent of the node
+// skipping the link blocks. Empty ID is returned for the top level objects
+func getParentId(nodeObj *node.Node) string {
+	parentNode := nodeObj.GetParentNode()
+	for parentNode != nil && isLinkBlock(parentNode) {
+		parentNode = parentNode.GetParentNode()
+	}
+
+	if parentNode == nil || parentNode.GetNodeType() == node.ROOT {
+		return ""
+	}
+
+	return parentNode.GetNotionObjectId()
+}
+
+// Helper function to collect the nodes of the subtree in depth first order
+func collectNodes(nodeObj *node.Node, nodes []*node.Node) []*node.Node {
+	for childNode := nodeObj.GetChildNode(); childNode != nil; childNode =
+		childNode.GetSiblingNode() {
+		if !isLinkBlock(childNode) {
+			nodes = append(nodes, childNode)
+		}
+		nodes = collectNodes(childNode, nodes)
+	}
+
+	return nodes
+}
+
+// Helper function to index the nodes of the snapshot with their keys
+func (s *snapshot) index() {
+	s.nodes = collectNodes(s.tree.RootNode, []*node.Node{})
+	s.nodeMap = make(map[string]*node.Node)
+	for _, nodeObj := range s.nodes {
+		if _, found := s.nodeMap[getKey(nodeObj)]; !found {
+			s.nodeMap[getKey(nodeObj)] = nodeObj
+		}
+	}
+}
+
+// Helper function to read the object of the node from the snapshot
+func (s *snapshot) readObject(ctx context.Context,
+	nodeObj *node.Node) (interface{}, error) {
+	identifier := nodeObj.GetStorageIdentifier()
+	switch nodeObj.GetNodeType() {
+	case node.PAGE:
+		return s.rwClient.ReadPage(ctx, identifier)
+	case node.DATABASE:
+		return s.rwClient.ReadDatabase(ctx, identifier)
+	case node.BLOCK:
+		return s.rwClient.ReadBlock(ctx, identifier)
+	case node.COMMENT:
+		return s.rwClient.ReadComment(ctx, identifier)
+	}
+
+	return nil, fmt.Errorf("unknown node type: %s", nodeObj.GetNodeType())
+}
+
+// Helper function to get the checksum of the object of the node. Object is
+// read if the checksum is not recorded by the snapshot
+func (s *snapshot) getChecksum(ctx context.Context,
+	nodeObj *node.Node) (string, error) {
+	if nodeObj.GetChecksum() != "" {
+		return nodeObj.GetChecksum(), nil
+	}
+
+	object, err := s.readObject(ctx, nodeObj)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.GetObjectChecksum(object)
+}
+
+// Helper function to get the title of the page or database and the type of
+// the block
+func (s *snapshot) getTitle(ctx context.Context,
+	nodeObj *node.Node) (string, error) {
+	if nodeObj.GetNodeType() == node.COMMENT {
+		return "", nil
+	}
+
+	object, err := s.readObject(ctx, nodeObj)
+	if err != nil {
+		return "", err
+	}
+
+	switch object := object.(type) {
+	case *notionapi.Page:
+		return utils.GetPageTitle(object), nil
+	case *notionapi.Database:
+		return utils.GetDatabaseTitle(object), nil
+	case notionapi.Block:
+		return string(object.GetType()), nil
+	}
+
+	return "", nil
+}
+
+// Helper function to get the rich text of the block. false is returned if the
+// block does not have any rich text
+func getBlockRichText(block notionapi.Block) ([]notionapi.RichText, bool,
+	error) {
+	dataBytes, err := json.Marshal(block)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var decoded map[string]json.RawMessage
+	err = json.Unmarshal(dataBytes, &decoded)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var content map[string]json.RawMessage
+	err = json.Unmarshal(decoded[string(block.GetType())], &content)
+	if err != nil || content["rich_text"] == nil {
+		return nil, false, nil
+	}
+
+	richText := []notionapi.RichText{}
+	err = json.Unmarshal(content["rich_text"], &richText)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return richText, true, nil
+}
+
+// Helper function to get the word level difference of the rich text of the
+// changed block. nil is returned if plain text of the block is not changed
+func (d *Differ) getTextDiff(ctx context.Context, oldNode *node.Node,
+	newNode *node.Node) ([]TextEdit, error) {
+	if !d.textDiff || newNode.GetNodeType() != node.BLOCK {
+		return nil, nil
+	}
+
+	texts := []string{}
+	for _, pair := range []struct {
+		s       *snapshot
+		nodeObj *node.Node
+	}{{d.oldSnapshot, oldNode}, {d.newSnapshot, newNode}} {
+		block, err := pair.s.rwClient.ReadBlock(ctx,
+			pair.nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return nil, err
+		}
+
+		richText, found, err := getBlockRichText(block)
+		if err != nil || !found {
+			return nil, err
+		}
+		texts = append(texts, utils.GetPlainText(richText))
+	}
+
+	if texts[0] == texts[1] {
+		return nil, nil
+	}
+
+	return diffText(texts[0], texts[1]), nil
+}
+
+// Helper function to check if the object is changed between the snapshots.
+// Last edited time is compared if both the snapshots record it, as objects
+// like pages with files hosted by Notion are serialized differently every time
+func (d *Differ) isChanged(ctx context.Context, oldNode *node.Node,
+	newNode *node.Node) (bool, error) {
+	if !oldNode.GetLastEditedTime().IsZero() &&
+		!newNode.GetLastEditedTime().IsZero() {
+		return !oldNode.GetLastEditedTime().Equal(newNode.GetLastEditedTime()),
+			nil
+	}
+
+	oldChecksum, err := d.oldSnapshot.getChecksum(ctx, oldNode)
+	if err != nil {
+		return false, err
+	}
+
+	newChecksum, err := d.newSnapshot.getChecksum(ctx, newNode)
+	if err != nil {
+		return false, err
+	}
+
+	return oldChecksum != newChecksum, nil
+}
+
+// Helper function to add the change of the object to the report
+func (d *Differ) addChange(ctx context.Context, changeType ChangeType,
+	s *snapshot, nodeObj *node.Node, change Change) error {
+	title, err := s.getTitle(ctx, nodeObj)
+	if err != nil {
+		return err
+	}
+
+	change.Type = changeType
+	change.ObjectType = strings.ToLower(string(nodeObj.GetNodeType()))
+	change.NotionObjectId = nodeObj.GetNotionObjectId()
+	change.Title = title
+	d.report.Changes = append(d.report.Changes, change)
+
+	switch changeType {
+	case ADDED:
+		d.report.Added++
+	case REMOVED:
+		d.report.Removed++
+	case MOVED:
+		d.report.Moved++
+	case CHANGED:
+		d.report.Changed++
+	}
+
+	return nil
+}
+
+// Helper function to compare the object present in both the snapshots
+func (d *Differ) compareNodes(ctx context.Context, oldNode *node.Node,
+	newNode *node.Node) error {
+	oldParentId, newParentId := getParentId(oldNode), getParentId(newNode)
+	moved := oldParentId != newParentId
+	if moved {
+		err := d.addChange(ctx, MOVED, d.newSnapshot, newNode, Change{
+			OldParentId: oldParentId,
+			NewParentId: newParentId,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	changed, err := d.isChanged(ctx, oldNode, newNode)
+	if err != nil {
+		return err
+	}
+
+	if !changed {
+		if !moved {
+			d.report.Unchanged++
+		}
+		return nil
+	}
+
+	textDiff, err := d.getTextDiff(ctx, oldNode, newNode)
+	if err != nil {
+		return err
+	}
+
+	return d.addChange(ctx, CHANGED, d.newSnapshot, newNode, Change{
+		TextDiff: textDiff,
+	})
+}
+
+// Compare the snapshots. Changes are reported in the depth first order of the
+// new snapshot followed by the objects removed from the old snapshot
+func (d *Differ) Diff(ctx context.Context) (*Report, error) {
+	log := zerolog.Ctx(ctx)
+	d.report = &Report{Changes: []Change{}}
+	d.oldSnapshot.index()
+	d.newSnapshot.index()
+
+	for _, newNode := range d.newSnapshot.nodes {
+		oldNode, found := d.oldSnapshot.nodeMap[getKey(newNode)]
+		var err error
+		if found {
+			err = d.compareNodes(ctx, oldNode, newNode)
+		} else {
+			err = d.addChange(ctx, ADDED, d.newSnapshot, newNode, Change{
+				NewParentId: getParentId(newNode),
+			})
+		}
+
+		if err != nil {
+			log.Error().Err(err).Str(logging.NodeID, newNode.GetID().String()).Msg(
+				"Failed to compare the object")
+			return nil, err
+		}
+	}
+
+	for _, oldNode := range d.oldSnapshot.nodes {
+		if _, found := d.newSnapshot.nodeMap[getKey(oldNode)]; found {
+			continue
+		}
+
+		err := d.addChange(ctx, REMOVED, d.oldSnapshot, oldNode, Change{
+			OldParentId: getParentId(oldNode),
+		})
+		if err != nil {
+			log.Error().Err(err).Str(logging.NodeID, oldNode.GetID().String()).Msg(
+				"Failed to read the removed object")
+			return nil, err
+		}
+	}
+
+	return d.report, nil
+}
@@ -0,0 +1,213 @@
+package differ_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/differ"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/builder"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+var editedTime = time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC)
+
+func getPage(id string, title string) *notionapi.Page {
+	return &notionapi.Page{
+		Object:         notionapi.ObjectTypePage,
+		ID:             notionapi.ObjectID(id),
+		LastEditedTime: editedTime,
+		Properties: notionapi.Properties{
+			"title": &notionapi.TitleProperty{
+				Type:  notionapi.PropertyTypeTitle,
+				Title: []notionapi.RichText{{PlainText: title}},
+			},
+		},
+	}
+}
+
+func getParagraph(id string, content string,
+	lastEditedTime time.Time) notionapi.Block {
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object:         notionapi.ObjectTypeBlock,
+			ID:             notionapi.BlockID(id),
+			Type:           notionapi.BlockTypeParagraph,
+			LastEditedTime: &lastEditedTime,
+		},
+		Paragraph: notionapi.Paragraph{
+			RichText: []notionapi.RichText{{PlainText: content}},
+		},
+	}
+}
+
+func getChildPageBlock(id string) notionapi.Block {
+	return &notionapi.ChildPageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object:         notionapi.ObjectTypeBlock,
+			ID:             notionapi.BlockID(id),
+			Type:           notionapi.BlockTypeChildPage,
+			LastEditedTime: &editedTime,
+		},
+	}
+}
+
+// Helper function to write the objects as a backup and build its tree from
+// the metadata. children maps the ID of the parent to the objects added to it
+// in order, with empty ID for the top level objects
+func createSnapshot(t *testing.T, children map[string][]interface{}) (
+	rw.ReaderWriter, *tree.Tree) {
+	ctx := context.Background()
+	rwClient, err := rw.GetFileReaderWriter(ctx, t.TempDir(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var addChildren func(parentNode *node.Node, parentId string)
+	addChildren = func(parentNode *node.Node, parentId string) {
+		for _, object := range children[parentId] {
+			var nodeObj *node.Node
+			switch object := object.(type) {
+			case *notionapi.Page:
+				nodeObj, err = node.CreatePageNode(ctx, object, rwClient)
+			case notionapi.Block:
+				nodeObj, err = node.CreateBlockNode(ctx, object, rwClient)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			parentNode.AddChild(nodeObj)
+			childId := nodeObj.GetNotionObjectId()
+			if nodeObj.GetNodeType() == node.BLOCK {
+				childId = "block:" + childId
+			}
+			addChildren(nodeObj, childId)
+		}
+	}
+
+	rootNode := node.CreateRootNode()
+	addChildren(rootNode, "")
+
+	metadataObj, err := exporter.CreateMetadata(ctx,
+		&tree.Tree{RootNode: rootNode})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	treeObj, err := builder.GetMetaDataTreeBuilder(ctx, metadataObj).
+		BuildTree(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return rwClient, treeObj
+}
+
+func TestDiff(t *testing.T) {
+	assert := assert.New(t)
+	ctx := context.Background()
+	laterTime := editedTime.Add(time.Hour)
+
+	oldRW, oldTree := createSnapshot(t, map[string][]interface{}{
+		"": {getPage("page_a", "A"), getPage("page_b", "B")},
+		"page_a": {
+			getParagraph("block_1", "hello world", editedTime),
+			getParagraph("block_2", "removed", editedTime),
+			getChildPageBlock("page_c"),
+		},
+		"block:page_c": {getPage("page_c", "C")},
+	})
+
+	// Page C is moved from page A to page B
+	newRW, newTree := createSnapshot(t, map[string][]interface{}{
+		"": {getPage("page_a", "A"), getPage("page_b", "B")},
+		"page_a": {
+			getParagraph("block_1", "hello there", laterTime),
+		},
+		"page_b": {
+			getParagraph("block_3", "added", editedTime),
+			getChildPageBlock("page_c"),
+		},
+		"block:page_c": {getPage("page_c", "C")},
+	})
+
+	t.Run("Without text diff", func(t *testing.T) {
+		report, err := differ.GetDiffer(oldRW, oldTree, newRW, newTree).Diff(ctx)
+		assert.Nil(err)
+		assert.Equal(1, report.Added)
+		assert.Equal(1, report.Removed)
+		assert.Equal(1, report.Moved)
+		assert.Equal(1, report.Changed)
+		assert.Equal(2, report.Unchanged)
+		assert.Equal([]differ.Change{
+			{
+				Type:           differ.CHANGED,
+				ObjectType:     "block",
+				NotionObjectId: "block_1",
+				Title:          "paragraph",
+			},
+			{
+				Type:           differ.ADDED,
+				ObjectType:     "block",
+				NotionObjectId: "block_3",
+				Title:          "paragraph",
+				NewParentId:    "page_b",
+			},
+			{
+				Type:           differ.MOVED,
+				ObjectType:     "page",
+				NotionObjectId: "page_c",
+				Title:          "C",
+				OldParentId:    "page_a",
+				NewParentId:    "page_b",
+			},
+			{
+				Type:           differ.REMOVED,
+				ObjectType:     "block",
+				NotionObjectId: "block_2",
+				Title:          "paragraph",
+				OldParentId:    "page_a",
+			},
+		}, report.Changes)
+	})
+
+	t.Run("With text diff", func(t *testing.T) {
+		report, err := differ.GetDiffer(oldRW, oldTree, newRW, newTree,
+			differ.WithTextDiff()).Diff(ctx)
+		assert.Nil(err)
+		assert.Equal([]differ.TextEdit{
+			{Op: differ.EQUAL, Text: "hello "},
+			{Op: differ.DELETE, Text: "world"},
+			{Op: differ.INSERT, Text: "there"},
+		}, report.Changes[0].TextDiff)
+
+		out := &bytes.Buffer{}
+		assert.Nil(report.WriteTable(out))
+		assert.Contains(out.String(), "hello [-world-]{+there+}")
+		assert.Contains(out.String(), "page_a -> page_b")
+	})
+
+	t.Run("Same snapshot", func(t *testing.T) {
+		report, err := differ.GetDiffer(oldRW, oldTree, oldRW, oldTree).Diff(ctx)
+		assert.Nil(err)
+		assert.Equal(5, report.Unchanged)
+		assert.Empty(report.Changes)
+	})
+}
+
+func TestFormatTextDiff(t *testing.T) {
+	assert.Equal(t, "keep [-old-]{+new+} text", differ.FormatTextDiff(
+		[]differ.TextEdit{
+			{Op: differ.EQUAL, Text: "keep "},
+			{Op: differ.DELETE, Text: "old"},
+			{Op: differ.INSERT, Text: "new"},
+			{Op: differ.EQUAL, Text: " text"},
+		}))
+}
@@ -0,0 +1,69 @@
+package differ
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+)
+
+// Helper function to get the printable ID of the parent. Top level objects do
+// not have a parent in the backup
+func formatParentId(parentId string) string {
+	if parentId == "" {
+		return "-"
+	}
+
+	return parentId
+}
+
+// Write the report as tables readable in terminal followed by the text
+// difference of the changed blocks
+func (r *Report) WriteTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "CHANGE\tCOUNT")
+	fmt.Fprintf(w, "Added\t%d\n", r.Added)
+	fmt.Fprintf(w, "Removed\t%d\n", r.Removed)
+	fmt.Fprintf(w, "Moved\t%d\n", r.Moved)
+	fmt.Fprintf(w, "Changed\t%d\n", r.Changed)
+	fmt.Fprintf(w, "Unchanged\t%d\n", r.Unchanged)
+
+	if len(r.Changes) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "CHANGE\tOBJECT\tNOTION OBJECT ID\tTITLE\tPARENT")
+		for _, change := range r.Changes {
+			parent := ""
+			switch change.Type {
+			case ADDED:
+				parent = formatParentId(change.NewParentId)
+			case REMOVED:
+				parent = formatParentId(change.OldParentId)
+			case MOVED:
+				parent = formatParentId(change.OldParentId) + " -> " +
+					formatParentId(change.NewParentId)
+			}
+
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", change.Type, change.ObjectType,
+				change.NotionObjectId, change.Title, parent)
+		}
+	}
+
+	err := w.Flush()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range r.Changes {
+		if len(change.TextDiff) == 0 {
+			continue
+		}
+
+		_, err = fmt.Fprintf(out, "\n%s %s %s:\n%s\n", change.Type,
+			change.Title, change.NotionObjectId, FormatTextDiff(change.TextDiff))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,130 @@
+package differ
+
+import (
+	"strings"
+	"unicode"
+)
+
+type TextOp string
+
+const (
+	EQUAL  TextOp = "equal"
+	INSERT TextOp = "insert"
+	DELETE TextOp = "delete"
+
+	// Texts with more word pairs than this are diffed as a whole to bound the
+	// memory used by the diff
+	MAX_DIFF_CELLS = 1000000
+)
+
+// Part of the text which is kept, inserted or deleted by the change
+type TextEdit struct {
+	Op   TextOp `json:"op"`
+	Text string `json:"text"`
+}
+
+// Helper function to split the text into words and runs of whitespace so that
+// joining the parts gives back the text
+func splitWords(text string) []string {
+	words := []string{}
+	start := 0
+	previousSpace := false
+	for i, r := range text {
+		space := unicode.IsSpace(r)
+		if i != 0 && space != previousSpace {
+			words = append(words, text[start:i])
+			start = i
+		}
+		previousSpace = space
+	}
+
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+
+	return words
+}
+
+// Helper function to append the text to the edits, merging it with the last
+// edit if it has the same operation
+func appendEdit(edits []TextEdit, op TextOp, text string) []TextEdit {
+	if text == "" {
+		return edits
+	}
+
+	if len(edits) != 0 && edits[len(edits)-1].Op == op {
+		edits[len(edits)-1].Text += text
+		return edits
+	}
+
+	return append(edits, TextEdit{Op: op, Text: text})
+}
+
+// Get the word level difference between the texts. Deleted words are placed
+// before the inserted words replacing them
+func diffText(oldText string, newText string) []TextEdit {
+	oldWords := splitWords(oldText)
+	newWords := splitWords(newText)
+	n, m := len(oldWords), len(newWords)
+
+	edits := []TextEdit{}
+	if n*m > MAX_DIFF_CELLS {
+		edits = appendEdit(edits, DELETE, oldText)
+		return appendEdit(edits, INSERT, newText)
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// oldWords[i:] and newWords[j:]
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldWords[i] == newWords[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && oldWords[i] == newWords[j]:
+			edits = appendEdit(edits, EQUAL, oldWords[i])
+			i++
+			j++
+		case i < n && (j == m || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = appendEdit(edits, DELETE, oldWords[i])
+			i++
+		default:
+			edits = appendEdit(edits, INSERT, newWords[j])
+			j++
+		}
+	}
+
+	return edits
+}
+
+// Format the edits in the style of word diff of git i.e. [-deleted-] and
+// {+inserted+}
+func FormatTextDiff(edits []TextEdit) string {
+	var builder strings.Builder
+	for _, edit := range edits {
+		switch edit.Op {
+		case DELETE:
+			builder.WriteString("[-" + edit.Text + "-]")
+		case INSERT:
+			builder.WriteString("{+" + edit.Text + "+}")
+		default:
+			builder.WriteString(edit.Text)
+		}
+	}
+
+	return builder.String()
+}
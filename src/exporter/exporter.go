@@ -3,6 +3,7 @@ package exporter
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/shivaji17/notionbackup/src/metadata"
@@ -10,6 +11,7 @@ import (
 	"github.com/shivaji17/notionbackup/src/tree"
 	"github.com/shivaji17/notionbackup/src/tree/iterator"
 	"github.com/shivaji17/notionbackup/src/tree/node"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 func Convert2ProtoNotionObject(nodeObj *node.Node) (*metadata.NotionObject,
@@ -18,6 +20,10 @@ func Convert2ProtoNotionObject(nodeObj *node.Node) (*metadata.NotionObject,
 	notionObj.Uuid = nodeObj.GetID().String()
 	notionObj.StorageIdentifier = nodeObj.GetStorageIdentifier().String()
 	notionObj.NotionObjectId = nodeObj.GetNotionObjectId()
+	notionObj.Checksum = nodeObj.GetChecksum()
+	if !nodeObj.GetLastEditedTime().IsZero() {
+		notionObj.LastEditedTime = timestamppb.New(nodeObj.GetLastEditedTime())
+	}
 
 	switch nodeObj.GetNodeType() {
 	case node.ROOT:
@@ -28,6 +34,8 @@ func Convert2ProtoNotionObject(nodeObj *node.Node) (*metadata.NotionObject,
 		notionObj.Type = metadata.NotionObjectType_DATABASE
 	case node.BLOCK:
 		notionObj.Type = metadata.NotionObjectType_BLOCK
+	case node.COMMENT:
+		notionObj.Type = metadata.NotionObjectType_COMMENT
 	default:
 		return nil, fmt.Errorf("unknown notion object type of node")
 	}
@@ -63,6 +71,7 @@ func CreateMetadata(ctx context.Context, tree *tree.Tree) (*metadata.MetaData,
 		ParentUuid_2ChildrenUuidMap: make(
 			map[string]*metadata.ChildrenNotionObjectUuids),
 		StorageConfig: nil,
+		AssetMap:      tree.Assets,
 	}
 
 	rootNodeNotionObject, err := Convert2ProtoNotionObject(tree.RootNode)
@@ -107,14 +116,49 @@ func CreateMetadata(ctx context.Context, tree *tree.Tree) (*metadata.MetaData,
 	return metadataObj, nil
 }
 
+// Option to set additional fields of metadata which cannot be derived from the
+// tree
+type MetadataOption func(*metadata.MetaData)
+
+// Record the metadata file path and ID of the snapshot from which the
+// incremental snapshot was taken. Path is expected to be relative to the
+// directory of the metadata file of the incremental snapshot
+func WithParentSnapshot(metadataFilePath string,
+	snapshotId string) MetadataOption {
+	return func(metadataObj *metadata.MetaData) {
+		metadataObj.ParentSnapshotPath = metadataFilePath
+		metadataObj.ParentSnapshotId = snapshotId
+	}
+}
+
+// Record the ID and creation time with which the snapshot is identified while
+// pruning the snapshots
+func WithSnapshot(snapshotId string, createdAt time.Time) MetadataOption {
+	return func(metadataObj *metadata.MetaData) {
+		metadataObj.SnapshotId = snapshotId
+		metadataObj.CreatedAt = timestamppb.New(createdAt)
+	}
+}
+
+// Record the filter which excluded objects from the backup
+func WithFilter(filter *metadata.BackupFilter) MetadataOption {
+	return func(metadataObj *metadata.MetaData) {
+		metadataObj.Filter = filter
+	}
+}
+
 func ExportTree(ctx context.Context, rw rw.ReaderWriter,
-	tree *tree.Tree) error {
+	tree *tree.Tree, opts ...MetadataOption) error {
 
 	metadataObj, err := CreateMetadata(ctx, tree)
 	if err != nil {
 		return err
 	}
 
+	for _, opt := range opts {
+		opt(metadataObj)
+	}
+
 	storageConfig, err := rw.GetStorageConfig(ctx)
 	if err != nil {
 		return err
@@ -0,0 +1,136 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+// Notion API cannot create files hosted by Notion, so the assets of the backup
+// are uploaded somewhere else and restored as external files
+type AssetUploader interface {
+	// Upload the asset and get the URL with which Notion can fetch it
+	UploadAsset(context.Context, *metadata.Asset, []byte) (string, error)
+}
+
+// Upload the assets of the backup with the uploader and restore the files
+// hosted by Notion as external files pointing to the uploaded assets
+func WithAssetUploader(uploader AssetUploader) ImporterOption {
+	return func(c *Importer) {
+		c.assetUploader = uploader
+	}
+}
+
+// Restore the files hosted by Notion which cannot be uploaded as external
+// files pointing to the given URL. Files are restored as is if the URL is not
+// set, which Notion API rejects
+func WithAssetFallbackUrl(fallbackUrl string) ImporterOption {
+	return func(c *Importer) {
+		c.assetFallbackUrl = fallbackUrl
+	}
+}
+
+// Helper function to get the URL from which Notion can fetch the file. Empty
+// URL is returned if file cannot be restored
+func (c *Importer) getAssetUrl(ctx context.Context,
+	fileUrl string) (string, error) {
+	log := zerolog.Ctx(ctx)
+	key := utils.GetAssetKey(fileUrl)
+	if assetUrl, found := c.assetUrls[key]; found {
+		return assetUrl, nil
+	}
+
+	asset, found := c.treeObj.Assets[key]
+	if !found || c.assetUploader == nil {
+		if !found {
+			log.Warn().Msgf("Asset %s is not present in the backup", key)
+		}
+		c.assetUrls[key] = c.assetFallbackUrl
+		return c.assetFallbackUrl, nil
+	}
+
+	dataBytes, err := rw.ReadAsset(ctx, c.rwClient,
+		rw.DataIdentifier(asset.StorageIdentifier))
+	if err != nil {
+		return "", err
+	}
+
+	log.Debug().Msgf("Uploading asset %s...", key)
+	assetUrl, err := c.assetUploader.UploadAsset(ctx, asset, dataBytes)
+	if err != nil {
+		return "", err
+	}
+
+	c.assetUrls[key] = assetUrl
+	return assetUrl, nil
+}
+
+// Helper function to replace the files hosted by Notion with the external
+// files pointing to the restored assets. Returns false if object does not
+// reference any file hosted by Notion
+func (c *Importer) rewriteObjectAssets(ctx context.Context,
+	object interface{}) (map[string]interface{}, bool, error) {
+	dataBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal(dataBytes, &decoded)
+	if err != nil {
+		return nil, false, err
+	}
+
+	rewritten := false
+	err = utils.WalkNotionHostedFiles(decoded, func(
+		fileObject map[string]interface{}, fileUrl string) error {
+		assetUrl, err := c.getAssetUrl(ctx, fileUrl)
+		if err != nil || assetUrl == "" {
+			return err
+		}
+
+		delete(fileObject, utils.NOTION_HOSTED_FILE_TYPE)
+		fileObject["type"] = notionapi.FileTypeExternal
+		fileObject[string(notionapi.FileTypeExternal)] = map[string]interface{}{
+			"url": assetUrl,
+		}
+		rewritten = true
+		return nil
+	})
+
+	return decoded, rewritten, err
+}
+
+// Replace the files hosted by Notion referenced by the block, like images and
+// icons of callouts, with the restored assets
+func (c *Importer) rewriteBlockAssets(ctx context.Context,
+	block notionapi.Block) (notionapi.Block, error) {
+	decoded, rewritten, err := c.rewriteObjectAssets(ctx, block)
+	if err != nil || !rewritten {
+		return block, err
+	}
+
+	return utils.DecodeBlockObject(decoded)
+}
+
+// Replace the files hosted by Notion referenced by the page, like its icon,
+// cover and files properties, with the restored assets
+func (c *Importer) rewritePageAssets(ctx context.Context,
+	page *notionapi.Page) (*notionapi.Page, error) {
+	decoded, rewritten, err := c.rewriteObjectAssets(ctx, page)
+	if err != nil || !rewritten {
+		return page, err
+	}
+
+	dataBytes, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.ParsePageJsonString(dataBytes)
+}
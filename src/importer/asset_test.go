@@ -0,0 +1,176 @@
+package importer_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const (
+	ICON_URL     = "https://s3.amazonaws.com/secure/icon.png?X-Amz-Expires=3600"
+	MISSING_URL  = "https://s3.amazonaws.com/secure/missing.png"
+	FALLBACK_URL = "https://example.com/missing.png"
+	UPLOADED_URL = "https://assets.example.com/icon.png"
+)
+
+// Uploader recording the uploaded assets
+type fakeAssetUploader struct {
+	uploads map[string][]byte
+	err     error
+}
+
+func (u *fakeAssetUploader) UploadAsset(ctx context.Context,
+	asset *metadata.Asset, dataBytes []byte) (string, error) {
+	if u.err != nil {
+		return "", u.err
+	}
+
+	u.uploads[asset.FileName] = dataBytes
+	return "https://assets.example.com/" + asset.FileName, nil
+}
+
+func getImageBlock(id string, fileUrl string) *notionapi.ImageBlock {
+	return &notionapi.ImageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeImage,
+		},
+		Image: notionapi.Image{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{URL: fileUrl},
+		},
+	}
+}
+
+func TestAssetUrls(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	restoreToPageUUID := uuid.NewString()
+	iconData := []byte("icon")
+
+	// Icon of the page and the first image point to the same asset with
+	// different signatures. Asset of the second image is not part of the
+	// backup
+	identifier, err := rw.WriteAsset(ctx, writer, iconData)
+	assert.Nil(t, err)
+
+	rootNode := node.CreateRootNode()
+	page := getPage("page", nil)
+	page.Icon = &notionapi.Icon{
+		Type: notionapi.FileTypeFile,
+		File: &notionapi.FileObject{URL: ICON_URL},
+	}
+	pageNode := addPageNode(t, writer, rootNode, page)
+	addBlockNode(t, writer, pageNode, getImageBlock("image",
+		utils.GetAssetKey(ICON_URL)+"?X-Amz-Expires=60"))
+	addBlockNode(t, writer, pageNode, getImageBlock("missing_image",
+		MISSING_URL))
+	treeObj := &tree.Tree{
+		RootNode: rootNode,
+		Assets: map[string]*metadata.Asset{
+			utils.GetAssetKey(ICON_URL): {
+				StorageIdentifier: string(identifier),
+				FileName:          "icon.png",
+			},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		uploader        *fakeAssetUploader
+		fallbackUrl     string
+		iconUrl         string
+		missingImageUrl string
+		isError         bool
+	}{
+		{
+			name:            "Uploaded assets",
+			uploader:        &fakeAssetUploader{uploads: map[string][]byte{}},
+			fallbackUrl:     FALLBACK_URL,
+			iconUrl:         UPLOADED_URL,
+			missingImageUrl: FALLBACK_URL,
+		},
+		{
+			name:            "Fallback URL without uploader",
+			fallbackUrl:     FALLBACK_URL,
+			iconUrl:         FALLBACK_URL,
+			missingImageUrl: FALLBACK_URL,
+		},
+		{
+			name:     "Files are kept without fallback URL",
+			uploader: &fakeAssetUploader{uploads: map[string][]byte{}},
+			iconUrl:  UPLOADED_URL,
+		},
+		{
+			name:     "Error while uploading",
+			uploader: &fakeAssetUploader{err: errGeneric},
+			isError:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts := []importer.ImporterOption{
+				importer.WithAssetFallbackUrl(test.fallbackUrl),
+			}
+			if test.uploader != nil {
+				opts = append(opts, importer.WithAssetUploader(test.uploader))
+			}
+
+			mockedNotionClient := mocks.NewNotionClient(t)
+			importerObj := importer.GetImporter(writer, mockedNotionClient,
+				restoreToPageUUID, treeObj, opts...)
+			if test.isError {
+				err := importerObj.ImportObjects(ctx)
+				assert.NotNil(t, err)
+				return
+			}
+
+			mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(
+				&notionapi.Page{ID: "new_page"}, nil).Once()
+			mockAppendBlocks(mockedNotionClient, "new_page", "new_image",
+				"new_missing_image")
+			err := importerObj.ImportObjects(ctx)
+			assert.Nil(t, err)
+
+			// Asset shared by the icon and the image is uploaded once
+			if test.uploader != nil {
+				assert.Equal(t, map[string][]byte{"icon.png": iconData},
+					test.uploader.uploads)
+			}
+
+			pageReq := getRequest(mockedNotionClient,
+				"CreatePage").(*notionapi.PageCreateRequest)
+			assert.Equal(t, notionapi.FileTypeExternal, pageReq.Icon.Type)
+			assert.Equal(t, test.iconUrl, pageReq.Icon.External.URL)
+
+			appendReq := getRequest(mockedNotionClient,
+				"AppendBlocksToBlock").(*notionapi.AppendBlockChildrenRequest)
+			image := appendReq.Children[0].(*notionapi.ImageBlock)
+			assert.Equal(t, notionapi.FileTypeExternal, image.Image.Type)
+			assert.Equal(t, test.iconUrl, image.Image.GetURL())
+
+			missingImage := appendReq.Children[1].(*notionapi.ImageBlock)
+			if test.missingImageUrl == "" {
+				assert.Equal(t, notionapi.FileTypeFile, missingImage.Image.Type)
+				assert.Equal(t, MISSING_URL, missingImage.Image.GetURL())
+				return
+			}
+
+			assert.Equal(t, notionapi.FileTypeExternal, missingImage.Image.Type)
+			assert.Equal(t, test.missingImageUrl, missingImage.Image.GetURL())
+		})
+	}
+}
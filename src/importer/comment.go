@@ -0,0 +1,155 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+)
+
+const (
+	// Layout of the original time of the comment noted in the restored comment
+	COMMENT_TIME_LAYOUT = "2006-01-02 15:04 MST"
+)
+
+// Helper function to get the name of the author of the comment. Notion API
+// usually returns only the ID of the author
+func getCommentAuthor(comment *notionapi.Comment) string {
+	if comment.CreatedBy.Name != "" {
+		return comment.CreatedBy.Name
+	}
+
+	return fmt.Sprintf("user %s", comment.CreatedBy.ID)
+}
+
+// Helper function to get the note about the original author and time of the
+// comment. Notion API creates every comment as the integration
+func getCommentNote(comment *notionapi.Comment, onBlock bool) string {
+	target := ""
+	if onBlock {
+		target = " on a block"
+	}
+
+	return fmt.Sprintf("[Commented%s by %s at %s] ", target,
+		getCommentAuthor(comment),
+		comment.CreatedTime.UTC().Format(COMMENT_TIME_LAYOUT))
+}
+
+// Helper function to get the restored page to which discussion of the comment
+// node is added. Notion API starts discussions only on pages, so discussions
+// of blocks are added to the page containing the block
+func (c *Importer) getCommentPage(nodeObj *node.Node) (notionapi.PageID,
+	bool, error) {
+	parentNode := nodeObj.GetParentNode()
+	for parentNode != nil && parentNode.GetNodeType() != node.PAGE {
+		parentNode = parentNode.GetParentNode()
+	}
+
+	if parentNode == nil {
+		return "", false, fmt.Errorf("comment %s does not belong to any page",
+			nodeObj.GetNotionObjectId())
+	}
+
+	newUuid, err := c.objUuidMapping.getPageUuid(
+		notionapi.PageID(parentNode.GetNotionObjectId()))
+	if err != nil {
+		return "", false, err
+	}
+
+	onBlock := nodeObj.GetParentNode().GetNodeType() != node.PAGE
+	return newUuid, onBlock, nil
+}
+
+// Helper function to get the rich text of the restored comment with the links
+// pointing to the restored objects
+func (c *Importer) getCommentRichText(ctx context.Context, nodeObj *node.Node,
+	comment *notionapi.Comment, onBlock bool) ([]notionapi.RichText, error) {
+	rewrite := &linkRewrite{}
+	decoded, err := c.rewriteObjectLinks(map[string]interface{}{
+		"rich_text": comment.RichText,
+	}, rewrite)
+	if err != nil {
+		return nil, err
+	}
+	c.reportMissingLinks(ctx, nodeObj, rewrite.missing)
+
+	dataBytes, err := json.Marshal(decoded["rich_text"])
+	if err != nil {
+		return nil, err
+	}
+
+	richText := []notionapi.RichText{}
+	err = json.Unmarshal(dataBytes, &richText)
+	if err != nil {
+		return nil, err
+	}
+
+	note := getPlainText(getCommentNote(comment, onBlock))
+	note[0].Annotations = &notionapi.Annotations{Italic: true}
+	return append(note, richText...), nil
+}
+
+// Fourth pass of the import which recreates the comments of the restored pages
+// and blocks in the order of the tree. First comment of every discussion
+// starts a new discussion on the restored page and rest of the comments are
+// added to it. Journal records the restored discussion for every comment so
+// that resumed restore adds the remaining comments to the same discussions
+func (c *Importer) restoreComments(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+	discussions := make(map[notionapi.DiscussionID]notionapi.DiscussionID)
+	for _, nodeObj := range c.getRestoredNodes(node.COMMENT) {
+		comment, err := c.rwClient.ReadComment(ctx,
+			nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return err
+		}
+
+		if c.journal.isCreated(nodeObj) {
+			discussions[comment.DiscussionID] =
+				notionapi.DiscussionID(c.journal.getCreatedId(nodeObj))
+			continue
+		}
+
+		pageId, onBlock, err := c.getCommentPage(nodeObj)
+		if err != nil {
+			return err
+		}
+
+		richText, err := c.getCommentRichText(ctx, nodeObj, comment, onBlock)
+		if err != nil {
+			return err
+		}
+
+		req := &notionapi.CommentCreateRequest{RichText: richText}
+		if discussionId, found := discussions[comment.DiscussionID]; found {
+			req.DiscussionID = discussionId
+		} else {
+			req.Parent = notionapi.Parent{
+				Type:   notionapi.ParentTypePageID,
+				PageID: pageId,
+			}
+		}
+
+		log.Debug().Str(logging.NodeID, nodeObj.GetID().String()).Msgf(
+			"Restoring comment %s...", comment.ID)
+		createdComment, err := c.notionClient.CreateComment(ctx, req)
+		if err != nil {
+			b, _ := json.Marshal(req)
+			log.Err(err).Msgf("Comment Create Request: %s", b)
+			return err
+		}
+
+		discussions[comment.DiscussionID] = createdComment.DiscussionID
+		err = c.journal.recordCreated(nodeObj,
+			createdComment.DiscussionID.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
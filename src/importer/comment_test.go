@@ -0,0 +1,175 @@
+package importer_test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var commentTime = time.Date(2023, 1, 2, 10, 30, 0, 0, time.UTC)
+
+func getComment(id string, discussionId string, author notionapi.User,
+	richText []notionapi.RichText) *notionapi.Comment {
+	return &notionapi.Comment{
+		Object:       notionapi.ObjectTypeComment,
+		ID:           notionapi.ObjectID(id),
+		DiscussionID: notionapi.DiscussionID(discussionId),
+		CreatedTime:  commentTime,
+		CreatedBy:    author,
+		RichText:     richText,
+	}
+}
+
+func getText(content string) []notionapi.RichText {
+	return []notionapi.RichText{{
+		Type:      notionapi.ObjectTypeText,
+		Text:      &notionapi.Text{Content: content},
+		PlainText: content,
+	}}
+}
+
+func addCommentNode(t *testing.T, writer rw.ReaderWriter,
+	parentNode *node.Node, comment *notionapi.Comment) *node.Node {
+	commentNode, err := node.CreateCommentNode(context.Background(), comment,
+		writer)
+	assert.Nil(t, err)
+	parentNode.AddChild(commentNode)
+	return commentNode
+}
+
+func TestRestoreComments(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	journalPath := filepath.Join(t.TempDir(), "restore.journal")
+	restoreToPageUUID := uuid.NewString()
+	pageId := uuid.NewString()
+
+	// Discussion on the page has two comments, and the discussion of the block
+	// mentions the page
+	rootNode := node.CreateRootNode()
+	pageNode := addPageNode(t, writer, rootNode, getPage(pageId, nil))
+	blockNode := addBlockNode(t, writer, pageNode, getParagraphBlock("block",
+		nil))
+	addCommentNode(t, writer, pageNode, getComment("comment_1", "discussion_1",
+		notionapi.User{ID: "user_1", Name: "Alice"},
+		getText("First")))
+	addCommentNode(t, writer, pageNode, getComment("comment_2", "discussion_1",
+		notionapi.User{ID: "user_2"}, getText("Second")))
+	addCommentNode(t, writer, blockNode, getComment("comment_3",
+		"discussion_2", notionapi.User{ID: "user_1", Name: "Alice"},
+		[]notionapi.RichText{getMention(pageId, "Page")}))
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	tests := []struct {
+		name         string
+		discussionId notionapi.DiscussionID
+		pageId       notionapi.PageID
+		note         string
+		text         string
+		mentionId    notionapi.ObjectID
+		newId        notionapi.DiscussionID
+	}{
+		{
+			name:   "First comment of discussion on page",
+			pageId: "new_page",
+			note:   "[Commented by Alice at 2023-01-02 10:30 UTC] ",
+			text:   "First",
+			newId:  "new_discussion_1",
+		},
+		{
+			name:         "Reply to discussion",
+			discussionId: "new_discussion_1",
+			note:         "[Commented by user user_2 at 2023-01-02 10:30 UTC] ",
+			text:         "Second",
+			newId:        "new_discussion_1",
+		},
+		{
+			name:   "Discussion on block",
+			pageId: "new_page",
+			note: "[Commented on a block by Alice at " +
+				"2023-01-02 10:30 UTC] ",
+			mentionId: "new_page",
+			newId:     "new_discussion_2",
+		},
+	}
+
+	// Requests of the comments are recorded by the name of the test case
+	requests := make(map[string]*notionapi.CommentCreateRequest)
+	mockCreateComment := func(m *mocks.NotionClient, index int, err error) {
+		test := tests[index]
+		m.On("CreateComment", ctx, mock.MatchedBy(
+			func(req *notionapi.CommentCreateRequest) bool {
+				if req.DiscussionID != test.discussionId ||
+					req.Parent.PageID != test.pageId {
+					return false
+				}
+
+				last := req.RichText[len(req.RichText)-1]
+				if test.mentionId != "" {
+					return last.Mention != nil &&
+						last.Mention.Page.ID == test.mentionId
+				}
+				return last.Text != nil && last.Text.Content == test.text
+			})).Run(func(args mock.Arguments) {
+			requests[test.name] = args.Get(1).(*notionapi.CommentCreateRequest)
+		}).Return(&notionapi.Comment{DiscussionID: test.newId}, err).Once()
+	}
+
+	getImporter := func(notionClient *mocks.NotionClient,
+		resume bool) (*importer.Importer, *importer.Journal) {
+		journal, err := importer.OpenJournal(journalPath, restoreToPageUUID,
+			resume)
+		assert.Nil(t, err)
+		return importer.GetImporter(writer, notionClient, restoreToPageUUID,
+			treeObj, importer.WithJournal(journal)), journal
+	}
+
+	// First attempt fails while replying to the discussion
+	mockedNotionClient := mocks.NewNotionClient(t)
+	mockCreatePage(mockedNotionClient, "new_page",
+		func(req *notionapi.PageCreateRequest) bool { return true })
+	mockAppendBlocks(mockedNotionClient, "new_page", "new_block")
+	mockCreateComment(mockedNotionClient, 0, nil)
+	mockCreateComment(mockedNotionClient, 1, errGeneric)
+
+	importerObj, journal := getImporter(mockedNotionClient, false)
+	err := importerObj.ImportObjects(ctx)
+	assert.NotNil(t, err)
+	assert.Nil(t, journal.Close())
+
+	// Resumed attempt adds the reply to the discussion started by first attempt
+	mockedNotionClient = mocks.NewNotionClient(t)
+	mockCreateComment(mockedNotionClient, 1, nil)
+	mockCreateComment(mockedNotionClient, 2, nil)
+
+	importerObj, journal = getImporter(mockedNotionClient, true)
+	err = importerObj.ImportObjects(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, journal.Remove())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, found := requests[test.name]
+			assert.True(t, found)
+			if !found {
+				return
+			}
+
+			// Note about the original author is added before the comment
+			assert.Equal(t, test.note, req.RichText[0].Text.Content)
+			assert.True(t, req.RichText[0].Annotations.Italic)
+			assert.Len(t, req.RichText, 2)
+		})
+	}
+}
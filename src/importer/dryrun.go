@@ -0,0 +1,142 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/shivaji17/notionbackup/src/notionclient"
+)
+
+// Block skipped by the restore as Notion API does not support it
+type SkippedBlock struct {
+	NotionObjectId string `json:"notion_object_id"`
+	NodeUuid       string `json:"node_uuid"`
+}
+
+// Plan of the restore found by the dry run
+type DryRunReport struct {
+	Pages               int                             `json:"pages"`
+	Databases           int                             `json:"databases"`
+	Blocks              int                             `json:"blocks"`
+	Comments            int                             `json:"comments"`
+	ApiCalls            int                             `json:"api_calls"`
+	ApiCallsByMethod    map[string]int                  `json:"api_calls_by_method"`
+	UnsupportedBlocks   []SkippedBlock                  `json:"unsupported_blocks"`
+	RejectedProperties  []notionclient.RejectedProperty `json:"rejected_properties"`
+	UnresolvedRelations []UnresolvedRelation            `json:"unresolved_relations"`
+	MissingLinks        []MissingLink                   `json:"missing_links"`
+
+	// Set by the caller when only a subtree of the backup is restored
+	ExternalDependencies []ExternalDependency `json:"external_dependencies,omitempty"`
+}
+
+// Run the restore with RecordingNotionClient so that objects are only recorded
+// instead of being created. Plan is available with GetDryRunReport once
+// ImportObjects returns
+func WithDryRun() ImporterOption {
+	return func(c *Importer) {
+		c.recordingClient = notionclient.GetRecordingNotionClient()
+		c.notionClient = c.recordingClient
+		c.unsupportedBlocks = []SkippedBlock{}
+		c.unresolvedRelations = []UnresolvedRelation{}
+		c.missingLinks = []MissingLink{}
+	}
+}
+
+// Get the plan of the restore recorded by the dry run. nil is returned if the
+// importer is not created with WithDryRun
+func (c *Importer) GetDryRunReport() *DryRunReport {
+	if c.recordingClient == nil {
+		return nil
+	}
+
+	recording := c.recordingClient.GetRecording()
+	report := &DryRunReport{
+		Pages:               recording.Pages,
+		Databases:           recording.Databases,
+		Blocks:              recording.Blocks,
+		Comments:            recording.Comments,
+		ApiCallsByMethod:    recording.ApiCalls,
+		UnsupportedBlocks:   c.unsupportedBlocks,
+		RejectedProperties:  recording.RejectedProperties,
+		UnresolvedRelations: c.unresolvedRelations,
+		MissingLinks:        c.missingLinks,
+	}
+
+	for _, count := range recording.ApiCalls {
+		report.ApiCalls += count
+	}
+
+	return report
+}
+
+// Write the report as tables readable in terminal
+func (r *DryRunReport) WriteTable(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "OBJECT\tTO CREATE")
+	fmt.Fprintf(w, "Pages\t%d\n", r.Pages)
+	fmt.Fprintf(w, "Databases\t%d\n", r.Databases)
+	fmt.Fprintf(w, "Blocks\t%d\n", r.Blocks)
+	fmt.Fprintf(w, "Comments\t%d\n", r.Comments)
+	fmt.Fprintln(w)
+
+	methods := []string{}
+	for method := range r.ApiCallsByMethod {
+		methods = append(methods, method)
+	}
+	sort.Strings(methods)
+
+	fmt.Fprintln(w, "API CALL\tCOUNT")
+	for _, method := range methods {
+		fmt.Fprintf(w, "%s\t%d\n", method, r.ApiCallsByMethod[method])
+	}
+	fmt.Fprintf(w, "Total\t%d\n", r.ApiCalls)
+
+	if len(r.UnsupportedBlocks) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "SKIPPED UNSUPPORTED BLOCK\tNODE UUID")
+		for _, block := range r.UnsupportedBlocks {
+			fmt.Fprintf(w, "%s\t%s\n", block.NotionObjectId, block.NodeUuid)
+		}
+	}
+
+	if len(r.RejectedProperties) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "OBJECT\tREJECTED PROPERTY\tTYPE\tREASON")
+		for _, property := range r.RejectedProperties {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", property.Object, property.Property,
+				property.Type, property.Reason)
+		}
+	}
+
+	if len(r.UnresolvedRelations) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "NODE UUID\tUNRESOLVED RELATION\tTARGET")
+		for _, relation := range r.UnresolvedRelations {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", relation.NodeUuid, relation.Property,
+				relation.TargetId)
+		}
+	}
+
+	if len(r.MissingLinks) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "NODE UUID\tMISSING LINK TARGET")
+		for _, link := range r.MissingLinks {
+			fmt.Fprintf(w, "%s\t%s\n", link.NodeUuid, link.TargetId)
+		}
+	}
+
+	if len(r.ExternalDependencies) > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "NODE UUID\tEXTERNAL DEPENDENCY\tPROPERTY\tTARGET")
+		for _, dependency := range r.ExternalDependencies {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", dependency.NodeUuid,
+				dependency.Kind, dependency.Property, dependency.TargetId)
+		}
+	}
+
+	return w.Flush()
+}
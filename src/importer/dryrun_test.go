@@ -0,0 +1,105 @@
+package importer_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func getMention(pageId string, plainText string) notionapi.RichText {
+	return notionapi.RichText{
+		Type: importer.MENTION_RICH_TEXT_TYPE,
+		Mention: &notionapi.Mention{
+			Type: notionapi.MentionTypePage,
+			Page: &notionapi.PageMention{ID: notionapi.ObjectID(pageId)},
+		},
+		PlainText: plainText,
+	}
+}
+
+func TestDryRun(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+
+	rootNode := node.CreateRootNode()
+	pageNode := addPageNode(t, writer, rootNode, getPage("page", nil))
+	// Page links to a page which is not part of the backup
+	addBlockNode(t, writer, pageNode, getParagraphBlock("paragraph",
+		[]notionapi.RichText{getMention("missing_page", "Missing")}))
+	unsupportedNode := addBlockNode(t, writer, pageNode,
+		&notionapi.UnsupportedBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     "unsupported",
+				Type:   notionapi.BlockTypeUnsupported,
+			},
+		})
+
+	// Row relates to a page which is not part of the backup
+	databaseNode := addDatabaseNode(t, writer, rootNode, getDatabase("db",
+		notionapi.PropertyConfigs{
+			"Other": getRelationConfig("other_db", ""),
+		}))
+	row := getPage("row", notionapi.Properties{
+		"Related": getRelation("missing_row"),
+	})
+	row.Parent = notionapi.Parent{
+		Type:       notionapi.ParentTypeDatabaseID,
+		DatabaseID: "db",
+	}
+	addPageNode(t, writer, databaseNode, row)
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	importerObj := importer.GetImporter(writer, mocks.NewNotionClient(t), "",
+		treeObj)
+	assert.Nil(t, importerObj.GetDryRunReport())
+
+	// Nothing is sent to Notion during dry run
+	importerObj = importer.GetImporter(writer, mocks.NewNotionClient(t), "",
+		treeObj, importer.WithDryRun())
+	err := importerObj.ImportObjects(ctx)
+	assert.Nil(t, err)
+
+	report := importerObj.GetDryRunReport()
+	assert.Equal(t, 2, report.Pages)
+	assert.Equal(t, 1, report.Databases)
+	assert.Equal(t, 1, report.Blocks)
+	assert.Equal(t, 4, report.ApiCalls)
+	assert.Equal(t, 2, report.ApiCallsByMethod["CreatePage"])
+	assert.Equal(t, []importer.SkippedBlock{{
+		NotionObjectId: "unsupported",
+		NodeUuid:       unsupportedNode.GetID().String(),
+	}}, report.UnsupportedBlocks)
+	assert.Len(t, report.UnresolvedRelations, 2)
+	assert.Len(t, report.MissingLinks, 1)
+	assert.Equal(t, "missing_page", report.MissingLinks[0].TargetId)
+
+	out := &bytes.Buffer{}
+	err = report.WriteTable(out)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "Objects", expected: "Pages      2"},
+		{name: "API calls", expected: "CreateDatabase       1"},
+		{name: "Total API calls", expected: "Total                4"},
+		{name: "Unsupported blocks", expected: "SKIPPED UNSUPPORTED BLOCK"},
+		{name: "Unresolved relations", expected: "other_db"},
+		{name: "Missing links", expected: "MISSING LINK TARGET"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Contains(t, out.String(), test.expected)
+		})
+	}
+}
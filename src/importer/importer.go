@@ -8,7 +8,9 @@ import (
 
 	"github.com/jomei/notionapi"
 	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
 	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/shivaji17/notionbackup/src/rw"
 	"github.com/shivaji17/notionbackup/src/tree"
 	"github.com/shivaji17/notionbackup/src/tree/iterator"
@@ -26,35 +28,76 @@ var FIELDS_TO_CLEAR = []string{
 }
 
 type Importer struct {
-	rwClient          rw.ReaderWriter
-	notionClient      notionclient.NotionClient
-	treeObj           *tree.Tree
-	objUuidMapping    *objectUuidMapping
-	nodeQueue         *list.List
-	restoreToPageUUID string
+	rwClient            rw.ReaderWriter
+	notionClient        notionclient.NotionClient
+	treeObj             *tree.Tree
+	objUuidMapping      *objectUuidMapping
+	nodeQueue           *list.List
+	restoreToPageUUID   string
+	journal             *Journal
+	recordingClient     *notionclient.RecordingNotionClient
+	unsupportedBlocks   []SkippedBlock
+	subtreeNode         *node.Node
+	unresolvedRelations []UnresolvedRelation
+	missingLinkPolicy   MissingLinkPolicy
+	restoredIds         map[string]bool
+	deferredLinks       map[string]bool
+	missingLinks        []MissingLink
+	assetUploader       AssetUploader
+	assetFallbackUrl    string
+	assetUrls           map[string]string
+}
+
+// Option to set optional fields of Importer
+type ImporterOption func(*Importer)
+
+// Record the progress of the restore in the journal. Objects recorded in the
+// journal by previous attempt are not created again
+func WithJournal(journal *Journal) ImporterOption {
+	return func(c *Importer) {
+		c.journal = journal
+	}
+}
+
+// Restore only the page or database node along with its children. Node is
+// created under the page to which data is restored
+func WithSubtree(nodeObj *node.Node) ImporterOption {
+	return func(c *Importer) {
+		c.subtreeNode = nodeObj
+	}
 }
 
 func GetImporter(rwClient rw.ReaderWriter,
 	notionClient notionclient.NotionClient, restoreToPageUUID string,
-	treeObj *tree.Tree) *Importer {
-	return &Importer{
+	treeObj *tree.Tree, opts ...ImporterOption) *Importer {
+	importerObj := &Importer{
 		rwClient:          rwClient,
 		notionClient:      notionClient,
 		treeObj:           treeObj,
 		nodeQueue:         list.New(),
 		restoreToPageUUID: restoreToPageUUID,
+		missingLinkPolicy: KEEP_MISSING_LINKS,
+		deferredLinks:     make(map[string]bool),
+		assetUrls:         make(map[string]string),
 		objUuidMapping: &objectUuidMapping{
 			pageMap:     make(map[notionapi.PageID]notionapi.PageID),
 			databaseMap: make(map[notionapi.DatabaseID]notionapi.DatabaseID),
 			blockMap:    make(map[notionapi.BlockID]notionapi.BlockID),
 		},
 	}
+
+	for _, opt := range opts {
+		opt(importerObj)
+	}
+
+	return importerObj
 }
 
 // This function creates and returns Parent object
 func (c *Importer) getParentObject(nodeObj *node.Node,
 	oldParent *notionapi.Parent) (*notionapi.Parent, error) {
-	if nodeObj.GetParentNode().GetNodeType() == node.ROOT {
+	if nodeObj == c.subtreeNode ||
+		nodeObj.GetParentNode().GetNodeType() == node.ROOT {
 		return &notionapi.Parent{
 			Type:   notionapi.ParentTypePageID,
 			PageID: notionapi.PageID(c.restoreToPageUUID),
@@ -100,19 +143,37 @@ func (c *Importer) getParentObject(nodeObj *node.Node,
 // it to Notion
 func (c *Importer) uploadPage(ctx context.Context, nodeObj *node.Node) error {
 	log := zerolog.Ctx(ctx)
+	if c.journal.isCreated(nodeObj) {
+		log.Debug().Msgf("Page %s already restored", nodeObj.GetNotionObjectId())
+		progress.Ctx(ctx).Add(progress.PAGES, progress.SKIPPED, 1)
+		c.nodeQueue.PushBack(nodeObj)
+		return nil
+	}
+
 	page, err := c.rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
 	if err != nil {
 		return err
 	}
 
+	page, err = c.rewritePageAssets(ctx, page)
+	if err != nil {
+		return err
+	}
+
 	parent, err := c.getParentObject(nodeObj, &page.Parent)
 	if err != nil {
 		return err
 	}
 
+	properties, err := c.rewritePageLinks(ctx, nodeObj,
+		getCreatablePageProperties(page.Properties))
+	if err != nil {
+		return err
+	}
+
 	req := &notionapi.PageCreateRequest{
 		Parent:     *parent,
-		Properties: page.Properties,
+		Properties: properties,
 		Children:   make([]notionapi.Block, 0),
 		Icon:       page.Icon,
 		Cover:      page.Cover,
@@ -127,6 +188,12 @@ func (c *Importer) uploadPage(ctx context.Context, nodeObj *node.Node) error {
 	}
 
 	c.objUuidMapping.insertPageUuid(page.ID, createdPage.ID)
+	progress.Ctx(ctx).Add(progress.PAGES, progress.CREATED, 1)
+	err = c.journal.recordCreated(nodeObj, createdPage.ID.String())
+	if err != nil {
+		return err
+	}
+
 	c.nodeQueue.PushBack(nodeObj)
 	return nil
 }
@@ -136,6 +203,14 @@ func (c *Importer) uploadPage(ctx context.Context, nodeObj *node.Node) error {
 func (c *Importer) uploadDatabase(ctx context.Context,
 	nodeObj *node.Node) error {
 	log := zerolog.Ctx(ctx)
+	if c.journal.isCreated(nodeObj) {
+		log.Debug().Msgf("Database %s already restored",
+			nodeObj.GetNotionObjectId())
+		progress.Ctx(ctx).Add(progress.DATABASES, progress.SKIPPED, 1)
+		c.nodeQueue.PushBack(nodeObj)
+		return nil
+	}
+
 	database, err := c.rwClient.ReadDatabase(ctx, nodeObj.GetStorageIdentifier())
 	if err != nil {
 		return err
@@ -149,7 +224,7 @@ func (c *Importer) uploadDatabase(ctx context.Context,
 	req := &notionapi.DatabaseCreateRequest{
 		Parent:     *parent,
 		Title:      database.Title,
-		Properties: database.Properties,
+		Properties: getCreatableDatabaseProperties(database.Properties),
 	}
 
 	log.Debug().Msgf("Uploading Database %s...", database.ID)
@@ -161,12 +236,19 @@ func (c *Importer) uploadDatabase(ctx context.Context,
 	}
 
 	c.objUuidMapping.insertDatabaseUuid(database.ID, createdDatabase.ID)
+	progress.Ctx(ctx).Add(progress.DATABASES, progress.CREATED, 1)
+	err = c.journal.recordCreated(nodeObj, createdDatabase.ID.String())
+	if err != nil {
+		return err
+	}
+
 	c.nodeQueue.PushBack(nodeObj)
 	return nil
 }
 
 func (c *Importer) createMappingForColumnList(ctx context.Context,
-	oldBlock notionapi.Block, newBlock notionapi.Block) error {
+	nodeObj *node.Node, oldBlock notionapi.Block,
+	newBlock notionapi.Block) error {
 	oldColumnsList, ok := oldBlock.(*notionapi.ColumnListBlock)
 	if !ok {
 		return fmt.Errorf("failed to cast block object to columnlist object")
@@ -189,25 +271,66 @@ func (c *Importer) createMappingForColumnList(ctx context.Context,
 			notionapi.ObjectID(rsp[i].GetID()))
 	}
 
+	// Column nodes are the children of column list node in the same order as
+	// columns of the request
+	iter := iterator.GetChildIterator(nodeObj)
+	for i := 0; i < len(rsp); {
+		columnNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if columnNode.GetNodeType() == node.COMMENT {
+			continue
+		}
+
+		err = c.journal.recordCreated(columnNode, rsp[i].GetID().String())
+		if err != nil {
+			return err
+		}
+		i++
+	}
+
 	return nil
 }
 
-// This function will upload the blocks to given block/page
+// This function will upload the blocks of given nodes to given block/page
 func (c *Importer) uploadBlocks(ctx context.Context, parentUuid string,
-	blocks notionapi.Blocks) error {
+	blocks notionapi.Blocks, blockNodes []*node.Node) error {
 	log := zerolog.Ctx(ctx).With().Str("Parent UUID", parentUuid).Logger()
 	if len(blocks) == 0 {
 		return nil
 	}
 
-	req := &notionapi.AppendBlockChildrenRequest{
-		Children: blocks,
-	}
-
+	req := &notionapi.AppendBlockChildrenRequest{}
 	var oldBlocks notionapi.Blocks
+	var createdNodes []*node.Node
 	for i := range blocks {
-		oldBlocks = append(oldBlocks, copyBlock(blocks[i]))
+		oldBlock := copyBlock(blocks[i])
 		c.clear(&blocks[i])
+
+		newBlock, err := c.rewriteBlockLinks(ctx, blockNodes[i], blocks[i])
+		if err != nil {
+			return err
+		}
+
+		// Link to page block is appended once its target is restored
+		if newBlock == nil {
+			continue
+		}
+
+		newBlock, err = c.rewriteBlockAssets(ctx, newBlock)
+		if err != nil {
+			return err
+		}
+
+		req.Children = append(req.Children, newBlock)
+		oldBlocks = append(oldBlocks, oldBlock)
+		createdNodes = append(createdNodes, blockNodes[i])
+	}
+
+	if len(req.Children) == 0 {
+		return nil
 	}
 
 	log.Debug().Msg("Appending blocks...")
@@ -226,16 +349,26 @@ func (c *Importer) uploadBlocks(ctx context.Context, parentUuid string,
 			"blocks in response")
 	}
 
+	progress.Ctx(ctx).Add(progress.BLOCKS, progress.CREATED,
+		getCreatedBlockCount(oldBlocks))
+
 	for i := range oldBlocks {
 		c.objUuidMapping.insertBlockUuid(notionapi.ObjectID(oldBlocks[i].GetID()),
 			notionapi.ObjectID(rsp.Results[i].GetID()))
 
 		if oldBlocks[i].GetType() == notionapi.BlockTypeColumnList {
-			err = c.createMappingForColumnList(ctx, oldBlocks[i], rsp.Results[i])
+			err = c.createMappingForColumnList(ctx, createdNodes[i], oldBlocks[i],
+				rsp.Results[i])
 			if err != nil {
 				return err
 			}
 		}
+
+		err = c.journal.recordCreated(createdNodes[i],
+			rsp.Results[i].GetID().String())
+		if err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -277,6 +410,10 @@ func (c *Importer) handleTableObject(ctx context.Context, nodeObj *node.Node,
 			break
 		}
 
+		if childObj.GetNodeType() == node.COMMENT {
+			continue
+		}
+
 		tableRow, err := c.rwClient.ReadBlock(ctx, childObj.GetStorageIdentifier())
 		if err != nil {
 			return nil, err
@@ -303,6 +440,10 @@ func (c *Importer) handleColumnListObject(ctx context.Context,
 			break
 		}
 
+		if childObj.GetNodeType() == node.COMMENT {
+			continue
+		}
+
 		column, err := c.rwClient.ReadBlock(ctx, childObj.GetStorageIdentifier())
 		if err != nil {
 			return nil, err
@@ -344,6 +485,7 @@ func (c *Importer) processChildrenNodes(ctx context.Context, parentUuid string,
 	blocksIter := iterator.GetChildIterator(nodeObj)
 
 	blockList := notionapi.Blocks{}
+	blockNodes := []*node.Node{}
 
 	for {
 		childObj, err := blocksIter.Next()
@@ -352,6 +494,11 @@ func (c *Importer) processChildrenNodes(ctx context.Context, parentUuid string,
 			break
 		}
 
+		// Comments are restored once all the objects are restored
+		if childObj.GetNodeType() == node.COMMENT {
+			continue
+		}
+
 		block, err := c.rwClient.ReadBlock(ctx, childObj.GetStorageIdentifier())
 		if err != nil {
 			return err
@@ -360,12 +507,19 @@ func (c *Importer) processChildrenNodes(ctx context.Context, parentUuid string,
 		if block.GetType() == notionapi.BlockTypeUnsupported {
 			log.Warn().Msgf("Unsupported block type encountered. Skipping restore "+
 				"for block: %s", block.GetID())
+			progress.Ctx(ctx).Add(progress.BLOCKS, progress.SKIPPED, 1)
+			if c.recordingClient != nil {
+				c.unsupportedBlocks = append(c.unsupportedBlocks, SkippedBlock{
+					NotionObjectId: block.GetID().String(),
+					NodeUuid:       childObj.GetID().String(),
+				})
+			}
 			continue
 		}
 
 		if block.GetType() == notionapi.BlockTypeChildPage ||
 			block.GetType() == notionapi.BlockTypeChildDatabase {
-			err = c.uploadBlocks(ctx, parentUuid, blockList)
+			err = c.uploadBlocks(ctx, parentUuid, blockList, blockNodes)
 			if err != nil {
 				return err
 			}
@@ -373,6 +527,7 @@ func (c *Importer) processChildrenNodes(ctx context.Context, parentUuid string,
 			// No need of creating separate block for Database or Page object. Once,
 			// the Database or Page gets uploaded, the block would be automatically
 			// created
+			progress.Ctx(ctx).Add(progress.BLOCKS, progress.SKIPPED, 1)
 			if block.GetType() == notionapi.BlockTypeChildPage {
 				err = c.uploadPage(ctx, childObj.GetChildNode())
 			} else {
@@ -384,17 +539,26 @@ func (c *Importer) processChildrenNodes(ctx context.Context, parentUuid string,
 			}
 
 			blockList = notionapi.Blocks{}
+			blockNodes = []*node.Node{}
 		} else {
 			block, err = c.handleBlockObject(ctx, childObj, block)
 			if err != nil {
 				return err
 			}
 
+			// Children of the block restored by previous attempt are still
+			// queued by handleBlockObject
+			if c.journal.isCreated(childObj) {
+				progress.Ctx(ctx).Add(progress.BLOCKS, progress.SKIPPED, 1)
+				continue
+			}
+
 			blockList = append(blockList, block)
+			blockNodes = append(blockNodes, childObj)
 		}
 	}
 
-	return c.uploadBlocks(ctx, parentUuid, blockList)
+	return c.uploadBlocks(ctx, parentUuid, blockList, blockNodes)
 }
 
 // This function will iterate all child nodes of Page node and upload it to
@@ -494,9 +658,64 @@ func (c *Importer) processNodeObject(ctx context.Context,
 	return fmt.Errorf("unknown node object type: %s", nodeObj.GetNodeType())
 }
 
-// Import all objects from tree
+// Helper function to restore the mapping of the objects created by the
+// previous attempt from the journal
+func (c *Importer) loadJournal() {
+	if c.journal == nil {
+		return
+	}
+
+	for nodeUuid := range c.journal.remappedNodes[LINKS_DEFERRED] {
+		c.deferredLinks[nodeUuid] = true
+	}
+
+	for _, entry := range c.journal.createdEntries {
+		oldUuid := notionapi.ObjectID(entry.NotionObjectId)
+		newUuid := notionapi.ObjectID(entry.NewNotionObjectId)
+		switch entry.NodeType {
+		case node.PAGE:
+			c.objUuidMapping.insertPageUuid(oldUuid, newUuid)
+		case node.DATABASE:
+			c.objUuidMapping.insertDatabaseUuid(oldUuid, newUuid)
+		case node.BLOCK:
+			c.objUuidMapping.insertBlockUuid(oldUuid, newUuid)
+		}
+	}
+}
+
+// Import all objects from tree, or only the objects of the subtree if it is
+// selected, then remap the relations and links between the restored objects
+// and finally recreate the comments. Nodes are processed in the same order on
+// every attempt, so the nodes completed by previous attempt recorded in the
+// journal are only walked to queue their children without creating any object
 func (c *Importer) ImportObjects(ctx context.Context) error {
-	c.nodeQueue.PushBack(c.treeObj.RootNode)
+	log := zerolog.Ctx(ctx)
+	c.loadJournal()
+	c.loadRestoredIds()
+	if c.subtreeNode != nil {
+		progress.Ctx(ctx).SetTotal(getObjectCount(c.subtreeNode))
+	} else {
+		progress.Ctx(ctx).SetTotal(getObjectCount(c.treeObj.RootNode))
+	}
+
+	switch {
+	case c.subtreeNode == nil:
+		c.nodeQueue.PushBack(c.treeObj.RootNode)
+	case c.subtreeNode.GetNodeType() == node.PAGE:
+		err := c.uploadPage(ctx, c.subtreeNode)
+		if err != nil {
+			return err
+		}
+	case c.subtreeNode.GetNodeType() == node.DATABASE:
+		err := c.uploadDatabase(ctx, c.subtreeNode)
+		if err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("only page or database node can be restored, got %s",
+			c.subtreeNode.GetNodeType())
+	}
+
 	for {
 		if c.nodeQueue.Len() == 0 {
 			break
@@ -508,13 +727,36 @@ func (c *Importer) ImportObjects(ctx context.Context) error {
 			return fmt.Errorf("failed to parse node object")
 		}
 
+		completed := c.journal.isCompleted(currNode)
+		if completed {
+			log.Debug().Str(logging.NodeID, currNode.GetID().String()).Msg(
+				"Node already restored")
+		}
+
 		err := c.processNodeObject(ctx, currNode)
 		if err != nil {
 			return err
 		}
 
+		if !completed {
+			err = c.journal.recordCompleted(currNode)
+			if err != nil {
+				return err
+			}
+		}
+
 		c.nodeQueue.Remove(front)
 	}
 
-	return nil
+	err := c.remapRelations(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = c.updateDeferredLinks(ctx)
+	if err != nil {
+		return err
+	}
+
+	return c.restoreComments(ctx)
 }
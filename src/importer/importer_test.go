@@ -0,0 +1,241 @@
+package importer_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const ERROR_STR = "error occurred"
+
+var errGeneric = fmt.Errorf(ERROR_STR)
+
+// Helper function to get the ReaderWriter storing the objects of the tree
+// being restored
+func getReaderWriter(t *testing.T) rw.ReaderWriter {
+	writer, err := rw.GetFileReaderWriter(context.Background(), t.TempDir(),
+		false)
+	assert.Nil(t, err)
+	return writer
+}
+
+func getPage(id string, properties notionapi.Properties) *notionapi.Page {
+	return &notionapi.Page{
+		Object:     notionapi.ObjectTypePage,
+		ID:         notionapi.ObjectID(id),
+		Properties: properties,
+	}
+}
+
+func getDatabase(id string,
+	properties notionapi.PropertyConfigs) *notionapi.Database {
+	return &notionapi.Database{
+		Object:     notionapi.ObjectTypeDatabase,
+		ID:         notionapi.ObjectID(id),
+		Properties: properties,
+	}
+}
+
+func getParagraphBlock(id string,
+	richText []notionapi.RichText) *notionapi.ParagraphBlock {
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeParagraph,
+		},
+		Paragraph: notionapi.Paragraph{RichText: richText},
+	}
+}
+
+func addPageNode(t *testing.T, writer rw.ReaderWriter, parentNode *node.Node,
+	page *notionapi.Page) *node.Node {
+	pageNode, err := node.CreatePageNode(context.Background(), page, writer)
+	assert.Nil(t, err)
+	parentNode.AddChild(pageNode)
+	return pageNode
+}
+
+func addDatabaseNode(t *testing.T, writer rw.ReaderWriter,
+	parentNode *node.Node, database *notionapi.Database) *node.Node {
+	databaseNode, err := node.CreateDatabaseNode(context.Background(), database,
+		writer)
+	assert.Nil(t, err)
+	parentNode.AddChild(databaseNode)
+	return databaseNode
+}
+
+func addBlockNode(t *testing.T, writer rw.ReaderWriter, parentNode *node.Node,
+	block notionapi.Block) *node.Node {
+	blockNode, err := node.CreateBlockNode(context.Background(), block, writer)
+	assert.Nil(t, err)
+	parentNode.AddChild(blockNode)
+	return blockNode
+}
+
+// Helper function to mock the creation of the page returning the page with
+// given ID
+func mockCreatePage(m *mocks.NotionClient, newPageId string,
+	fn func(*notionapi.PageCreateRequest) bool) {
+	m.On("CreatePage", context.Background(), mock.MatchedBy(fn)).Return(
+		&notionapi.Page{ID: notionapi.ObjectID(newPageId)}, nil).Once()
+}
+
+// Helper function to mock appending the blocks to the block returning the
+// blocks with given IDs
+func mockAppendBlocks(m *mocks.NotionClient, parentId string,
+	newBlockIds ...string) {
+	results := []notionapi.Block{}
+	for _, newBlockId := range newBlockIds {
+		results = append(results, &notionapi.BasicBlock{
+			ID: notionapi.BlockID(newBlockId),
+		})
+	}
+
+	m.On("AppendBlocksToBlock", context.Background(),
+		notionclient.BlockID(parentId), mock.MatchedBy(
+			func(req *notionapi.AppendBlockChildrenRequest) bool {
+				return len(req.Children) == len(newBlockIds)
+			})).Return(&notionapi.AppendBlockChildrenResponse{
+		Results: results,
+	}, nil).Once()
+}
+
+func TestOpenJournal(t *testing.T) {
+	restoreToPageUUID := uuid.NewString()
+
+	t.Run("Existing journal", func(t *testing.T) {
+		journalPath := filepath.Join(t.TempDir(), "restore.journal")
+		journal, err := importer.OpenJournal(journalPath, restoreToPageUUID,
+			false)
+		assert.Nil(t, err)
+		assert.Nil(t, journal.Close())
+
+		// Interrupted restore is only continued with resume
+		_, err = importer.OpenJournal(journalPath, restoreToPageUUID, false)
+		assert.NotNil(t, err)
+		journal, err = importer.OpenJournal(journalPath, restoreToPageUUID, true)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, journal.GetCreatedCount())
+
+		assert.Nil(t, journal.Remove())
+		assert.NoFileExists(t, journalPath)
+	})
+
+	tests := []struct {
+		name     string
+		content  string
+		pageUUID string
+		isError  bool
+	}{
+		{
+			name:     "Journal of another page",
+			content:  `{"state":"started","restore_to_page_uuid":"other_page"}`,
+			pageUUID: restoreToPageUUID,
+			isError:  true,
+		},
+		{
+			name: "Partially written last entry",
+			content: `{"state":"started","restore_to_page_uuid":"page"}` + "\n" +
+				`{"state":"created","node_uuid":"node","node_type":"PAGE"}` + "\n" +
+				`{"state":"created","node_`,
+			pageUUID: "page",
+		},
+		{
+			name: "Invalid entry",
+			content: `{"state":"started","restore_to_page_uuid":"page"}` + "\n" +
+				`{"state":"created","node_` + "\n" +
+				`{"state":"created","node_uuid":"node","node_type":"PAGE"}`,
+			pageUUID: "page",
+			isError:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			journalPath := filepath.Join(t.TempDir(), "restore.journal")
+			err := ioutil.WriteFile(journalPath, []byte(test.content), 0644)
+			assert.Nil(t, err)
+
+			journal, err := importer.OpenJournal(journalPath, test.pageUUID, true)
+			if test.isError {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, 1, journal.GetCreatedCount())
+			assert.Nil(t, journal.Close())
+		})
+	}
+}
+
+func TestImportObjectsResume(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	journalPath := filepath.Join(t.TempDir(), "restore.journal")
+	restoreToPageUUID := uuid.NewString()
+
+	rootNode := node.CreateRootNode()
+	firstPageNode := addPageNode(t, writer, rootNode, getPage("page_1", nil))
+	addBlockNode(t, writer, firstPageNode, getParagraphBlock("block_1", nil))
+	addPageNode(t, writer, rootNode, getPage("page_2", nil))
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	getImporter := func(notionClient notionclient.NotionClient,
+		resume bool) (*importer.Importer, *importer.Journal) {
+		journal, err := importer.OpenJournal(journalPath, restoreToPageUUID,
+			resume)
+		assert.Nil(t, err)
+		return importer.GetImporter(writer, notionClient, restoreToPageUUID,
+			treeObj, importer.WithJournal(journal)), journal
+	}
+
+	// First attempt fails after creating the first page
+	mockedNotionClient := mocks.NewNotionClient(t)
+	mockCreatePage(mockedNotionClient, "new_page_1",
+		func(req *notionapi.PageCreateRequest) bool {
+			return req.Parent.PageID == notionapi.PageID(restoreToPageUUID)
+		})
+	mockedNotionClient.On("CreatePage", ctx, mock.Anything).Return(nil,
+		errGeneric).Once()
+
+	importerObj, journal := getImporter(mockedNotionClient, false)
+	err := importerObj.ImportObjects(ctx)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, journal.GetCreatedCount())
+	assert.Nil(t, journal.Close())
+
+	// Resumed attempt creates the second page and appends the block to the
+	// page created by first attempt
+	mockedNotionClient = mocks.NewNotionClient(t)
+	mockCreatePage(mockedNotionClient, "new_page_2",
+		func(req *notionapi.PageCreateRequest) bool { return true })
+	mockAppendBlocks(mockedNotionClient, "new_page_1", "new_block_1")
+
+	importerObj, journal = getImporter(mockedNotionClient, true)
+	err = importerObj.ImportObjects(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, journal.GetCreatedCount())
+	assert.Nil(t, journal.Close())
+
+	// Completed restore does not create any object again
+	importerObj, journal = getImporter(mocks.NewNotionClient(t), true)
+	err = importerObj.ImportObjects(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, journal.Remove())
+	assert.NoFileExists(t, journalPath)
+}
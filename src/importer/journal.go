@@ -0,0 +1,304 @@
+package importer
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/shivaji17/notionbackup/src/tree/node"
+)
+
+type journalState string
+
+const (
+	// First entry of the journal recording the page to which data is restored
+	STARTED journalState = "started"
+
+	// Notion object of the node is created
+	CREATED journalState = "created"
+
+	// All children of the node are created
+	COMPLETED journalState = "completed"
+
+	// Relation properties of the database or relation values of the page are
+	// remapped to the restored objects
+	RELATIONS_REMAPPED journalState = "relations_remapped"
+
+	// Rollup properties of the database are restored
+	ROLLUPS_REMAPPED journalState = "rollups_remapped"
+
+	// Links of the page or block are restored as text until their targets are
+	// created
+	LINKS_DEFERRED journalState = "links_deferred"
+
+	// Deferred links of the page or block are updated
+	LINKS_UPDATED journalState = "links_updated"
+
+	JOURNAL_FILE_PERM = 0644
+)
+
+// Entry of the journal written as a single line of JSON
+type journalEntry struct {
+	State             journalState  `json:"state"`
+	NodeUuid          string        `json:"node_uuid,omitempty"`
+	NodeType          node.NodeType `json:"node_type,omitempty"`
+	NotionObjectId    string        `json:"notion_object_id,omitempty"`
+	NewNotionObjectId string        `json:"new_notion_object_id,omitempty"`
+	RestoreToPageUUID string        `json:"restore_to_page_uuid,omitempty"`
+}
+
+// Journal is an append-only log of the restore progress. It maps each node of
+// the backup to the Notion object created for it so that failed restore can be
+// resumed without creating the objects again
+type Journal struct {
+	filePath       string
+	file           *os.File
+	createdEntries map[string]*journalEntry
+	completedNodes map[string]bool
+	remappedNodes  map[journalState]map[string]bool
+}
+
+// Helper function to read the entries of the existing journal. Last entry is
+// ignored if it was only partially written
+func readJournalEntries(filePath string) ([]*journalEntry, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	lines := []string{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	entries := []*journalEntry{}
+	for i, line := range lines {
+		entry := &journalEntry{}
+		err = json.Unmarshal([]byte(line), entry)
+		if err != nil {
+			if i == len(lines)-1 {
+				break
+			}
+			return nil, fmt.Errorf("invalid entry at line %d of journal: %v", i+1,
+				err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Open the journal of the restore to given page. Entries of the existing
+// journal are loaded if resume is true, otherwise existing journal is treated
+// as an error so that an interrupted restore is not repeated by mistake
+func OpenJournal(filePath string, restoreToPageUUID string,
+	resume bool) (*Journal, error) {
+	journal := &Journal{
+		filePath:       filePath,
+		createdEntries: make(map[string]*journalEntry),
+		completedNodes: make(map[string]bool),
+		remappedNodes: map[journalState]map[string]bool{
+			RELATIONS_REMAPPED: make(map[string]bool),
+			ROLLUPS_REMAPPED:   make(map[string]bool),
+			LINKS_DEFERRED:     make(map[string]bool),
+			LINKS_UPDATED:      make(map[string]bool),
+		},
+	}
+
+	entries := []*journalEntry{}
+	_, err := os.Stat(filePath)
+	if err == nil {
+		if !resume {
+			return nil, fmt.Errorf("journal of an interrupted restore exists at "+
+				"%s. Resume the restore or delete the journal", filePath)
+		}
+
+		entries, err = readJournalEntries(filePath)
+		if err != nil {
+			return nil, err
+		}
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	for _, entry := range entries {
+		switch entry.State {
+		case STARTED:
+			if entry.RestoreToPageUUID != restoreToPageUUID {
+				return nil, fmt.Errorf("journal belongs to restore to page %s",
+					entry.RestoreToPageUUID)
+			}
+		case CREATED:
+			journal.createdEntries[entry.NodeUuid] = entry
+		case COMPLETED:
+			journal.completedNodes[entry.NodeUuid] = true
+		case RELATIONS_REMAPPED, ROLLUPS_REMAPPED, LINKS_DEFERRED, LINKS_UPDATED:
+			journal.remappedNodes[entry.State][entry.NodeUuid] = true
+		}
+	}
+
+	journal.file, err = os.OpenFile(filePath,
+		os.O_CREATE|os.O_APPEND|os.O_WRONLY, JOURNAL_FILE_PERM)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		err = journal.write(&journalEntry{
+			State:             STARTED,
+			RestoreToPageUUID: restoreToPageUUID,
+		})
+		if err != nil {
+			journal.file.Close()
+			return nil, err
+		}
+	}
+
+	return journal, nil
+}
+
+// Helper function to append the entry to the journal. Entry is synced to disk
+// before the restore continues
+func (j *Journal) write(entry *journalEntry) error {
+	dataBytes, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	_, err = j.file.Write(append(dataBytes, '\n'))
+	if err != nil {
+		return err
+	}
+
+	return j.file.Sync()
+}
+
+// Record the Notion object created for the node. Nothing is recorded if the
+// journal is not used
+func (j *Journal) recordCreated(nodeObj *node.Node,
+	newNotionObjectId string) error {
+	if j == nil {
+		return nil
+	}
+
+	entry := &journalEntry{
+		State:             CREATED,
+		NodeUuid:          nodeObj.GetID().String(),
+		NodeType:          nodeObj.GetNodeType(),
+		NotionObjectId:    nodeObj.GetNotionObjectId(),
+		NewNotionObjectId: newNotionObjectId,
+	}
+
+	err := j.write(entry)
+	if err != nil {
+		return err
+	}
+
+	j.createdEntries[entry.NodeUuid] = entry
+	return nil
+}
+
+// Record that all the children of the node are created
+func (j *Journal) recordCompleted(nodeObj *node.Node) error {
+	if j == nil {
+		return nil
+	}
+
+	err := j.write(&journalEntry{
+		State:    COMPLETED,
+		NodeUuid: nodeObj.GetID().String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	j.completedNodes[nodeObj.GetID().String()] = true
+	return nil
+}
+
+// Check if the Notion object of the node was created by the previous attempt
+func (j *Journal) isCreated(nodeObj *node.Node) bool {
+	if j == nil {
+		return false
+	}
+
+	_, found := j.createdEntries[nodeObj.GetID().String()]
+	return found
+}
+
+// Get the ID of the Notion object created for the node by the previous attempt.
+// Empty string is returned if the object was not created
+func (j *Journal) getCreatedId(nodeObj *node.Node) string {
+	if j == nil {
+		return ""
+	}
+
+	entry, found := j.createdEntries[nodeObj.GetID().String()]
+	if !found {
+		return ""
+	}
+	return entry.NewNotionObjectId
+}
+
+// Check if all the children of the node were created by the previous attempt
+func (j *Journal) isCompleted(nodeObj *node.Node) bool {
+	if j == nil {
+		return false
+	}
+
+	return j.completedNodes[nodeObj.GetID().String()]
+}
+
+// Record that the relations, rollups or links of the node are remapped
+func (j *Journal) recordRemapped(state journalState, nodeObj *node.Node) error {
+	if j == nil {
+		return nil
+	}
+
+	err := j.write(&journalEntry{
+		State:    state,
+		NodeUuid: nodeObj.GetID().String(),
+	})
+	if err != nil {
+		return err
+	}
+
+	j.remappedNodes[state][nodeObj.GetID().String()] = true
+	return nil
+}
+
+// Check if the relations, rollups or links of the node were remapped by the
+// previous attempt
+func (j *Journal) isRemapped(state journalState, nodeObj *node.Node) bool {
+	if j == nil {
+		return false
+	}
+
+	return j.remappedNodes[state][nodeObj.GetID().String()]
+}
+
+// Get the number of nodes whose Notion objects are created
+func (j *Journal) GetCreatedCount() int {
+	return len(j.createdEntries)
+}
+
+func (j *Journal) Close() error {
+	return j.file.Close()
+}
+
+// Close and delete the journal once the restore is successful
+func (j *Journal) Remove() error {
+	err := j.Close()
+	if err != nil {
+		return err
+	}
+
+	return os.Remove(j.filePath)
+}
@@ -0,0 +1,621 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+// Policy for the links to the objects which are not part of the restored
+// backup
+type MissingLinkPolicy string
+
+const (
+	// Links keep pointing to the backed up objects
+	KEEP_MISSING_LINKS MissingLinkPolicy = "keep"
+
+	// Links are replaced by their text
+	TEXT_MISSING_LINKS MissingLinkPolicy = "text"
+)
+
+// Type of the rich text mentioning an object. It is not defined by notionapi
+const MENTION_RICH_TEXT_TYPE notionapi.ObjectType = "mention"
+
+type linkState int
+
+const (
+	// Target of the link is restored
+	LINK_RESOLVED linkState = iota
+
+	// Target of the link is part of the restore but not created yet
+	LINK_PENDING
+
+	// Target of the link is not part of the restore
+	LINK_MISSING
+)
+
+// Internal links of the rich text point to the page, optionally with the
+// anchor of the block, e.g. /<page id>#<block id>
+var internalLinkRegex = regexp.MustCompile(
+	`^(?:https://www\.notion\.so)?/(?:[^/#?]*-)?([0-9a-f]{32})` +
+		`(?:#([0-9a-f]{32}))?$`)
+
+// Link of the restored object whose target is not part of the restored backup
+type MissingLink struct {
+	NodeUuid       string `json:"node_uuid"`
+	NotionObjectId string `json:"notion_object_id"`
+	TargetId       string `json:"target_id"`
+}
+
+// Links found while rewriting a single object
+type linkRewrite struct {
+	pending bool
+	missing []string
+}
+
+// Set the policy for the links to the objects which are not part of the
+// restored backup. Links are kept by default
+func WithMissingLinkPolicy(policy MissingLinkPolicy) ImporterOption {
+	return func(c *Importer) {
+		c.missingLinkPolicy = policy
+	}
+}
+
+// Helper function to get the Notion ID in the form used by the backup
+func getCanonicalId(id string) string {
+	parsedUuid, err := uuid.Parse(id)
+	if err != nil {
+		return id
+	}
+	return parsedUuid.String()
+}
+
+// Helper function to collect the Notion IDs of all the restored objects
+func (c *Importer) loadRestoredIds() {
+	c.restoredIds = make(map[string]bool)
+	for _, nodeObj := range c.getRestoredScope() {
+		if nodeObj.GetNotionObjectId() != "" &&
+			nodeObj.GetNodeType() != node.COMMENT {
+			c.restoredIds[getCanonicalId(nodeObj.GetNotionObjectId())] = true
+		}
+	}
+}
+
+// Helper function to find the restored object for the link target
+func (c *Importer) resolveLink(targetId string) (string, linkState) {
+	newUuid, found := c.objUuidMapping.getNewUuid(getCanonicalId(targetId))
+	if found {
+		return newUuid, LINK_RESOLVED
+	}
+
+	if c.restoredIds[getCanonicalId(targetId)] {
+		return "", LINK_PENDING
+	}
+
+	return "", LINK_MISSING
+}
+
+// Helper function to convert the rich text to plain text without any link
+func getPlainRichText(richText map[string]interface{}) map[string]interface{} {
+	plainText, _ := richText["plain_text"].(string)
+	textObj := map[string]interface{}{
+		"type":       string(notionapi.ObjectTypeText),
+		"text":       map[string]interface{}{"content": plainText},
+		"plain_text": plainText,
+	}
+
+	if annotations, found := richText["annotations"]; found {
+		textObj["annotations"] = annotations
+	}
+	return textObj
+}
+
+// Helper function to rewrite the target of the mention of page or database
+func (c *Importer) rewriteMention(richText map[string]interface{},
+	rewrite *linkRewrite) map[string]interface{} {
+	mention, _ := richText["mention"].(map[string]interface{})
+	mentionType, _ := mention["type"].(string)
+	if mentionType != string(notionapi.MentionTypePage) &&
+		mentionType != string(notionapi.MentionTypeDatabase) {
+		return richText
+	}
+
+	target, _ := mention[mentionType].(map[string]interface{})
+	targetId, _ := target["id"].(string)
+	newUuid, state := c.resolveLink(targetId)
+	switch state {
+	case LINK_RESOLVED:
+		target["id"] = newUuid
+		delete(richText, "href")
+	case LINK_PENDING:
+		rewrite.pending = true
+		return getPlainRichText(richText)
+	case LINK_MISSING:
+		rewrite.missing = append(rewrite.missing, targetId)
+		if c.missingLinkPolicy == TEXT_MISSING_LINKS {
+			return getPlainRichText(richText)
+		}
+	}
+
+	return richText
+}
+
+// Helper function to rewrite the internal link of the text
+func (c *Importer) rewriteTextLink(richText map[string]interface{},
+	rewrite *linkRewrite) map[string]interface{} {
+	text, _ := richText["text"].(map[string]interface{})
+	link, _ := text["link"].(map[string]interface{})
+	url, _ := link["url"].(string)
+	matches := internalLinkRegex.FindStringSubmatch(url)
+	if matches == nil {
+		return richText
+	}
+
+	newUrl := ""
+	state := LINK_RESOLVED
+	for i, targetId := range matches[1:] {
+		if targetId == "" {
+			continue
+		}
+
+		newUuid, targetState := c.resolveLink(targetId)
+		if targetState > state {
+			state = targetState
+		}
+
+		if targetState == LINK_MISSING {
+			rewrite.missing = append(rewrite.missing, getCanonicalId(targetId))
+		}
+
+		if i == 0 {
+			newUrl = "/" + strings.ReplaceAll(newUuid, "-", "")
+		} else {
+			newUrl += "#" + strings.ReplaceAll(newUuid, "-", "")
+		}
+	}
+
+	switch {
+	case state == LINK_RESOLVED:
+		link["url"] = newUrl
+		richText["href"] = newUrl
+	case state == LINK_PENDING:
+		rewrite.pending = true
+		delete(text, "link")
+		delete(richText, "href")
+	case c.missingLinkPolicy == TEXT_MISSING_LINKS:
+		delete(text, "link")
+		delete(richText, "href")
+	}
+
+	return richText
+}
+
+// Helper function to rewrite the links of all the rich text found in the
+// decoded JSON object
+func (c *Importer) rewriteLinks(value interface{}, rewrite *linkRewrite) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, child := range v {
+			c.rewriteLinks(child, rewrite)
+		}
+	case []interface{}:
+		for i := range v {
+			richText, ok := v[i].(map[string]interface{})
+			if !ok {
+				c.rewriteLinks(v[i], rewrite)
+				continue
+			}
+
+			_, isRichText := richText["plain_text"]
+			switch {
+			case isRichText && richText["type"] == string(MENTION_RICH_TEXT_TYPE):
+				v[i] = c.rewriteMention(richText, rewrite)
+			case isRichText &&
+				richText["type"] == string(notionapi.ObjectTypeText):
+				v[i] = c.rewriteTextLink(richText, rewrite)
+			default:
+				c.rewriteLinks(richText, rewrite)
+			}
+		}
+	}
+}
+
+// Helper function to rewrite the links of the object through its JSON form
+func (c *Importer) rewriteObjectLinks(object interface{},
+	rewrite *linkRewrite) (map[string]interface{}, error) {
+	dataBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded map[string]interface{}
+	err = json.Unmarshal(dataBytes, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	c.rewriteLinks(decoded, rewrite)
+	return decoded, nil
+}
+
+// Helper function to get the paragraph with given rich text
+func getParagraphBlock(richText []notionapi.RichText) notionapi.Block {
+	return &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			Type:   notionapi.BlockTypeParagraph,
+		},
+		Paragraph: notionapi.Paragraph{RichText: richText},
+	}
+}
+
+// Helper function to get the plain text with given content
+func getPlainText(content string) []notionapi.RichText {
+	return []notionapi.RichText{{
+		Type: notionapi.ObjectTypeText,
+		Text: &notionapi.Text{Content: content},
+	}}
+}
+
+// Helper function to rewrite the target of link to page block. nil is returned
+// for the link to the object which is not created yet, as type of the block
+// cannot be changed once it is created. Such block is appended once all the
+// objects are restored
+func (c *Importer) rewriteLinkToPage(block *notionapi.LinkToPageBlock,
+	rewrite *linkRewrite) notionapi.Block {
+	targetId := block.LinkToPage.PageID.String()
+	if block.LinkToPage.DatabaseID != "" {
+		targetId = block.LinkToPage.DatabaseID.String()
+	}
+
+	newUuid, state := c.resolveLink(targetId)
+	switch state {
+	case LINK_RESOLVED:
+		newBlock := *block
+		if newBlock.LinkToPage.DatabaseID != "" {
+			newBlock.LinkToPage.DatabaseID = notionapi.DatabaseID(newUuid)
+		} else {
+			newBlock.LinkToPage.PageID = notionapi.PageID(newUuid)
+		}
+		return &newBlock
+	case LINK_PENDING:
+		rewrite.pending = true
+		return nil
+	}
+
+	rewrite.missing = append(rewrite.missing, targetId)
+	if c.missingLinkPolicy == TEXT_MISSING_LINKS {
+		return getParagraphBlock(getPlainText(targetId))
+	}
+	return block
+}
+
+// Helper function to get the request updating the rich text of the block. nil
+// is returned if Notion API cannot update the block
+func (c *Importer) getBlockUpdateRequest(
+	block notionapi.Block) *notionapi.BlockUpdateRequest {
+	switch b := block.(type) {
+	case *notionapi.ParagraphBlock:
+		return &notionapi.BlockUpdateRequest{Paragraph: &b.Paragraph}
+	case *notionapi.Heading1Block:
+		return &notionapi.BlockUpdateRequest{Heading1: &b.Heading1}
+	case *notionapi.Heading2Block:
+		return &notionapi.BlockUpdateRequest{Heading2: &b.Heading2}
+	case *notionapi.Heading3Block:
+		return &notionapi.BlockUpdateRequest{Heading3: &b.Heading3}
+	case *notionapi.BulletedListItemBlock:
+		return &notionapi.BlockUpdateRequest{BulletedListItem: &b.BulletedListItem}
+	case *notionapi.NumberedListItemBlock:
+		return &notionapi.BlockUpdateRequest{NumberedListItem: &b.NumberedListItem}
+	case *notionapi.ToDoBlock:
+		return &notionapi.BlockUpdateRequest{ToDo: &b.ToDo}
+	case *notionapi.ToggleBlock:
+		return &notionapi.BlockUpdateRequest{Toggle: &b.Toggle}
+	case *notionapi.CalloutBlock:
+		return &notionapi.BlockUpdateRequest{Callout: &b.Callout}
+	case *notionapi.CodeBlock:
+		return &notionapi.BlockUpdateRequest{Code: &b.Code}
+	}
+
+	return nil
+}
+
+// Helper function to report the links to the objects which are not part of
+// the restored backup
+func (c *Importer) reportMissingLinks(ctx context.Context, nodeObj *node.Node,
+	targetIds []string) {
+	log := zerolog.Ctx(ctx)
+	for _, targetId := range targetIds {
+		log.Warn().Str(logging.NodeID, nodeObj.GetID().String()).Msgf(
+			"Link of %s refers to %s which is not part of the backup",
+			nodeObj.GetNotionObjectId(), targetId)
+
+		c.missingLinks = append(c.missingLinks, MissingLink{
+			NodeUuid:       nodeObj.GetID().String(),
+			NotionObjectId: nodeObj.GetNotionObjectId(),
+			TargetId:       targetId,
+		})
+	}
+}
+
+// Helper function to record the object whose links are updated once all the
+// objects are restored
+func (c *Importer) deferLinks(nodeObj *node.Node) error {
+	c.deferredLinks[nodeObj.GetID().String()] = true
+	return c.journal.recordRemapped(LINKS_DEFERRED, nodeObj)
+}
+
+// Helper function to rewrite the links of the block before it is created.
+// Links to the objects which are not created yet are replaced by text and the
+// block is updated with the links once all objects are restored. nil is
+// returned for the link to page block which is appended only then
+func (c *Importer) rewriteBlockLinks(ctx context.Context, nodeObj *node.Node,
+	block notionapi.Block) (notionapi.Block, error) {
+	log := zerolog.Ctx(ctx)
+	rewrite := &linkRewrite{}
+	newBlock := block
+	if linkToPage, ok := block.(*notionapi.LinkToPageBlock); ok {
+		newBlock = c.rewriteLinkToPage(linkToPage, rewrite)
+		if newBlock == nil {
+			return nil, c.deferLinks(nodeObj)
+		}
+	} else {
+		decoded, err := c.rewriteObjectLinks(block, rewrite)
+		if err != nil {
+			return nil, err
+		}
+
+		newBlock, err = utils.DecodeBlockObject(decoded)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	c.reportMissingLinks(ctx, nodeObj, rewrite.missing)
+	if !rewrite.pending {
+		return newBlock, nil
+	}
+
+	if c.getBlockUpdateRequest(block) == nil {
+		log.Warn().Str(logging.NodeID, nodeObj.GetID().String()).Msgf(
+			"Links of %s block %s are restored as text as Notion API cannot "+
+				"update the block", block.GetType(), nodeObj.GetNotionObjectId())
+		return newBlock, nil
+	}
+
+	return newBlock, c.deferLinks(nodeObj)
+}
+
+// Helper function to rewrite the links of the rich text properties of the page
+func (c *Importer) rewritePropertyLinks(properties notionapi.Properties,
+	rewrite *linkRewrite) (notionapi.Properties, error) {
+	decoded, err := c.rewriteObjectLinks(properties, rewrite)
+	if err != nil {
+		return nil, err
+	}
+
+	dataBytes, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	newProperties := notionapi.Properties{}
+	err = json.Unmarshal(dataBytes, &newProperties)
+	return newProperties, err
+}
+
+// Helper function to rewrite the links of the page properties before the page
+// is created. Page is updated with the links to the objects which are not
+// created yet once all objects are restored
+func (c *Importer) rewritePageLinks(ctx context.Context, nodeObj *node.Node,
+	properties notionapi.Properties) (notionapi.Properties, error) {
+	rewrite := &linkRewrite{}
+	newProperties, err := c.rewritePropertyLinks(properties, rewrite)
+	if err != nil {
+		return nil, err
+	}
+
+	c.reportMissingLinks(ctx, nodeObj, rewrite.missing)
+	if rewrite.pending {
+		return newProperties, c.deferLinks(nodeObj)
+	}
+
+	return newProperties, nil
+}
+
+// Helper function to append the link to page block whose target was not
+// created yet when the other children of its parent were appended. Block is
+// appended after the other children as Notion API cannot insert it at its
+// original position
+func (c *Importer) appendLinkToPage(ctx context.Context, nodeObj *node.Node,
+	block *notionapi.LinkToPageBlock) error {
+	log := zerolog.Ctx(ctx)
+
+	// Block appended by previous attempt
+	if c.journal.isCreated(nodeObj) {
+		return nil
+	}
+
+	parentNode := nodeObj.GetParentNode()
+	var parentUuid string
+	if parentNode.GetNodeType() == node.PAGE {
+		newPageUuid, err := c.objUuidMapping.getPageUuid(
+			notionapi.PageID(parentNode.GetNotionObjectId()))
+		if err != nil {
+			return err
+		}
+		parentUuid = newPageUuid.String()
+	} else {
+		newBlockUuid, err := c.objUuidMapping.getBlockUuid(
+			notionapi.BlockID(parentNode.GetNotionObjectId()))
+		if err != nil {
+			return err
+		}
+		parentUuid = newBlockUuid.String()
+	}
+
+	var newBlock notionapi.Block = block
+	c.clear(&newBlock)
+	newBlock = c.rewriteLinkToPage(newBlock.(*notionapi.LinkToPageBlock),
+		&linkRewrite{})
+	if newBlock == nil {
+		return fmt.Errorf("target of link to page block %s is not restored",
+			block.GetID())
+	}
+
+	req := &notionapi.AppendBlockChildrenRequest{
+		Children: notionapi.Blocks{newBlock},
+	}
+
+	log.Debug().Msgf("Appending link to page Block %s...", block.GetID())
+	rsp, err := c.notionClient.AppendBlocksToBlock(ctx,
+		notionclient.BlockID(parentUuid), req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Block Append Request: %s", b)
+		return err
+	}
+
+	if len(rsp.Results) != 1 {
+		return fmt.Errorf("number of blocks in request does not match number of " +
+			"blocks in response")
+	}
+
+	c.objUuidMapping.insertBlockUuid(notionapi.ObjectID(block.GetID()),
+		notionapi.ObjectID(rsp.Results[0].GetID()))
+	progress.Ctx(ctx).Add(progress.BLOCKS, progress.CREATED, 1)
+	return c.journal.recordCreated(nodeObj, rsp.Results[0].GetID().String())
+}
+
+// Helper function to set the links of the restored block
+func (c *Importer) updateBlockLinks(ctx context.Context,
+	nodeObj *node.Node) error {
+	log := zerolog.Ctx(ctx)
+	block, err := c.rwClient.ReadBlock(ctx, nodeObj.GetStorageIdentifier())
+	if err != nil {
+		return err
+	}
+
+	if linkToPage, ok := block.(*notionapi.LinkToPageBlock); ok {
+		return c.appendLinkToPage(ctx, nodeObj, linkToPage)
+	}
+
+	newBlockUuid, err := c.objUuidMapping.getBlockUuid(
+		notionapi.BlockID(nodeObj.GetNotionObjectId()))
+	if err != nil {
+		return err
+	}
+
+	decoded, err := c.rewriteObjectLinks(block, &linkRewrite{})
+	if err != nil {
+		return err
+	}
+
+	block, err = utils.DecodeBlockObject(decoded)
+	if err != nil {
+		return err
+	}
+
+	block, err = c.rewriteBlockAssets(ctx, block)
+	if err != nil {
+		return err
+	}
+
+	req := c.getBlockUpdateRequest(block)
+	if req == nil {
+		return fmt.Errorf("%s block cannot be updated", block.GetType())
+	}
+
+	log.Debug().Msgf("Restoring links of Block %s...", block.GetID())
+	_, err = c.notionClient.UpdateBlock(ctx, notionclient.BlockID(newBlockUuid),
+		req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Block Update Request: %s", b)
+	}
+	return err
+}
+
+// Helper function to set the links of the rich text properties of the
+// restored page
+func (c *Importer) updatePageLinks(ctx context.Context,
+	nodeObj *node.Node) error {
+	log := zerolog.Ctx(ctx)
+	page, err := c.rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
+	if err != nil {
+		return err
+	}
+
+	newPageUuid, err := c.objUuidMapping.getPageUuid(notionapi.PageID(page.ID))
+	if err != nil {
+		return err
+	}
+
+	properties, err := c.rewritePropertyLinks(page.Properties, &linkRewrite{})
+	if err != nil {
+		return err
+	}
+
+	req := &notionapi.PageUpdateRequest{Properties: notionapi.Properties{}}
+	for name, property := range properties {
+		switch property.(type) {
+		case *notionapi.TitleProperty, *notionapi.RichTextProperty:
+			req.Properties[name] = property
+		}
+	}
+
+	log.Debug().Msgf("Restoring links of Page %s...", page.ID)
+	_, err = c.notionClient.UpdatePage(ctx, notionclient.PageID(newPageUuid),
+		req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Page Update Request: %s", b)
+	}
+	return err
+}
+
+// Third pass of the import which sets the links to the objects which were not
+// created yet when the linking objects were created
+func (c *Importer) updateDeferredLinks(ctx context.Context) error {
+	for _, nodeObj := range c.getRestoredScope() {
+		if !c.deferredLinks[nodeObj.GetID().String()] ||
+			c.journal.isRemapped(LINKS_UPDATED, nodeObj) {
+			continue
+		}
+
+		var err error
+		if nodeObj.GetNodeType() == node.PAGE {
+			err = c.updatePageLinks(ctx, nodeObj)
+		} else {
+			err = c.updateBlockLinks(ctx, nodeObj)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		err = c.journal.recordRemapped(LINKS_UPDATED, nodeObj)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get the links which point to the objects which are not part of the restored
+// backup
+func (c *Importer) GetMissingLinks() []MissingLink {
+	return c.missingLinks
+}
@@ -0,0 +1,208 @@
+package importer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func getTextLink(url string, content string) notionapi.RichText {
+	return notionapi.RichText{
+		Type: notionapi.ObjectTypeText,
+		Text: &notionapi.Text{
+			Content: content,
+			Link:    &notionapi.Link{Url: url},
+		},
+		PlainText: content,
+		Href:      url,
+	}
+}
+
+// Helper function to get the last request of given method sent to the mocked
+// client
+func getRequest(m *mocks.NotionClient, method string) interface{} {
+	var req interface{}
+	for _, call := range m.Calls {
+		if call.Method == method {
+			req = call.Arguments.Get(len(call.Arguments) - 1)
+		}
+	}
+	return req
+}
+
+// Helper function to get the rich text of the paragraph block
+func getRichText(t *testing.T, block notionapi.Block) []notionapi.RichText {
+	paragraph, ok := block.(*notionapi.ParagraphBlock)
+	assert.True(t, ok)
+	if !ok {
+		return nil
+	}
+	return paragraph.Paragraph.RichText
+}
+
+func TestDeferredLinks(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	restoreToPageUUID := uuid.NewString()
+	firstPageId := uuid.NewString()
+	secondPageId := uuid.NewString()
+	thirdPageId := uuid.NewString()
+	newSecondPageId := uuid.NewString()
+
+	// Title of the first page and its blocks link to the pages which are
+	// created after the first page or its blocks
+	rootNode := node.CreateRootNode()
+	firstPageNode := addPageNode(t, writer, rootNode, getPage(firstPageId,
+		notionapi.Properties{
+			"Name": &notionapi.TitleProperty{
+				Type:  notionapi.PropertyTypeTitle,
+				Title: []notionapi.RichText{getMention(secondPageId, "Second")},
+			},
+		}))
+	addBlockNode(t, writer, firstPageNode, getParagraphBlock("mention",
+		[]notionapi.RichText{getMention(thirdPageId, "Third")}))
+	addBlockNode(t, writer, firstPageNode, getLinkToPageBlock("link_to_page",
+		thirdPageId))
+	addBlockNode(t, writer, firstPageNode, getParagraphBlock("self_mention",
+		[]notionapi.RichText{getMention(firstPageId, "First")}))
+	addBlockNode(t, writer, firstPageNode, getParagraphBlock("text_link",
+		[]notionapi.RichText{getTextLink("/"+strings.ReplaceAll(secondPageId,
+			"-", ""), "Second")}))
+	missingNode := addBlockNode(t, writer, firstPageNode, getParagraphBlock(
+		"missing_mention", []notionapi.RichText{getMention("missing",
+			"Missing")}))
+
+	secondPageNode := addPageNode(t, writer, rootNode, getPage(secondPageId,
+		nil))
+	childPageNode := addBlockNode(t, writer, secondPageNode,
+		&notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(thirdPageId),
+				Type:   notionapi.BlockTypeChildPage,
+			},
+		})
+	thirdPage := getPage(thirdPageId, nil)
+	thirdPage.Parent = notionapi.Parent{
+		Type:   notionapi.ParentTypePageID,
+		PageID: notionapi.PageID(secondPageId),
+	}
+	addPageNode(t, writer, childPageNode, thirdPage)
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	tests := []struct {
+		name                string
+		policy              importer.MissingLinkPolicy
+		expectedMissingType notionapi.ObjectType
+	}{
+		{name: "Keep missing links", policy: importer.KEEP_MISSING_LINKS,
+			expectedMissingType: importer.MENTION_RICH_TEXT_TYPE},
+		{name: "Replace missing links by text",
+			policy:              importer.TEXT_MISSING_LINKS,
+			expectedMissingType: notionapi.ObjectTypeText},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockedNotionClient := mocks.NewNotionClient(t)
+			mockCreatePage(mockedNotionClient, "new_page_1",
+				func(req *notionapi.PageCreateRequest) bool {
+					return req.Parent.PageID == notionapi.PageID(restoreToPageUUID)
+				})
+			mockCreatePage(mockedNotionClient, newSecondPageId,
+				func(req *notionapi.PageCreateRequest) bool { return true })
+			mockCreatePage(mockedNotionClient, "new_page_3",
+				func(req *notionapi.PageCreateRequest) bool {
+					return req.Parent.PageID == notionapi.PageID(newSecondPageId)
+				})
+			mockAppendBlocks(mockedNotionClient, "new_page_1", "new_mention",
+				"new_self_mention", "new_text_link", "new_missing_mention")
+			mockAppendBlocks(mockedNotionClient, "new_page_1", "new_link_to_page")
+			mockedNotionClient.On("UpdatePage", ctx,
+				notionclient.PageID("new_page_1"), mock.Anything).Return(
+				&notionapi.Page{}, nil).Once()
+			mockedNotionClient.On("UpdateBlock", ctx,
+				notionclient.BlockID("new_mention"), mock.Anything).Return(
+				&notionapi.ParagraphBlock{}, nil).Once()
+
+			importerObj := importer.GetImporter(writer, mockedNotionClient,
+				restoreToPageUUID, treeObj,
+				importer.WithMissingLinkPolicy(test.policy))
+			err := importerObj.ImportObjects(ctx)
+			assert.Nil(t, err)
+
+			// Links to the pages which are not created yet are restored as text
+			// and the link to page block is not appended yet
+			var appendReqs []*notionapi.AppendBlockChildrenRequest
+			for _, call := range mockedNotionClient.Calls {
+				if call.Method == "AppendBlocksToBlock" {
+					appendReqs = append(appendReqs,
+						call.Arguments.Get(2).(*notionapi.AppendBlockChildrenRequest))
+				}
+			}
+			assert.Len(t, appendReqs, 2)
+			if len(appendReqs) != 2 {
+				return
+			}
+
+			appendReq := appendReqs[0]
+			assert.Len(t, appendReq.Children, 4)
+			mention := getRichText(t, appendReq.Children[0])
+			assert.Equal(t, notionapi.ObjectTypeText, mention[0].Type)
+			assert.Equal(t, "Third", mention[0].Text.Content)
+
+			// Links to the pages which are already created are rewritten
+			selfMention := getRichText(t, appendReq.Children[1])
+			assert.Equal(t, notionapi.ObjectID("new_page_1"),
+				selfMention[0].Mention.Page.ID)
+			textLink := getRichText(t, appendReq.Children[2])
+			assert.Equal(t, "/"+strings.ReplaceAll(newSecondPageId, "-", ""),
+				textLink[0].Text.Link.Url)
+			missing := getRichText(t, appendReq.Children[3])
+			assert.Equal(t, test.expectedMissingType, missing[0].Type)
+
+			// Link to page block keeps its type and is appended once its target
+			// is created
+			assert.Len(t, appendReqs[1].Children, 1)
+			linkToPage, ok := appendReqs[1].Children[0].(*notionapi.LinkToPageBlock)
+			assert.True(t, ok)
+			if ok {
+				assert.Equal(t, notionapi.PageID("new_page_3"),
+					linkToPage.LinkToPage.PageID)
+			}
+
+			// Links are set once all the pages are created
+			pageReq := getRequest(mockedNotionClient,
+				"UpdatePage").(*notionapi.PageUpdateRequest)
+			title := pageReq.Properties["Name"].(*notionapi.TitleProperty)
+			assert.Equal(t, notionapi.ObjectID(newSecondPageId),
+				title.Title[0].Mention.Page.ID)
+
+			for _, call := range mockedNotionClient.Calls {
+				if call.Method != "UpdateBlock" {
+					continue
+				}
+
+				req := call.Arguments.Get(2).(*notionapi.BlockUpdateRequest)
+				assert.Equal(t, notionapi.ObjectID("new_page_3"),
+					req.Paragraph.RichText[0].Mention.Page.ID)
+			}
+
+			assert.Equal(t, []importer.MissingLink{{
+				NodeUuid:       missingNode.GetID().String(),
+				NotionObjectId: "missing_mention",
+				TargetId:       "missing",
+			}}, importerObj.GetMissingLinks())
+		})
+	}
+}
@@ -0,0 +1,398 @@
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+)
+
+const (
+	SINGLE_PROPERTY_RELATION = "single_property"
+	DUAL_PROPERTY_RELATION   = "dual_property"
+)
+
+// Relation of the restored object which is not restored as its target is not
+// part of the restored backup
+type UnresolvedRelation struct {
+	NodeUuid       string `json:"node_uuid"`
+	NotionObjectId string `json:"notion_object_id"`
+	Property       string `json:"property"`
+	TargetId       string `json:"target_id"`
+}
+
+// Relation property config in the format accepted by Notion API for creating
+// relations. Config of notionapi is only able to read relations
+type relationPropertyConfig struct {
+	Type     notionapi.PropertyConfigType `json:"type"`
+	Relation relationConfig               `json:"relation"`
+}
+
+type relationConfig struct {
+	DatabaseID     notionapi.DatabaseID `json:"database_id"`
+	Type           string               `json:"type"`
+	SingleProperty *struct{}            `json:"single_property,omitempty"`
+	DualProperty   *dualPropertyConfig  `json:"dual_property,omitempty"`
+}
+
+type dualPropertyConfig struct {
+	SyncedPropertyName string `json:"synced_property_name,omitempty"`
+}
+
+func (p relationPropertyConfig) GetType() notionapi.PropertyConfigType {
+	return p.Type
+}
+
+// Rollup property config referring to the properties only by their names as
+// IDs of the properties of restored databases are not known
+type rollupPropertyConfig struct {
+	Type   notionapi.PropertyConfigType `json:"type"`
+	Rollup rollupConfig                 `json:"rollup"`
+}
+
+type rollupConfig struct {
+	RelationPropertyName string                 `json:"relation_property_name"`
+	RollupPropertyName   string                 `json:"rollup_property_name"`
+	Function             notionapi.FunctionType `json:"function"`
+}
+
+func (p rollupPropertyConfig) GetType() notionapi.PropertyConfigType {
+	return p.Type
+}
+
+// Helper function to get the properties of the database which can be created
+// before the related databases are restored. Relation and rollup properties
+// are added by remapRelations once all the objects are restored
+func getCreatableDatabaseProperties(
+	properties notionapi.PropertyConfigs) notionapi.PropertyConfigs {
+	creatable := make(notionapi.PropertyConfigs)
+	for name, property := range properties {
+		switch property.(type) {
+		case *notionapi.RelationPropertyConfig, *notionapi.RollupPropertyConfig:
+			continue
+		}
+		creatable[name] = property
+	}
+	return creatable
+}
+
+// Helper function to get the properties of the page without the relation
+// values which are set by remapRelations once all the objects are restored
+func getCreatablePageProperties(
+	properties notionapi.Properties) notionapi.Properties {
+	creatable := make(notionapi.Properties)
+	for name, property := range properties {
+		if _, ok := property.(*notionapi.RelationProperty); ok {
+			continue
+		}
+		creatable[name] = property
+	}
+	return creatable
+}
+
+// Helper function to get the property names in the same order on every attempt
+func getSortedNames(properties map[string]bool) []string {
+	names := []string{}
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Helper function to get the key of the property of the backed up database
+func getPropertyKey(databaseId notionapi.DatabaseID, name string) string {
+	return databaseId.String() + "/" + name
+}
+
+func (c *Importer) reportUnresolvedRelation(ctx context.Context,
+	nodeObj *node.Node, property string, targetId string) {
+	log := zerolog.Ctx(ctx)
+	log.Warn().Str(logging.NodeID, nodeObj.GetID().String()).Msgf(
+		"Relation %s of %s refers to %s which is not part of the backup",
+		property, nodeObj.GetNotionObjectId(), targetId)
+
+	c.unresolvedRelations = append(c.unresolvedRelations, UnresolvedRelation{
+		NodeUuid:       nodeObj.GetID().String(),
+		NotionObjectId: nodeObj.GetNotionObjectId(),
+		Property:       property,
+		TargetId:       targetId,
+	})
+}
+
+// Helper function to get all restored nodes in the order of the tree
+func (c *Importer) getRestoredScope() []*node.Node {
+	if c.subtreeNode != nil {
+		return collectNodes(c.subtreeNode)
+	}
+	return collectNodes(c.treeObj.RootNode)
+}
+
+// Helper function to get all restored nodes of given type in the order of the
+// tree
+func (c *Importer) getRestoredNodes(nodeType node.NodeType) []*node.Node {
+	nodes := []*node.Node{}
+	for _, nodeObj := range c.getRestoredScope() {
+		if nodeObj.GetNodeType() == nodeType {
+			nodes = append(nodes, nodeObj)
+		}
+	}
+	return nodes
+}
+
+// Helper function to add the relation properties of the restored database
+// pointing to the restored related databases. Synced relation is created only
+// from one of the databases as Notion adds its counterpart to the related
+// database itself. Relation properties which are skipped are added to
+// skippedProperties
+func (c *Importer) remapDatabaseRelations(ctx context.Context,
+	nodeObj *node.Node, database *notionapi.Database,
+	syncedProperties map[string]bool, skippedProperties map[string]bool) error {
+	log := zerolog.Ctx(ctx)
+	newDatabaseUuid, err := c.objUuidMapping.getDatabaseUuid(
+		notionapi.DatabaseID(database.ID))
+	if err != nil {
+		return err
+	}
+
+	relations := make(map[string]bool)
+	for name, property := range database.Properties {
+		if _, ok := property.(*notionapi.RelationPropertyConfig); ok {
+			relations[name] = true
+		}
+	}
+
+	properties := make(notionapi.PropertyConfigs)
+	for _, name := range getSortedNames(relations) {
+		relation := database.Properties[name].(*notionapi.RelationPropertyConfig)
+		oldTargetUuid := relation.Relation.DatabaseID
+		newTargetUuid, err := c.objUuidMapping.getDatabaseUuid(oldTargetUuid)
+		if err != nil {
+			c.reportUnresolvedRelation(ctx, nodeObj, name, oldTargetUuid.String())
+			skippedProperties[getPropertyKey(notionapi.DatabaseID(database.ID),
+				name)] = true
+			continue
+		}
+
+		// Counterpart of the synced relation created by the related database
+		if syncedProperties[getPropertyKey(notionapi.DatabaseID(database.ID),
+			name)] {
+			continue
+		}
+
+		config := relationConfig{
+			DatabaseID:     newTargetUuid,
+			Type:           SINGLE_PROPERTY_RELATION,
+			SingleProperty: &struct{}{},
+		}
+
+		syncedName := relation.Relation.SyncedPropertyName
+		if syncedName != "" {
+			config = relationConfig{
+				DatabaseID: newTargetUuid,
+				Type:       DUAL_PROPERTY_RELATION,
+				DualProperty: &dualPropertyConfig{
+					SyncedPropertyName: syncedName,
+				},
+			}
+			syncedProperties[getPropertyKey(oldTargetUuid, syncedName)] = true
+		}
+
+		properties[name] = relationPropertyConfig{
+			Type:     notionapi.PropertyConfigTypeRelation,
+			Relation: config,
+		}
+	}
+
+	if len(properties) == 0 || c.journal.isRemapped(RELATIONS_REMAPPED,
+		nodeObj) {
+		return nil
+	}
+
+	req := &notionapi.DatabaseUpdateRequest{Properties: properties}
+	log.Debug().Msgf("Remapping relations of Database %s...", database.ID)
+	_, err = c.notionClient.UpdateDatabase(ctx,
+		notionclient.DatabaseID(newDatabaseUuid), req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Database Update Request: %s", b)
+		return err
+	}
+
+	return c.journal.recordRemapped(RELATIONS_REMAPPED, nodeObj)
+}
+
+// Helper function to add the rollup properties of the restored database.
+// Rollups of the skipped relations are skipped too
+func (c *Importer) remapDatabaseRollups(ctx context.Context,
+	nodeObj *node.Node, database *notionapi.Database,
+	skippedProperties map[string]bool) error {
+	log := zerolog.Ctx(ctx)
+	properties := make(notionapi.PropertyConfigs)
+	for name, property := range database.Properties {
+		rollup, ok := property.(*notionapi.RollupPropertyConfig)
+		if !ok {
+			continue
+		}
+
+		if skippedProperties[getPropertyKey(notionapi.DatabaseID(database.ID),
+			rollup.Rollup.RelationPropertyName)] {
+			log.Warn().Str(logging.NodeID, nodeObj.GetID().String()).Msgf(
+				"Skipping rollup %s of %s as its relation is not restored", name,
+				database.ID)
+			continue
+		}
+
+		properties[name] = rollupPropertyConfig{
+			Type: notionapi.PropertyConfigTypeRollup,
+			Rollup: rollupConfig{
+				RelationPropertyName: rollup.Rollup.RelationPropertyName,
+				RollupPropertyName:   rollup.Rollup.RollupPropertyName,
+				Function:             rollup.Rollup.Function,
+			},
+		}
+	}
+
+	if len(properties) == 0 || c.journal.isRemapped(ROLLUPS_REMAPPED, nodeObj) {
+		return nil
+	}
+
+	newDatabaseUuid, err := c.objUuidMapping.getDatabaseUuid(
+		notionapi.DatabaseID(database.ID))
+	if err != nil {
+		return err
+	}
+
+	req := &notionapi.DatabaseUpdateRequest{Properties: properties}
+	log.Debug().Msgf("Restoring rollups of Database %s...", database.ID)
+	_, err = c.notionClient.UpdateDatabase(ctx,
+		notionclient.DatabaseID(newDatabaseUuid), req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Database Update Request: %s", b)
+		return err
+	}
+
+	return c.journal.recordRemapped(ROLLUPS_REMAPPED, nodeObj)
+}
+
+// Helper function to set the relation values of the restored page to the
+// restored related pages
+func (c *Importer) remapPageRelations(ctx context.Context, nodeObj *node.Node,
+	skippedProperties map[string]bool) error {
+	log := zerolog.Ctx(ctx)
+	page, err := c.rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
+	if err != nil {
+		return err
+	}
+
+	relations := make(map[string]bool)
+	for name, property := range page.Properties {
+		relation, ok := property.(*notionapi.RelationProperty)
+		if ok && len(relation.Relation) > 0 && !skippedProperties[getPropertyKey(
+			page.Parent.DatabaseID, name)] {
+			relations[name] = true
+		}
+	}
+
+	properties := make(notionapi.Properties)
+	for _, name := range getSortedNames(relations) {
+		relation := page.Properties[name].(*notionapi.RelationProperty)
+		targets := []notionapi.Relation{}
+		for _, target := range relation.Relation {
+			newTargetUuid, err := c.objUuidMapping.getPageUuid(target.ID)
+			if err != nil {
+				c.reportUnresolvedRelation(ctx, nodeObj, name, target.ID.String())
+				continue
+			}
+			targets = append(targets, notionapi.Relation{ID: newTargetUuid})
+		}
+
+		if len(targets) == 0 {
+			continue
+		}
+
+		properties[name] = &notionapi.RelationProperty{
+			Type:     notionapi.PropertyTypeRelation,
+			Relation: targets,
+		}
+	}
+
+	if len(properties) == 0 || c.journal.isRemapped(RELATIONS_REMAPPED,
+		nodeObj) {
+		return nil
+	}
+
+	newPageUuid, err := c.objUuidMapping.getPageUuid(
+		notionapi.PageID(page.ID))
+	if err != nil {
+		return err
+	}
+
+	req := &notionapi.PageUpdateRequest{Properties: properties}
+	log.Debug().Msgf("Remapping relations of Page %s...", page.ID)
+	_, err = c.notionClient.UpdatePage(ctx, notionclient.PageID(newPageUuid),
+		req)
+	if err != nil {
+		b, _ := json.Marshal(req)
+		log.Err(err).Msgf("Page Update Request: %s", b)
+		return err
+	}
+
+	return c.journal.recordRemapped(RELATIONS_REMAPPED, nodeObj)
+}
+
+// Second pass of the import which points the relations of the restored
+// databases and pages to the restored objects instead of the backed up ones.
+// Relation properties are added to all the databases before the rollups which
+// may depend on them, and the relation values of the pages are set last
+func (c *Importer) remapRelations(ctx context.Context) error {
+	databaseNodes := c.getRestoredNodes(node.DATABASE)
+	databases := []*notionapi.Database{}
+	for _, nodeObj := range databaseNodes {
+		database, err := c.rwClient.ReadDatabase(ctx,
+			nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return err
+		}
+		databases = append(databases, database)
+	}
+
+	syncedProperties := make(map[string]bool)
+	skippedProperties := make(map[string]bool)
+	for i, nodeObj := range databaseNodes {
+		err := c.remapDatabaseRelations(ctx, nodeObj, databases[i],
+			syncedProperties, skippedProperties)
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, nodeObj := range databaseNodes {
+		err := c.remapDatabaseRollups(ctx, nodeObj, databases[i],
+			skippedProperties)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, nodeObj := range c.getRestoredNodes(node.PAGE) {
+		err := c.remapPageRelations(ctx, nodeObj, skippedProperties)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Get the relations which are not restored as their targets are not part of
+// the restored backup
+func (c *Importer) GetUnresolvedRelations() []UnresolvedRelation {
+	return c.unresolvedRelations
+}
@@ -0,0 +1,205 @@
+package importer_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func getTitleConfig() *notionapi.TitlePropertyConfig {
+	return &notionapi.TitlePropertyConfig{
+		Type: notionapi.PropertyConfigTypeTitle,
+	}
+}
+
+func getRollupConfig(relationName string,
+	rollupName string) *notionapi.RollupPropertyConfig {
+	return &notionapi.RollupPropertyConfig{
+		Type: notionapi.PropertyConfigTypeRollup,
+		Rollup: notionapi.RollupConfig{
+			RelationPropertyName: relationName,
+			RollupPropertyName:   rollupName,
+			Function:             notionapi.FunctionSum,
+		},
+	}
+}
+
+func getRow(id string, databaseId string,
+	properties notionapi.Properties) *notionapi.Page {
+	row := getPage(id, properties)
+	row.Parent = notionapi.Parent{
+		Type:       notionapi.ParentTypeDatabaseID,
+		DatabaseID: notionapi.DatabaseID(databaseId),
+	}
+	return row
+}
+
+// Helper function to mock the creation of the database with given title
+// returning the database with given ID
+func mockCreateDatabase(m *mocks.NotionClient, title string,
+	newDatabaseId string) {
+	m.On("CreateDatabase", context.Background(), mock.MatchedBy(
+		func(req *notionapi.DatabaseCreateRequest) bool {
+			// Relations and rollups are added once all databases are created
+			for _, property := range req.Properties {
+				switch property.(type) {
+				case *notionapi.RelationPropertyConfig,
+					*notionapi.RollupPropertyConfig:
+					return false
+				}
+			}
+			return len(req.Title) == 1 && req.Title[0].PlainText == title
+		})).Return(&notionapi.Database{
+		ID: notionapi.ObjectID(newDatabaseId),
+	}, nil).Once()
+}
+
+func TestRemapRelations(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	restoreToPageUUID := uuid.NewString()
+
+	// Tasks and projects are related with a synced relation. Archive database
+	// is not part of the backup, so the relation to it and the rollup of that
+	// relation are not restored
+	rootNode := node.CreateRootNode()
+	tasks := getDatabase("tasks", notionapi.PropertyConfigs{
+		"Name":           getTitleConfig(),
+		"Project":        getRelationConfig("projects", "Tasks"),
+		"Hours":          getRollupConfig("Project", "Hours"),
+		"Archive":        getRelationConfig("archive", ""),
+		"Archived hours": getRollupConfig("Archive", "Hours"),
+	})
+	tasks.Title = []notionapi.RichText{{PlainText: "Tasks"}}
+	tasksNode := addDatabaseNode(t, writer, rootNode, tasks)
+	projects := getDatabase("projects", notionapi.PropertyConfigs{
+		"Name":        getTitleConfig(),
+		"Tasks":       getRelationConfig("tasks", "Project"),
+		"Subprojects": getRelationConfig("projects", ""),
+	})
+	projects.Title = []notionapi.RichText{{PlainText: "Projects"}}
+	projectsNode := addDatabaseNode(t, writer, rootNode, projects)
+	addPageNode(t, writer, tasksNode, getRow("task", "tasks",
+		notionapi.Properties{
+			"Project": getRelation("project"),
+			"Archive": getRelation("archived_task"),
+		}))
+	addPageNode(t, writer, projectsNode, getRow("project", "projects",
+		notionapi.Properties{
+			"Tasks":       getRelation("task"),
+			"Subprojects": getRelation(),
+		}))
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	mockedNotionClient := mocks.NewNotionClient(t)
+	mockCreateDatabase(mockedNotionClient, "Tasks", "new_tasks")
+	mockCreateDatabase(mockedNotionClient, "Projects", "new_projects")
+	mockCreatePage(mockedNotionClient, "new_task",
+		func(req *notionapi.PageCreateRequest) bool {
+			return req.Parent.DatabaseID == "new_tasks" &&
+				len(req.Properties) == 0
+		})
+	mockCreatePage(mockedNotionClient, "new_project",
+		func(req *notionapi.PageCreateRequest) bool {
+			return req.Parent.DatabaseID == "new_projects" &&
+				len(req.Properties) == 0
+		})
+
+	// Requests are recorded in the order they are sent
+	updates := make(map[string][]string)
+	recordUpdate := func(id string, properties interface{}) {
+		b, err := json.Marshal(properties)
+		assert.Nil(t, err)
+		updates[id] = append(updates[id], string(b))
+	}
+	mockedNotionClient.On("UpdateDatabase", ctx, mock.Anything,
+		mock.Anything).Run(func(args mock.Arguments) {
+		recordUpdate(string(args.Get(1).(notionclient.DatabaseID)),
+			args.Get(2).(*notionapi.DatabaseUpdateRequest).Properties)
+	}).Return(&notionapi.Database{}, nil)
+	mockedNotionClient.On("UpdatePage", ctx, mock.Anything,
+		mock.Anything).Run(func(args mock.Arguments) {
+		recordUpdate(string(args.Get(1).(notionclient.PageID)),
+			args.Get(2).(*notionapi.PageUpdateRequest).Properties)
+	}).Return(&notionapi.Page{}, nil)
+
+	importerObj := importer.GetImporter(writer, mockedNotionClient,
+		restoreToPageUUID, treeObj)
+	err := importerObj.ImportObjects(ctx)
+	assert.Nil(t, err)
+
+	tests := []struct {
+		name     string
+		id       string
+		expected []string
+	}{
+		{
+			// Counterpart of the synced relation is created by Notion, so the
+			// relation is only created from the tasks database
+			name: "Synced relation and rollup",
+			id:   "new_tasks",
+			expected: []string{
+				`{"Project":{"type":"relation","relation":{` +
+					`"database_id":"new_projects","type":"dual_property",` +
+					`"dual_property":{"synced_property_name":"Tasks"}}}}`,
+				`{"Hours":{"type":"rollup","rollup":{` +
+					`"relation_property_name":"Project",` +
+					`"rollup_property_name":"Hours","function":"sum"}}}`,
+			},
+		},
+		{
+			name: "Single property relation",
+			id:   "new_projects",
+			expected: []string{
+				`{"Subprojects":{"type":"relation","relation":{` +
+					`"database_id":"new_projects","type":"single_property",` +
+					`"single_property":{}}}}`,
+			},
+		},
+		{
+			name: "Relation values of task",
+			id:   "new_task",
+			expected: []string{
+				`{"Project":{"type":"relation",` +
+					`"relation":[{"id":"new_project"}]}}`,
+			},
+		},
+		{
+			name: "Relation values of project",
+			id:   "new_project",
+			expected: []string{
+				`{"Tasks":{"type":"relation","relation":[{"id":"new_task"}]}}`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Len(t, updates[test.id], len(test.expected))
+			for i, expected := range test.expected {
+				if i < len(updates[test.id]) {
+					assert.JSONEq(t, expected, updates[test.id][i])
+				}
+			}
+		})
+	}
+
+	assert.Equal(t, []importer.UnresolvedRelation{
+		{
+			NodeUuid:       tasksNode.GetID().String(),
+			NotionObjectId: "tasks",
+			Property:       "Archive",
+			TargetId:       "archive",
+		},
+	}, importerObj.GetUnresolvedRelations())
+}
@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+type DependencyKind string
+
+const (
+	RELATION_DEPENDENCY          DependencyKind = "relation"
+	RELATION_DATABASE_DEPENDENCY DependencyKind = "relation_database"
+	LINK_TO_PAGE_DEPENDENCY      DependencyKind = "link_to_page"
+)
+
+// Selector of the page or database of the backup which is restored along with
+// its children instead of the whole backup. Only one of the fields is set
+type SubtreeSelector struct {
+	NodeUuid       string
+	NotionObjectId string
+	Title          string
+}
+
+func (s *SubtreeSelector) IsEmpty() bool {
+	return s.NodeUuid == "" && s.NotionObjectId == "" && s.Title == ""
+}
+
+// Object outside the restored subtree referenced by an object of the subtree.
+// Such references cannot be remapped to the restored objects
+type ExternalDependency struct {
+	NodeUuid       string         `json:"node_uuid"`
+	NotionObjectId string         `json:"notion_object_id"`
+	Kind           DependencyKind `json:"kind"`
+	Property       string         `json:"property,omitempty"`
+	TargetId       string         `json:"target_id"`
+}
+
+// Helper function to get the title of the page or database node
+func getNodeTitle(ctx context.Context, rwClient rw.ReaderWriter,
+	nodeObj *node.Node) (string, error) {
+	if nodeObj.GetNodeType() == node.PAGE {
+		page, err := rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return "", err
+		}
+		return utils.GetPageTitle(page), nil
+	}
+
+	database, err := rwClient.ReadDatabase(ctx, nodeObj.GetStorageIdentifier())
+	if err != nil {
+		return "", err
+	}
+	return utils.GetDatabaseTitle(database), nil
+}
+
+// Find the page or database node matching the selector. Selector must match
+// exactly one node
+func SelectSubtree(ctx context.Context, rwClient rw.ReaderWriter,
+	treeObj *tree.Tree, selector *SubtreeSelector) (*node.Node, error) {
+	matches := []*node.Node{}
+	iter := iterator.GetTreeIterator(treeObj.RootNode)
+	for {
+		nodeObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if nodeObj.GetNodeType() != node.PAGE &&
+			nodeObj.GetNodeType() != node.DATABASE {
+			if selector.NodeUuid == nodeObj.GetID().String() {
+				return nil, fmt.Errorf("node %s is a %s. Only pages and databases "+
+					"can be restored", selector.NodeUuid, nodeObj.GetNodeType())
+			}
+			continue
+		}
+
+		switch {
+		case selector.NodeUuid != "":
+			if nodeObj.GetID().String() == selector.NodeUuid {
+				matches = append(matches, nodeObj)
+			}
+		case selector.NotionObjectId != "":
+			if utils.NormalizeNotionId(nodeObj.GetNotionObjectId()) ==
+				utils.NormalizeNotionId(selector.NotionObjectId) {
+				matches = append(matches, nodeObj)
+			}
+		case selector.Title != "":
+			title, err := getNodeTitle(ctx, rwClient, nodeObj)
+			if err != nil {
+				return nil, err
+			}
+
+			if title == selector.Title {
+				matches = append(matches, nodeObj)
+			}
+		}
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no page or database in the backup matches the " +
+			"selector")
+	}
+
+	if len(matches) > 1 {
+		nodeUuids := []string{}
+		for _, nodeObj := range matches {
+			nodeUuids = append(nodeUuids, nodeObj.GetID().String())
+		}
+		return nil, fmt.Errorf("%d pages or databases match the selector. Select "+
+			"one of the nodes with --node: %s", len(matches),
+			strings.Join(nodeUuids, ", "))
+	}
+
+	return matches[0], nil
+}
+
+// Helper function to get the dependencies of the object of the node which are
+// not present in given Notion IDs
+func getNodeDependencies(ctx context.Context, rwClient rw.ReaderWriter,
+	nodeObj *node.Node, notionIds map[string]bool) ([]ExternalDependency,
+	error) {
+	dependencies := []ExternalDependency{}
+	addDependency := func(kind DependencyKind, property string,
+		targetId string) {
+		if targetId == "" || notionIds[utils.NormalizeNotionId(targetId)] {
+			return
+		}
+
+		dependencies = append(dependencies, ExternalDependency{
+			NodeUuid:       nodeObj.GetID().String(),
+			NotionObjectId: nodeObj.GetNotionObjectId(),
+			Kind:           kind,
+			Property:       property,
+			TargetId:       targetId,
+		})
+	}
+
+	switch nodeObj.GetNodeType() {
+	case node.PAGE:
+		page, err := rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for name := range page.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			relation, ok := page.Properties[name].(*notionapi.RelationProperty)
+			if !ok {
+				continue
+			}
+
+			for _, target := range relation.Relation {
+				addDependency(RELATION_DEPENDENCY, name, target.ID.String())
+			}
+		}
+	case node.DATABASE:
+		database, err := rwClient.ReadDatabase(ctx,
+			nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return nil, err
+		}
+
+		names := []string{}
+		for name := range database.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			relation, ok := database.Properties[name].(*notionapi.
+				RelationPropertyConfig)
+			if ok {
+				addDependency(RELATION_DATABASE_DEPENDENCY, name,
+					relation.Relation.DatabaseID.String())
+			}
+		}
+	case node.BLOCK:
+		block, err := rwClient.ReadBlock(ctx, nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return nil, err
+		}
+
+		linkToPage, ok := block.(*notionapi.LinkToPageBlock)
+		if ok {
+			addDependency(LINK_TO_PAGE_DEPENDENCY, "",
+				linkToPage.LinkToPage.PageID.String())
+			addDependency(LINK_TO_PAGE_DEPENDENCY, "",
+				linkToPage.LinkToPage.DatabaseID.String())
+		}
+	}
+
+	return dependencies, nil
+}
+
+// Find the objects referenced by the subtree which are not restored with it
+func FindExternalDependencies(ctx context.Context, rwClient rw.ReaderWriter,
+	subtreeNode *node.Node) ([]ExternalDependency, error) {
+	nodes := collectNodes(subtreeNode)
+	notionIds := make(map[string]bool)
+	for _, nodeObj := range nodes {
+		notionIds[utils.NormalizeNotionId(nodeObj.GetNotionObjectId())] = true
+	}
+
+	dependencies := []ExternalDependency{}
+	for _, nodeObj := range nodes {
+		nodeDependencies, err := getNodeDependencies(ctx, rwClient, nodeObj,
+			notionIds)
+		if err != nil {
+			return nil, err
+		}
+		dependencies = append(dependencies, nodeDependencies...)
+	}
+
+	return dependencies, nil
+}
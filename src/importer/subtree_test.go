@@ -0,0 +1,227 @@
+package importer_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/importer"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func getTitledPage(id string, title string) *notionapi.Page {
+	return getPage(id, notionapi.Properties{
+		"Name": &notionapi.TitleProperty{
+			Type:  notionapi.PropertyTypeTitle,
+			Title: []notionapi.RichText{{PlainText: title}},
+		},
+	})
+}
+
+func getLinkToPageBlock(id string, pageId string) *notionapi.LinkToPageBlock {
+	return &notionapi.LinkToPageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(id),
+			Type:   notionapi.BlockTypeLinkToPage,
+		},
+		LinkToPage: notionapi.LinkToPage{
+			Type:   notionapi.BlockTypeLinkToPage,
+			PageID: notionapi.PageID(pageId),
+		},
+	}
+}
+
+func getRelation(targetIds ...string) *notionapi.RelationProperty {
+	relations := []notionapi.Relation{}
+	for _, targetId := range targetIds {
+		relations = append(relations, notionapi.Relation{
+			ID: notionapi.PageID(targetId),
+		})
+	}
+
+	return &notionapi.RelationProperty{
+		Type:     notionapi.PropertyTypeRelation,
+		Relation: relations,
+	}
+}
+
+func getRelationConfig(databaseId string,
+	syncedPropertyName string) *notionapi.RelationPropertyConfig {
+	return &notionapi.RelationPropertyConfig{
+		Type: notionapi.PropertyConfigTypeRelation,
+		Relation: notionapi.RelationConfig{
+			DatabaseID:         notionapi.DatabaseID(databaseId),
+			SyncedPropertyName: syncedPropertyName,
+		},
+	}
+}
+
+func TestSelectSubtree(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	notesId := uuid.NewString()
+
+	rootNode := node.CreateRootNode()
+	notesNode := addPageNode(t, writer, rootNode, getTitledPage(notesId,
+		"Notes"))
+	blockNode := addBlockNode(t, writer, notesNode, getParagraphBlock(
+		uuid.NewString(), nil))
+	addPageNode(t, writer, notesNode, getTitledPage(uuid.NewString(),
+		"Archive"))
+	addPageNode(t, writer, rootNode, getTitledPage(uuid.NewString(),
+		"Archive"))
+	database := getDatabase(uuid.NewString(), nil)
+	database.Title = []notionapi.RichText{{PlainText: "Tasks"}}
+	tasksNode := addDatabaseNode(t, writer, rootNode, database)
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	tests := []struct {
+		name     string
+		selector importer.SubtreeSelector
+		expected *node.Node
+	}{
+		{name: "Node uuid",
+			selector: importer.SubtreeSelector{
+				NodeUuid: notesNode.GetID().String(),
+			}, expected: notesNode},
+		{name: "Notion ID without dashes",
+			selector: importer.SubtreeSelector{
+				NotionObjectId: strings.ToUpper(strings.ReplaceAll(notesId, "-",
+					"")),
+			}, expected: notesNode},
+		{name: "Database title",
+			selector: importer.SubtreeSelector{Title: "Tasks"},
+			expected: tasksNode},
+		{name: "Ambiguous title",
+			selector: importer.SubtreeSelector{Title: "Archive"}},
+		{name: "No match",
+			selector: importer.SubtreeSelector{Title: "Journal"}},
+		{name: "Block node",
+			selector: importer.SubtreeSelector{
+				NodeUuid: blockNode.GetID().String(),
+			}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.False(t, test.selector.IsEmpty())
+			subtreeNode, err := importer.SelectSubtree(ctx, writer, treeObj,
+				&test.selector)
+			if test.expected == nil {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expected, subtreeNode)
+		})
+	}
+}
+
+func TestFindExternalDependencies(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+
+	// Relations and links within the subtree are not external dependencies
+	rootNode := node.CreateRootNode()
+	subtreeNode := addDatabaseNode(t, writer, rootNode, getDatabase("db",
+		notionapi.PropertyConfigs{
+			"Self":  getRelationConfig("db", ""),
+			"Other": getRelationConfig("other_db", ""),
+		}))
+	rowNode := addPageNode(t, writer, subtreeNode, getPage("row",
+		notionapi.Properties{
+			"Self":  getRelation("row"),
+			"Other": getRelation("other_row", "row"),
+		}))
+	linkNode := addBlockNode(t, writer, rowNode, getLinkToPageBlock("link",
+		"outside"))
+	addBlockNode(t, writer, rowNode, getLinkToPageBlock("self_link", "row"))
+	addPageNode(t, writer, rootNode, getPage("outside", nil))
+
+	dependencies, err := importer.FindExternalDependencies(ctx, writer,
+		subtreeNode)
+	assert.Nil(t, err)
+	assert.Equal(t, []importer.ExternalDependency{
+		{
+			NodeUuid:       subtreeNode.GetID().String(),
+			NotionObjectId: "db",
+			Kind:           importer.RELATION_DATABASE_DEPENDENCY,
+			Property:       "Other",
+			TargetId:       "other_db",
+		},
+		{
+			NodeUuid:       rowNode.GetID().String(),
+			NotionObjectId: "row",
+			Kind:           importer.RELATION_DEPENDENCY,
+			Property:       "Other",
+			TargetId:       "other_row",
+		},
+		{
+			NodeUuid:       linkNode.GetID().String(),
+			NotionObjectId: "link",
+			Kind:           importer.LINK_TO_PAGE_DEPENDENCY,
+			TargetId:       "outside",
+		},
+	}, dependencies)
+}
+
+func TestImportSubtree(t *testing.T) {
+	ctx := context.Background()
+	writer := getReaderWriter(t)
+	restoreToPageUUID := uuid.NewString()
+
+	rootNode := node.CreateRootNode()
+	addPageNode(t, writer, rootNode, getPage("page_1", nil))
+	subtreeNode := addPageNode(t, writer, rootNode, getPage("page_2", nil))
+	subtreePage := getPage("child", nil)
+	subtreePage.Parent = notionapi.Parent{
+		Type:   notionapi.ParentTypePageID,
+		PageID: "page_2",
+	}
+	childPageNode := addBlockNode(t, writer, subtreeNode,
+		&notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     "child",
+				Type:   notionapi.BlockTypeChildPage,
+			},
+		})
+	childNode := addPageNode(t, writer, childPageNode, subtreePage)
+	blockNode := addBlockNode(t, writer, childNode, getParagraphBlock("block",
+		nil))
+	treeObj := &tree.Tree{RootNode: rootNode}
+
+	t.Run("Page subtree", func(t *testing.T) {
+		// Only the selected page and its children are created, with the page
+		// under the page to which data is restored
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockCreatePage(mockedNotionClient, "new_page_2",
+			func(req *notionapi.PageCreateRequest) bool {
+				return req.Parent.PageID == notionapi.PageID(restoreToPageUUID)
+			})
+		mockCreatePage(mockedNotionClient, "new_child",
+			func(req *notionapi.PageCreateRequest) bool {
+				return req.Parent.PageID == "new_page_2"
+			})
+		mockAppendBlocks(mockedNotionClient, "new_child", "new_block")
+
+		importerObj := importer.GetImporter(writer, mockedNotionClient,
+			restoreToPageUUID, treeObj, importer.WithSubtree(subtreeNode))
+		err := importerObj.ImportObjects(ctx)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Block subtree", func(t *testing.T) {
+		importerObj := importer.GetImporter(writer, mocks.NewNotionClient(t),
+			restoreToPageUUID, treeObj, importer.WithSubtree(blockNode))
+		err := importerObj.ImportObjects(ctx)
+		assert.NotNil(t, err)
+	})
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
+	"github.com/shivaji17/notionbackup/src/tree/node"
 	"github.com/shivaji17/notionbackup/src/utils"
 )
 
@@ -18,6 +20,56 @@ func copyBlock(block notionapi.Block) notionapi.Block {
 	return newBlock
 }
 
+// Get the node along with all of its descendants. Tree iterator already
+// returns the node itself unless it is the root node, which is not a Notion
+// object
+func collectNodes(nodeObj *node.Node) []*node.Node {
+	nodes := []*node.Node{}
+	iter := iterator.GetTreeIterator(nodeObj)
+	for {
+		childObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+		nodes = append(nodes, childObj)
+	}
+	return nodes
+}
+
+// Get the number of Pages, Databases and Blocks of the node and its
+// descendants
+func getObjectCount(nodeObj *node.Node) int {
+	count := 0
+	iter := iterator.GetTreeIterator(nodeObj)
+	for {
+		childObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		switch childObj.GetNodeType() {
+		case node.PAGE, node.DATABASE, node.BLOCK:
+			count++
+		}
+	}
+	return count
+}
+
+// Get the number of blocks created by appending the given blocks. Rows of the
+// tables and columns of the column lists are created along with them
+func getCreatedBlockCount(blocks notionapi.Blocks) int {
+	count := len(blocks)
+	for _, block := range blocks {
+		switch typedBlock := block.(type) {
+		case *notionapi.TableBlock:
+			count += len(typedBlock.Table.Children)
+		case *notionapi.ColumnListBlock:
+			count += len(typedBlock.ColumnList.Children)
+		}
+	}
+	return count
+}
+
 type objectUuidMapping struct {
 	pageMap     map[notionapi.PageID]notionapi.PageID
 	databaseMap map[notionapi.DatabaseID]notionapi.DatabaseID
@@ -68,3 +120,20 @@ func (o *objectUuidMapping) getBlockUuid(
 
 	return newUuid, nil
 }
+
+// Get the new uuid of the restored page, database or block
+func (o *objectUuidMapping) getNewUuid(oldUuid string) (string, bool) {
+	if newUuid, found := o.pageMap[notionapi.PageID(oldUuid)]; found {
+		return newUuid.String(), true
+	}
+
+	if newUuid, found := o.databaseMap[notionapi.DatabaseID(oldUuid)]; found {
+		return newUuid.String(), true
+	}
+
+	if newUuid, found := o.blockMap[notionapi.BlockID(oldUuid)]; found {
+		return newUuid.String(), true
+	}
+
+	return "", false
+}
@@ -5,14 +5,19 @@ const (
 	PageUUID              = "Page UUID"
 	DatabaseUUID          = "Database UUID"
 	BlockUUID             = "Block UUID"
+	CommentUUID           = "Comment UUID"
 	ExportPath            = "Export Path"
 	MetaDataFilePath      = "File Path"
+	NodeID                = "Node ID"
+	JobName               = "Job"
 	PageNodeCreateErr     = "Failed to create Page node object"
 	DatabaseNodeCreateErr = "Failed to create Database node object"
 	BlockNodeCreateErr    = "Failed to create Block node object"
+	CommentNodeCreateErr  = "Failed to create Comment node object"
 	PageFetchErr          = "Failed to fetch Page(s)"
 	PageBlocksFetchErr    = "Failed to fetch Page Blocks"
 	DatabaseFetchErr      = "Failed to fetch Database(s) "
 	DatabasePagesFetchErr = "Failed to fetch Database Pages"
 	ChildBlockFetchErr    = "Failed to fetch Child Blocks"
+	CommentsFetchErr      = "Failed to fetch Comments"
 )
@@ -0,0 +1,264 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+const (
+	MARKDOWN_FILE_EXTENSION = ".md"
+	MARKDOWN_FILE_PERM      = 0644
+	NOTION_URL_FORMAT       = "https://www.notion.so/%s"
+)
+
+// MarkdownExporter renders every page and database of the tree as a markdown
+// file. Files are arranged in directories matching the page tree i.e. the
+// children of a page or database with file "Title.md" are kept in directory
+// "Title"
+type MarkdownExporter struct {
+	rwClient rw.ReaderWriter
+	treeObj  *tree.Tree
+	outDir   string
+
+	// Path of the markdown file relative to output directory for each page and
+	// database node
+	nodePathMap map[node.NodeID]string
+
+	// Path of the markdown file for each notion object ID of page and database,
+	// used to convert links and mentions to relative links
+	objectPathMap  map[string]string
+	objectTitleMap map[string]string
+	titleMap       map[node.NodeID]string
+	usedPaths      map[string]bool
+}
+
+func GetMarkdownExporter(rwClient rw.ReaderWriter, treeObj *tree.Tree,
+	outDir string) *MarkdownExporter {
+	return &MarkdownExporter{
+		rwClient:       rwClient,
+		treeObj:        treeObj,
+		outDir:         outDir,
+		nodePathMap:    make(map[node.NodeID]string),
+		objectPathMap:  make(map[string]string),
+		objectTitleMap: make(map[string]string),
+		titleMap:       make(map[node.NodeID]string),
+		usedPaths:      make(map[string]bool),
+	}
+}
+
+// Helper function to get the path not used by any other file in the given
+// directory. Paths are compared case insensitively as few filesystems are
+// case insensitive
+func (e *MarkdownExporter) getUniquePath(dir string, title string) string {
+	name := utils.SanitizeFileName(title)
+	path := filepath.Join(dir, name)
+	for i := 2; e.usedPaths[strings.ToLower(path)]; i++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s (%d)", name, i))
+	}
+
+	e.usedPaths[strings.ToLower(path)] = true
+	return path
+}
+
+// Helper function to read the title of page or database node
+func (e *MarkdownExporter) readTitle(ctx context.Context,
+	nodeObj *node.Node) (string, error) {
+	if nodeObj.GetNodeType() == node.PAGE {
+		page, err := e.rwClient.ReadPage(ctx, nodeObj.GetStorageIdentifier())
+		if err != nil {
+			return "", err
+		}
+		return utils.GetPageTitle(page), nil
+	}
+
+	database, err := e.rwClient.ReadDatabase(ctx,
+		nodeObj.GetStorageIdentifier())
+	if err != nil {
+		return "", err
+	}
+	return utils.GetDatabaseTitle(database), nil
+}
+
+// Decide the markdown file path of each page and database of the subtree of
+// given node. Paths are decided before rendering any page as links can refer to
+// pages which are rendered later
+func (e *MarkdownExporter) assignPaths(ctx context.Context,
+	nodeObj *node.Node, dir string) error {
+	childDir := dir
+	if nodeObj.GetNodeType() == node.PAGE ||
+		nodeObj.GetNodeType() == node.DATABASE {
+		title, err := e.readTitle(ctx, nodeObj)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str(logging.NodeID,
+				nodeObj.GetID().String()).Msg("Failed to read title of the node")
+			return err
+		}
+
+		childDir = e.getUniquePath(dir, title)
+		path := childDir + MARKDOWN_FILE_EXTENSION
+		e.titleMap[nodeObj.GetID()] = title
+		e.nodePathMap[nodeObj.GetID()] = path
+		e.objectPathMap[nodeObj.GetNotionObjectId()] = path
+		e.objectTitleMap[nodeObj.GetNotionObjectId()] = title
+	}
+
+	iter := iterator.GetChildIterator(nodeObj)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		err = e.assignPaths(ctx, childNode, childDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Helper function to get the link to a markdown file relative to the markdown
+// file of given path
+func getRelativeLink(fromPath string, toPath string) string {
+	relPath, err := filepath.Rel(filepath.Dir(fromPath), toPath)
+	if err != nil {
+		relPath = toPath
+	}
+
+	segments := strings.Split(filepath.ToSlash(relPath), "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
+
+// Get the link to the notion object from the markdown file of given path. If the
+// object is not exported, fallback link is returned
+func (e *MarkdownExporter) getLink(fromPath string, notionObjectId string,
+	fallback string) string {
+	if toPath, found := e.objectPathMap[notionObjectId]; found {
+		return getRelativeLink(fromPath, toPath)
+	}
+
+	return fallback
+}
+
+// Get the link to the notion object which may not be exported. Link to the
+// object on Notion is returned if object is not exported
+func (e *MarkdownExporter) getObjectLink(fromPath string,
+	notionObjectId string) string {
+	return e.getLink(fromPath, notionObjectId, fmt.Sprintf(NOTION_URL_FORMAT,
+		strings.ReplaceAll(notionObjectId, "-", "")))
+}
+
+// Helper function to write the markdown file
+func (e *MarkdownExporter) writeFile(path string, content string) error {
+	filePath := filepath.Join(e.outDir, path)
+	err := utils.CreateDirectory(filepath.Dir(filePath))
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, []byte(content), MARKDOWN_FILE_PERM)
+}
+
+// Render the page with all of its blocks
+func (e *MarkdownExporter) exportPage(ctx context.Context,
+	pageNode *node.Node) error {
+	path := e.nodePathMap[pageNode.GetID()]
+	content, err := e.renderChildren(ctx, pageNode, path)
+	if err != nil {
+		return err
+	}
+
+	title := e.titleMap[pageNode.GetID()]
+	if title == "" {
+		title = utils.UNTITLED
+	}
+
+	return e.writeFile(path, fmt.Sprintf("# %s\n\n%s", title, content))
+}
+
+// Render the database as the list of links to its pages
+func (e *MarkdownExporter) exportDatabase(ctx context.Context,
+	databaseNode *node.Node) error {
+	path := e.nodePathMap[databaseNode.GetID()]
+	title := e.titleMap[databaseNode.GetID()]
+	if title == "" {
+		title = utils.UNTITLED
+	}
+
+	var builder strings.Builder
+	builder.WriteString(fmt.Sprintf("# %s\n\n", title))
+
+	iter := iterator.GetChildIterator(databaseNode)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		childTitle := e.titleMap[childNode.GetID()]
+		if childTitle == "" {
+			childTitle = utils.UNTITLED
+		}
+
+		builder.WriteString(fmt.Sprintf("- [%s](%s)\n", childTitle,
+			getRelativeLink(path, e.nodePathMap[childNode.GetID()])))
+	}
+
+	return e.writeFile(path, builder.String())
+}
+
+// Export all pages and databases of the tree as markdown files
+func (e *MarkdownExporter) Export(ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+	err := utils.CreateDirectory(e.outDir)
+	if err != nil {
+		log.Error().Err(err).Str(logging.ExportPath, e.outDir).Msg(
+			"Failed to create output directory")
+		return err
+	}
+
+	err = e.assignPaths(ctx, e.treeObj.RootNode, "")
+	if err != nil {
+		return err
+	}
+
+	iter := iterator.GetTreeIterator(e.treeObj.RootNode)
+	for {
+		nodeObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		switch nodeObj.GetNodeType() {
+		case node.PAGE:
+			err = e.exportPage(ctx, nodeObj)
+		case node.DATABASE:
+			err = e.exportDatabase(ctx, nodeObj)
+		}
+
+		if err != nil {
+			log.Error().Err(err).Str(logging.NodeID, nodeObj.GetID().String()).
+				Msg("Failed to export node as markdown")
+			return err
+		}
+	}
+
+	return nil
+}
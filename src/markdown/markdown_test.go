@@ -0,0 +1,231 @@
+package markdown_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/markdown"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/stretchr/testify/assert"
+)
+
+func getRichText(content string,
+	annotations *notionapi.Annotations) []notionapi.RichText {
+	return []notionapi.RichText{
+		{
+			Type:        notionapi.ObjectTypeText,
+			Text:        &notionapi.Text{Content: content},
+			Annotations: annotations,
+			PlainText:   content,
+		},
+	}
+}
+
+func getBasicBlock(blockType notionapi.BlockType) notionapi.BasicBlock {
+	return notionapi.BasicBlock{
+		Object: notionapi.ObjectTypeBlock,
+		ID:     notionapi.BlockID(uuid.NewString()),
+		Type:   blockType,
+	}
+}
+
+func getPage(id string, title string) *notionapi.Page {
+	return &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     notionapi.ObjectID(id),
+		Properties: notionapi.Properties{
+			"Name": &notionapi.TitleProperty{
+				Type:  notionapi.PropertyTypeTitle,
+				Title: getRichText(title, nil),
+			},
+		},
+	}
+}
+
+// Create node of given type and mock reading its object from ReaderWriter
+func addNode(t *testing.T, mockedRW *mocks.ReaderWriter, parentNode *node.Node,
+	objectType metadata.NotionObjectType, notionObjectId string,
+	object interface{}) *node.Node {
+	identifier := uuid.NewString()
+	nodeObj, err := node.CreateNode(&metadata.NotionObject{
+		Uuid:              uuid.NewString(),
+		Type:              objectType,
+		StorageIdentifier: identifier,
+		NotionObjectId:    notionObjectId,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	switch objectType {
+	case metadata.NotionObjectType_PAGE:
+		mockedRW.On("ReadPage", context.Background(),
+			rw.DataIdentifier(identifier)).Return(object, nil)
+	case metadata.NotionObjectType_DATABASE:
+		mockedRW.On("ReadDatabase", context.Background(),
+			rw.DataIdentifier(identifier)).Return(object, nil)
+	case metadata.NotionObjectType_BLOCK:
+		mockedRW.On("ReadBlock", context.Background(),
+			rw.DataIdentifier(identifier)).Return(object, nil)
+	}
+
+	parentNode.AddChild(nodeObj)
+	return nodeObj
+}
+
+func addBlock(t *testing.T, mockedRW *mocks.ReaderWriter, parentNode *node.Node,
+	block notionapi.Block) *node.Node {
+	return addNode(t, mockedRW, parentNode, metadata.NotionObjectType_BLOCK,
+		block.GetID().String(), block)
+}
+
+func TestMarkdownExport(t *testing.T) {
+	mockedRW := mocks.NewReaderWriter(t)
+	rootNode := node.CreateRootNode()
+
+	homeId := uuid.NewString()
+	childId := uuid.NewString()
+	databaseId := uuid.NewString()
+	rowId := uuid.NewString()
+
+	homeNode := addNode(t, mockedRW, rootNode, metadata.NotionObjectType_PAGE,
+		homeId, getPage(homeId, "Home"))
+
+	addBlock(t, mockedRW, homeNode, &notionapi.Heading1Block{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeHeading1),
+		Heading1:   notionapi.Heading{RichText: getRichText("Heading", nil)},
+	})
+	addBlock(t, mockedRW, homeNode, &notionapi.ParagraphBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeParagraph),
+		Paragraph: notionapi.Paragraph{
+			RichText: append(getRichText("Some ", nil),
+				getRichText("bold ", &notionapi.Annotations{Bold: true})...),
+		},
+	})
+	itemNode := addBlock(t, mockedRW, homeNode,
+		&notionapi.BulletedListItemBlock{
+			BasicBlock:       getBasicBlock(notionapi.BlockTypeBulletedListItem),
+			BulletedListItem: notionapi.ListItem{RichText: getRichText("One", nil)},
+		})
+	addBlock(t, mockedRW, itemNode, &notionapi.BulletedListItemBlock{
+		BasicBlock:       getBasicBlock(notionapi.BlockTypeBulletedListItem),
+		BulletedListItem: notionapi.ListItem{RichText: getRichText("Nested", nil)},
+	})
+	addBlock(t, mockedRW, homeNode, &notionapi.BulletedListItemBlock{
+		BasicBlock:       getBasicBlock(notionapi.BlockTypeBulletedListItem),
+		BulletedListItem: notionapi.ListItem{RichText: getRichText("Two", nil)},
+	})
+	for _, text := range []string{"First", "Second"} {
+		addBlock(t, mockedRW, homeNode, &notionapi.NumberedListItemBlock{
+			BasicBlock:       getBasicBlock(notionapi.BlockTypeNumberedListItem),
+			NumberedListItem: notionapi.ListItem{RichText: getRichText(text, nil)},
+		})
+	}
+	addBlock(t, mockedRW, homeNode, &notionapi.ToDoBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeToDo),
+		ToDo: notionapi.ToDo{
+			RichText: getRichText("Done", nil),
+			Checked:  true,
+		},
+	})
+	addBlock(t, mockedRW, homeNode, &notionapi.CodeBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeCode),
+		Code: notionapi.Code{
+			RichText: getRichText("fmt.Println()", nil),
+			Language: "go",
+		},
+	})
+	addBlock(t, mockedRW, homeNode, &notionapi.QuoteBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockQuote),
+		Quote:      notionapi.Quote{RichText: getRichText("Quote", nil)},
+	})
+	tableNode := addBlock(t, mockedRW, homeNode, &notionapi.TableBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeTableBlock),
+		Table: notionapi.Table{
+			TableWidth:      2,
+			HasColumnHeader: true,
+		},
+	})
+	for _, row := range [][]string{{"A", "B"}, {"1", "2|3"}} {
+		addBlock(t, mockedRW, tableNode, &notionapi.TableRowBlock{
+			BasicBlock: getBasicBlock(notionapi.BlockTypeTableRowBlock),
+			TableRow: notionapi.TableRow{
+				Cells: [][]notionapi.RichText{
+					getRichText(row[0], nil), getRichText(row[1], nil),
+				},
+			},
+		})
+	}
+	addBlock(t, mockedRW, homeNode, &notionapi.EquationBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeEquation),
+		Equation:   notionapi.Equation{Expression: "e=mc^2"},
+	})
+
+	childPageBlock := &notionapi.ChildPageBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeChildPage),
+	}
+	childPageBlock.ID = notionapi.BlockID(childId)
+	childPageBlock.ChildPage.Title = "Child Page"
+	childPageBlockNode := addBlock(t, mockedRW, homeNode, childPageBlock)
+	childNode := addNode(t, mockedRW, childPageBlockNode,
+		metadata.NotionObjectType_PAGE, childId, getPage(childId, "Child Page"))
+	addBlock(t, mockedRW, childNode, &notionapi.LinkToPageBlock{
+		BasicBlock: getBasicBlock(notionapi.BlockTypeLinkToPage),
+		LinkToPage: notionapi.LinkToPage{
+			Type:   notionapi.BlockType(notionapi.ParentTypePageID),
+			PageID: notionapi.PageID(homeId),
+		},
+	})
+
+	databaseNode := addNode(t, mockedRW, rootNode,
+		metadata.NotionObjectType_DATABASE, databaseId, &notionapi.Database{
+			ID:    notionapi.ObjectID(databaseId),
+			Title: getRichText("Tasks", nil),
+		})
+	addNode(t, mockedRW, databaseNode, metadata.NotionObjectType_PAGE, rowId,
+		getPage(rowId, "Task/1"))
+
+	outDir := t.TempDir()
+	exporter := markdown.GetMarkdownExporter(mockedRW,
+		&tree.Tree{RootNode: rootNode}, outDir)
+	err := exporter.Export(context.Background())
+	assert.Nil(t, err)
+
+	expectedFiles := map[string]string{
+		"Home.md": "# Home\n\n" +
+			"# Heading\n\n" +
+			"Some **bold** \n\n" +
+			"- One\n" +
+			"  - Nested\n" +
+			"- Two\n\n" +
+			"1. First\n" +
+			"2. Second\n\n" +
+			"- [x] Done\n\n" +
+			"```go\nfmt.Println()\n```\n\n" +
+			"> Quote\n\n" +
+			"| A | B |\n" +
+			"| --- | --- |\n" +
+			"| 1 | 2\\|3 |\n\n" +
+			"$$\ne=mc^2\n$$\n\n" +
+			"[Child Page](Home/Child%20Page.md)\n",
+		"Home/Child Page.md": "# Child Page\n\n" +
+			"[Home](../Home.md)\n",
+		"Tasks.md": "# Tasks\n\n" +
+			"- [Task/1](Tasks/Task_1.md)\n",
+		"Tasks/Task_1.md": "# Task/1\n\n",
+	}
+
+	for path, expectedContent := range expectedFiles {
+		content, err := os.ReadFile(filepath.Join(outDir, path))
+		assert.Nil(t, err)
+		assert.Equal(t, expectedContent, string(content))
+	}
+}
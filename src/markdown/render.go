@@ -0,0 +1,348 @@
+package markdown
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+const (
+	BULLETED_LIST_INDENT = "  "
+	NUMBERED_LIST_INDENT = "   "
+	QUOTE_PREFIX         = "> "
+)
+
+// Helper function to add prefix to every line of the text. Trailing spaces of
+// the prefix are not added to empty lines
+func prefixLines(text string, prefix string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + line
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// Helper function to get URL of Notion hosted or external file
+func getFileURL(file *notionapi.FileObject,
+	external *notionapi.FileObject) string {
+	if file != nil {
+		return file.URL
+	}
+
+	if external != nil {
+		return external.URL
+	}
+
+	return ""
+}
+
+// Helper function to render the link to a file with caption as link text
+func renderFileLink(caption []notionapi.RichText, defaultText string,
+	url string) string {
+	text := utils.GetPlainText(caption)
+	if text == "" {
+		text = defaultText
+	}
+
+	return fmt.Sprintf("[%s](%s)", text, url)
+}
+
+// Helper function to check if both blocks are items of same list and thus must
+// not be separated by an empty line
+func isSameList(previous notionapi.BlockType, current notionapi.BlockType) bool {
+	if previous != current {
+		return false
+	}
+
+	return current == notionapi.BlockTypeBulletedListItem ||
+		current == notionapi.BlockTypeNumberedListItem ||
+		current == notionapi.BlockTypeToDo
+}
+
+// Render all the child blocks of given node which are part of the page of
+// given path
+func (e *MarkdownExporter) renderChildren(ctx context.Context,
+	parentNode *node.Node, pagePath string) (string, error) {
+	var builder strings.Builder
+	var previousType notionapi.BlockType
+	listNumber := 0
+
+	iter := iterator.GetChildIterator(parentNode)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		// Child pages and databases are rendered as separate files
+		if childNode.GetNodeType() != node.BLOCK {
+			continue
+		}
+
+		block, err := e.rwClient.ReadBlock(ctx, childNode.GetStorageIdentifier())
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str(logging.BlockUUID,
+				childNode.GetNotionObjectId()).Msg("Failed to read block")
+			return "", err
+		}
+
+		if block.GetType() == notionapi.BlockTypeNumberedListItem {
+			listNumber++
+		} else {
+			listNumber = 0
+		}
+
+		content, err := e.renderBlock(ctx, childNode, block, pagePath,
+			listNumber)
+		if err != nil {
+			return "", err
+		}
+
+		if content == "" {
+			continue
+		}
+
+		if builder.Len() != 0 {
+			if isSameList(previousType, block.GetType()) {
+				builder.WriteString("\n")
+			} else {
+				builder.WriteString("\n\n")
+			}
+		}
+
+		builder.WriteString(content)
+		previousType = block.GetType()
+	}
+
+	if builder.Len() != 0 {
+		builder.WriteString("\n")
+	}
+
+	return builder.String(), nil
+}
+
+// Helper function to render the text of a block followed by its children.
+// Children are prefixed with given indent
+func (e *MarkdownExporter) renderWithChildren(ctx context.Context,
+	nodeObj *node.Node, pagePath string, text string, indent string) (string,
+	error) {
+	children, err := e.renderChildren(ctx, nodeObj, pagePath)
+	if err != nil {
+		return "", err
+	}
+
+	children = strings.TrimRight(children, "\n")
+	if children == "" {
+		return text, nil
+	}
+
+	if indent == "" {
+		return text + "\n\n" + children, nil
+	}
+
+	return text + "\n" + prefixLines(children, indent), nil
+}
+
+// Render the table block with its rows as markdown table. Markdown tables must
+// have a header, so an empty header is added if table has no column header
+func (e *MarkdownExporter) renderTable(ctx context.Context,
+	nodeObj *node.Node, table *notionapi.TableBlock, pagePath string) (string,
+	error) {
+	rows := make([]string, 0)
+	iter := iterator.GetChildIterator(nodeObj)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if childNode.GetNodeType() != node.BLOCK {
+			continue
+		}
+
+		block, err := e.rwClient.ReadBlock(ctx, childNode.GetStorageIdentifier())
+		if err != nil {
+			return "", err
+		}
+
+		row, ok := block.(*notionapi.TableRowBlock)
+		if !ok {
+			continue
+		}
+
+		cells := make([]string, table.Table.TableWidth)
+		for i := range cells {
+			if i < len(row.TableRow.Cells) {
+				cell := e.renderRichText(pagePath, row.TableRow.Cells[i])
+				cell = strings.ReplaceAll(cell, "|", "\\|")
+				cells[i] = strings.ReplaceAll(cell, "\n", "<br>")
+			}
+		}
+
+		rows = append(rows, "| "+strings.Join(cells, " | ")+" |")
+	}
+
+	separator := "|" + strings.Repeat(" --- |", table.Table.TableWidth)
+	if table.Table.HasColumnHeader && len(rows) != 0 {
+		rows = append([]string{rows[0], separator}, rows[1:]...)
+	} else {
+		header := "|" + strings.Repeat("  |", table.Table.TableWidth)
+		rows = append([]string{header, separator}, rows...)
+	}
+
+	return strings.Join(rows, "\n"), nil
+}
+
+// Render the link to child page, child database or linked page
+func (e *MarkdownExporter) renderObjectLink(pagePath string,
+	notionObjectId string, title string) string {
+	if title == "" {
+		title = e.objectTitle(notionObjectId)
+	}
+
+	return fmt.Sprintf("[%s](%s)", title, e.getObjectLink(pagePath,
+		notionObjectId))
+}
+
+// Get the title of exported page or database with given notion object ID
+func (e *MarkdownExporter) objectTitle(notionObjectId string) string {
+	if title, found := e.objectTitleMap[notionObjectId]; found && title != "" {
+		return title
+	}
+
+	return utils.UNTITLED
+}
+
+// Render the block and its children as markdown. listNumber is the position of
+// the block in numbered list
+func (e *MarkdownExporter) renderBlock(ctx context.Context, nodeObj *node.Node,
+	block notionapi.Block, pagePath string, listNumber int) (string, error) {
+	switch b := block.(type) {
+	case *notionapi.ParagraphBlock:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			e.renderRichText(pagePath, b.Paragraph.RichText), "")
+	case *notionapi.Heading1Block:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			"# "+e.renderRichText(pagePath, b.Heading1.RichText), "")
+	case *notionapi.Heading2Block:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			"## "+e.renderRichText(pagePath, b.Heading2.RichText), "")
+	case *notionapi.Heading3Block:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			"### "+e.renderRichText(pagePath, b.Heading3.RichText), "")
+	case *notionapi.BulletedListItemBlock:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			"- "+e.renderRichText(pagePath, b.BulletedListItem.RichText),
+			BULLETED_LIST_INDENT)
+	case *notionapi.NumberedListItemBlock:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			fmt.Sprintf("%d. %s", listNumber, e.renderRichText(pagePath,
+				b.NumberedListItem.RichText)), NUMBERED_LIST_INDENT)
+	case *notionapi.ToDoBlock:
+		checkbox := "- [ ] "
+		if b.ToDo.Checked {
+			checkbox = "- [x] "
+		}
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			checkbox+e.renderRichText(pagePath, b.ToDo.RichText),
+			BULLETED_LIST_INDENT)
+	case *notionapi.ToggleBlock:
+		content, err := e.renderChildren(ctx, nodeObj, pagePath)
+		if err != nil {
+			return "", err
+		}
+		summary := fmt.Sprintf("<details>\n<summary>%s</summary>\n",
+			e.renderRichText(pagePath, b.Toggle.RichText))
+		if content != "" {
+			summary += "\n" + content
+		}
+		return summary + "</details>", nil
+	case *notionapi.QuoteBlock:
+		content, err := e.renderWithChildren(ctx, nodeObj, pagePath,
+			e.renderRichText(pagePath, b.Quote.RichText), "")
+		if err != nil {
+			return "", err
+		}
+		return prefixLines(content, QUOTE_PREFIX), nil
+	case *notionapi.CalloutBlock:
+		text := e.renderRichText(pagePath, b.Callout.RichText)
+		if b.Callout.Icon != nil && b.Callout.Icon.Emoji != nil {
+			text = string(*b.Callout.Icon.Emoji) + " " + text
+		}
+		content, err := e.renderWithChildren(ctx, nodeObj, pagePath, text, "")
+		if err != nil {
+			return "", err
+		}
+		return prefixLines(content, QUOTE_PREFIX), nil
+	case *notionapi.CodeBlock:
+		content := fmt.Sprintf("```%s\n%s\n```", b.Code.Language,
+			utils.GetPlainText(b.Code.RichText))
+		if len(b.Code.Caption) != 0 {
+			content += "\n\n" + e.renderRichText(pagePath, b.Code.Caption)
+		}
+		return content, nil
+	case *notionapi.EquationBlock:
+		return fmt.Sprintf("$$\n%s\n$$", b.Equation.Expression), nil
+	case *notionapi.DividerBlock:
+		return "---", nil
+	case *notionapi.TableBlock:
+		return e.renderTable(ctx, nodeObj, b, pagePath)
+	case *notionapi.ImageBlock:
+		return fmt.Sprintf("![%s](%s)", utils.GetPlainText(b.Image.Caption),
+			b.Image.GetURL()), nil
+	case *notionapi.VideoBlock:
+		return renderFileLink(b.Video.Caption, "Video",
+			getFileURL(b.Video.File, b.Video.External)), nil
+	case *notionapi.AudioBlock:
+		return renderFileLink(b.Audio.Caption, "Audio",
+			b.Audio.GetURL()), nil
+	case *notionapi.FileBlock:
+		return renderFileLink(b.File.Caption, "File",
+			getFileURL(b.File.File, b.File.External)), nil
+	case *notionapi.PdfBlock:
+		return renderFileLink(b.Pdf.Caption, "PDF",
+			getFileURL(b.Pdf.File, b.Pdf.External)), nil
+	case *notionapi.BookmarkBlock:
+		return renderFileLink(b.Bookmark.Caption, b.Bookmark.URL,
+			b.Bookmark.URL), nil
+	case *notionapi.EmbedBlock:
+		return renderFileLink(b.Embed.Caption, b.Embed.URL,
+			b.Embed.URL), nil
+	case *notionapi.LinkPreviewBlock:
+		return fmt.Sprintf("[%s](%s)", b.LinkPreview.URL,
+			b.LinkPreview.URL), nil
+	case *notionapi.ChildPageBlock:
+		return e.renderObjectLink(pagePath, b.ID.String(), b.ChildPage.Title), nil
+	case *notionapi.ChildDatabaseBlock:
+		return e.renderObjectLink(pagePath, b.ID.String(),
+			b.ChildDatabase.Title), nil
+	case *notionapi.LinkToPageBlock:
+		if b.LinkToPage.DatabaseID != "" {
+			return e.renderObjectLink(pagePath,
+				b.LinkToPage.DatabaseID.String(), ""), nil
+		}
+		return e.renderObjectLink(pagePath, b.LinkToPage.PageID.String(), ""), nil
+	case *notionapi.TemplateBlock:
+		return e.renderWithChildren(ctx, nodeObj, pagePath,
+			e.renderRichText(pagePath, b.Template.RichText), "")
+	case *notionapi.ColumnListBlock, *notionapi.ColumnBlock,
+		*notionapi.SyncedBlock:
+		content, err := e.renderChildren(ctx, nodeObj, pagePath)
+		return strings.TrimRight(content, "\n"), err
+	}
+
+	// Blocks like table of contents and breadcrumb have no content
+	return "", nil
+}
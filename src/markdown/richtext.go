@@ -0,0 +1,108 @@
+package markdown
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/jomei/notionapi"
+)
+
+const (
+	RICH_TEXT_TYPE_MENTION  notionapi.ObjectType = "mention"
+	RICH_TEXT_TYPE_EQUATION notionapi.ObjectType = "equation"
+)
+
+// Helper function to wrap the text with given markers. Markdown markers are
+// not honored when they are adjacent to whitespace, so leading and trailing
+// whitespaces of the text are kept outside the markers
+func wrapText(text string, startMarker string, endMarker string) string {
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return text
+	}
+
+	start := strings.Index(text, trimmed)
+	return text[:start] + startMarker + trimmed + endMarker +
+		text[start+len(trimmed):]
+}
+
+// Helper function to apply the annotations of rich text on given text
+func applyAnnotations(text string, annotations *notionapi.Annotations) string {
+	if annotations == nil {
+		return text
+	}
+
+	if annotations.Code {
+		text = wrapText(text, "`", "`")
+	}
+
+	if annotations.Bold {
+		text = wrapText(text, "**", "**")
+	}
+
+	if annotations.Italic {
+		text = wrapText(text, "_", "_")
+	}
+
+	if annotations.Strikethrough {
+		text = wrapText(text, "~~", "~~")
+	}
+
+	if annotations.Underline {
+		text = wrapText(text, "<u>", "</u>")
+	}
+
+	return text
+}
+
+// Render the rich text of a page of given path as markdown. Mentions of pages
+// and databases which are part of the export become relative links
+func (e *MarkdownExporter) renderRichText(pagePath string,
+	richTexts []notionapi.RichText) string {
+	var builder strings.Builder
+	for _, richText := range richTexts {
+		var text string
+		link := richText.Href
+
+		switch richText.Type {
+		case notionapi.ObjectTypeText:
+			if richText.Text != nil {
+				text = richText.Text.Content
+				if richText.Text.Link != nil {
+					link = richText.Text.Link.Url
+				}
+			}
+		case RICH_TEXT_TYPE_EQUATION:
+			if richText.Equation != nil {
+				text = fmt.Sprintf("$%s$", richText.Equation.Expression)
+				link = ""
+			}
+		case RICH_TEXT_TYPE_MENTION:
+			text = richText.PlainText
+			if richText.Mention != nil {
+				if richText.Mention.Page != nil {
+					link = e.getLink(pagePath, richText.Mention.Page.ID.String(),
+						link)
+				} else if richText.Mention.Database != nil {
+					link = e.getLink(pagePath,
+						richText.Mention.Database.ID.String(), link)
+				}
+			}
+		default:
+			text = richText.PlainText
+		}
+
+		if text == "" {
+			text = richText.PlainText
+		}
+
+		text = applyAnnotations(text, richText.Annotations)
+		if link != "" && text != "" {
+			text = fmt.Sprintf("[%s](%s)", text, link)
+		}
+
+		builder.WriteString(text)
+	}
+
+	return builder.String()
+}
@@ -9,6 +9,7 @@ package metadata
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 )
@@ -28,6 +29,12 @@ const (
 	NotionObjectType_PAGE     NotionObjectType = 2
 	NotionObjectType_DATABASE NotionObjectType = 3
 	NotionObjectType_BLOCK    NotionObjectType = 4
+	// File hosted by Notion. Assets are stored along with the objects but are
+	// never a part of the tree
+	NotionObjectType_ASSET NotionObjectType = 5
+	// Comment of a page or block. Comment nodes are the children of the node of
+	// the commented page or block
+	NotionObjectType_COMMENT NotionObjectType = 6
 )
 
 // Enum value maps for NotionObjectType.
@@ -38,6 +45,8 @@ var (
 		2: "PAGE",
 		3: "DATABASE",
 		4: "BLOCK",
+		5: "ASSET",
+		6: "COMMENT",
 	}
 	NotionObjectType_value = map[string]int32{
 		"UNKNOWN":  0,
@@ -45,6 +54,8 @@ var (
 		"PAGE":     2,
 		"DATABASE": 3,
 		"BLOCK":    4,
+		"ASSET":    5,
+		"COMMENT":  6,
 	}
 )
 
@@ -89,6 +100,12 @@ type NotionObject struct {
 	Type NotionObjectType `protobuf:"varint,3,opt,name=type,proto3,enum=NotionObjectType" json:"type,omitempty"`
 	// ID of the notion object. This ID belongs to ID created by Notion App
 	NotionObjectId string `protobuf:"bytes,4,opt,name=notion_object_id,json=notionObjectId,proto3" json:"notion_object_id,omitempty"`
+	// Time at which the notion object was last edited in Notion App. It is used
+	// to find the unchanged objects while taking incremental backup
+	LastEditedTime *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=last_edited_time,json=lastEditedTime,proto3" json:"last_edited_time,omitempty"`
+	// Hex encoded SHA-256 checksum of the serialized object. Empty for ROOT
+	// object and for objects of backups taken by older versions
+	Checksum string `protobuf:"bytes,6,opt,name=checksum,proto3" json:"checksum,omitempty"`
 }
 
 func (x *NotionObject) Reset() {
@@ -151,6 +168,20 @@ func (x *NotionObject) GetNotionObjectId() string {
 	return ""
 }
 
+func (x *NotionObject) GetLastEditedTime() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastEditedTime
+	}
+	return nil
+}
+
+func (x *NotionObject) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
 // List of UUIDs of different NotionObject
 type ChildrenNotionObjectUuids struct {
 	state         protoimpl.MessageState
@@ -199,6 +230,83 @@ func (x *ChildrenNotionObjectUuids) GetChildrenUuidList() []string {
 	return nil
 }
 
+// File hosted by Notion which was downloaded while taking the backup. URLs of
+// such files expire an hour after they are fetched from Notion
+type Asset struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Storage identifier of the file in the assets area of the storage
+	StorageIdentifier string `protobuf:"bytes,1,opt,name=storage_identifier,json=storageIdentifier,proto3" json:"storage_identifier,omitempty"`
+	// Name of the file taken from its URL
+	FileName string `protobuf:"bytes,2,opt,name=file_name,json=fileName,proto3" json:"file_name,omitempty"`
+	// Content type with which Notion served the file
+	ContentType string `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	// Hex encoded SHA-256 checksum of the file
+	Checksum string `protobuf:"bytes,4,opt,name=checksum,proto3" json:"checksum,omitempty"`
+}
+
+func (x *Asset) Reset() {
+	*x = Asset{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Asset) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Asset) ProtoMessage() {}
+
+func (x *Asset) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Asset.ProtoReflect.Descriptor instead.
+func (*Asset) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Asset) GetStorageIdentifier() string {
+	if x != nil {
+		return x.StorageIdentifier
+	}
+	return ""
+}
+
+func (x *Asset) GetFileName() string {
+	if x != nil {
+		return x.FileName
+	}
+	return ""
+}
+
+func (x *Asset) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Asset) GetChecksum() string {
+	if x != nil {
+		return x.Checksum
+	}
+	return ""
+}
+
 // Structure to store storage configuration for any one type of configuration
 type StorageConfig struct {
 	state         protoimpl.MessageState
@@ -207,13 +315,16 @@ type StorageConfig struct {
 
 	// Types that are assignable to Config:
 	//	*StorageConfig_Local_
+	//	*StorageConfig_Archive_
+	//	*StorageConfig_S3_
+	//	*StorageConfig_ContentAddressed_
 	Config isStorageConfig_Config `protobuf_oneof:"config"`
 }
 
 func (x *StorageConfig) Reset() {
 	*x = StorageConfig{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_notion_backup_proto_msgTypes[2]
+		mi := &file_notion_backup_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -226,7 +337,7 @@ func (x *StorageConfig) String() string {
 func (*StorageConfig) ProtoMessage() {}
 
 func (x *StorageConfig) ProtoReflect() protoreflect.Message {
-	mi := &file_notion_backup_proto_msgTypes[2]
+	mi := &file_notion_backup_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -239,7 +350,7 @@ func (x *StorageConfig) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StorageConfig.ProtoReflect.Descriptor instead.
 func (*StorageConfig) Descriptor() ([]byte, []int) {
-	return file_notion_backup_proto_rawDescGZIP(), []int{2}
+	return file_notion_backup_proto_rawDescGZIP(), []int{3}
 }
 
 func (m *StorageConfig) GetConfig() isStorageConfig_Config {
@@ -256,6 +367,27 @@ func (x *StorageConfig) GetLocal() *StorageConfig_Local {
 	return nil
 }
 
+func (x *StorageConfig) GetArchive() *StorageConfig_Archive {
+	if x, ok := x.GetConfig().(*StorageConfig_Archive_); ok {
+		return x.Archive
+	}
+	return nil
+}
+
+func (x *StorageConfig) GetS3() *StorageConfig_S3 {
+	if x, ok := x.GetConfig().(*StorageConfig_S3_); ok {
+		return x.S3
+	}
+	return nil
+}
+
+func (x *StorageConfig) GetContentAddressed() *StorageConfig_ContentAddressed {
+	if x, ok := x.GetConfig().(*StorageConfig_ContentAddressed_); ok {
+		return x.ContentAddressed
+	}
+	return nil
+}
+
 type isStorageConfig_Config interface {
 	isStorageConfig_Config()
 }
@@ -264,8 +396,218 @@ type StorageConfig_Local_ struct {
 	Local *StorageConfig_Local `protobuf:"bytes,1,opt,name=local,proto3,oneof"`
 }
 
+type StorageConfig_Archive_ struct {
+	Archive *StorageConfig_Archive `protobuf:"bytes,2,opt,name=archive,proto3,oneof"`
+}
+
+type StorageConfig_S3_ struct {
+	S3 *StorageConfig_S3 `protobuf:"bytes,3,opt,name=s3,proto3,oneof"`
+}
+
+type StorageConfig_ContentAddressed_ struct {
+	ContentAddressed *StorageConfig_ContentAddressed `protobuf:"bytes,4,opt,name=content_addressed,json=contentAddressed,proto3,oneof"`
+}
+
 func (*StorageConfig_Local_) isStorageConfig_Config() {}
 
+func (*StorageConfig_Archive_) isStorageConfig_Config() {}
+
+func (*StorageConfig_S3_) isStorageConfig_Config() {}
+
+func (*StorageConfig_ContentAddressed_) isStorageConfig_Config() {}
+
+// Parameters with which objects and metadata of the backup are encrypted. Key
+// itself is never stored
+type EncryptionConfig struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Encryption algorithm. (Algorithms: AES-256-GCM)
+	Algorithm string `protobuf:"bytes,1,opt,name=algorithm,proto3" json:"algorithm,omitempty"`
+	// Function with which the key is derived from the passphrase. (Functions:
+	// argon2id, none). Key is read from the key file as is for none
+	Kdf string `protobuf:"bytes,2,opt,name=kdf,proto3" json:"kdf,omitempty"`
+	// Random salt of the key derivation function
+	Salt []byte `protobuf:"bytes,3,opt,name=salt,proto3" json:"salt,omitempty"`
+	// Number of passes over the memory by argon2id
+	Argon2Time uint32 `protobuf:"varint,4,opt,name=argon2_time,json=argon2Time,proto3" json:"argon2_time,omitempty"`
+	// Memory in KiB used by argon2id
+	Argon2Memory uint32 `protobuf:"varint,5,opt,name=argon2_memory,json=argon2Memory,proto3" json:"argon2_memory,omitempty"`
+	// Number of threads used by argon2id
+	Argon2Threads uint32 `protobuf:"varint,6,opt,name=argon2_threads,json=argon2Threads,proto3" json:"argon2_threads,omitempty"`
+	// Hex encoded fingerprint of the key with which a wrong passphrase or key
+	// file is detected before decrypting any object
+	KeyFingerprint string `protobuf:"bytes,7,opt,name=key_fingerprint,json=keyFingerprint,proto3" json:"key_fingerprint,omitempty"`
+}
+
+func (x *EncryptionConfig) Reset() {
+	*x = EncryptionConfig{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *EncryptionConfig) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EncryptionConfig) ProtoMessage() {}
+
+func (x *EncryptionConfig) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EncryptionConfig.ProtoReflect.Descriptor instead.
+func (*EncryptionConfig) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *EncryptionConfig) GetAlgorithm() string {
+	if x != nil {
+		return x.Algorithm
+	}
+	return ""
+}
+
+func (x *EncryptionConfig) GetKdf() string {
+	if x != nil {
+		return x.Kdf
+	}
+	return ""
+}
+
+func (x *EncryptionConfig) GetSalt() []byte {
+	if x != nil {
+		return x.Salt
+	}
+	return nil
+}
+
+func (x *EncryptionConfig) GetArgon2Time() uint32 {
+	if x != nil {
+		return x.Argon2Time
+	}
+	return 0
+}
+
+func (x *EncryptionConfig) GetArgon2Memory() uint32 {
+	if x != nil {
+		return x.Argon2Memory
+	}
+	return 0
+}
+
+func (x *EncryptionConfig) GetArgon2Threads() uint32 {
+	if x != nil {
+		return x.Argon2Threads
+	}
+	return 0
+}
+
+func (x *EncryptionConfig) GetKeyFingerprint() string {
+	if x != nil {
+		return x.KeyFingerprint
+	}
+	return ""
+}
+
+// Filters with which the objects were excluded from the backup
+type BackupFilter struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Notion IDs of the pages and databases excluded along with their children
+	ExcludePageIds     []string `protobuf:"bytes,1,rep,name=exclude_page_ids,json=excludePageIds,proto3" json:"exclude_page_ids,omitempty"`
+	ExcludeDatabaseIds []string `protobuf:"bytes,2,rep,name=exclude_database_ids,json=excludeDatabaseIds,proto3" json:"exclude_database_ids,omitempty"`
+	// Regular expression matching the titles of the pages and databases
+	// excluded along with their children
+	ExcludeTitleRegex string `protobuf:"bytes,3,opt,name=exclude_title_regex,json=excludeTitleRegex,proto3" json:"exclude_title_regex,omitempty"`
+	// Maximum depth of nested pages and databases, the objects backed up
+	// directly being at depth 1. Zero for no limit
+	MaxDepth int32 `protobuf:"varint,4,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// Types of the excluded blocks, e.g. embed and link_preview
+	ExcludeBlockTypes []string `protobuf:"bytes,5,rep,name=exclude_block_types,json=excludeBlockTypes,proto3" json:"exclude_block_types,omitempty"`
+}
+
+func (x *BackupFilter) Reset() {
+	*x = BackupFilter{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[5]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *BackupFilter) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupFilter) ProtoMessage() {}
+
+func (x *BackupFilter) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[5]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupFilter.ProtoReflect.Descriptor instead.
+func (*BackupFilter) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BackupFilter) GetExcludePageIds() []string {
+	if x != nil {
+		return x.ExcludePageIds
+	}
+	return nil
+}
+
+func (x *BackupFilter) GetExcludeDatabaseIds() []string {
+	if x != nil {
+		return x.ExcludeDatabaseIds
+	}
+	return nil
+}
+
+func (x *BackupFilter) GetExcludeTitleRegex() string {
+	if x != nil {
+		return x.ExcludeTitleRegex
+	}
+	return ""
+}
+
+func (x *BackupFilter) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *BackupFilter) GetExcludeBlockTypes() []string {
+	if x != nil {
+		return x.ExcludeBlockTypes
+	}
+	return nil
+}
+
 type MetaData struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -277,12 +619,38 @@ type MetaData struct {
 	ParentUuid_2ChildrenUuidMap map[string]*ChildrenNotionObjectUuids `protobuf:"bytes,2,rep,name=parent_uuid_2_children_uuid_map,json=parentUuid2ChildrenUuidMap,proto3" json:"parent_uuid_2_children_uuid_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
 	// Storage configuration in which notion data is stored
 	StorageConfig *StorageConfig `protobuf:"bytes,3,opt,name=storage_config,json=storageConfig,proto3" json:"storage_config,omitempty"`
+	// Path of the metadata file of the snapshot from which this snapshot was
+	// taken incrementally, relative to the directory of the metadata file of
+	// this snapshot. Absolute for snapshots taken by older versions. Empty for
+	// full backups
+	ParentSnapshotPath string `protobuf:"bytes,4,opt,name=parent_snapshot_path,json=parentSnapshotPath,proto3" json:"parent_snapshot_path,omitempty"`
+	// Encryption parameters of the encrypted backup. Metadata file of encrypted
+	// backup only contains storage_config, encryption_config,
+	// encrypted_metadata and the identity of the snapshot i.e. snapshot_id,
+	// created_at and parent_snapshot_id
+	EncryptionConfig *EncryptionConfig `protobuf:"bytes,5,opt,name=encryption_config,json=encryptionConfig,proto3" json:"encryption_config,omitempty"`
+	// MetaData of the encrypted backup encrypted with the backup key
+	EncryptedMetadata []byte `protobuf:"bytes,6,opt,name=encrypted_metadata,json=encryptedMetadata,proto3" json:"encrypted_metadata,omitempty"`
+	// Map for storing the downloaded assets with the URL of the file without
+	// query parameters as a key. Query parameters of the URLs of Notion hosted
+	// files change every time the object is fetched
+	AssetMap map[string]*Asset `protobuf:"bytes,7,rep,name=asset_map,json=assetMap,proto3" json:"asset_map,omitempty" protobuf_key:"bytes,1,opt,name=key,proto3" protobuf_val:"bytes,2,opt,name=value,proto3"`
+	// Random ID of the snapshot. Empty for backups taken by older versions
+	SnapshotId string `protobuf:"bytes,8,opt,name=snapshot_id,json=snapshotId,proto3" json:"snapshot_id,omitempty"`
+	// Time at which the snapshot was created. Unset for backups taken by older
+	// versions
+	CreatedAt *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	// ID of the snapshot from which this snapshot was taken incrementally. Empty
+	// for full backups and for backups taken by older versions
+	ParentSnapshotId string `protobuf:"bytes,10,opt,name=parent_snapshot_id,json=parentSnapshotId,proto3" json:"parent_snapshot_id,omitempty"`
+	// Filters applied while taking the backup. Unset if no object was excluded
+	Filter *BackupFilter `protobuf:"bytes,11,opt,name=filter,proto3" json:"filter,omitempty"`
 }
 
 func (x *MetaData) Reset() {
 	*x = MetaData{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_notion_backup_proto_msgTypes[3]
+		mi := &file_notion_backup_proto_msgTypes[6]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -295,7 +663,7 @@ func (x *MetaData) String() string {
 func (*MetaData) ProtoMessage() {}
 
 func (x *MetaData) ProtoReflect() protoreflect.Message {
-	mi := &file_notion_backup_proto_msgTypes[3]
+	mi := &file_notion_backup_proto_msgTypes[6]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -308,7 +676,7 @@ func (x *MetaData) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use MetaData.ProtoReflect.Descriptor instead.
 func (*MetaData) Descriptor() ([]byte, []int) {
-	return file_notion_backup_proto_rawDescGZIP(), []int{3}
+	return file_notion_backup_proto_rawDescGZIP(), []int{6}
 }
 
 func (x *MetaData) GetNotionObjectMap() map[string]*NotionObject {
@@ -332,6 +700,62 @@ func (x *MetaData) GetStorageConfig() *StorageConfig {
 	return nil
 }
 
+func (x *MetaData) GetParentSnapshotPath() string {
+	if x != nil {
+		return x.ParentSnapshotPath
+	}
+	return ""
+}
+
+func (x *MetaData) GetEncryptionConfig() *EncryptionConfig {
+	if x != nil {
+		return x.EncryptionConfig
+	}
+	return nil
+}
+
+func (x *MetaData) GetEncryptedMetadata() []byte {
+	if x != nil {
+		return x.EncryptedMetadata
+	}
+	return nil
+}
+
+func (x *MetaData) GetAssetMap() map[string]*Asset {
+	if x != nil {
+		return x.AssetMap
+	}
+	return nil
+}
+
+func (x *MetaData) GetSnapshotId() string {
+	if x != nil {
+		return x.SnapshotId
+	}
+	return ""
+}
+
+func (x *MetaData) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *MetaData) GetParentSnapshotId() string {
+	if x != nil {
+		return x.ParentSnapshotId
+	}
+	return ""
+}
+
+func (x *MetaData) GetFilter() *BackupFilter {
+	if x != nil {
+		return x.Filter
+	}
+	return nil
+}
+
 // Config of data stored in local directory
 type StorageConfig_Local struct {
 	state         protoimpl.MessageState
@@ -344,12 +768,18 @@ type StorageConfig_Local struct {
 	DatabaseDir string `protobuf:"bytes,2,opt,name=database_dir,json=databaseDir,proto3" json:"database_dir,omitempty"`
 	// Directory in which all blocks are stored
 	BlocksDir string `protobuf:"bytes,3,opt,name=blocks_dir,json=blocksDir,proto3" json:"blocks_dir,omitempty"`
+	// Directory in which all assets are stored. Empty for backups taken by
+	// older versions
+	AssetsDir string `protobuf:"bytes,4,opt,name=assets_dir,json=assetsDir,proto3" json:"assets_dir,omitempty"`
+	// Directory in which all comments are stored. Empty for backups taken by
+	// older versions
+	CommentsDir string `protobuf:"bytes,5,opt,name=comments_dir,json=commentsDir,proto3" json:"comments_dir,omitempty"`
 }
 
 func (x *StorageConfig_Local) Reset() {
 	*x = StorageConfig_Local{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_notion_backup_proto_msgTypes[4]
+		mi := &file_notion_backup_proto_msgTypes[7]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -362,7 +792,7 @@ func (x *StorageConfig_Local) String() string {
 func (*StorageConfig_Local) ProtoMessage() {}
 
 func (x *StorageConfig_Local) ProtoReflect() protoreflect.Message {
-	mi := &file_notion_backup_proto_msgTypes[4]
+	mi := &file_notion_backup_proto_msgTypes[7]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -375,7 +805,7 @@ func (x *StorageConfig_Local) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use StorageConfig_Local.ProtoReflect.Descriptor instead.
 func (*StorageConfig_Local) Descriptor() ([]byte, []int) {
-	return file_notion_backup_proto_rawDescGZIP(), []int{2, 0}
+	return file_notion_backup_proto_rawDescGZIP(), []int{3, 0}
 }
 
 func (x *StorageConfig_Local) GetPageDir() string {
@@ -399,71 +829,481 @@ func (x *StorageConfig_Local) GetBlocksDir() string {
 	return ""
 }
 
+func (x *StorageConfig_Local) GetAssetsDir() string {
+	if x != nil {
+		return x.AssetsDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_Local) GetCommentsDir() string {
+	if x != nil {
+		return x.CommentsDir
+	}
+	return ""
+}
+
+// Config of data stored in a single archive file along with the metadata
+// file
+type StorageConfig_Archive struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Format of the archive. (Formats: tar.gz, zip)
+	Format string `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	// Directory inside the archive in which all pages are stored
+	PageDir string `protobuf:"bytes,2,opt,name=page_dir,json=pageDir,proto3" json:"page_dir,omitempty"`
+	// Directory inside the archive in which all databases are stored
+	DatabaseDir string `protobuf:"bytes,3,opt,name=database_dir,json=databaseDir,proto3" json:"database_dir,omitempty"`
+	// Directory inside the archive in which all blocks are stored
+	BlocksDir string `protobuf:"bytes,4,opt,name=blocks_dir,json=blocksDir,proto3" json:"blocks_dir,omitempty"`
+	// Directory inside the archive in which all assets are stored. Empty for
+	// backups taken by older versions
+	AssetsDir string `protobuf:"bytes,5,opt,name=assets_dir,json=assetsDir,proto3" json:"assets_dir,omitempty"`
+	// Directory inside the archive in which all comments are stored. Empty
+	// for backups taken by older versions
+	CommentsDir string `protobuf:"bytes,6,opt,name=comments_dir,json=commentsDir,proto3" json:"comments_dir,omitempty"`
+}
+
+func (x *StorageConfig_Archive) Reset() {
+	*x = StorageConfig_Archive{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[8]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageConfig_Archive) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageConfig_Archive) ProtoMessage() {}
+
+func (x *StorageConfig_Archive) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[8]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageConfig_Archive.ProtoReflect.Descriptor instead.
+func (*StorageConfig_Archive) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *StorageConfig_Archive) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *StorageConfig_Archive) GetPageDir() string {
+	if x != nil {
+		return x.PageDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_Archive) GetDatabaseDir() string {
+	if x != nil {
+		return x.DatabaseDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_Archive) GetBlocksDir() string {
+	if x != nil {
+		return x.BlocksDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_Archive) GetAssetsDir() string {
+	if x != nil {
+		return x.AssetsDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_Archive) GetCommentsDir() string {
+	if x != nil {
+		return x.CommentsDir
+	}
+	return ""
+}
+
+// Config of data stored as objects in a bucket of S3 compatible object
+// storage. Credentials are never stored in the metadata
+type StorageConfig_S3 struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Endpoint of the object storage. (Example: s3.amazonaws.com)
+	Endpoint string `protobuf:"bytes,1,opt,name=endpoint,proto3" json:"endpoint,omitempty"`
+	// Bucket in which all objects are stored
+	Bucket string `protobuf:"bytes,2,opt,name=bucket,proto3" json:"bucket,omitempty"`
+	// Prefix of the keys of all objects of the backup
+	Prefix string `protobuf:"bytes,3,opt,name=prefix,proto3" json:"prefix,omitempty"`
+	// Region of the bucket
+	Region string `protobuf:"bytes,4,opt,name=region,proto3" json:"region,omitempty"`
+	// Key prefix, relative to prefix, under which all pages are stored
+	PageDir string `protobuf:"bytes,5,opt,name=page_dir,json=pageDir,proto3" json:"page_dir,omitempty"`
+	// Key prefix, relative to prefix, under which all databases are stored
+	DatabaseDir string `protobuf:"bytes,6,opt,name=database_dir,json=databaseDir,proto3" json:"database_dir,omitempty"`
+	// Key prefix, relative to prefix, under which all blocks are stored
+	BlocksDir string `protobuf:"bytes,7,opt,name=blocks_dir,json=blocksDir,proto3" json:"blocks_dir,omitempty"`
+	// Key prefix, relative to prefix, under which all assets are stored. Empty
+	// for backups taken by older versions
+	AssetsDir string `protobuf:"bytes,8,opt,name=assets_dir,json=assetsDir,proto3" json:"assets_dir,omitempty"`
+	// Key prefix, relative to prefix, under which all comments are stored.
+	// Empty for backups taken by older versions
+	CommentsDir string `protobuf:"bytes,9,opt,name=comments_dir,json=commentsDir,proto3" json:"comments_dir,omitempty"`
+}
+
+func (x *StorageConfig_S3) Reset() {
+	*x = StorageConfig_S3{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[9]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageConfig_S3) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageConfig_S3) ProtoMessage() {}
+
+func (x *StorageConfig_S3) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[9]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageConfig_S3.ProtoReflect.Descriptor instead.
+func (*StorageConfig_S3) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{3, 2}
+}
+
+func (x *StorageConfig_S3) GetEndpoint() string {
+	if x != nil {
+		return x.Endpoint
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetBucket() string {
+	if x != nil {
+		return x.Bucket
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetPrefix() string {
+	if x != nil {
+		return x.Prefix
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetRegion() string {
+	if x != nil {
+		return x.Region
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetPageDir() string {
+	if x != nil {
+		return x.PageDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetDatabaseDir() string {
+	if x != nil {
+		return x.DatabaseDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetBlocksDir() string {
+	if x != nil {
+		return x.BlocksDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetAssetsDir() string {
+	if x != nil {
+		return x.AssetsDir
+	}
+	return ""
+}
+
+func (x *StorageConfig_S3) GetCommentsDir() string {
+	if x != nil {
+		return x.CommentsDir
+	}
+	return ""
+}
+
+// Config of data stored in a content addressed object pool shared by many
+// snapshots. Objects are identified by SHA-256 hash of their content
+type StorageConfig_ContentAddressed struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// Directory of the object pool relative to the directory of the metadata
+	// file
+	ObjectsDir string `protobuf:"bytes,1,opt,name=objects_dir,json=objectsDir,proto3" json:"objects_dir,omitempty"`
+}
+
+func (x *StorageConfig_ContentAddressed) Reset() {
+	*x = StorageConfig_ContentAddressed{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_notion_backup_proto_msgTypes[10]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *StorageConfig_ContentAddressed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StorageConfig_ContentAddressed) ProtoMessage() {}
+
+func (x *StorageConfig_ContentAddressed) ProtoReflect() protoreflect.Message {
+	mi := &file_notion_backup_proto_msgTypes[10]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StorageConfig_ContentAddressed.ProtoReflect.Descriptor instead.
+func (*StorageConfig_ContentAddressed) Descriptor() ([]byte, []int) {
+	return file_notion_backup_proto_rawDescGZIP(), []int{3, 3}
+}
+
+func (x *StorageConfig_ContentAddressed) GetObjectsDir() string {
+	if x != nil {
+		return x.ObjectsDir
+	}
+	return ""
+}
+
 var File_notion_backup_proto protoreflect.FileDescriptor
 
 var file_notion_backup_proto_rawDesc = []byte{
 	0x0a, 0x13, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x62, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x2e,
-	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0xa2, 0x01, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
-	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18, 0x01,
-	0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x74,
-	0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72,
-	0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49,
-	0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79, 0x70,
-	0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e,
-	0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70, 0x65,
-	0x12, 0x28, 0x0a, 0x10, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6f, 0x62, 0x6a, 0x65, 0x63,
-	0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6e, 0x6f, 0x74, 0x69,
-	0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64, 0x22, 0x49, 0x0a, 0x19, 0x43, 0x68,
-	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65,
-	0x63, 0x74, 0x55, 0x75, 0x69, 0x64, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x68, 0x69, 0x6c, 0x64,
-	0x72, 0x65, 0x6e, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x73, 0x74, 0x18, 0x01, 0x20,
-	0x03, 0x28, 0x09, 0x52, 0x10, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55, 0x75, 0x69,
-	0x64, 0x4c, 0x69, 0x73, 0x74, 0x22, 0xad, 0x01, 0x0a, 0x0d, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67,
-	0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2c, 0x0a, 0x05, 0x6c, 0x6f, 0x63, 0x61, 0x6c,
-	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x05,
-	0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x1a, 0x64, 0x0a, 0x05, 0x4c, 0x6f, 0x63, 0x61, 0x6c, 0x12, 0x19,
-	0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x07, 0x70, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74,
-	0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52,
-	0x0b, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x1d, 0x0a, 0x0a,
-	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09,
-	0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x44, 0x69, 0x72, 0x42, 0x08, 0x0a, 0x06, 0x63,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xbb, 0x03, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x44, 0x61,
-	0x74, 0x61, 0x12, 0x4a, 0x0a, 0x11, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6f, 0x62, 0x6a,
-	0x65, 0x63, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e,
-	0x4d, 0x65, 0x74, 0x61, 0x44, 0x61, 0x74, 0x61, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f,
-	0x62, 0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0f, 0x6e,
-	0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x6e,
-	0x0a, 0x1f, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x32, 0x5f,
-	0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x6d, 0x61,
-	0x70, 0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x44, 0x61,
-	0x74, 0x61, 0x2e, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43, 0x68,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x1f, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2f, 0x74, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70,
+	0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x84, 0x02, 0x0a, 0x0c, 0x4e, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x75, 0x75, 0x69, 0x64, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x04, 0x75, 0x75, 0x69, 0x64, 0x12, 0x2d, 0x0a, 0x12, 0x73,
+	0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
+	0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x12, 0x25, 0x0a, 0x04, 0x74, 0x79,
+	0x70, 0x65, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0e, 0x32, 0x11, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f,
+	0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x52, 0x04, 0x74, 0x79, 0x70,
+	0x65, 0x12, 0x28, 0x0a, 0x10, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6e, 0x6f, 0x74,
+	0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x49, 0x64, 0x12, 0x44, 0x0a, 0x10, 0x6c,
+	0x61, 0x73, 0x74, 0x5f, 0x65, 0x64, 0x69, 0x74, 0x65, 0x64, 0x5f, 0x74, 0x69, 0x6d, 0x65, 0x18,
+	0x05, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d,
+	0x70, 0x52, 0x0e, 0x6c, 0x61, 0x73, 0x74, 0x45, 0x64, 0x69, 0x74, 0x65, 0x64, 0x54, 0x69, 0x6d,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x06, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x22, 0x49, 0x0a,
+	0x19, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f,
+	0x62, 0x6a, 0x65, 0x63, 0x74, 0x55, 0x75, 0x69, 0x64, 0x73, 0x12, 0x2c, 0x0a, 0x12, 0x63, 0x68,
+	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x6c, 0x69, 0x73, 0x74,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x10, 0x63, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e,
+	0x55, 0x75, 0x69, 0x64, 0x4c, 0x69, 0x73, 0x74, 0x22, 0x92, 0x01, 0x0a, 0x05, 0x41, 0x73, 0x73,
+	0x65, 0x74, 0x12, 0x2d, 0x0a, 0x12, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64,
+	0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x11,
+	0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x49, 0x64, 0x65, 0x6e, 0x74, 0x69, 0x66, 0x69, 0x65,
+	0x72, 0x12, 0x1b, 0x0a, 0x09, 0x66, 0x69, 0x6c, 0x65, 0x5f, 0x6e, 0x61, 0x6d, 0x65, 0x18, 0x02,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x66, 0x69, 0x6c, 0x65, 0x4e, 0x61, 0x6d, 0x65, 0x12, 0x21,
+	0x0a, 0x0c, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x54, 0x79, 0x70,
+	0x65, 0x12, 0x1a, 0x0a, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x18, 0x04, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x08, 0x63, 0x68, 0x65, 0x63, 0x6b, 0x73, 0x75, 0x6d, 0x22, 0x9b, 0x07,
+	0x0a, 0x0d, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12,
+	0x2c, 0x0a, 0x05, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x14,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x4c,
+	0x6f, 0x63, 0x61, 0x6c, 0x48, 0x00, 0x52, 0x05, 0x6c, 0x6f, 0x63, 0x61, 0x6c, 0x12, 0x32, 0x0a,
+	0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x16,
+	0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x41,
+	0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x48, 0x00, 0x52, 0x07, 0x61, 0x72, 0x63, 0x68, 0x69, 0x76,
+	0x65, 0x12, 0x23, 0x0a, 0x02, 0x73, 0x33, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x11, 0x2e,
+	0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x2e, 0x53, 0x33,
+	0x48, 0x00, 0x52, 0x02, 0x73, 0x33, 0x12, 0x4e, 0x0a, 0x11, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e,
+	0x74, 0x5f, 0x61, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x1f, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f, 0x6e, 0x66, 0x69,
+	0x67, 0x2e, 0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73,
+	0x65, 0x64, 0x48, 0x00, 0x52, 0x10, 0x63, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64,
+	0x72, 0x65, 0x73, 0x73, 0x65, 0x64, 0x1a, 0xa6, 0x01, 0x0a, 0x05, 0x4c, 0x6f, 0x63, 0x61, 0x6c,
+	0x12, 0x19, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x01, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64,
+	0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x1d,
+	0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x03, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x44, 0x69, 0x72, 0x12, 0x1d, 0x0a,
+	0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x44, 0x69, 0x72, 0x12, 0x21, 0x0a, 0x0c,
+	0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x44, 0x69, 0x72, 0x1a,
+	0xc0, 0x01, 0x0a, 0x07, 0x41, 0x72, 0x63, 0x68, 0x69, 0x76, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x66,
+	0x6f, 0x72, 0x6d, 0x61, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x66, 0x6f, 0x72,
+	0x6d, 0x61, 0x74, 0x12, 0x19, 0x0a, 0x08, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x70, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72, 0x12, 0x21,
+	0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x44, 0x69,
+	0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x44, 0x69, 0x72,
+	0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x05,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x44, 0x69, 0x72, 0x12,
+	0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x44,
+	0x69, 0x72, 0x1a, 0x87, 0x02, 0x0a, 0x02, 0x53, 0x33, 0x12, 0x1a, 0x0a, 0x08, 0x65, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x08, 0x65, 0x6e, 0x64,
+	0x70, 0x6f, 0x69, 0x6e, 0x74, 0x12, 0x16, 0x0a, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x62, 0x75, 0x63, 0x6b, 0x65, 0x74, 0x12, 0x16, 0x0a,
+	0x06, 0x70, 0x72, 0x65, 0x66, 0x69, 0x78, 0x18, 0x03, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x70,
+	0x72, 0x65, 0x66, 0x69, 0x78, 0x12, 0x16, 0x0a, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x18,
+	0x04, 0x20, 0x01, 0x28, 0x09, 0x52, 0x06, 0x72, 0x65, 0x67, 0x69, 0x6f, 0x6e, 0x12, 0x19, 0x0a,
+	0x08, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x05, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x07, 0x70, 0x61, 0x67, 0x65, 0x44, 0x69, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x64, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0b,
+	0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x44, 0x69, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x62,
+	0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x09, 0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x73, 0x44, 0x69, 0x72, 0x12, 0x1d, 0x0a, 0x0a, 0x61, 0x73,
+	0x73, 0x65, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09, 0x52, 0x09,
+	0x61, 0x73, 0x73, 0x65, 0x74, 0x73, 0x44, 0x69, 0x72, 0x12, 0x21, 0x0a, 0x0c, 0x63, 0x6f, 0x6d,
+	0x6d, 0x65, 0x6e, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18, 0x09, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x0b, 0x63, 0x6f, 0x6d, 0x6d, 0x65, 0x6e, 0x74, 0x73, 0x44, 0x69, 0x72, 0x1a, 0x33, 0x0a, 0x10,
+	0x43, 0x6f, 0x6e, 0x74, 0x65, 0x6e, 0x74, 0x41, 0x64, 0x64, 0x72, 0x65, 0x73, 0x73, 0x65, 0x64,
+	0x12, 0x1f, 0x0a, 0x0b, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x5f, 0x64, 0x69, 0x72, 0x18,
+	0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0a, 0x6f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x73, 0x44, 0x69,
+	0x72, 0x42, 0x08, 0x0a, 0x06, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x22, 0xec, 0x01, 0x0a, 0x10,
+	0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67,
+	0x12, 0x1c, 0x0a, 0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x18, 0x01, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x09, 0x61, 0x6c, 0x67, 0x6f, 0x72, 0x69, 0x74, 0x68, 0x6d, 0x12, 0x10,
+	0x0a, 0x03, 0x6b, 0x64, 0x66, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x64, 0x66,
+	0x12, 0x12, 0x0a, 0x04, 0x73, 0x61, 0x6c, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04,
+	0x73, 0x61, 0x6c, 0x74, 0x12, 0x1f, 0x0a, 0x0b, 0x61, 0x72, 0x67, 0x6f, 0x6e, 0x32, 0x5f, 0x74,
+	0x69, 0x6d, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0a, 0x61, 0x72, 0x67, 0x6f, 0x6e,
+	0x32, 0x54, 0x69, 0x6d, 0x65, 0x12, 0x23, 0x0a, 0x0d, 0x61, 0x72, 0x67, 0x6f, 0x6e, 0x32, 0x5f,
+	0x6d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x18, 0x05, 0x20, 0x01, 0x28, 0x0d, 0x52, 0x0c, 0x61, 0x72,
+	0x67, 0x6f, 0x6e, 0x32, 0x4d, 0x65, 0x6d, 0x6f, 0x72, 0x79, 0x12, 0x25, 0x0a, 0x0e, 0x61, 0x72,
+	0x67, 0x6f, 0x6e, 0x32, 0x5f, 0x74, 0x68, 0x72, 0x65, 0x61, 0x64, 0x73, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0d, 0x52, 0x0d, 0x61, 0x72, 0x67, 0x6f, 0x6e, 0x32, 0x54, 0x68, 0x72, 0x65, 0x61, 0x64,
+	0x73, 0x12, 0x27, 0x0a, 0x0f, 0x6b, 0x65, 0x79, 0x5f, 0x66, 0x69, 0x6e, 0x67, 0x65, 0x72, 0x70,
+	0x72, 0x69, 0x6e, 0x74, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0e, 0x6b, 0x65, 0x79, 0x46,
+	0x69, 0x6e, 0x67, 0x65, 0x72, 0x70, 0x72, 0x69, 0x6e, 0x74, 0x22, 0xe7, 0x01, 0x0a, 0x0c, 0x42,
+	0x61, 0x63, 0x6b, 0x75, 0x70, 0x46, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x12, 0x28, 0x0a, 0x10, 0x65,
+	0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x09, 0x52, 0x0e, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x50, 0x61,
+	0x67, 0x65, 0x49, 0x64, 0x73, 0x12, 0x30, 0x0a, 0x14, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65,
+	0x5f, 0x64, 0x61, 0x74, 0x61, 0x62, 0x61, 0x73, 0x65, 0x5f, 0x69, 0x64, 0x73, 0x18, 0x02, 0x20,
+	0x03, 0x28, 0x09, 0x52, 0x12, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x44, 0x61, 0x74, 0x61,
+	0x62, 0x61, 0x73, 0x65, 0x49, 0x64, 0x73, 0x12, 0x2e, 0x0a, 0x13, 0x65, 0x78, 0x63, 0x6c, 0x75,
+	0x64, 0x65, 0x5f, 0x74, 0x69, 0x74, 0x6c, 0x65, 0x5f, 0x72, 0x65, 0x67, 0x65, 0x78, 0x18, 0x03,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x11, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x54, 0x69, 0x74,
+	0x6c, 0x65, 0x52, 0x65, 0x67, 0x65, 0x78, 0x12, 0x1b, 0x0a, 0x09, 0x6d, 0x61, 0x78, 0x5f, 0x64,
+	0x65, 0x70, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x6d, 0x61, 0x78, 0x44,
+	0x65, 0x70, 0x74, 0x68, 0x12, 0x2e, 0x0a, 0x13, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x5f,
+	0x62, 0x6c, 0x6f, 0x63, 0x6b, 0x5f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x18, 0x05, 0x20, 0x03, 0x28,
+	0x09, 0x52, 0x11, 0x65, 0x78, 0x63, 0x6c, 0x75, 0x64, 0x65, 0x42, 0x6c, 0x6f, 0x63, 0x6b, 0x54,
+	0x79, 0x70, 0x65, 0x73, 0x22, 0x88, 0x07, 0x0a, 0x08, 0x4d, 0x65, 0x74, 0x61, 0x44, 0x61, 0x74,
+	0x61, 0x12, 0x4a, 0x0a, 0x11, 0x6e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x6f, 0x62, 0x6a, 0x65,
+	0x63, 0x74, 0x5f, 0x6d, 0x61, 0x70, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x1e, 0x2e, 0x4d,
+	0x65, 0x74, 0x61, 0x44, 0x61, 0x74, 0x61, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x52, 0x0f, 0x6e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x6e, 0x0a,
+	0x1f, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x32, 0x5f, 0x63,
+	0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x5f, 0x75, 0x75, 0x69, 0x64, 0x5f, 0x6d, 0x61, 0x70,
+	0x18, 0x02, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x29, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x44, 0x61, 0x74,
+	0x61, 0x2e, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43, 0x68, 0x69,
+	0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55, 0x75, 0x69, 0x64, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x1a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43, 0x68,
+	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55, 0x75, 0x69, 0x64, 0x4d, 0x61, 0x70, 0x12, 0x35, 0x0a,
+	0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18,
+	0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43,
+	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x12, 0x30, 0x0a, 0x14, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x5f, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x70, 0x61, 0x74, 0x68, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x09, 0x52, 0x12, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68,
+	0x6f, 0x74, 0x50, 0x61, 0x74, 0x68, 0x12, 0x3e, 0x0a, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70,
+	0x74, 0x69, 0x6f, 0x6e, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x18, 0x05, 0x20, 0x01, 0x28,
+	0x0b, 0x32, 0x11, 0x2e, 0x45, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e, 0x43, 0x6f,
+	0x6e, 0x66, 0x69, 0x67, 0x52, 0x10, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x69, 0x6f, 0x6e,
+	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x12, 0x2d, 0x0a, 0x12, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70,
+	0x74, 0x65, 0x64, 0x5f, 0x6d, 0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x18, 0x06, 0x20, 0x01,
+	0x28, 0x0c, 0x52, 0x11, 0x65, 0x6e, 0x63, 0x72, 0x79, 0x70, 0x74, 0x65, 0x64, 0x4d, 0x65, 0x74,
+	0x61, 0x64, 0x61, 0x74, 0x61, 0x12, 0x34, 0x0a, 0x09, 0x61, 0x73, 0x73, 0x65, 0x74, 0x5f, 0x6d,
+	0x61, 0x70, 0x18, 0x07, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x17, 0x2e, 0x4d, 0x65, 0x74, 0x61, 0x44,
+	0x61, 0x74, 0x61, 0x2e, 0x41, 0x73, 0x73, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72,
+	0x79, 0x52, 0x08, 0x61, 0x73, 0x73, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x12, 0x1f, 0x0a, 0x0b, 0x73,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x08, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x0a, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x39, 0x0a, 0x0a,
+	0x63, 0x72, 0x65, 0x61, 0x74, 0x65, 0x64, 0x5f, 0x61, 0x74, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x1a, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62,
+	0x75, 0x66, 0x2e, 0x54, 0x69, 0x6d, 0x65, 0x73, 0x74, 0x61, 0x6d, 0x70, 0x52, 0x09, 0x63, 0x72,
+	0x65, 0x61, 0x74, 0x65, 0x64, 0x41, 0x74, 0x12, 0x2c, 0x0a, 0x12, 0x70, 0x61, 0x72, 0x65, 0x6e,
+	0x74, 0x5f, 0x73, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x5f, 0x69, 0x64, 0x18, 0x0a, 0x20,
+	0x01, 0x28, 0x09, 0x52, 0x10, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x53, 0x6e, 0x61, 0x70, 0x73,
+	0x68, 0x6f, 0x74, 0x49, 0x64, 0x12, 0x25, 0x0a, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x18,
+	0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x42, 0x61, 0x63, 0x6b, 0x75, 0x70, 0x46, 0x69,
+	0x6c, 0x74, 0x65, 0x72, 0x52, 0x06, 0x66, 0x69, 0x6c, 0x74, 0x65, 0x72, 0x1a, 0x51, 0x0a, 0x14,
+	0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x45,
+	0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28,
+	0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x23, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18,
+	0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62,
+	0x6a, 0x65, 0x63, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a,
+	0x69, 0x0a, 0x1f, 0x50, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43, 0x68,
 	0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55, 0x75, 0x69, 0x64, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74,
-	0x72, 0x79, 0x52, 0x1a, 0x70, 0x61, 0x72, 0x65, 0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43,
-	0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55, 0x75, 0x69, 0x64, 0x4d, 0x61, 0x70, 0x12, 0x35,
-	0x0a, 0x0e, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x5f, 0x63, 0x6f, 0x6e, 0x66, 0x69, 0x67,
-	0x18, 0x03, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65,
-	0x43, 0x6f, 0x6e, 0x66, 0x69, 0x67, 0x52, 0x0d, 0x73, 0x74, 0x6f, 0x72, 0x61, 0x67, 0x65, 0x43,
-	0x6f, 0x6e, 0x66, 0x69, 0x67, 0x1a, 0x51, 0x0a, 0x14, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f,
-	0x62, 0x6a, 0x65, 0x63, 0x74, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a,
-	0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12,
-	0x23, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d,
-	0x2e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x52, 0x05, 0x76,
-	0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x69, 0x0a, 0x1f, 0x50, 0x61, 0x72, 0x65,
-	0x6e, 0x74, 0x55, 0x75, 0x69, 0x64, 0x32, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x55,
-	0x75, 0x69, 0x64, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
-	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x30, 0x0a,
-	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x43,
-	0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a,
-	0x65, 0x63, 0x74, 0x55, 0x75, 0x69, 0x64, 0x73, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a,
-	0x02, 0x38, 0x01, 0x2a, 0x4c, 0x0a, 0x10, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a,
-	0x65, 0x63, 0x74, 0x54, 0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f,
-	0x57, 0x4e, 0x10, 0x00, 0x12, 0x08, 0x0a, 0x04, 0x52, 0x4f, 0x4f, 0x54, 0x10, 0x01, 0x12, 0x08,
-	0x0a, 0x04, 0x50, 0x41, 0x47, 0x45, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x41, 0x54, 0x41,
-	0x42, 0x41, 0x53, 0x45, 0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10,
-	0x04, 0x42, 0x10, 0x5a, 0x0e, 0x2e, 0x2f, 0x73, 0x72, 0x63, 0x2f, 0x6d, 0x65, 0x74, 0x61, 0x64,
-	0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b, 0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52,
+	0x03, 0x6b, 0x65, 0x79, 0x12, 0x30, 0x0a, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x1a, 0x2e, 0x43, 0x68, 0x69, 0x6c, 0x64, 0x72, 0x65, 0x6e, 0x4e, 0x6f,
+	0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x55, 0x75, 0x69, 0x64, 0x73, 0x52,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x1a, 0x43, 0x0a, 0x0d, 0x41, 0x73,
+	0x73, 0x65, 0x74, 0x4d, 0x61, 0x70, 0x45, 0x6e, 0x74, 0x72, 0x79, 0x12, 0x10, 0x0a, 0x03, 0x6b,
+	0x65, 0x79, 0x18, 0x01, 0x20, 0x01, 0x28, 0x09, 0x52, 0x03, 0x6b, 0x65, 0x79, 0x12, 0x1c, 0x0a,
+	0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x06, 0x2e, 0x41,
+	0x73, 0x73, 0x65, 0x74, 0x52, 0x05, 0x76, 0x61, 0x6c, 0x75, 0x65, 0x3a, 0x02, 0x38, 0x01, 0x2a,
+	0x64, 0x0a, 0x10, 0x4e, 0x6f, 0x74, 0x69, 0x6f, 0x6e, 0x4f, 0x62, 0x6a, 0x65, 0x63, 0x74, 0x54,
+	0x79, 0x70, 0x65, 0x12, 0x0b, 0x0a, 0x07, 0x55, 0x4e, 0x4b, 0x4e, 0x4f, 0x57, 0x4e, 0x10, 0x00,
+	0x12, 0x08, 0x0a, 0x04, 0x52, 0x4f, 0x4f, 0x54, 0x10, 0x01, 0x12, 0x08, 0x0a, 0x04, 0x50, 0x41,
+	0x47, 0x45, 0x10, 0x02, 0x12, 0x0c, 0x0a, 0x08, 0x44, 0x41, 0x54, 0x41, 0x42, 0x41, 0x53, 0x45,
+	0x10, 0x03, 0x12, 0x09, 0x0a, 0x05, 0x42, 0x4c, 0x4f, 0x43, 0x4b, 0x10, 0x04, 0x12, 0x09, 0x0a,
+	0x05, 0x41, 0x53, 0x53, 0x45, 0x54, 0x10, 0x05, 0x12, 0x0b, 0x0a, 0x07, 0x43, 0x4f, 0x4d, 0x4d,
+	0x45, 0x4e, 0x54, 0x10, 0x06, 0x42, 0x10, 0x5a, 0x0e, 0x2e, 0x2f, 0x73, 0x72, 0x63, 0x2f, 0x6d,
+	0x65, 0x74, 0x61, 0x64, 0x61, 0x74, 0x61, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -479,30 +1319,47 @@ func file_notion_backup_proto_rawDescGZIP() []byte {
 }
 
 var file_notion_backup_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_notion_backup_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_notion_backup_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_notion_backup_proto_goTypes = []interface{}{
-	(NotionObjectType)(0),             // 0: NotionObjectType
-	(*NotionObject)(nil),              // 1: NotionObject
-	(*ChildrenNotionObjectUuids)(nil), // 2: ChildrenNotionObjectUuids
-	(*StorageConfig)(nil),             // 3: StorageConfig
-	(*MetaData)(nil),                  // 4: MetaData
-	(*StorageConfig_Local)(nil),       // 5: StorageConfig.Local
-	nil,                               // 6: MetaData.NotionObjectMapEntry
-	nil,                               // 7: MetaData.ParentUuid2ChildrenUuidMapEntry
+	(NotionObjectType)(0),                  // 0: NotionObjectType
+	(*NotionObject)(nil),                   // 1: NotionObject
+	(*ChildrenNotionObjectUuids)(nil),      // 2: ChildrenNotionObjectUuids
+	(*Asset)(nil),                          // 3: Asset
+	(*StorageConfig)(nil),                  // 4: StorageConfig
+	(*EncryptionConfig)(nil),               // 5: EncryptionConfig
+	(*BackupFilter)(nil),                   // 6: BackupFilter
+	(*MetaData)(nil),                       // 7: MetaData
+	(*StorageConfig_Local)(nil),            // 8: StorageConfig.Local
+	(*StorageConfig_Archive)(nil),          // 9: StorageConfig.Archive
+	(*StorageConfig_S3)(nil),               // 10: StorageConfig.S3
+	(*StorageConfig_ContentAddressed)(nil), // 11: StorageConfig.ContentAddressed
+	nil,                                    // 12: MetaData.NotionObjectMapEntry
+	nil,                                    // 13: MetaData.ParentUuid2ChildrenUuidMapEntry
+	nil,                                    // 14: MetaData.AssetMapEntry
+	(*timestamppb.Timestamp)(nil),          // 15: google.protobuf.Timestamp
 }
 var file_notion_backup_proto_depIdxs = []int32{
-	0, // 0: NotionObject.type:type_name -> NotionObjectType
-	5, // 1: StorageConfig.local:type_name -> StorageConfig.Local
-	6, // 2: MetaData.notion_object_map:type_name -> MetaData.NotionObjectMapEntry
-	7, // 3: MetaData.parent_uuid_2_children_uuid_map:type_name -> MetaData.ParentUuid2ChildrenUuidMapEntry
-	3, // 4: MetaData.storage_config:type_name -> StorageConfig
-	1, // 5: MetaData.NotionObjectMapEntry.value:type_name -> NotionObject
-	2, // 6: MetaData.ParentUuid2ChildrenUuidMapEntry.value:type_name -> ChildrenNotionObjectUuids
-	7, // [7:7] is the sub-list for method output_type
-	7, // [7:7] is the sub-list for method input_type
-	7, // [7:7] is the sub-list for extension type_name
-	7, // [7:7] is the sub-list for extension extendee
-	0, // [0:7] is the sub-list for field type_name
+	0,  // 0: NotionObject.type:type_name -> NotionObjectType
+	15, // 1: NotionObject.last_edited_time:type_name -> google.protobuf.Timestamp
+	8,  // 2: StorageConfig.local:type_name -> StorageConfig.Local
+	9,  // 3: StorageConfig.archive:type_name -> StorageConfig.Archive
+	10, // 4: StorageConfig.s3:type_name -> StorageConfig.S3
+	11, // 5: StorageConfig.content_addressed:type_name -> StorageConfig.ContentAddressed
+	12, // 6: MetaData.notion_object_map:type_name -> MetaData.NotionObjectMapEntry
+	13, // 7: MetaData.parent_uuid_2_children_uuid_map:type_name -> MetaData.ParentUuid2ChildrenUuidMapEntry
+	4,  // 8: MetaData.storage_config:type_name -> StorageConfig
+	5,  // 9: MetaData.encryption_config:type_name -> EncryptionConfig
+	14, // 10: MetaData.asset_map:type_name -> MetaData.AssetMapEntry
+	15, // 11: MetaData.created_at:type_name -> google.protobuf.Timestamp
+	6,  // 12: MetaData.filter:type_name -> BackupFilter
+	1,  // 13: MetaData.NotionObjectMapEntry.value:type_name -> NotionObject
+	2,  // 14: MetaData.ParentUuid2ChildrenUuidMapEntry.value:type_name -> ChildrenNotionObjectUuids
+	3,  // 15: MetaData.AssetMapEntry.value:type_name -> Asset
+	16, // [16:16] is the sub-list for method output_type
+	16, // [16:16] is the sub-list for method input_type
+	16, // [16:16] is the sub-list for extension type_name
+	16, // [16:16] is the sub-list for extension extendee
+	0,  // [0:16] is the sub-list for field type_name
 }
 
 func init() { file_notion_backup_proto_init() }
@@ -536,7 +1393,7 @@ func file_notion_backup_proto_init() {
 			}
 		}
 		file_notion_backup_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*StorageConfig); i {
+			switch v := v.(*Asset); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -548,7 +1405,7 @@ func file_notion_backup_proto_init() {
 			}
 		}
 		file_notion_backup_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*MetaData); i {
+			switch v := v.(*StorageConfig); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -560,6 +1417,42 @@ func file_notion_backup_proto_init() {
 			}
 		}
 		file_notion_backup_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*EncryptionConfig); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notion_backup_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*BackupFilter); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notion_backup_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*MetaData); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notion_backup_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StorageConfig_Local); i {
 			case 0:
 				return &v.state
@@ -571,9 +1464,48 @@ func file_notion_backup_proto_init() {
 				return nil
 			}
 		}
+		file_notion_backup_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageConfig_Archive); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notion_backup_proto_msgTypes[9].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageConfig_S3); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_notion_backup_proto_msgTypes[10].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*StorageConfig_ContentAddressed); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
 	}
-	file_notion_backup_proto_msgTypes[2].OneofWrappers = []interface{}{
+	file_notion_backup_proto_msgTypes[3].OneofWrappers = []interface{}{
 		(*StorageConfig_Local_)(nil),
+		(*StorageConfig_Archive_)(nil),
+		(*StorageConfig_S3_)(nil),
+		(*StorageConfig_ContentAddressed_)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -581,7 +1513,7 @@ func file_notion_backup_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_notion_backup_proto_rawDesc,
 			NumEnums:      1,
-			NumMessages:   7,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
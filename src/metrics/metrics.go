@@ -0,0 +1,379 @@
+package metrics
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	NAMESPACE = "notionbackup"
+
+	BACKUP = "backup"
+
+	SUCCESS_OUTCOME = "success"
+	FAILURE_OUTCOME = "failure"
+
+	// Status code label of the requests which failed without a response
+	NO_STATUS_CODE = "none"
+
+	METRICS_PATH = "/metrics"
+)
+
+type recorderCtxKey struct{}
+
+// Metrics holds the Prometheus collectors of the process. Collectors are
+// registered to a registry of their own so that only the metrics of
+// notionbackup are exposed
+type Metrics struct {
+	registry         *prometheus.Registry
+	apiRequests      *prometheus.CounterVec
+	apiLatency       *prometheus.HistogramVec
+	apiRetries       *prometheus.CounterVec
+	objectsWritten   *prometheus.CounterVec
+	bytesStored      *prometheus.CounterVec
+	runs             *prometheus.CounterVec
+	runDuration      *prometheus.HistogramVec
+	lastRunTimestamp *prometheus.GaugeVec
+}
+
+func GetMetrics() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		apiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NAMESPACE,
+			Name:      "notion_api_requests_total",
+			Help:      "Number of requests sent to Notion API",
+		}, []string{"method", "status_code"}),
+		apiLatency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: NAMESPACE,
+			Name:      "notion_api_request_duration_seconds",
+			Help:      "Latency of the requests sent to Notion API",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method"}),
+		apiRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NAMESPACE,
+			Name:      "notion_api_retries_total",
+			Help: "Number of Notion API requests retried after transient " +
+				"errors",
+		}, []string{"method"}),
+		objectsWritten: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NAMESPACE,
+			Name:      "objects_written_total",
+			Help:      "Number of objects written by the ReaderWriter",
+		}, []string{"rw"}),
+		bytesStored: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NAMESPACE,
+			Name:      "bytes_stored_total",
+			Help:      "Number of bytes stored by the ReaderWriter",
+		}, []string{"rw"}),
+		runs: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: NAMESPACE,
+			Name:      "runs_total",
+			Help:      "Number of finished runs by outcome",
+		}, []string{"operation", "job", "outcome"}),
+		runDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: NAMESPACE,
+			Name:      "run_duration_seconds",
+			Help:      "Duration of the finished runs by outcome",
+			Buckets:   prometheus.ExponentialBuckets(1, 4, 8),
+		}, []string{"operation", "job", "outcome"}),
+		lastRunTimestamp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: NAMESPACE,
+			Name:      "last_run_timestamp_seconds",
+			Help:      "Unix time at which the last run with the outcome finished",
+		}, []string{"operation", "job", "outcome"}),
+	}
+
+	m.registry.MustRegister(m.apiRequests, m.apiLatency, m.apiRetries,
+		m.objectsWritten, m.bytesStored, m.runs, m.runDuration,
+		m.lastRunTimestamp)
+	return m
+}
+
+// Serve the metrics on the address until the context is cancelled. Error is
+// returned if the address cannot be listened on
+func (m *Metrics) Serve(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(METRICS_PATH, promhttp.HandlerFor(m.registry,
+		promhttp.HandlerOpts{}))
+	server := &http.Server{Handler: mux}
+
+	go server.Serve(listener)
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	return nil
+}
+
+// Write the metrics to the file in the text format read by the textfile
+// collector of node exporter. File is replaced atomically
+func (m *Metrics) WriteTextfile(path string) error {
+	return prometheus.WriteToTextfile(path, m.registry)
+}
+
+// Recorder records the metrics of a single run, both to the collectors of the
+// process and to the totals of the run from which its summary is created.
+// All the methods are no-op for nil recorder
+type Recorder struct {
+	metrics   *Metrics
+	operation string
+	job       string
+	startTime time.Time
+
+	mutex          sync.Mutex
+	snapshotId     string
+	apiRequests    map[string]map[string]int64
+	apiLatency     map[string]time.Duration
+	apiRetries     map[string]int64
+	objectsWritten map[string]int64
+	bytesStored    map[string]int64
+
+	// Set once the run is finished
+	finished    bool
+	finishTime  time.Time
+	outcome     string
+	errMessage  string
+	finishHooks []func(*Summary)
+}
+
+// Get the recorder of a run of the operation starting now. Job is the name of
+// the scheduled job the run belongs to, if any. Metrics can be nil, in which
+// case only the totals of the run are recorded
+func (m *Metrics) GetRecorder(operation string, job string) *Recorder {
+	return &Recorder{
+		metrics:        m,
+		operation:      operation,
+		job:            job,
+		startTime:      time.Now(),
+		apiRequests:    make(map[string]map[string]int64),
+		apiLatency:     make(map[string]time.Duration),
+		apiRetries:     make(map[string]int64),
+		objectsWritten: make(map[string]int64),
+		bytesStored:    make(map[string]int64),
+	}
+}
+
+// Get the recorder associated with the context. Nil is returned if there is no
+// recorder associated with the context
+func Ctx(ctx context.Context) *Recorder {
+	recorder, _ := ctx.Value(recorderCtxKey{}).(*Recorder)
+	return recorder
+}
+
+// Get a copy of the context associated with the recorder
+func (r *Recorder) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, recorderCtxKey{}, r)
+}
+
+// Record the Notion API request of the method which completed with the status
+// code after the duration. Status code is zero if no response was received
+func (r *Recorder) ObserveAPIRequest(method string, statusCode int,
+	duration time.Duration) {
+	if r == nil {
+		return
+	}
+
+	statusCodeLabel := NO_STATUS_CODE
+	if statusCode != 0 {
+		statusCodeLabel = strconv.Itoa(statusCode)
+	}
+
+	r.mutex.Lock()
+	if r.apiRequests[method] == nil {
+		r.apiRequests[method] = make(map[string]int64)
+	}
+	r.apiRequests[method][statusCodeLabel]++
+	r.apiLatency[method] += duration
+	r.mutex.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.apiRequests.WithLabelValues(method, statusCodeLabel).Inc()
+		r.metrics.apiLatency.WithLabelValues(method).Observe(duration.Seconds())
+	}
+}
+
+// Record the ID of the snapshot taken by the run
+func (r *Recorder) SetSnapshotId(snapshotId string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.snapshotId = snapshotId
+	r.mutex.Unlock()
+}
+
+// Call the hook with the final summary of the run once it is finished, e.g. to
+// write the summary along with the snapshot
+func (r *Recorder) OnFinish(hook func(*Summary)) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.finishHooks = append(r.finishHooks, hook)
+	r.mutex.Unlock()
+}
+
+func (r *Recorder) AddRetry(method string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.apiRetries[method]++
+	r.mutex.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.apiRetries.WithLabelValues(method).Inc()
+	}
+}
+
+// Record the object of the size written by the ReaderWriter of the type
+func (r *Recorder) AddObjectWritten(rwType string, size int) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	r.objectsWritten[rwType]++
+	r.bytesStored[rwType] += int64(size)
+	r.mutex.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.objectsWritten.WithLabelValues(rwType).Inc()
+		r.metrics.bytesStored.WithLabelValues(rwType).Add(float64(size))
+	}
+}
+
+// Record the duration and the outcome of the finished run and call the finish
+// hooks with its summary. Only the first call has any effect
+func (r *Recorder) Finish(err error) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	if r.finished {
+		r.mutex.Unlock()
+		return
+	}
+
+	r.finished = true
+	r.finishTime = time.Now()
+	r.outcome = SUCCESS_OUTCOME
+	if err != nil {
+		r.outcome = FAILURE_OUTCOME
+		r.errMessage = err.Error()
+	}
+	hooks := r.finishHooks
+	r.mutex.Unlock()
+
+	if r.metrics != nil {
+		r.metrics.runs.WithLabelValues(r.operation, r.job, r.outcome).Inc()
+		r.metrics.runDuration.WithLabelValues(r.operation, r.job, r.outcome).
+			Observe(r.finishTime.Sub(r.startTime).Seconds())
+		r.metrics.lastRunTimestamp.WithLabelValues(r.operation, r.job,
+			r.outcome).Set(float64(r.finishTime.UnixNano()) / 1e9)
+	}
+
+	summary := r.GetSummary()
+	for _, hook := range hooks {
+		hook(summary)
+	}
+}
+
+// Totals of the Notion API requests of a method
+type APIRequestTotals struct {
+	Requests       int64            `json:"requests"`
+	ByStatusCode   map[string]int64 `json:"by_status_code"`
+	Retries        int64            `json:"retries"`
+	LatencySeconds float64          `json:"latency_seconds"`
+}
+
+// Summary of the totals of a run. Outcome, error and finish time are only set
+// once the run is finished
+type Summary struct {
+	Operation       string                       `json:"operation"`
+	Job             string                       `json:"job,omitempty"`
+	SnapshotId      string                       `json:"snapshot_id,omitempty"`
+	Outcome         string                       `json:"outcome,omitempty"`
+	Error           string                       `json:"error,omitempty"`
+	StartedAt       time.Time                    `json:"started_at"`
+	FinishedAt      *time.Time                   `json:"finished_at,omitempty"`
+	DurationSeconds float64                      `json:"duration_seconds"`
+	APIRequests     int64                        `json:"api_requests"`
+	Retries         int64                        `json:"retries"`
+	APIMethods      map[string]*APIRequestTotals `json:"api_methods"`
+	ObjectsWritten  map[string]int64             `json:"objects_written"`
+	BytesStored     map[string]int64             `json:"bytes_stored"`
+}
+
+// Get the summary of the run so far. Nil is returned for nil recorder
+func (r *Recorder) GetSummary() *Summary {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	summary := &Summary{
+		Operation:       r.operation,
+		Job:             r.job,
+		SnapshotId:      r.snapshotId,
+		StartedAt:       r.startTime.UTC(),
+		DurationSeconds: time.Since(r.startTime).Seconds(),
+		APIMethods:      make(map[string]*APIRequestTotals),
+		ObjectsWritten:  make(map[string]int64),
+		BytesStored:     make(map[string]int64),
+	}
+
+	if r.finished {
+		finishedAt := r.finishTime.UTC()
+		summary.Outcome = r.outcome
+		summary.Error = r.errMessage
+		summary.FinishedAt = &finishedAt
+
+		// Duration is computed from the reported times so that they always agree
+		summary.DurationSeconds = finishedAt.Sub(summary.StartedAt).Seconds()
+	}
+
+	for method, byStatusCode := range r.apiRequests {
+		totals := &APIRequestTotals{
+			ByStatusCode:   make(map[string]int64),
+			Retries:        r.apiRetries[method],
+			LatencySeconds: r.apiLatency[method].Seconds(),
+		}
+
+		for statusCode, count := range byStatusCode {
+			totals.ByStatusCode[statusCode] = count
+			totals.Requests += count
+		}
+
+		summary.APIMethods[method] = totals
+		summary.APIRequests += totals.Requests
+		summary.Retries += totals.Retries
+	}
+
+	for rwType, count := range r.objectsWritten {
+		summary.ObjectsWritten[rwType] = count
+		summary.BytesStored[rwType] = r.bytesStored[rwType]
+	}
+
+	return summary
+}
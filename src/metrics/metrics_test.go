@@ -0,0 +1,162 @@
+package metrics_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to record a run with requests, a retry and a written object
+func recordRun(recorder *metrics.Recorder, err error) {
+	recorder.ObserveAPIRequest("GetPageByID", http.StatusOK, time.Second)
+	recorder.ObserveAPIRequest("GetPageByID", http.StatusTooManyRequests,
+		time.Second)
+	recorder.ObserveAPIRequest("GetPageBlocks", 0, time.Second)
+	recorder.AddRetry("GetPageByID")
+	recorder.AddObjectWritten("file", 100)
+	recorder.Finish(err)
+}
+
+func TestRecorder(t *testing.T) {
+	t.Run("Summary", func(t *testing.T) {
+		recorder := metrics.GetMetrics().GetRecorder(metrics.BACKUP, "job")
+		recordRun(recorder, nil)
+
+		summary := recorder.GetSummary()
+		assert.Equal(t, metrics.BACKUP, summary.Operation)
+		assert.Equal(t, "job", summary.Job)
+		assert.Equal(t, int64(3), summary.APIRequests)
+		assert.Equal(t, int64(1), summary.Retries)
+		assert.Equal(t, &metrics.APIRequestTotals{
+			Requests: 2,
+			ByStatusCode: map[string]int64{
+				"200": 1,
+				"429": 1,
+			},
+			Retries:        1,
+			LatencySeconds: 2,
+		}, summary.APIMethods["GetPageByID"])
+		assert.Equal(t, map[string]int64{metrics.NO_STATUS_CODE: 1},
+			summary.APIMethods["GetPageBlocks"].ByStatusCode)
+		assert.Equal(t, map[string]int64{"file": 1}, summary.ObjectsWritten)
+		assert.Equal(t, map[string]int64{"file": 100}, summary.BytesStored)
+	})
+
+	t.Run("Summary of finished run", func(t *testing.T) {
+		recorder := metrics.GetMetrics().GetRecorder(metrics.BACKUP, "job")
+		recorder.SetSnapshotId("snapshot_id")
+		assert.Empty(t, recorder.GetSummary().Outcome)
+
+		summaries := []*metrics.Summary{}
+		recorder.OnFinish(func(summary *metrics.Summary) {
+			summaries = append(summaries, summary)
+		})
+		recordRun(recorder, errors.New("failed"))
+
+		// Run is only finished once
+		recorder.Finish(nil)
+		assert.Len(t, summaries, 1)
+
+		summary := summaries[0]
+		assert.Equal(t, "snapshot_id", summary.SnapshotId)
+		assert.Equal(t, metrics.FAILURE_OUTCOME, summary.Outcome)
+		assert.Equal(t, "failed", summary.Error)
+		assert.NotNil(t, summary.FinishedAt)
+		assert.Equal(t, summary.FinishedAt.Sub(summary.StartedAt).Seconds(),
+			summary.DurationSeconds)
+		assert.Equal(t, summary, recorder.GetSummary())
+	})
+
+	t.Run("Recorder without metrics", func(t *testing.T) {
+		var m *metrics.Metrics
+		recorder := m.GetRecorder(metrics.BACKUP, "")
+		recordRun(recorder, nil)
+		assert.Equal(t, int64(3), recorder.GetSummary().APIRequests)
+	})
+
+	t.Run("Nil recorder", func(t *testing.T) {
+		recorder := metrics.Ctx(context.Background())
+		assert.Nil(t, recorder)
+		assert.NotPanics(t, func() {
+			recordRun(recorder, nil)
+		})
+		assert.Nil(t, recorder.GetSummary())
+	})
+
+	t.Run("Recorder in context", func(t *testing.T) {
+		recorder := metrics.GetMetrics().GetRecorder(metrics.BACKUP, "")
+		ctx := recorder.WithContext(context.Background())
+		assert.Same(t, recorder, metrics.Ctx(ctx))
+	})
+}
+
+func TestWriteTextfile(t *testing.T) {
+	m := metrics.GetMetrics()
+	recordRun(m.GetRecorder(metrics.BACKUP, "job"), nil)
+	recordRun(m.GetRecorder(metrics.BACKUP, "job"), errors.New("failed"))
+
+	path := filepath.Join(t.TempDir(), "notionbackup.prom")
+	err := m.WriteTextfile(path)
+	assert.Nil(t, err)
+
+	dataBytes, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	content := string(dataBytes)
+	for _, line := range []string{
+		`notionbackup_notion_api_requests_total{method="GetPageByID",` +
+			`status_code="200"} 2`,
+		`notionbackup_notion_api_retries_total{method="GetPageByID"} 2`,
+		`notionbackup_notion_api_request_duration_seconds_count{` +
+			`method="GetPageByID"} 4`,
+		`notionbackup_objects_written_total{rw="file"} 2`,
+		`notionbackup_bytes_stored_total{rw="file"} 200`,
+		`notionbackup_runs_total{job="job",operation="backup",` +
+			`outcome="success"} 1`,
+		`notionbackup_runs_total{job="job",operation="backup",` +
+			`outcome="failure"} 1`,
+		`notionbackup_run_duration_seconds_count{job="job",` +
+			`operation="backup",outcome="success"} 1`,
+	} {
+		assert.Contains(t, content, line)
+	}
+}
+
+func TestServe(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Free port is found by listening on port zero
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.Nil(t, err)
+	address := listener.Addr().String()
+	listener.Close()
+
+	m := metrics.GetMetrics()
+	recordRun(m.GetRecorder(metrics.BACKUP, ""), nil)
+	err = m.Serve(ctx, address)
+	assert.Nil(t, err)
+
+	err = m.Serve(ctx, address)
+	assert.NotNil(t, err)
+
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", address,
+		metrics.METRICS_PATH))
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	dataBytes, err := ioutil.ReadAll(resp.Body)
+	assert.Nil(t, err)
+	assert.True(t, strings.Contains(string(dataBytes),
+		"notionbackup_notion_api_requests_total"))
+}
@@ -68,6 +68,32 @@ func (_m *NotionClient) AppendBlocksToPage(_a0 context.Context, _a1 notionclient
 	return r0, r1
 }
 
+// CreateComment provides a mock function with given fields: _a0, _a1
+func (_m *NotionClient) CreateComment(_a0 context.Context, _a1 *notionapi.CommentCreateRequest) (*notionapi.Comment, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *notionapi.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *notionapi.CommentCreateRequest) (*notionapi.Comment, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *notionapi.CommentCreateRequest) *notionapi.Comment); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*notionapi.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *notionapi.CommentCreateRequest) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateDatabase provides a mock function with given fields: _a0, _a1
 func (_m *NotionClient) CreateDatabase(_a0 context.Context, _a1 *notionapi.DatabaseCreateRequest) (*notionapi.Database, error) {
 	ret := _m.Called(_a0, _a1)
@@ -245,6 +271,39 @@ func (_m *NotionClient) GetChildBlocksOfBlock(_a0 context.Context, _a1 notioncli
 	return r0, r1, r2
 }
 
+// GetComments provides a mock function with given fields: _a0, _a1, _a2
+func (_m *NotionClient) GetComments(_a0 context.Context, _a1 notionclient.BlockID, _a2 notionapi.Cursor) ([]notionapi.Comment, notionapi.Cursor, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 []notionapi.Comment
+	var r1 notionapi.Cursor
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.BlockID, notionapi.Cursor) ([]notionapi.Comment, notionapi.Cursor, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.BlockID, notionapi.Cursor) []notionapi.Comment); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]notionapi.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, notionclient.BlockID, notionapi.Cursor) notionapi.Cursor); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Get(1).(notionapi.Cursor)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, notionclient.BlockID, notionapi.Cursor) error); ok {
+		r2 = rf(_a0, _a1, _a2)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
 // GetDatabaseByID provides a mock function with given fields: _a0, _a1
 func (_m *NotionClient) GetDatabaseByID(_a0 context.Context, _a1 notionclient.DatabaseID) (*notionapi.Database, error) {
 	ret := _m.Called(_a0, _a1)
@@ -429,6 +488,84 @@ func (_m *NotionClient) GetPagesByName(_a0 context.Context, _a1 notionclient.Pag
 	return r0, r1, r2
 }
 
+// UpdateBlock provides a mock function with given fields: _a0, _a1, _a2
+func (_m *NotionClient) UpdateBlock(_a0 context.Context, _a1 notionclient.BlockID, _a2 *notionapi.BlockUpdateRequest) (notionapi.Block, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 notionapi.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.BlockID, *notionapi.BlockUpdateRequest) (notionapi.Block, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.BlockID, *notionapi.BlockUpdateRequest) notionapi.Block); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(notionapi.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, notionclient.BlockID, *notionapi.BlockUpdateRequest) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateDatabase provides a mock function with given fields: _a0, _a1, _a2
+func (_m *NotionClient) UpdateDatabase(_a0 context.Context, _a1 notionclient.DatabaseID, _a2 *notionapi.DatabaseUpdateRequest) (*notionapi.Database, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *notionapi.Database
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.DatabaseID, *notionapi.DatabaseUpdateRequest) (*notionapi.Database, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.DatabaseID, *notionapi.DatabaseUpdateRequest) *notionapi.Database); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*notionapi.Database)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, notionclient.DatabaseID, *notionapi.DatabaseUpdateRequest) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdatePage provides a mock function with given fields: _a0, _a1, _a2
+func (_m *NotionClient) UpdatePage(_a0 context.Context, _a1 notionclient.PageID, _a2 *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	ret := _m.Called(_a0, _a1, _a2)
+
+	var r0 *notionapi.Page
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.PageID, *notionapi.PageUpdateRequest) (*notionapi.Page, error)); ok {
+		return rf(_a0, _a1, _a2)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, notionclient.PageID, *notionapi.PageUpdateRequest) *notionapi.Page); ok {
+		r0 = rf(_a0, _a1, _a2)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*notionapi.Page)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, notionclient.PageID, *notionapi.PageUpdateRequest) error); ok {
+		r1 = rf(_a0, _a1, _a2)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewNotionClient interface {
 	mock.TestingT
 	Cleanup(func())
@@ -84,6 +84,32 @@ func (_m *ReaderWriter) ReadBlock(_a0 context.Context, _a1 rw.DataIdentifier) (n
 	return r0, r1
 }
 
+// ReadComment provides a mock function with given fields: _a0, _a1
+func (_m *ReaderWriter) ReadComment(_a0 context.Context, _a1 rw.DataIdentifier) (*notionapi.Comment, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 *notionapi.Comment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, rw.DataIdentifier) (*notionapi.Comment, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, rw.DataIdentifier) *notionapi.Comment); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*notionapi.Comment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, rw.DataIdentifier) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // ReadDatabase provides a mock function with given fields: _a0, _a1
 func (_m *ReaderWriter) ReadDatabase(_a0 context.Context, _a1 rw.DataIdentifier) (*notionapi.Database, error) {
 	ret := _m.Called(_a0, _a1)
@@ -136,6 +162,30 @@ func (_m *ReaderWriter) ReadPage(_a0 context.Context, _a1 rw.DataIdentifier) (*n
 	return r0, r1
 }
 
+// ReuseObject provides a mock function with given fields: _a0, _a1, _a2, _a3
+func (_m *ReaderWriter) ReuseObject(_a0 context.Context, _a1 rw.ReaderWriter, _a2 metadata.NotionObjectType, _a3 rw.DataIdentifier) (rw.DataIdentifier, error) {
+	ret := _m.Called(_a0, _a1, _a2, _a3)
+
+	var r0 rw.DataIdentifier
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, rw.ReaderWriter, metadata.NotionObjectType, rw.DataIdentifier) (rw.DataIdentifier, error)); ok {
+		return rf(_a0, _a1, _a2, _a3)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, rw.ReaderWriter, metadata.NotionObjectType, rw.DataIdentifier) rw.DataIdentifier); ok {
+		r0 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r0 = ret.Get(0).(rw.DataIdentifier)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, rw.ReaderWriter, metadata.NotionObjectType, rw.DataIdentifier) error); ok {
+		r1 = rf(_a0, _a1, _a2, _a3)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WriteBlock provides a mock function with given fields: _a0, _a1
 func (_m *ReaderWriter) WriteBlock(_a0 context.Context, _a1 notionapi.Block) (rw.DataIdentifier, error) {
 	ret := _m.Called(_a0, _a1)
@@ -160,6 +210,30 @@ func (_m *ReaderWriter) WriteBlock(_a0 context.Context, _a1 notionapi.Block) (rw
 	return r0, r1
 }
 
+// WriteComment provides a mock function with given fields: _a0, _a1
+func (_m *ReaderWriter) WriteComment(_a0 context.Context, _a1 *notionapi.Comment) (rw.DataIdentifier, error) {
+	ret := _m.Called(_a0, _a1)
+
+	var r0 rw.DataIdentifier
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *notionapi.Comment) (rw.DataIdentifier, error)); ok {
+		return rf(_a0, _a1)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *notionapi.Comment) rw.DataIdentifier); ok {
+		r0 = rf(_a0, _a1)
+	} else {
+		r0 = ret.Get(0).(rw.DataIdentifier)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *notionapi.Comment) error); ok {
+		r1 = rf(_a0, _a1)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // WriteDatabase provides a mock function with given fields: _a0, _a1
 func (_m *ReaderWriter) WriteDatabase(_a0 context.Context, _a1 *notionapi.Database) (rw.DataIdentifier, error) {
 	ret := _m.Called(_a0, _a1)
@@ -62,6 +62,21 @@ type NotionClient interface {
 	AppendBlocksToBlock(context.Context, BlockID,
 		*notionapi.AppendBlockChildrenRequest) (
 		*notionapi.AppendBlockChildrenResponse, error)
+
+	UpdatePage(context.Context, PageID, *notionapi.PageUpdateRequest) (
+		*notionapi.Page, error)
+
+	UpdateDatabase(context.Context, DatabaseID,
+		*notionapi.DatabaseUpdateRequest) (*notionapi.Database, error)
+
+	UpdateBlock(context.Context, BlockID, *notionapi.BlockUpdateRequest) (
+		notionapi.Block, error)
+
+	GetComments(context.Context, BlockID, notionapi.Cursor) ([]notionapi.Comment,
+		notionapi.Cursor, error)
+
+	CreateComment(context.Context, *notionapi.CommentCreateRequest) (
+		*notionapi.Comment, error)
 }
 
 type NotionApiClient struct {
@@ -70,9 +85,9 @@ type NotionApiClient struct {
 
 // Function to get NotionApiClient instance
 func GetNotionApiClient(ctx context.Context, token notionapi.Token,
-	newClient NewClient) NotionClient {
+	newClient NewClient, opts ...notionapi.ClientOption) NotionClient {
 	return &NotionApiClient{
-		Client: newClient(token),
+		Client: newClient(token, opts...),
 	}
 }
 
@@ -284,3 +299,54 @@ func (c *NotionApiClient) AppendBlocksToBlock(ctx context.Context,
 	*notionapi.AppendBlockChildrenResponse, error) {
 	return c.Client.Block.AppendChildren(ctx, notionapi.BlockID(blockID), req)
 }
+
+// Update properties of given page ID
+func (c *NotionApiClient) UpdatePage(ctx context.Context, pageID PageID,
+	req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	return c.Client.Page.Update(ctx, notionapi.PageID(pageID), req)
+}
+
+// Update property schema of given database ID
+func (c *NotionApiClient) UpdateDatabase(ctx context.Context,
+	databaseID DatabaseID, req *notionapi.DatabaseUpdateRequest) (
+	*notionapi.Database, error) {
+	return c.Client.Database.Update(ctx, notionapi.DatabaseID(databaseID), req)
+}
+
+// Update content of given block ID
+func (c *NotionApiClient) UpdateBlock(ctx context.Context, blockID BlockID,
+	req *notionapi.BlockUpdateRequest) (notionapi.Block, error) {
+	return c.Client.Block.Update(ctx, notionapi.BlockID(blockID), req)
+}
+
+// Get all unresolved comments of given page or block
+func (c *NotionApiClient) GetComments(ctx context.Context, id BlockID,
+	cursor notionapi.Cursor) ([]notionapi.Comment, notionapi.Cursor, error) {
+	pagination := &notionapi.Pagination{
+		StartCursor: cursor,
+		PageSize:    DEFAULT_PAGE_SIZE,
+	}
+
+	resp, err := c.Client.Comment.Get(ctx, notionapi.BlockID(id), pagination)
+	if err != nil {
+		return nil, "", err
+	}
+
+	comments := []notionapi.Comment{}
+	comments = append(comments, resp.Results...)
+
+	var newCursor notionapi.Cursor
+	if resp.HasMore {
+		newCursor = resp.NextCursor
+	} else {
+		newCursor = notionapi.Cursor("")
+	}
+
+	return comments, newCursor, nil
+}
+
+// Create a comment on a page or in an existing discussion
+func (c *NotionApiClient) CreateComment(ctx context.Context,
+	req *notionapi.CommentCreateRequest) (*notionapi.Comment, error) {
+	return c.Client.Comment.Create(ctx, req)
+}
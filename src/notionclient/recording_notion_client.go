@@ -0,0 +1,364 @@
+package notionclient
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+// Property of the restored object which Notion API would not accept
+type RejectedProperty struct {
+	Object   string `json:"object"`
+	Property string `json:"property"`
+	Type     string `json:"type"`
+	Reason   string `json:"reason"`
+}
+
+// Objects which would be created and API calls which would be sent by the
+// requests recorded by RecordingNotionClient
+type Recording struct {
+	Pages              int
+	Databases          int
+	Blocks             int
+	Comments           int
+	ApiCalls           map[string]int
+	RejectedProperties []RejectedProperty
+}
+
+// RecordingNotionClient records the requests to create objects instead of
+// sending them to Notion API. Created objects are returned with new IDs so that
+// restore can continue as if the objects were created
+type RecordingNotionClient struct {
+	recording   *Recording
+	childBlocks map[BlockID][]notionapi.Block
+}
+
+// Page and database properties which are computed by Notion and cannot be set
+var READ_ONLY_PROPERTY_TYPES = map[string]bool{
+	string(notionapi.PropertyTypeFormula):        true,
+	string(notionapi.PropertyTypeRollup):         true,
+	string(notionapi.PropertyTypeCreatedTime):    true,
+	string(notionapi.PropertyTypeCreatedBy):      true,
+	string(notionapi.PropertyTypeLastEditedTime): true,
+	string(notionapi.PropertyTypeLastEditedBy):   true,
+}
+
+func GetRecordingNotionClient() *RecordingNotionClient {
+	return &RecordingNotionClient{
+		recording: &Recording{
+			ApiCalls:           make(map[string]int),
+			RejectedProperties: []RejectedProperty{},
+		},
+		childBlocks: make(map[BlockID][]notionapi.Block),
+	}
+}
+
+// Get the requests recorded so far
+func (c *RecordingNotionClient) GetRecording() *Recording {
+	return c.recording
+}
+
+func (c *RecordingNotionClient) record(method string) {
+	c.recording.ApiCalls[method]++
+}
+
+func (c *RecordingNotionClient) rejectProperty(object string, property string,
+	propertyType string, reason string) {
+	c.recording.RejectedProperties = append(c.recording.RejectedProperties,
+		RejectedProperty{
+			Object:   object,
+			Property: property,
+			Type:     propertyType,
+			Reason:   reason,
+		})
+}
+
+// Helper function to check the properties of the page
+func (c *RecordingNotionClient) checkPageProperties(
+	properties notionapi.Properties) {
+	title := utils.GetPageTitle(&notionapi.Page{Properties: properties})
+	names := []string{}
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		propertyType := string(properties[name].GetType())
+		if READ_ONLY_PROPERTY_TYPES[propertyType] {
+			c.rejectProperty(title, name, propertyType,
+				"property is computed by Notion")
+			continue
+		}
+
+		filesProperty, ok := properties[name].(*notionapi.FilesProperty)
+		if !ok {
+			continue
+		}
+
+		for _, file := range filesProperty.Files {
+			if file.Type == notionapi.FileTypeFile {
+				c.rejectProperty(title, name, propertyType,
+					"files hosted by Notion cannot be added")
+				break
+			}
+		}
+	}
+}
+
+// Helper function to check the property schema of the database
+func (c *RecordingNotionClient) checkDatabaseProperties(title string,
+	properties notionapi.PropertyConfigs) {
+	names := []string{}
+	for name := range properties {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+	for _, name := range names {
+		propertyType := properties[name].GetType()
+
+		// Type of status property config is not set by notionapi
+		if _, ok := properties[name].(*notionapi.StatusPropertyConfig); ok {
+			propertyType = notionapi.PropertyConfigStatus
+		}
+
+		switch propertyType {
+		case notionapi.PropertyConfigStatus:
+			c.rejectProperty(title, name, string(propertyType),
+				"status property cannot be created")
+		case notionapi.PropertyConfigTypeRelation:
+			c.rejectProperty(title, name, string(propertyType),
+				"related database belongs to the backed up workspace")
+		case notionapi.PropertyConfigTypeRollup:
+			c.rejectProperty(title, name, string(propertyType),
+				"rollup depends on the relation to the backed up workspace")
+		}
+	}
+}
+
+// Helper function to count the blocks created along with their children in a
+// single request
+func countBlocks(blocks notionapi.Blocks) int {
+	count := 0
+	for _, block := range blocks {
+		count++
+		switch b := block.(type) {
+		case *notionapi.TableBlock:
+			count += countBlocks(b.Table.Children)
+		case *notionapi.ColumnListBlock:
+			count += countBlocks(b.ColumnList.Children)
+		case *notionapi.ColumnBlock:
+			count += countBlocks(b.Column.Children)
+		}
+	}
+	return count
+}
+
+// Helper function to create the blocks of the response with new IDs. Columns
+// of column lists are kept so that they can be read like Notion API
+func (c *RecordingNotionClient) appendBlocks(
+	req *notionapi.AppendBlockChildrenRequest) *notionapi.
+	AppendBlockChildrenResponse {
+	c.recording.Blocks += countBlocks(req.Children)
+
+	results := []notionapi.Block{}
+	for _, block := range req.Children {
+		createdBlock := &notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(uuid.NewString()),
+			Type:   block.GetType(),
+		}
+
+		if columnList, ok := block.(*notionapi.ColumnListBlock); ok {
+			columns := []notionapi.Block{}
+			for range columnList.ColumnList.Children {
+				columns = append(columns, &notionapi.BasicBlock{
+					Object: notionapi.ObjectTypeBlock,
+					ID:     notionapi.BlockID(uuid.NewString()),
+					Type:   notionapi.BlockTypeColumn,
+				})
+			}
+			c.childBlocks[BlockID(createdBlock.ID)] = columns
+		}
+
+		results = append(results, createdBlock)
+	}
+
+	return &notionapi.AppendBlockChildrenResponse{
+		Object:  notionapi.ObjectTypeList,
+		Results: results,
+	}
+}
+
+func (c *RecordingNotionClient) GetAllPages(ctx context.Context,
+	cursor notionapi.Cursor) ([]notionapi.Page, notionapi.Cursor, error) {
+	c.record("GetAllPages")
+	return nil, "", fmt.Errorf("searching pages is not recorded")
+}
+
+func (c *RecordingNotionClient) GetAllDatabases(ctx context.Context,
+	cursor notionapi.Cursor) ([]notionapi.Database, notionapi.Cursor, error) {
+	c.record("GetAllDatabases")
+	return nil, "", fmt.Errorf("searching databases is not recorded")
+}
+
+func (c *RecordingNotionClient) GetPagesByName(ctx context.Context,
+	name PageName, cursor notionapi.Cursor) ([]notionapi.Page, notionapi.Cursor,
+	error) {
+	c.record("GetPagesByName")
+	return nil, "", fmt.Errorf("searching pages is not recorded")
+}
+
+func (c *RecordingNotionClient) GetDatabasesByName(ctx context.Context,
+	name DatabaseName, cursor notionapi.Cursor) ([]notionapi.Database,
+	notionapi.Cursor, error) {
+	c.record("GetDatabasesByName")
+	return nil, "", fmt.Errorf("searching databases is not recorded")
+}
+
+func (c *RecordingNotionClient) GetPageByID(ctx context.Context,
+	id PageID) (*notionapi.Page, error) {
+	c.record("GetPageByID")
+	return nil, fmt.Errorf("reading page is not recorded")
+}
+
+func (c *RecordingNotionClient) GetDatabaseByID(ctx context.Context,
+	id DatabaseID) (*notionapi.Database, error) {
+	c.record("GetDatabaseByID")
+	return nil, fmt.Errorf("reading database is not recorded")
+}
+
+func (c *RecordingNotionClient) GetDatabasePages(ctx context.Context,
+	id DatabaseID, cursor notionapi.Cursor) ([]notionapi.Page, notionapi.Cursor,
+	error) {
+	c.record("GetDatabasePages")
+	return nil, "", fmt.Errorf("reading database pages is not recorded")
+}
+
+func (c *RecordingNotionClient) GetPageBlocks(ctx context.Context, id PageID,
+	cursor notionapi.Cursor) ([]notionapi.Block, notionapi.Cursor, error) {
+	c.record("GetPageBlocks")
+	return nil, "", fmt.Errorf("reading page blocks is not recorded")
+}
+
+// Only the children of the blocks created by this client can be read
+func (c *RecordingNotionClient) GetChildBlocksOfBlock(ctx context.Context,
+	id BlockID, cursor notionapi.Cursor) ([]notionapi.Block, notionapi.Cursor,
+	error) {
+	c.record("GetChildBlocksOfBlock")
+	children, found := c.childBlocks[id]
+	if !found {
+		return nil, "", fmt.Errorf("block %s was not created by the dry run", id)
+	}
+
+	return children, "", nil
+}
+
+func (c *RecordingNotionClient) GetBlockByID(ctx context.Context,
+	id BlockID) (notionapi.Block, error) {
+	c.record("GetBlockByID")
+	return nil, fmt.Errorf("reading block is not recorded")
+}
+
+func (c *RecordingNotionClient) CreatePage(ctx context.Context,
+	req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	c.record("CreatePage")
+	c.recording.Pages++
+	c.checkPageProperties(req.Properties)
+	c.recording.Blocks += countBlocks(req.Children)
+
+	return &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     notionapi.ObjectID(uuid.NewString()),
+		Parent: req.Parent,
+	}, nil
+}
+
+func (c *RecordingNotionClient) CreateDatabase(ctx context.Context,
+	req *notionapi.DatabaseCreateRequest) (*notionapi.Database, error) {
+	c.record("CreateDatabase")
+	c.recording.Databases++
+
+	c.checkDatabaseProperties(utils.GetPlainText(req.Title), req.Properties)
+
+	return &notionapi.Database{
+		Object: notionapi.ObjectTypeDatabase,
+		ID:     notionapi.ObjectID(uuid.NewString()),
+		Parent: req.Parent,
+	}, nil
+}
+
+func (c *RecordingNotionClient) AppendBlocksToPage(ctx context.Context,
+	pageID PageID, req *notionapi.AppendBlockChildrenRequest) (
+	*notionapi.AppendBlockChildrenResponse, error) {
+	c.record("AppendBlocksToPage")
+	return c.appendBlocks(req), nil
+}
+
+func (c *RecordingNotionClient) AppendBlocksToBlock(ctx context.Context,
+	blockID BlockID, req *notionapi.AppendBlockChildrenRequest) (
+	*notionapi.AppendBlockChildrenResponse, error) {
+	c.record("AppendBlocksToBlock")
+	return c.appendBlocks(req), nil
+}
+
+func (c *RecordingNotionClient) UpdatePage(ctx context.Context, pageID PageID,
+	req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	c.record("UpdatePage")
+	return &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     notionapi.ObjectID(pageID),
+	}, nil
+}
+
+func (c *RecordingNotionClient) UpdateDatabase(ctx context.Context,
+	databaseID DatabaseID, req *notionapi.DatabaseUpdateRequest) (
+	*notionapi.Database, error) {
+	c.record("UpdateDatabase")
+	return &notionapi.Database{
+		Object: notionapi.ObjectTypeDatabase,
+		ID:     notionapi.ObjectID(databaseID),
+	}, nil
+}
+
+func (c *RecordingNotionClient) UpdateBlock(ctx context.Context,
+	blockID BlockID, req *notionapi.BlockUpdateRequest) (notionapi.Block,
+	error) {
+	c.record("UpdateBlock")
+	return &notionapi.BasicBlock{
+		Object: notionapi.ObjectTypeBlock,
+		ID:     notionapi.BlockID(blockID),
+	}, nil
+}
+
+func (c *RecordingNotionClient) GetComments(ctx context.Context, id BlockID,
+	cursor notionapi.Cursor) ([]notionapi.Comment, notionapi.Cursor, error) {
+	c.record("GetComments")
+	return nil, "", fmt.Errorf("reading comments is not recorded")
+}
+
+// Comment created on a page starts a new discussion, otherwise it is added to
+// the discussion of the request
+func (c *RecordingNotionClient) CreateComment(ctx context.Context,
+	req *notionapi.CommentCreateRequest) (*notionapi.Comment, error) {
+	c.record("CreateComment")
+	c.recording.Comments++
+
+	discussionID := req.DiscussionID
+	if discussionID == "" {
+		discussionID = notionapi.DiscussionID(uuid.NewString())
+	}
+
+	return &notionapi.Comment{
+		Object:       notionapi.ObjectTypeComment,
+		ID:           notionapi.ObjectID(uuid.NewString()),
+		DiscussionID: discussionID,
+		Parent:       req.Parent,
+		RichText:     req.RichText,
+	}, nil
+}
@@ -0,0 +1,110 @@
+package notionclient_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordingNotionClient(t *testing.T) {
+	ctx := context.Background()
+	client := notionclient.GetRecordingNotionClient()
+
+	page, err := client.CreatePage(ctx, &notionapi.PageCreateRequest{
+		Properties: notionapi.Properties{
+			"Name": &notionapi.TitleProperty{
+				Type:  notionapi.PropertyTypeTitle,
+				Title: []notionapi.RichText{{PlainText: "Tasks"}},
+			},
+			"Total": &notionapi.FormulaProperty{
+				Type: notionapi.PropertyTypeFormula,
+			},
+			"Attachments": &notionapi.FilesProperty{
+				Type: notionapi.PropertyTypeFiles,
+				Files: []notionapi.File{{
+					Type: notionapi.FileTypeFile,
+				}},
+			},
+		},
+	})
+	assert.Nil(t, err)
+	assert.NotEmpty(t, page.ID)
+
+	_, err = client.CreateDatabase(ctx, &notionapi.DatabaseCreateRequest{
+		Title: []notionapi.RichText{{PlainText: "Projects"}},
+		Properties: notionapi.PropertyConfigs{
+			"Status": &notionapi.StatusPropertyConfig{},
+			"Name": &notionapi.TitlePropertyConfig{
+				Type: notionapi.PropertyConfigTypeTitle,
+			},
+		},
+	})
+	assert.Nil(t, err)
+
+	rsp, err := client.AppendBlocksToBlock(ctx, notionclient.BlockID(page.ID),
+		&notionapi.AppendBlockChildrenRequest{
+			Children: notionapi.Blocks{
+				&notionapi.ParagraphBlock{
+					BasicBlock: notionapi.BasicBlock{
+						Type: notionapi.BlockTypeParagraph,
+					},
+				},
+				&notionapi.ColumnListBlock{
+					BasicBlock: notionapi.BasicBlock{
+						Type: notionapi.BlockTypeColumnList,
+					},
+					ColumnList: notionapi.ColumnList{
+						Children: notionapi.Blocks{
+							&notionapi.ColumnBlock{}, &notionapi.ColumnBlock{},
+						},
+					},
+				},
+			},
+		})
+	assert.Nil(t, err)
+	assert.Len(t, rsp.Results, 2)
+	assert.Equal(t, notionapi.BlockTypeColumnList, rsp.Results[1].GetType())
+
+	// Columns of the created column list can be read back
+	columns, _, err := client.GetChildBlocksOfBlock(ctx,
+		notionclient.BlockID(rsp.Results[1].GetID()), "")
+	assert.Nil(t, err)
+	assert.Len(t, columns, 2)
+
+	_, _, err = client.GetChildBlocksOfBlock(ctx, "unknown_block_id", "")
+	assert.NotNil(t, err)
+
+	recording := client.GetRecording()
+	assert.Equal(t, 1, recording.Pages)
+	assert.Equal(t, 1, recording.Databases)
+	assert.Equal(t, 4, recording.Blocks)
+	assert.Equal(t, map[string]int{
+		"CreatePage":            1,
+		"CreateDatabase":        1,
+		"AppendBlocksToBlock":   1,
+		"GetChildBlocksOfBlock": 2,
+	}, recording.ApiCalls)
+	assert.Equal(t, []notionclient.RejectedProperty{
+		{
+			Object:   "Tasks",
+			Property: "Attachments",
+			Type:     string(notionapi.PropertyTypeFiles),
+			Reason:   "files hosted by Notion cannot be added",
+		},
+		{
+			Object:   "Tasks",
+			Property: "Total",
+			Type:     string(notionapi.PropertyTypeFormula),
+			Reason:   "property is computed by Notion",
+		},
+		{
+			Object:   "Projects",
+			Property: "Status",
+			Type:     string(notionapi.PropertyConfigStatus),
+			Reason:   "status property cannot be created",
+		},
+	}, recording.RejectedProperties)
+}
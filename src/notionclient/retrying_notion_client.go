@@ -0,0 +1,536 @@
+package notionclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/progress"
+	"golang.org/x/time/rate"
+)
+
+const (
+	DEFAULT_REQUESTS_PER_SECOND = 3
+	DEFAULT_MAX_RETRIES         = 5
+	DEFAULT_INITIAL_BACKOFF     = 500 * time.Millisecond
+	DEFAULT_MAX_BACKOFF         = 30 * time.Second
+)
+
+// Error returned by the retryable transport when Notion API responds with
+// a status code for which the request can be retried
+type TransientError struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("notion api responded with status code %d", e.StatusCode)
+}
+
+// Check if the status code indicates a transient failure on Notion side
+func isTransientStatusCode(statusCode int) bool {
+	switch statusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError,
+		http.StatusBadGateway, http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// Parse the Retry-After header which can either be delay in seconds or a HTTP
+// date. Zero duration is returned if header is missing or cannot be parsed
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay
+		}
+	}
+
+	return 0
+}
+
+// retryableTransport converts the responses with transient status codes into
+// TransientError so that the RetryingNotionClient can decide how long to wait
+// before retrying the request. Notion API returns non-JSON bodies for some of
+// these responses (e.g. 502 from load balancer) which notionapi fails to decode
+type retryableTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryableTransport) RoundTrip(req *http.Request) (*http.Response,
+	error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isTransientStatusCode(resp.StatusCode) {
+		return resp, nil
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return nil, &TransientError{
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+	}
+}
+
+// Client option for notionapi.Client which makes HTTP responses with transient
+// status codes available to RetryingNotionClient. If base is nil,
+// http.DefaultTransport is used
+func WithRetryableTransport(base http.RoundTripper) notionapi.ClientOption {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return notionapi.WithHTTPClient(&http.Client{
+		Transport: &retryableTransport{base: base},
+	})
+}
+
+type RetryConfig struct {
+	// Maximum number of retries for a single call. Zero disables retries
+	MaxRetries int
+
+	// Number of requests allowed per second. Zero or negative value disables
+	// rate limiting
+	RequestsPerSecond float64
+
+	// Delay before first retry which gets doubled with every retry
+	InitialBackoff time.Duration
+
+	// Upper bound for delay between two retries
+	MaxBackoff time.Duration
+}
+
+// RetryingNotionClient decorates NotionClient with token bucket rate limiter
+// and retries the calls which failed with transient errors
+type RetryingNotionClient struct {
+	client  NotionClient
+	limiter *rate.Limiter
+	config  RetryConfig
+}
+
+// Function to get RetryingNotionClient instance wrapping the given client
+func GetRetryingNotionClient(ctx context.Context, client NotionClient,
+	config *RetryConfig) NotionClient {
+	cfg := RetryConfig{
+		InitialBackoff: DEFAULT_INITIAL_BACKOFF,
+		MaxBackoff:     DEFAULT_MAX_BACKOFF,
+	}
+
+	if config != nil {
+		cfg.MaxRetries = config.MaxRetries
+		cfg.RequestsPerSecond = config.RequestsPerSecond
+		if config.InitialBackoff > 0 {
+			cfg.InitialBackoff = config.InitialBackoff
+		}
+		if config.MaxBackoff > 0 {
+			cfg.MaxBackoff = config.MaxBackoff
+		}
+	}
+
+	limiter := rate.NewLimiter(rate.Inf, 1)
+	if cfg.RequestsPerSecond > 0 {
+		burst := int(math.Ceil(cfg.RequestsPerSecond))
+		limiter = rate.NewLimiter(rate.Limit(cfg.RequestsPerSecond), burst)
+	}
+
+	return &RetryingNotionClient{
+		client:  client,
+		limiter: limiter,
+		config:  cfg,
+	}
+}
+
+// Check if the error is transient and return the delay requested by Notion API
+// if any
+func isTransientError(err error) (bool, time.Duration) {
+	var transientErr *TransientError
+	if errors.As(err, &transientErr) {
+		return true, transientErr.RetryAfter
+	}
+
+	var rateLimitedErr *notionapi.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return true, 0
+	}
+
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) {
+		return isTransientStatusCode(apiErr.Status), 0
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true, 0
+	}
+
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true, 0
+	}
+
+	return false, 0
+}
+
+// Check if the request was rejected before Notion API processed it. Only such
+// requests are retried for calls creating objects so that no duplicates are
+// created in Notion
+func isRateLimitedError(err error) bool {
+	var transientErr *TransientError
+	if errors.As(err, &transientErr) {
+		return transientErr.StatusCode == http.StatusTooManyRequests
+	}
+
+	var rateLimitedErr *notionapi.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return true
+	}
+
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status == http.StatusTooManyRequests
+	}
+
+	return false
+}
+
+// Get the status code with which Notion API responded to the request. Zero is
+// returned if no response was received
+func getStatusCode(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+
+	var transientErr *TransientError
+	if errors.As(err, &transientErr) {
+		return transientErr.StatusCode
+	}
+
+	var rateLimitedErr *notionapi.RateLimitedError
+	if errors.As(err, &rateLimitedErr) {
+		return http.StatusTooManyRequests
+	}
+
+	var apiErr *notionapi.Error
+	if errors.As(err, &apiErr) {
+		return apiErr.Status
+	}
+
+	return 0
+}
+
+// Exponential backoff with full jitter
+func (c *RetryingNotionClient) getBackoff(attempt int) time.Duration {
+	backoff := c.config.MaxBackoff
+	if attempt < 32 {
+		if exp := c.config.InitialBackoff << uint(attempt); exp > 0 &&
+			exp < backoff {
+			backoff = exp
+		}
+	}
+
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// Helper function which waits for rate limiter and calls the given function
+// until it succeeds, fails with non-transient error or retries are exhausted
+func (c *RetryingNotionClient) do(ctx context.Context, method string,
+	idempotent bool, call func() error) error {
+	log := zerolog.Ctx(ctx)
+	tracker := progress.Ctx(ctx)
+	recorder := metrics.Ctx(ctx)
+	attempt := 0
+	for {
+		err := c.limiter.Wait(ctx)
+		if err != nil {
+			return err
+		}
+
+		tracker.AddAPICall()
+		startTime := time.Now()
+		err = call()
+		recorder.ObserveAPIRequest(method, getStatusCode(err),
+			time.Since(startTime))
+		if err == nil {
+			return nil
+		}
+
+		transient, retryAfter := isTransientError(err)
+		if !transient || (!idempotent && !isRateLimitedError(err)) {
+			return err
+		}
+
+		if attempt >= c.config.MaxRetries {
+			log.Error().Err(err).Msgf("%s failed after %d retries", method, attempt)
+			return err
+		}
+
+		delay := retryAfter
+		if delay == 0 {
+			delay = c.getBackoff(attempt)
+		}
+
+		attempt++
+		tracker.AddRetry()
+		recorder.AddRetry(method)
+		log.Warn().Err(err).Msgf("%s failed with transient error. Retrying in %s "+
+			"(attempt %d of %d)", method, delay, attempt, c.config.MaxRetries)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *RetryingNotionClient) GetAllPages(ctx context.Context,
+	cursor notionapi.Cursor) ([]notionapi.Page, notionapi.Cursor, error) {
+	var pages []notionapi.Page
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetAllPages", true, func() error {
+		var err error
+		pages, newCursor, err = c.client.GetAllPages(ctx, cursor)
+		return err
+	})
+	return pages, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetAllDatabases(ctx context.Context,
+	cursor notionapi.Cursor) ([]notionapi.Database, notionapi.Cursor, error) {
+	var databases []notionapi.Database
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetAllDatabases", true, func() error {
+		var err error
+		databases, newCursor, err = c.client.GetAllDatabases(ctx, cursor)
+		return err
+	})
+	return databases, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetPagesByName(ctx context.Context,
+	name PageName, cursor notionapi.Cursor) ([]notionapi.Page,
+	notionapi.Cursor, error) {
+	var pages []notionapi.Page
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetPagesByName", true, func() error {
+		var err error
+		pages, newCursor, err = c.client.GetPagesByName(ctx, name, cursor)
+		return err
+	})
+	return pages, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetDatabasesByName(ctx context.Context,
+	name DatabaseName, cursor notionapi.Cursor) ([]notionapi.Database,
+	notionapi.Cursor, error) {
+	var databases []notionapi.Database
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetDatabasesByName", true, func() error {
+		var err error
+		databases, newCursor, err = c.client.GetDatabasesByName(ctx, name, cursor)
+		return err
+	})
+	return databases, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetPageByID(ctx context.Context,
+	id PageID) (*notionapi.Page, error) {
+	var page *notionapi.Page
+	err := c.do(ctx, "GetPageByID", true, func() error {
+		var err error
+		page, err = c.client.GetPageByID(ctx, id)
+		return err
+	})
+	return page, err
+}
+
+func (c *RetryingNotionClient) GetDatabaseByID(ctx context.Context,
+	id DatabaseID) (*notionapi.Database, error) {
+	var database *notionapi.Database
+	err := c.do(ctx, "GetDatabaseByID", true, func() error {
+		var err error
+		database, err = c.client.GetDatabaseByID(ctx, id)
+		return err
+	})
+	return database, err
+}
+
+func (c *RetryingNotionClient) GetDatabasePages(ctx context.Context,
+	id DatabaseID, cursor notionapi.Cursor) ([]notionapi.Page,
+	notionapi.Cursor, error) {
+	var pages []notionapi.Page
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetDatabasePages", true, func() error {
+		var err error
+		pages, newCursor, err = c.client.GetDatabasePages(ctx, id, cursor)
+		return err
+	})
+	return pages, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetPageBlocks(ctx context.Context, id PageID,
+	cursor notionapi.Cursor) ([]notionapi.Block, notionapi.Cursor, error) {
+	var blocks []notionapi.Block
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetPageBlocks", true, func() error {
+		var err error
+		blocks, newCursor, err = c.client.GetPageBlocks(ctx, id, cursor)
+		return err
+	})
+	return blocks, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetChildBlocksOfBlock(ctx context.Context,
+	id BlockID, cursor notionapi.Cursor) ([]notionapi.Block, notionapi.Cursor,
+	error) {
+	var blocks []notionapi.Block
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetChildBlocksOfBlock", true, func() error {
+		var err error
+		blocks, newCursor, err = c.client.GetChildBlocksOfBlock(ctx, id, cursor)
+		return err
+	})
+	return blocks, newCursor, err
+}
+
+func (c *RetryingNotionClient) GetBlockByID(ctx context.Context,
+	id BlockID) (notionapi.Block, error) {
+	var block notionapi.Block
+	err := c.do(ctx, "GetBlockByID", true, func() error {
+		var err error
+		block, err = c.client.GetBlockByID(ctx, id)
+		return err
+	})
+	return block, err
+}
+
+func (c *RetryingNotionClient) CreatePage(ctx context.Context,
+	req *notionapi.PageCreateRequest) (*notionapi.Page, error) {
+	var page *notionapi.Page
+	err := c.do(ctx, "CreatePage", false, func() error {
+		var err error
+		page, err = c.client.CreatePage(ctx, req)
+		return err
+	})
+	return page, err
+}
+
+func (c *RetryingNotionClient) CreateDatabase(ctx context.Context,
+	req *notionapi.DatabaseCreateRequest) (*notionapi.Database, error) {
+	var database *notionapi.Database
+	err := c.do(ctx, "CreateDatabase", false, func() error {
+		var err error
+		database, err = c.client.CreateDatabase(ctx, req)
+		return err
+	})
+	return database, err
+}
+
+func (c *RetryingNotionClient) AppendBlocksToPage(ctx context.Context,
+	pageID PageID, req *notionapi.AppendBlockChildrenRequest) (
+	*notionapi.AppendBlockChildrenResponse, error) {
+	var resp *notionapi.AppendBlockChildrenResponse
+	err := c.do(ctx, "AppendBlocksToPage", false, func() error {
+		var err error
+		resp, err = c.client.AppendBlocksToPage(ctx, pageID, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *RetryingNotionClient) AppendBlocksToBlock(ctx context.Context,
+	blockID BlockID, req *notionapi.AppendBlockChildrenRequest) (
+	*notionapi.AppendBlockChildrenResponse, error) {
+	var resp *notionapi.AppendBlockChildrenResponse
+	err := c.do(ctx, "AppendBlocksToBlock", false, func() error {
+		var err error
+		resp, err = c.client.AppendBlocksToBlock(ctx, blockID, req)
+		return err
+	})
+	return resp, err
+}
+
+func (c *RetryingNotionClient) UpdatePage(ctx context.Context, pageID PageID,
+	req *notionapi.PageUpdateRequest) (*notionapi.Page, error) {
+	var page *notionapi.Page
+	err := c.do(ctx, "UpdatePage", true, func() error {
+		var err error
+		page, err = c.client.UpdatePage(ctx, pageID, req)
+		return err
+	})
+	return page, err
+}
+
+// Updating the schema is not retried on ambiguous failures as adding a synced
+// relation again creates one more property in the related database
+func (c *RetryingNotionClient) UpdateDatabase(ctx context.Context,
+	databaseID DatabaseID, req *notionapi.DatabaseUpdateRequest) (
+	*notionapi.Database, error) {
+	var database *notionapi.Database
+	err := c.do(ctx, "UpdateDatabase", false, func() error {
+		var err error
+		database, err = c.client.UpdateDatabase(ctx, databaseID, req)
+		return err
+	})
+	return database, err
+}
+
+func (c *RetryingNotionClient) UpdateBlock(ctx context.Context,
+	blockID BlockID, req *notionapi.BlockUpdateRequest) (notionapi.Block,
+	error) {
+	var block notionapi.Block
+	err := c.do(ctx, "UpdateBlock", true, func() error {
+		var err error
+		block, err = c.client.UpdateBlock(ctx, blockID, req)
+		return err
+	})
+	return block, err
+}
+
+func (c *RetryingNotionClient) GetComments(ctx context.Context, id BlockID,
+	cursor notionapi.Cursor) ([]notionapi.Comment, notionapi.Cursor, error) {
+	var comments []notionapi.Comment
+	var newCursor notionapi.Cursor
+	err := c.do(ctx, "GetComments", true, func() error {
+		var err error
+		comments, newCursor, err = c.client.GetComments(ctx, id, cursor)
+		return err
+	})
+	return comments, newCursor, err
+}
+
+func (c *RetryingNotionClient) CreateComment(ctx context.Context,
+	req *notionapi.CommentCreateRequest) (*notionapi.Comment, error) {
+	var comment *notionapi.Comment
+	err := c.do(ctx, "CreateComment", false, func() error {
+		var err error
+		comment, err = c.client.CreateComment(ctx, req)
+		return err
+	})
+	return comment, err
+}
@@ -0,0 +1,232 @@
+package notionclient_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+// Transport which redirects all requests sent to Notion API to the test server
+type redirectTransport struct {
+	serverURL *url.URL
+}
+
+func (t *redirectTransport) RoundTrip(req *http.Request) (*http.Response,
+	error) {
+	req.URL.Scheme = t.serverURL.Scheme
+	req.URL.Host = t.serverURL.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// Creates test server which responds with given status codes in order. Once
+// all status codes are consumed, the given JSON file is returned with status
+// 200
+func getTestServer(t *testing.T, statusCodes []int, retryAfter string,
+	jsonFilePath string, requestCount *int32) *httptest.Server {
+	jsonBytes, err := ioutil.ReadFile(jsonFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			count := int(atomic.AddInt32(requestCount, 1))
+			if count <= len(statusCodes) {
+				if retryAfter != "" {
+					w.Header().Set("Retry-After", retryAfter)
+				}
+				w.WriteHeader(statusCodes[count-1])
+				w.Write([]byte("<html>error</html>"))
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			w.Write(jsonBytes)
+		}))
+}
+
+func getRetryingClient(t *testing.T, server *httptest.Server,
+	maxRetries int) notionclient.NotionClient {
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	apiClient := notionclient.GetNotionApiClient(context.Background(),
+		"mocked_token", notionapi.NewClient, notionclient.WithRetryableTransport(
+			&redirectTransport{serverURL: serverURL}))
+
+	return notionclient.GetRetryingNotionClient(context.Background(), apiClient,
+		&notionclient.RetryConfig{
+			MaxRetries:     maxRetries,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		})
+}
+
+func TestRetryingNotionClientGetPageByID(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCodes      []int
+		retryAfter       string
+		maxRetries       int
+		expectedRequests int32
+		wantErr          bool
+	}{
+		{
+			name:             "Successful without retries",
+			statusCodes:      []int{},
+			maxRetries:       3,
+			expectedRequests: 1,
+			wantErr:          false,
+		},
+		{
+			name: "Successful after transient errors",
+			statusCodes: []int{http.StatusBadGateway,
+				http.StatusServiceUnavailable, http.StatusInternalServerError},
+			maxRetries:       3,
+			expectedRequests: 4,
+			wantErr:          false,
+		},
+		{
+			name:             "Successful after rate limited with Retry-After",
+			statusCodes:      []int{http.StatusTooManyRequests},
+			retryAfter:       "1",
+			maxRetries:       3,
+			expectedRequests: 2,
+			wantErr:          false,
+		},
+		{
+			name: "Retries exhausted",
+			statusCodes: []int{http.StatusBadGateway, http.StatusBadGateway,
+				http.StatusBadGateway},
+			maxRetries:       2,
+			expectedRequests: 3,
+			wantErr:          true,
+		},
+		{
+			name:             "Non transient error is not retried",
+			statusCodes:      []int{http.StatusNotFound},
+			maxRetries:       3,
+			expectedRequests: 1,
+			wantErr:          true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestCount int32
+			server := getTestServer(t, test.statusCodes, test.retryAfter, PAGE_JSON,
+				&requestCount)
+			defer server.Close()
+
+			client := getRetryingClient(t, server, test.maxRetries)
+			tracker := progress.GetTracker(progress.BACKUP)
+			recorder := metrics.GetMetrics().GetRecorder(metrics.BACKUP, "")
+			ctx := recorder.WithContext(tracker.WithContext(context.Background()))
+			start := time.Now()
+			page, err := client.GetPageByID(ctx, "some_id")
+			if test.wantErr {
+				assert.Nil(t, page)
+				assert.NotNil(t, err)
+			} else {
+				assert.NotNil(t, page)
+				assert.Nil(t, err)
+			}
+
+			if test.retryAfter != "" {
+				assert.GreaterOrEqual(t, time.Since(start), time.Second)
+			}
+			assert.Equal(t, test.expectedRequests, atomic.LoadInt32(&requestCount))
+
+			status := tracker.GetStatus()
+			assert.Equal(t, int64(test.expectedRequests), status.APICalls)
+			assert.Equal(t, int64(test.expectedRequests-1), status.Retries)
+
+			totals := recorder.GetSummary().APIMethods["GetPageByID"]
+			assert.Equal(t, int64(test.expectedRequests), totals.Requests)
+			assert.Equal(t, int64(test.expectedRequests-1), totals.Retries)
+			for _, statusCode := range test.statusCodes {
+				if statusCode != http.StatusNotFound {
+					assert.Greater(t, totals.ByStatusCode[strconv.Itoa(statusCode)],
+						int64(0))
+				}
+			}
+			if !test.wantErr {
+				assert.Equal(t, int64(1), totals.ByStatusCode["200"])
+			}
+		})
+	}
+}
+
+func TestRetryingNotionClientCreatePage(t *testing.T) {
+	tests := []struct {
+		name             string
+		statusCodes      []int
+		expectedRequests int32
+		wantErr          bool
+	}{
+		{
+			name:             "Rate limited request is retried",
+			statusCodes:      []int{http.StatusTooManyRequests},
+			expectedRequests: 2,
+			wantErr:          false,
+		},
+		{
+			name:             "Server error is not retried",
+			statusCodes:      []int{http.StatusBadGateway},
+			expectedRequests: 1,
+			wantErr:          true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var requestCount int32
+			server := getTestServer(t, test.statusCodes, "", PAGE_JSON,
+				&requestCount)
+			defer server.Close()
+
+			client := getRetryingClient(t, server, 3)
+			page, err := client.CreatePage(context.Background(),
+				&notionapi.PageCreateRequest{})
+			if test.wantErr {
+				assert.Nil(t, page)
+				assert.NotNil(t, err)
+			} else {
+				assert.NotNil(t, page)
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, test.expectedRequests, atomic.LoadInt32(&requestCount))
+		})
+	}
+}
+
+func TestRetryingNotionClientContextCancelled(t *testing.T) {
+	var requestCount int32
+	server := getTestServer(t, []int{http.StatusTooManyRequests}, "30",
+		PAGE_JSON, &requestCount)
+	defer server.Close()
+
+	client := getRetryingClient(t, server, 3)
+	ctx, cancel := context.WithTimeout(context.Background(),
+		100*time.Millisecond)
+	defer cancel()
+
+	page, err := client.GetPageByID(ctx, "some_id")
+	assert.Nil(t, page)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requestCount))
+}
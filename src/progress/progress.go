@@ -0,0 +1,205 @@
+package progress
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+type ObjectType int
+
+const (
+	PAGES ObjectType = iota
+	DATABASES
+	BLOCKS
+
+	objectTypeCount
+)
+
+type Stage int
+
+const (
+	// Object is found while building the tree
+	DISCOVERED Stage = iota
+
+	// Children of the object are fetched from Notion or reused from the
+	// previous snapshot
+	FETCHED
+
+	// Object is written to the storage of the backup
+	WRITTEN
+
+	// Object is created in Notion while restoring
+	CREATED
+
+	// Object is not created while restoring, as it was created by previous
+	// attempt, is created along with its parent or cannot be restored
+	SKIPPED
+
+	stageCount
+)
+
+const (
+	BACKUP  = "backup"
+	RESTORE = "restore"
+)
+
+type trackerCtxKey struct{}
+
+// Tracker counts the progress of a backup or restore. Counters are updated
+// atomically, so the tracker can be shared by the workers building the tree
+// and the reporter. All the methods are no-op for nil tracker, so that the
+// code reporting the progress works without a tracker
+type Tracker struct {
+	operation    string
+	startTime    time.Time
+	counts       [objectTypeCount][stageCount]int64
+	apiCalls     int64
+	retries      int64
+	bytesWritten int64
+
+	// Number of objects to be processed, if known in advance
+	total int64
+}
+
+func GetTracker(operation string) *Tracker {
+	return &Tracker{
+		operation: operation,
+		startTime: time.Now(),
+	}
+}
+
+// Get the tracker associated with the context. Nil is returned if there is no
+// tracker associated with the context
+func Ctx(ctx context.Context) *Tracker {
+	tracker, _ := ctx.Value(trackerCtxKey{}).(*Tracker)
+	return tracker
+}
+
+// Get a copy of the context associated with the tracker
+func (t *Tracker) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, trackerCtxKey{}, t)
+}
+
+func (t *Tracker) Add(objectType ObjectType, stage Stage, count int) {
+	if t == nil {
+		return
+	}
+
+	atomic.AddInt64(&t.counts[objectType][stage], int64(count))
+}
+
+func (t *Tracker) AddAPICall() {
+	if t != nil {
+		atomic.AddInt64(&t.apiCalls, 1)
+	}
+}
+
+func (t *Tracker) AddRetry() {
+	if t != nil {
+		atomic.AddInt64(&t.retries, 1)
+	}
+}
+
+func (t *Tracker) AddBytesWritten(count int) {
+	if t != nil {
+		atomic.AddInt64(&t.bytesWritten, int64(count))
+	}
+}
+
+// Set the number of objects to be processed, with which the percentage and
+// the estimated time to finish are computed
+func (t *Tracker) SetTotal(total int) {
+	if t != nil {
+		atomic.StoreInt64(&t.total, int64(total))
+	}
+}
+
+// Counts of the objects of a type in each stage
+type ObjectCounts struct {
+	Discovered int64 `json:"discovered"`
+	Fetched    int64 `json:"fetched"`
+	Written    int64 `json:"written"`
+	Created    int64 `json:"created"`
+	Skipped    int64 `json:"skipped"`
+}
+
+// Point in time status of the tracker
+type Status struct {
+	Operation      string        `json:"operation"`
+	Elapsed        time.Duration `json:"-"`
+	ElapsedSeconds float64       `json:"elapsed_seconds"`
+	Pages          ObjectCounts  `json:"pages"`
+	Databases      ObjectCounts  `json:"databases"`
+	Blocks         ObjectCounts  `json:"blocks"`
+	APICalls       int64         `json:"api_calls"`
+	Retries        int64         `json:"retries"`
+	BytesWritten   int64         `json:"bytes_written"`
+
+	// Objects processed out of the total. Total of backup is the number of
+	// objects discovered so far, as size of the tree is not known in advance
+	Done    int64   `json:"done"`
+	Total   int64   `json:"total"`
+	Percent float64 `json:"percent"`
+
+	// Estimated time to finish, only known if the total is known in advance
+	ETA        time.Duration `json:"-"`
+	ETASeconds float64       `json:"eta_seconds,omitempty"`
+}
+
+func (t *Tracker) getObjectCounts(objectType ObjectType) ObjectCounts {
+	counts := &t.counts[objectType]
+	return ObjectCounts{
+		Discovered: atomic.LoadInt64(&counts[DISCOVERED]),
+		Fetched:    atomic.LoadInt64(&counts[FETCHED]),
+		Written:    atomic.LoadInt64(&counts[WRITTEN]),
+		Created:    atomic.LoadInt64(&counts[CREATED]),
+		Skipped:    atomic.LoadInt64(&counts[SKIPPED]),
+	}
+}
+
+// Get the current status of the tracker
+func (t *Tracker) GetStatus() *Status {
+	elapsed := time.Since(t.startTime)
+	status := &Status{
+		Operation:      t.operation,
+		Elapsed:        elapsed,
+		ElapsedSeconds: elapsed.Seconds(),
+		Pages:          t.getObjectCounts(PAGES),
+		Databases:      t.getObjectCounts(DATABASES),
+		Blocks:         t.getObjectCounts(BLOCKS),
+		APICalls:       atomic.LoadInt64(&t.apiCalls),
+		Retries:        atomic.LoadInt64(&t.retries),
+		BytesWritten:   atomic.LoadInt64(&t.bytesWritten),
+		Total:          atomic.LoadInt64(&t.total),
+	}
+
+	knownTotal := status.Total > 0
+	for _, counts := range []ObjectCounts{status.Pages, status.Databases,
+		status.Blocks} {
+		if knownTotal {
+			status.Done += counts.Created + counts.Skipped
+		} else {
+			status.Done += counts.Fetched
+			status.Total += counts.Discovered
+		}
+	}
+
+	// Objects created along with their parent may not be part of the total
+	if status.Done > status.Total {
+		status.Done = status.Total
+	}
+
+	if status.Total > 0 {
+		status.Percent = float64(status.Done) * 100 / float64(status.Total)
+	}
+
+	if knownTotal && status.Done > 0 {
+		remaining := status.Total - status.Done
+		status.ETA = time.Duration(float64(elapsed) / float64(status.Done) *
+			float64(remaining))
+		status.ETASeconds = status.ETA.Seconds()
+	}
+
+	return status
+}
@@ -0,0 +1,116 @@
+package progress_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/shivaji17/notionbackup/src/progress"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracker(t *testing.T) {
+	t.Run("Backup status", func(t *testing.T) {
+		tracker := progress.GetTracker(progress.BACKUP)
+		tracker.Add(progress.PAGES, progress.DISCOVERED, 2)
+		tracker.Add(progress.PAGES, progress.FETCHED, 1)
+		tracker.Add(progress.BLOCKS, progress.DISCOVERED, 2)
+		tracker.Add(progress.BLOCKS, progress.FETCHED, 2)
+		tracker.AddAPICall()
+		tracker.AddAPICall()
+		tracker.AddRetry()
+		tracker.AddBytesWritten(100)
+
+		status := tracker.GetStatus()
+		assert.Equal(t, progress.BACKUP, status.Operation)
+		assert.Equal(t, progress.ObjectCounts{Discovered: 2, Fetched: 1},
+			status.Pages)
+		assert.Equal(t, progress.ObjectCounts{Discovered: 2, Fetched: 2},
+			status.Blocks)
+		assert.Equal(t, int64(2), status.APICalls)
+		assert.Equal(t, int64(1), status.Retries)
+		assert.Equal(t, int64(100), status.BytesWritten)
+		assert.Equal(t, int64(3), status.Done)
+		assert.Equal(t, int64(4), status.Total)
+		assert.Equal(t, float64(75), status.Percent)
+		assert.Zero(t, status.ETA)
+	})
+
+	t.Run("Restore status", func(t *testing.T) {
+		tracker := progress.GetTracker(progress.RESTORE)
+		tracker.SetTotal(4)
+		tracker.Add(progress.PAGES, progress.CREATED, 1)
+		tracker.Add(progress.BLOCKS, progress.SKIPPED, 1)
+
+		status := tracker.GetStatus()
+		assert.Equal(t, int64(2), status.Done)
+		assert.Equal(t, int64(4), status.Total)
+		assert.Equal(t, float64(50), status.Percent)
+		assert.Greater(t, int64(status.ETA), int64(0))
+
+		// Done is capped at the total
+		tracker.Add(progress.BLOCKS, progress.CREATED, 5)
+		status = tracker.GetStatus()
+		assert.Equal(t, int64(4), status.Done)
+		assert.Equal(t, float64(100), status.Percent)
+		assert.Zero(t, status.ETA)
+	})
+
+	t.Run("Nil tracker", func(t *testing.T) {
+		tracker := progress.Ctx(context.Background())
+		assert.Nil(t, tracker)
+		assert.NotPanics(t, func() {
+			tracker.Add(progress.PAGES, progress.DISCOVERED, 1)
+			tracker.AddAPICall()
+			tracker.AddRetry()
+			tracker.AddBytesWritten(1)
+			tracker.SetTotal(1)
+		})
+	})
+
+	t.Run("Tracker in context", func(t *testing.T) {
+		tracker := progress.GetTracker(progress.BACKUP)
+		ctx := tracker.WithContext(context.Background())
+		assert.Same(t, tracker, progress.Ctx(ctx))
+	})
+}
+
+func TestReporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "progress.log")
+	file, err := os.Create(path)
+	assert.Nil(t, err)
+	defer file.Close()
+
+	tracker := progress.GetTracker(progress.BACKUP)
+	tracker.Add(progress.PAGES, progress.DISCOVERED, 1)
+	tracker.Add(progress.PAGES, progress.FETCHED, 1)
+
+	reporter := progress.StartReporter(tracker, file)
+	reporter.Stop()
+	reporter.Stop()
+
+	dataBytes, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	lines := strings.Split(strings.TrimSpace(string(dataBytes)), "\n")
+	assert.Len(t, lines, 1)
+
+	status := &progress.Status{}
+	err = json.Unmarshal([]byte(lines[0]), status)
+	assert.Nil(t, err)
+	assert.Equal(t, progress.BACKUP, status.Operation)
+	assert.Equal(t, int64(1), status.Pages.Fetched)
+	assert.Equal(t, float64(100), status.Percent)
+}
+
+func TestClearLineWriter(t *testing.T) {
+	out := &bytes.Buffer{}
+	n, err := progress.GetClearLineWriter(out).Write([]byte("log\n"))
+	assert.Nil(t, err)
+	assert.Equal(t, 4, n)
+	assert.Equal(t, progress.CLEAR_LINE+"log\n", out.String())
+}
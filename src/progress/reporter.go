@@ -0,0 +1,178 @@
+package progress
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	BAR_WIDTH = 30
+
+	TERMINAL_REFRESH_INTERVAL = 200 * time.Millisecond
+	LOG_INTERVAL              = 10 * time.Second
+
+	// Carriage return followed by the escape sequence erasing the line
+	CLEAR_LINE = "\r\033[K"
+)
+
+// Reporter periodically reports the status of the tracker. Live progress bar
+// is drawn on terminals, otherwise the status is written as JSON lines
+type Reporter struct {
+	tracker  *Tracker
+	out      io.Writer
+	terminal bool
+	interval time.Duration
+	done     chan struct{}
+	wg       sync.WaitGroup
+	once     sync.Once
+}
+
+// Start reporting the progress of the tracker to the given file until Stop is
+// called
+func StartReporter(tracker *Tracker, out *os.File) *Reporter {
+	terminal := term.IsTerminal(int(out.Fd()))
+	interval := LOG_INTERVAL
+	if terminal {
+		interval = TERMINAL_REFRESH_INTERVAL
+	}
+
+	return startReporter(tracker, out, terminal, interval)
+}
+
+func startReporter(tracker *Tracker, out io.Writer, terminal bool,
+	interval time.Duration) *Reporter {
+	r := &Reporter{
+		tracker:  tracker,
+		out:      out,
+		terminal: terminal,
+		interval: interval,
+		done:     make(chan struct{}),
+	}
+
+	r.wg.Add(1)
+	go r.run()
+	return r
+}
+
+func (r *Reporter) run() {
+	defer r.wg.Done()
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.report()
+		case <-r.done:
+			return
+		}
+	}
+}
+
+func (r *Reporter) report() {
+	status := r.tracker.GetStatus()
+	if r.terminal {
+		// Line is cleared first as it may be longer than the new line
+		fmt.Fprintf(r.out, "%s%s", CLEAR_LINE, formatProgressBar(status))
+		return
+	}
+
+	dataBytes, err := json.Marshal(status)
+	if err == nil {
+		fmt.Fprintf(r.out, "%s\n", dataBytes)
+	}
+}
+
+// Stop reporting after reporting the final status. Stop can be called more
+// than once
+func (r *Reporter) Stop() {
+	r.once.Do(func() {
+		close(r.done)
+		r.wg.Wait()
+		r.report()
+		if r.terminal {
+			fmt.Fprintln(r.out)
+		}
+	})
+}
+
+// Writer clearing the line of the progress bar before writing to the terminal,
+// so that the logs are not appended to the progress bar. Progress bar is drawn
+// again below the logs on the next refresh
+type ClearLineWriter struct {
+	out io.Writer
+}
+
+func GetClearLineWriter(out io.Writer) *ClearLineWriter {
+	return &ClearLineWriter{out: out}
+}
+
+func (w *ClearLineWriter) Write(p []byte) (int, error) {
+	// Written with a single call so that the progress bar is not drawn between
+	// clearing the line and writing the logs
+	_, err := w.out.Write(append([]byte(CLEAR_LINE), p...))
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Helper function to format the byte count with binary units
+func formatBytes(count int64) string {
+	const unit = 1024
+	if count < unit {
+		return fmt.Sprintf("%d B", count)
+	}
+
+	div, exp := int64(unit), 0
+	for n := count / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(count)/float64(div),
+		"KMGTPE"[exp])
+}
+
+// Helper function to format the duration as [h:]mm:ss
+func formatDuration(duration time.Duration) string {
+	seconds := int64(duration.Round(time.Second).Seconds())
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60,
+			seconds%60)
+	}
+
+	return fmt.Sprintf("%02d:%02d", seconds/60, seconds%60)
+}
+
+// Helper function to format the status as a single line progress bar
+func formatProgressBar(status *Status) string {
+	filled := 0
+	if status.Total > 0 {
+		filled = int(status.Done * BAR_WIDTH / status.Total)
+	}
+
+	bar := strings.Repeat("=", filled) + strings.Repeat(" ", BAR_WIDTH-filled)
+	line := fmt.Sprintf("%s [%s] %3.0f%% %d/%d objects | %d pages %d "+
+		"databases %d blocks | %d API calls %d retries | %s | %s",
+		status.Operation, bar, status.Percent, status.Done, status.Total,
+		status.Pages.Discovered+status.Pages.Created,
+		status.Databases.Discovered+status.Databases.Created,
+		status.Blocks.Discovered+status.Blocks.Created, status.APICalls,
+		status.Retries, formatBytes(status.BytesWritten),
+		formatDuration(status.Elapsed))
+
+	if status.ETA > 0 {
+		line += " ETA " + formatDuration(status.ETA)
+	}
+
+	return line
+}
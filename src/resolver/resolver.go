@@ -0,0 +1,199 @@
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+// Mode in which the titles of the search results are matched with the name
+type MatchMode string
+
+const (
+	EXACT_MATCH  MatchMode = "exact"
+	PREFIX_MATCH MatchMode = "prefix"
+	REGEX_MATCH  MatchMode = "regex"
+
+	PAGE_OBJECT     = "Page"
+	DATABASE_OBJECT = "Database"
+)
+
+// Check if the match mode is supported
+func (m MatchMode) IsValid() bool {
+	return m == EXACT_MATCH || m == PREFIX_MATCH || m == REGEX_MATCH
+}
+
+// Page or Database whose title matched the name
+type Candidate struct {
+	Id    string
+	Title string
+	URL   string
+}
+
+// Error returned when more than one Page or Database matches the name
+type AmbiguousMatchError struct {
+	ObjectType string
+	Name       string
+	Candidates []Candidate
+}
+
+func (e *AmbiguousMatchError) Error() string {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "%d %ss match '%s', use the ID or URL of one of:",
+		len(e.Candidates), strings.ToLower(e.ObjectType), e.Name)
+	for _, candidate := range e.Candidates {
+		fmt.Fprintf(&builder, "\n  %s  %s  %s", candidate.Id, candidate.Title,
+			candidate.URL)
+	}
+
+	return builder.String()
+}
+
+// Resolver resolves the names of the Pages and Databases to their IDs through
+// the search API of Notion
+type Resolver struct {
+	client notionclient.NotionClient
+	mode   MatchMode
+}
+
+func GetResolver(client notionclient.NotionClient, mode MatchMode) *Resolver {
+	return &Resolver{
+		client: client,
+		mode:   mode,
+	}
+}
+
+// Helper function to get the matcher of the titles with the name
+func (r *Resolver) getMatcher(name string) (func(string) bool, error) {
+	switch r.mode {
+	case EXACT_MATCH:
+		return func(title string) bool { return title == name }, nil
+	case PREFIX_MATCH:
+		return func(title string) bool {
+			return strings.HasPrefix(title, name)
+		}, nil
+	case REGEX_MATCH:
+		regex, err := regexp.Compile(name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid title regex: %s", err)
+		}
+		return regex.MatchString, nil
+	}
+
+	return nil, fmt.Errorf("unsupported match mode: %s", r.mode)
+}
+
+// Helper function to get the query sent to the search API. Search API matches
+// the query anywhere in the title, so all the objects are searched for regex
+func (r *Resolver) getQuery(name string) string {
+	if r.mode == REGEX_MATCH {
+		return ""
+	}
+
+	return name
+}
+
+// Helper function to get the ID of the only candidate
+func getCandidateId(objectType string, name string,
+	candidates []Candidate) (string, error) {
+	if len(candidates) == 0 {
+		return "", fmt.Errorf("no %s matches '%s'", strings.ToLower(objectType),
+			name)
+	}
+
+	if len(candidates) > 1 {
+		return "", &AmbiguousMatchError{
+			ObjectType: objectType,
+			Name:       name,
+			Candidates: candidates,
+		}
+	}
+
+	return candidates[0].Id, nil
+}
+
+// Get the ID of the Page whose title matches the name. Error is returned if
+// no Page or more than one Page matches the name
+func (r *Resolver) ResolvePage(ctx context.Context, name string) (string,
+	error) {
+	matches, err := r.getMatcher(name)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []Candidate{}
+	cursor := notionapi.Cursor("")
+	for {
+		pages, newCursor, err := r.client.GetPagesByName(ctx,
+			notionclient.PageName(r.getQuery(name)), cursor)
+		if err != nil {
+			return "", err
+		}
+
+		for _, page := range pages {
+			title := utils.GetPageTitle(&page)
+			if matches(title) {
+				candidates = append(candidates, Candidate{
+					Id:    page.ID.String(),
+					Title: title,
+					URL:   page.URL,
+				})
+			}
+		}
+
+		if newCursor == "" {
+			break
+		}
+		cursor = newCursor
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Found %d pages matching '%s'",
+		len(candidates), name)
+	return getCandidateId(PAGE_OBJECT, name, candidates)
+}
+
+// Get the ID of the Database whose title matches the name. Error is returned
+// if no Database or more than one Database matches the name
+func (r *Resolver) ResolveDatabase(ctx context.Context, name string) (string,
+	error) {
+	matches, err := r.getMatcher(name)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []Candidate{}
+	cursor := notionapi.Cursor("")
+	for {
+		databases, newCursor, err := r.client.GetDatabasesByName(ctx,
+			notionclient.DatabaseName(r.getQuery(name)), cursor)
+		if err != nil {
+			return "", err
+		}
+
+		for _, database := range databases {
+			title := utils.GetDatabaseTitle(&database)
+			if matches(title) {
+				candidates = append(candidates, Candidate{
+					Id:    database.ID.String(),
+					Title: title,
+					URL:   database.URL,
+				})
+			}
+		}
+
+		if newCursor == "" {
+			break
+		}
+		cursor = newCursor
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Found %d databases matching '%s'",
+		len(candidates), name)
+	return getCandidateId(DATABASE_OBJECT, name, candidates)
+}
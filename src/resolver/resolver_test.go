@@ -0,0 +1,146 @@
+package resolver_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/mocks"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/resolver"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const NEXT_CURSOR = notionapi.Cursor("next")
+
+func getPage(id string, title string) notionapi.Page {
+	return notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     notionapi.ObjectID(id),
+		URL:    "https://www.notion.so/" + id,
+		Properties: notionapi.Properties{
+			"title": &notionapi.TitleProperty{
+				Type:  notionapi.PropertyTypeTitle,
+				Title: []notionapi.RichText{{PlainText: title}},
+			},
+		},
+	}
+}
+
+func getDatabase(id string, title string) notionapi.Database {
+	return notionapi.Database{
+		Object: notionapi.ObjectTypeDatabase,
+		ID:     notionapi.ObjectID(id),
+		Title:  []notionapi.RichText{{PlainText: title}},
+	}
+}
+
+func TestResolvePage(t *testing.T) {
+	notesId := uuid.NewString()
+	notesArchiveId := uuid.NewString()
+	meetingNotesId := uuid.NewString()
+
+	// Search results are returned in two batches
+	getMockedClient := func(t *testing.T, query string) *mocks.NotionClient {
+		mockedClient := mocks.NewNotionClient(t)
+		mockedClient.On("GetPagesByName", mock.Anything,
+			notionclient.PageName(query), notionapi.Cursor("")).Return(
+			[]notionapi.Page{getPage(notesId, "Notes"),
+				getPage(notesArchiveId, "Notes Archive")}, NEXT_CURSOR, nil)
+		mockedClient.On("GetPagesByName", mock.Anything,
+			notionclient.PageName(query), NEXT_CURSOR).Return(
+			[]notionapi.Page{getPage(meetingNotesId, "Meeting Notes")},
+			notionapi.Cursor(""), nil)
+		return mockedClient
+	}
+
+	tests := []struct {
+		name       string
+		mode       resolver.MatchMode
+		pageName   string
+		query      string
+		expectedId string
+		candidates int
+	}{
+		{name: "Exact match", mode: resolver.EXACT_MATCH, pageName: "Notes",
+			query: "Notes", expectedId: notesId},
+		{name: "Prefix match", mode: resolver.PREFIX_MATCH,
+			pageName: "Notes Arch", query: "Notes Arch",
+			expectedId: notesArchiveId},
+		{name: "Regex match", mode: resolver.REGEX_MATCH,
+			pageName: "^Meeting", query: "", expectedId: meetingNotesId},
+		{name: "Ambiguous match", mode: resolver.PREFIX_MATCH,
+			pageName: "Notes", query: "Notes", candidates: 2},
+		{name: "Ambiguous regex match", mode: resolver.REGEX_MATCH,
+			pageName: "Notes$", query: "", candidates: 2},
+		{name: "No match", mode: resolver.EXACT_MATCH, pageName: "Note",
+			query: "Note"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			pageResolver := resolver.GetResolver(getMockedClient(t, test.query),
+				test.mode)
+			id, err := pageResolver.ResolvePage(context.Background(),
+				test.pageName)
+			if test.expectedId != "" {
+				assert.Nil(t, err)
+				assert.Equal(t, test.expectedId, id)
+				return
+			}
+
+			assert.NotNil(t, err)
+			ambiguousErr := &resolver.AmbiguousMatchError{}
+			if test.candidates == 0 {
+				assert.False(t, errors.As(err, &ambiguousErr))
+				return
+			}
+
+			assert.True(t, errors.As(err, &ambiguousErr))
+			assert.Len(t, ambiguousErr.Candidates, test.candidates)
+			for _, candidate := range ambiguousErr.Candidates {
+				assert.Contains(t, err.Error(), candidate.Id)
+			}
+		})
+	}
+
+	t.Run("Invalid regex", func(t *testing.T) {
+		pageResolver := resolver.GetResolver(mocks.NewNotionClient(t),
+			resolver.REGEX_MATCH)
+		_, err := pageResolver.ResolvePage(context.Background(), "(Notes")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Error while searching", func(t *testing.T) {
+		mockedClient := mocks.NewNotionClient(t)
+		mockedClient.On("GetPagesByName", mock.Anything,
+			notionclient.PageName("Notes"), notionapi.Cursor("")).Return(nil,
+			notionapi.Cursor(""), errors.New("error occurred"))
+		pageResolver := resolver.GetResolver(mockedClient, resolver.EXACT_MATCH)
+		_, err := pageResolver.ResolvePage(context.Background(), "Notes")
+		assert.NotNil(t, err)
+	})
+}
+
+func TestResolveDatabase(t *testing.T) {
+	tasksId := uuid.NewString()
+	mockedClient := mocks.NewNotionClient(t)
+	mockedClient.On("GetDatabasesByName", mock.Anything,
+		notionclient.DatabaseName("Tasks"), notionapi.Cursor("")).Return(
+		[]notionapi.Database{getDatabase(tasksId, "Tasks"),
+			getDatabase(uuid.NewString(), "Tasks Archive")},
+		notionapi.Cursor(""), nil)
+
+	databaseResolver := resolver.GetResolver(mockedClient, resolver.EXACT_MATCH)
+	id, err := databaseResolver.ResolveDatabase(context.Background(), "Tasks")
+	assert.Nil(t, err)
+	assert.Equal(t, tasksId, id)
+}
+
+func TestMatchMode(t *testing.T) {
+	assert.True(t, resolver.PREFIX_MATCH.IsValid())
+	assert.False(t, resolver.MatchMode("fuzzy").IsValid())
+}
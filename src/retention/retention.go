@@ -0,0 +1,191 @@
+package retention
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+const (
+	DAILY_REASON   = "daily"
+	WEEKLY_REASON  = "weekly"
+	MONTHLY_REASON = "monthly"
+
+	PARENT_REASON_FORMAT = "parent of %s"
+	CREATED_AT_FORMAT    = "2006-01-02 15:04:05 MST"
+)
+
+// Grandfather-father-son retention policy. Newest snapshot of each of the last
+// KeepDaily days, KeepWeekly weeks and KeepMonthly months having a snapshot is
+// kept
+type Policy struct {
+	KeepDaily   int
+	KeepWeekly  int
+	KeepMonthly int
+}
+
+// Check if the policy keeps any snapshot at all
+func (p *Policy) IsEmpty() bool {
+	return p.KeepDaily <= 0 && p.KeepWeekly <= 0 && p.KeepMonthly <= 0
+}
+
+// Snapshot of the backup found in the root directory of the snapshots
+type Snapshot struct {
+	// Directory or archive of the snapshot which is removed while pruning
+	Path string `json:"path"`
+
+	// Metadata file path or archive path with which incremental snapshots
+	// refer to this snapshot
+	MetadataFilePath   string    `json:"metadata_file_path"`
+	SnapshotId         string    `json:"snapshot_id"`
+	CreatedAt          time.Time `json:"created_at"`
+	ParentSnapshotId   string    `json:"parent_snapshot_id,omitempty"`
+	ParentSnapshotPath string    `json:"parent_snapshot_path,omitempty"`
+
+	Keep    bool     `json:"keep"`
+	Reasons []string `json:"reasons"`
+}
+
+// Get the name with which the snapshot is referred in the reasons. Snapshots
+// taken by older versions do not have an ID
+func (s *Snapshot) GetName() string {
+	if s.SnapshotId != "" {
+		return s.SnapshotId
+	}
+
+	return s.Path
+}
+
+func (s *Snapshot) keep(reason string) {
+	s.Keep = true
+	s.Reasons = append(s.Reasons, reason)
+}
+
+// Rule keeping the newest snapshot of each of the last count periods. Period
+// of the snapshot is identified by the key derived from its creation time
+type rule struct {
+	reason string
+	count  int
+	getKey func(time.Time) string
+}
+
+func (p *Policy) getRules() []rule {
+	return []rule{
+		{DAILY_REASON, p.KeepDaily, func(t time.Time) string {
+			return t.Format("2006-01-02")
+		}},
+		{WEEKLY_REASON, p.KeepWeekly, func(t time.Time) string {
+			year, week := t.ISOWeek()
+			return fmt.Sprintf("%d-W%02d", year, week)
+		}},
+		{MONTHLY_REASON, p.KeepMonthly, func(t time.Time) string {
+			return t.Format("2006-01")
+		}},
+	}
+}
+
+// Helper function to keep the parents of the kept incremental snapshots, as
+// they cannot be removed while an incremental snapshot depends on them
+func keepParents(snapshots []*Snapshot) {
+	snapshotMap := make(map[string][]*Snapshot)
+	for _, snapshot := range snapshots {
+		if snapshot.SnapshotId != "" {
+			snapshotMap[snapshot.SnapshotId] = append(
+				snapshotMap[snapshot.SnapshotId], snapshot)
+		}
+		snapshotMap[snapshot.MetadataFilePath] = append(
+			snapshotMap[snapshot.MetadataFilePath], snapshot)
+		snapshotMap[snapshot.Path] = append(snapshotMap[snapshot.Path],
+			snapshot)
+	}
+
+	queue := []*Snapshot{}
+	for _, snapshot := range snapshots {
+		if snapshot.Keep {
+			queue = append(queue, snapshot)
+		}
+	}
+
+	for len(queue) != 0 {
+		snapshot := queue[0]
+		queue = queue[1:]
+
+		// Snapshots taken by older versions only record the path of the parent
+		parentKey := snapshot.ParentSnapshotId
+		if parentKey == "" {
+			parentKey = snapshot.ParentSnapshotPath
+		}
+
+		if parentKey == "" {
+			continue
+		}
+
+		for _, parent := range snapshotMap[parentKey] {
+			if parent.Keep {
+				continue
+			}
+
+			parent.keep(fmt.Sprintf(PARENT_REASON_FORMAT, snapshot.GetName()))
+			queue = append(queue, parent)
+		}
+	}
+}
+
+// Mark the snapshots kept by the policy along with the reasons for keeping
+// them. Rest of the snapshots can be removed
+func (p *Policy) Apply(snapshots []*Snapshot) {
+	sorted := make([]*Snapshot, len(snapshots))
+	copy(sorted, snapshots)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+	})
+
+	for _, snapshot := range sorted {
+		snapshot.Keep = false
+		snapshot.Reasons = []string{}
+	}
+
+	for _, rule := range p.getRules() {
+		kept := 0
+		lastKey := ""
+		for _, snapshot := range sorted {
+			if kept >= rule.count {
+				break
+			}
+
+			key := rule.getKey(snapshot.CreatedAt.Local())
+			if key == lastKey {
+				continue
+			}
+
+			snapshot.keep(rule.reason)
+			lastKey = key
+			kept++
+		}
+	}
+
+	keepParents(sorted)
+}
+
+// Write the snapshots with the action taken on them as a table readable in
+// terminal
+func WriteTable(out io.Writer, snapshots []*Snapshot) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "SNAPSHOT\tID\tCREATED\tACTION\tREASONS")
+	for _, snapshot := range snapshots {
+		action := "remove"
+		if snapshot.Keep {
+			action = "keep"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", snapshot.Path,
+			snapshot.SnapshotId, snapshot.CreatedAt.Local().Format(
+				CREATED_AT_FORMAT), action, strings.Join(snapshot.Reasons, ", "))
+	}
+
+	return w.Flush()
+}
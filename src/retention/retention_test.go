@@ -0,0 +1,104 @@
+package retention_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/retention"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to create a snapshot for every day, from newest to oldest,
+// taken at noon so that the day does not change with the local time zone
+func getDailySnapshots(days int) []*retention.Snapshot {
+	newest := time.Date(2022, 5, 31, 12, 0, 0, 0, time.UTC)
+	snapshots := []*retention.Snapshot{}
+	for i := 0; i < days; i++ {
+		createdAt := newest.AddDate(0, 0, -i)
+		path := "/backups/" + createdAt.Format("2006-01-02")
+		snapshots = append(snapshots, &retention.Snapshot{
+			Path:             path,
+			MetadataFilePath: path + "/metadata.pb",
+			SnapshotId:       fmt.Sprintf("snapshot_%d", i),
+			CreatedAt:        createdAt,
+		})
+	}
+
+	return snapshots
+}
+
+func getKeptPaths(snapshots []*retention.Snapshot) []string {
+	paths := []string{}
+	for _, snapshot := range snapshots {
+		if snapshot.Keep {
+			paths = append(paths, snapshot.Path)
+		}
+	}
+
+	return paths
+}
+
+func TestApply(t *testing.T) {
+	t.Run("Grandfather-father-son", func(t *testing.T) {
+		snapshots := getDailySnapshots(100)
+		policy := &retention.Policy{KeepDaily: 3, KeepWeekly: 2, KeepMonthly: 3}
+		policy.Apply(snapshots)
+
+		// 2022-05-29 is the last day of the week before the week of 2022-05-31
+		assert.Equal(t, []string{
+			"/backups/2022-05-31",
+			"/backups/2022-05-30",
+			"/backups/2022-05-29",
+			"/backups/2022-04-30",
+			"/backups/2022-03-31",
+		}, getKeptPaths(snapshots))
+		assert.Equal(t, []string{retention.DAILY_REASON, retention.WEEKLY_REASON,
+			retention.MONTHLY_REASON}, snapshots[0].Reasons)
+		assert.Equal(t, []string{retention.DAILY_REASON,
+			retention.WEEKLY_REASON}, snapshots[2].Reasons)
+	})
+
+	t.Run("Multiple snapshots on the same day", func(t *testing.T) {
+		snapshots := getDailySnapshots(2)
+		newer := &retention.Snapshot{
+			Path:      "/backups/2022-05-31-evening",
+			CreatedAt: snapshots[0].CreatedAt.Add(time.Hour),
+		}
+		snapshots = append(snapshots, newer)
+
+		policy := &retention.Policy{KeepDaily: 2}
+		policy.Apply(snapshots)
+		assert.Equal(t, []string{"/backups/2022-05-30",
+			"/backups/2022-05-31-evening"}, getKeptPaths(snapshots))
+	})
+
+	t.Run("Keep parents of incremental snapshots", func(t *testing.T) {
+		snapshots := getDailySnapshots(5)
+
+		// Newest snapshot is taken from the oldest one which is taken from the
+		// snapshot of an older version, recording only the path of the parent
+		snapshots[0].ParentSnapshotId = snapshots[4].SnapshotId
+		snapshots[4].ParentSnapshotPath = snapshots[3].MetadataFilePath
+		snapshots[3].SnapshotId = ""
+
+		policy := &retention.Policy{KeepDaily: 1}
+		policy.Apply(snapshots)
+		assert.Equal(t, []string{
+			"/backups/2022-05-31",
+			"/backups/2022-05-28",
+			"/backups/2022-05-27",
+		}, getKeptPaths(snapshots))
+		assert.Equal(t, []string{"parent of snapshot_0"}, snapshots[4].Reasons)
+
+		out := &bytes.Buffer{}
+		assert.Nil(t, retention.WriteTable(out, snapshots))
+		assert.Contains(t, out.String(), "parent of snapshot_4")
+	})
+}
+
+func TestIsEmpty(t *testing.T) {
+	assert.True(t, (&retention.Policy{}).IsEmpty())
+	assert.False(t, (&retention.Policy{KeepMonthly: 1}).IsEmpty())
+}
@@ -0,0 +1,792 @@
+package rw
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	TAR_GZ_FORMAT     = "tar.gz"
+	ZIP_FORMAT        = "zip"
+	ARCHIVE_FILE_NAME = "notion_backup"
+	ARCHIVE_FILE_PERM = 0600
+
+	// Archive is written to a temporary file which is renamed to the archive
+	// once the metadata is written
+	ARCHIVE_TEMP_FILE_PATTERN = ARCHIVE_FILE_NAME + "-*.tmp"
+)
+
+var errArchiveWriteOnly = fmt.Errorf("archive is opened for writing only")
+var errArchiveReadOnly = fmt.Errorf("archive is opened for reading only")
+var errArchiveClosed = fmt.Errorf("archive is already closed")
+
+// Writes the entries of the archive one after another
+type archiveWriter interface {
+	writeEntry(name string, data []byte) error
+	close() error
+}
+
+// Reads the entries of the archive by name
+type archiveReader interface {
+	readEntry(name string) ([]byte, error)
+	close() error
+}
+
+type tarGzWriter struct {
+	file       *os.File
+	gzipWriter *gzip.Writer
+	tarWriter  *tar.Writer
+}
+
+func (w *tarGzWriter) writeEntry(name string, data []byte) error {
+	err := w.tarWriter.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    OBJECT_FILE_PERM,
+		Size:    int64(len(data)),
+		ModTime: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = w.tarWriter.Write(data)
+	return err
+}
+
+func (w *tarGzWriter) close() error {
+	err := w.tarWriter.Close()
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+
+	err = w.gzipWriter.Close()
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+
+	return w.file.Close()
+}
+
+type zipWriter struct {
+	file      *os.File
+	zipWriter *zip.Writer
+}
+
+func (w *zipWriter) writeEntry(name string, data []byte) error {
+	header := &zip.FileHeader{
+		Name:     name,
+		Method:   zip.Deflate,
+		Modified: time.Now(),
+	}
+	header.SetMode(OBJECT_FILE_PERM)
+
+	writer, err := w.zipWriter.CreateHeader(header)
+	if err != nil {
+		return err
+	}
+
+	_, err = writer.Write(data)
+	return err
+}
+
+func (w *zipWriter) close() error {
+	err := w.zipWriter.Close()
+	if err != nil {
+		w.file.Close()
+		return err
+	}
+
+	return w.file.Close()
+}
+
+// tar.gz archives do not support random access, so the entries are extracted
+// to a temporary directory when archive is opened instead of being kept in
+// memory, as the archive can be larger than memory with the assets
+type tarGzReader struct {
+	extractDir string
+}
+
+// Helper function to get the path to which the entry is extracted. Entry name
+// is cleaned as an absolute path so that it cannot point outside the directory
+func getExtractPath(extractDir string, name string) string {
+	return filepath.Join(extractDir, filepath.FromSlash(path.Clean("/"+name)))
+}
+
+func (r *tarGzReader) readEntry(name string) ([]byte, error) {
+	data, err := ioutil.ReadFile(getExtractPath(r.extractDir, name))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("entry %s not found in archive", name)
+	}
+
+	return data, err
+}
+
+func (r *tarGzReader) close() error {
+	return os.RemoveAll(r.extractDir)
+}
+
+type zipReader struct {
+	readCloser *zip.ReadCloser
+	files      map[string]*zip.File
+}
+
+func (r *zipReader) readEntry(name string) ([]byte, error) {
+	file, found := r.files[name]
+	if !found {
+		return nil, fmt.Errorf("entry %s not found in archive", name)
+	}
+
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	return ioutil.ReadAll(reader)
+}
+
+func (r *zipReader) close() error {
+	return r.readCloser.Close()
+}
+
+// Helper function to call the function with each regular file entry of the
+// tar.gz archive, streaming the archive only once. Iteration stops when the
+// function returns false or an error
+func walkTarGz(archivePath string,
+	fn func(header *tar.Header, reader io.Reader) (bool, error)) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzipReader.Close()
+
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		next, err := fn(header, tarReader)
+		if err != nil || !next {
+			return err
+		}
+	}
+}
+
+// Helper function to extract the regular file entry to the directory
+func extractEntry(extractDir string, name string, reader io.Reader) error {
+	entryPath := getExtractPath(extractDir, name)
+	err := utils.CreateDirectory(filepath.Dir(entryPath))
+	if err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(entryPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC,
+		ARCHIVE_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(file, reader)
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// Helper function to open the tar.gz archive by extracting all of its entries
+// to a temporary directory
+func openTarGzReader(archivePath string) (archiveReader, error) {
+	extractDir, err := ioutil.TempDir("", ARCHIVE_FILE_NAME+"-*")
+	if err != nil {
+		return nil, err
+	}
+
+	err = walkTarGz(archivePath, func(header *tar.Header,
+		reader io.Reader) (bool, error) {
+		return true, extractEntry(extractDir, header.Name, reader)
+	})
+	if err != nil {
+		os.RemoveAll(extractDir)
+		return nil, err
+	}
+
+	return &tarGzReader{extractDir: extractDir}, nil
+}
+
+// Helper function to read a single entry of the tar.gz archive without
+// extracting the other entries
+func readTarGzEntry(archivePath string, name string) ([]byte, error) {
+	var data []byte
+	err := walkTarGz(archivePath, func(header *tar.Header,
+		reader io.Reader) (bool, error) {
+		if header.Name != name {
+			return true, nil
+		}
+
+		var err error
+		data, err = ioutil.ReadAll(reader)
+		return false, err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, fmt.Errorf("entry %s not found in archive", name)
+	}
+
+	return data, nil
+}
+
+// Helper function to open the zip archive
+func openZipReader(archivePath string) (archiveReader, error) {
+	readCloser, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*zip.File)
+	for _, file := range readCloser.File {
+		files[file.Name] = file
+	}
+
+	return &zipReader{readCloser: readCloser, files: files}, nil
+}
+
+// Get the archive format from the extension of the file. Empty string is
+// returned if file is not an archive
+func GetArchiveFormat(filePath string) string {
+	lowerPath := strings.ToLower(filePath)
+	if strings.HasSuffix(lowerPath, ".tar.gz") ||
+		strings.HasSuffix(lowerPath, ".tgz") {
+		return TAR_GZ_FORMAT
+	}
+
+	if strings.HasSuffix(lowerPath, ".zip") {
+		return ZIP_FORMAT
+	}
+
+	return ""
+}
+
+// ArchiveReaderWriter stores all the objects and the metadata file in a single
+// archive file. Archive is either opened for writing during backup or for
+// reading during restore, not both
+type ArchiveReaderWriter struct {
+	archivePath     string
+	tempPath        string
+	format          string
+	databaseDirPath string
+	pageDirPath     string
+	blockDirPath    string
+	assetDirPath    string
+	commentDirPath  string
+	writer          archiveWriter
+	reader          archiveReader
+	closed          bool
+
+	// Entries of the archive are written sequentially
+	mutex sync.Mutex
+}
+
+// Create the archive of given format in given directory for writing
+func GetArchiveReaderWriter(ctx context.Context, basePath string,
+	createDirIfNotExist bool, format string) (ReaderWriter, error) {
+	err := utils.CheckIfDirExists(basePath)
+	if err != nil {
+		if !createDirIfNotExist {
+			return nil, err
+		}
+
+		err = utils.CreateDirectory(basePath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if format != TAR_GZ_FORMAT && format != ZIP_FORMAT {
+		return nil, fmt.Errorf("unsupported archive format: %s", format)
+	}
+
+	// Existing archive is never overwritten, as it may be the only copy of an
+	// earlier snapshot or the parent of this snapshot
+	archivePath := filepath.Join(basePath, ARCHIVE_FILE_NAME+"."+format)
+	if _, err := os.Stat(archivePath); err == nil {
+		return nil, fmt.Errorf("archive %s already exists", archivePath)
+	}
+
+	// Temporary file is created with ARCHIVE_FILE_PERM permissions
+	file, err := ioutil.TempFile(basePath, ARCHIVE_TEMP_FILE_PATTERN)
+	if err != nil {
+		return nil, err
+	}
+
+	zerolog.Ctx(ctx).Info().Str(logging.ExportPath, archivePath).Msg(
+		"Archive backup path")
+
+	var writer archiveWriter
+	if format == TAR_GZ_FORMAT {
+		gzipWriter := gzip.NewWriter(file)
+		writer = &tarGzWriter{
+			file:       file,
+			gzipWriter: gzipWriter,
+			tarWriter:  tar.NewWriter(gzipWriter),
+		}
+	} else {
+		writer = &zipWriter{
+			file:      file,
+			zipWriter: zip.NewWriter(file),
+		}
+	}
+
+	return &ArchiveReaderWriter{
+		archivePath:     archivePath,
+		tempPath:        file.Name(),
+		format:          format,
+		databaseDirPath: DATABASE_DIR_NAME,
+		pageDirPath:     PAGE_DIR_NAME,
+		blockDirPath:    BLOCK_DIR_NAME,
+		assetDirPath:    ASSET_DIR_NAME,
+		commentDirPath:  COMMENT_DIR_NAME,
+		writer:          writer,
+	}, nil
+}
+
+// Helper function to open the archive of any supported format for reading
+func openArchiveReader(archivePath string) (archiveReader, error) {
+	switch GetArchiveFormat(archivePath) {
+	case TAR_GZ_FORMAT:
+		return openTarGzReader(archivePath)
+	case ZIP_FORMAT:
+		return openZipReader(archivePath)
+	}
+
+	return nil, fmt.Errorf("unsupported archive file: %s", archivePath)
+}
+
+// Helper function to parse the metadata stored in the archive
+func parseArchiveMetaData(dataBytes []byte) (*metadata.MetaData, error) {
+	metadataObj := &metadata.MetaData{}
+	err := proto.Unmarshal(dataBytes, metadataObj)
+	if err != nil {
+		return nil, err
+	}
+
+	return metadataObj, nil
+}
+
+// Helper function to read the metadata stored in the archive
+func readArchiveMetaData(reader archiveReader) (*metadata.MetaData, error) {
+	dataBytes, err := reader.readEntry(METADATA_FILE_NAME)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseArchiveMetaData(dataBytes)
+}
+
+// Read the metadata stored in the archive without keeping the archive open.
+// Entries of tar.gz archive are not extracted to read the metadata
+func ReadArchiveMetaData(archivePath string) (*metadata.MetaData, error) {
+	if GetArchiveFormat(archivePath) == TAR_GZ_FORMAT {
+		dataBytes, err := readTarGzEntry(archivePath, METADATA_FILE_NAME)
+		if err != nil {
+			return nil, err
+		}
+
+		return parseArchiveMetaData(dataBytes)
+	}
+
+	reader, err := openArchiveReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.close()
+
+	return readArchiveMetaData(reader)
+}
+
+// Open the archive for reading. Metadata stored in the archive is returned
+// along with the ReaderWriter
+func GetArchiveReaderWriterForArchive(ctx context.Context,
+	archivePath string) (ReaderWriter, *metadata.MetaData, error) {
+	format := GetArchiveFormat(archivePath)
+	reader, err := openArchiveReader(archivePath)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadataObj, err := readArchiveMetaData(reader)
+	if err != nil {
+		reader.close()
+		return nil, nil, err
+	}
+
+	archiveConfig := metadataObj.GetStorageConfig().GetArchive()
+	if archiveConfig == nil {
+		reader.close()
+		return nil, nil, fmt.Errorf("archive storage config not found in " +
+			"metadata")
+	}
+
+	return &ArchiveReaderWriter{
+		archivePath:     archivePath,
+		format:          format,
+		databaseDirPath: archiveConfig.DatabaseDir,
+		pageDirPath:     archiveConfig.PageDir,
+		blockDirPath:    archiveConfig.BlocksDir,
+		assetDirPath:    archiveConfig.AssetsDir,
+		commentDirPath:  archiveConfig.CommentsDir,
+		reader:          reader,
+	}, metadataObj, nil
+}
+
+// Helper function to write an entry in archive
+func (rw *ArchiveReaderWriter) writeEntry(name string, data []byte) error {
+	if rw.writer == nil {
+		return errArchiveReadOnly
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	if rw.closed {
+		return errArchiveClosed
+	}
+
+	return rw.writer.writeEntry(name, data)
+}
+
+func (rw *ArchiveReaderWriter) writeData(ctx context.Context, v interface{},
+	dirPath string) (DataIdentifier, error) {
+	dataBytes, err := json.Marshal(&v)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *ArchiveReaderWriter) writeBytes(ctx context.Context,
+	dataBytes []byte, dirPath string) (DataIdentifier, error) {
+	dataIdentifier := DataIdentifier(uuid.NewString())
+	err := rw.writeObject(ctx, dataBytes, dirPath, dataIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	return dataIdentifier, nil
+}
+
+// Helper function to append the object to the directory inside archive with
+// given identifier
+func (rw *ArchiveReaderWriter) writeObject(ctx context.Context,
+	dataBytes []byte, dirPath string, identifier DataIdentifier) error {
+	err := rw.writeEntry(path.Join(dirPath, identifier.String()), dataBytes)
+	if err != nil {
+		return err
+	}
+
+	recordWrite(ctx, ARCHIVE_RW_TYPE, len(dataBytes))
+	return nil
+}
+
+// Helper function to read an entry from archive
+func (rw *ArchiveReaderWriter) readEntry(dirPath string,
+	identifier DataIdentifier) ([]byte, error) {
+	if rw.reader == nil {
+		return nil, errArchiveWriteOnly
+	}
+
+	return rw.reader.readEntry(path.Join(dirPath, identifier.String()))
+}
+
+func (rw *ArchiveReaderWriter) readData(ctx context.Context, dirPath string,
+	identifier DataIdentifier, v interface{}) error {
+	dataBytes, err := rw.readEntry(dirPath, identifier)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(dataBytes, v)
+}
+
+func (rw *ArchiveReaderWriter) WriteDatabase(ctx context.Context,
+	database *notionapi.Database) (DataIdentifier, error) {
+	if database == nil {
+		return "", fmt.Errorf("nullptr received for database object")
+	}
+
+	return rw.writeData(ctx, database, rw.databaseDirPath)
+}
+
+func (rw *ArchiveReaderWriter) ReadDatabase(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Database, error) {
+	database := &notionapi.Database{}
+	err := rw.readData(ctx, rw.databaseDirPath, identifier, &database)
+	if err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+func (rw *ArchiveReaderWriter) WritePage(ctx context.Context,
+	page *notionapi.Page) (DataIdentifier, error) {
+	if page == nil {
+		return "", fmt.Errorf("nullptr received for page object")
+	}
+
+	return rw.writeData(ctx, page, rw.pageDirPath)
+}
+
+func (rw *ArchiveReaderWriter) ReadPage(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Page, error) {
+	page := &notionapi.Page{}
+	err := rw.readData(ctx, rw.pageDirPath, identifier, &page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (rw *ArchiveReaderWriter) WriteBlock(ctx context.Context,
+	block notionapi.Block) (DataIdentifier, error) {
+	if block == nil {
+		return "", fmt.Errorf("nullptr received for block object")
+	}
+
+	return rw.writeData(ctx, block, rw.blockDirPath)
+}
+
+func (rw *ArchiveReaderWriter) ReadBlock(ctx context.Context,
+	identifier DataIdentifier) (notionapi.Block, error) {
+	dataBytes, err := rw.readEntry(rw.blockDirPath, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(dataBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.DecodeBlockObject(response)
+}
+
+func (rw *ArchiveReaderWriter) WriteComment(ctx context.Context,
+	comment *notionapi.Comment) (DataIdentifier, error) {
+	if comment == nil {
+		return "", fmt.Errorf("nullptr received for comment object")
+	}
+
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeData(ctx, comment, dirPath)
+}
+
+func (rw *ArchiveReaderWriter) ReadComment(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Comment, error) {
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &notionapi.Comment{}
+	err = rw.readData(ctx, dirPath, identifier, &comment)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Metadata is the last entry of the archive. Archive is closed, made read only
+// and renamed from the temporary file after writing the metadata
+func (rw *ArchiveReaderWriter) WriteMetaData(ctx context.Context,
+	metadata *metadata.MetaData) error {
+	dataBytes, err := proto.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	zerolog.Ctx(ctx).Info().Str(logging.MetaDataFilePath, rw.archivePath).Msg(
+		"Writing Metadata file to archive")
+	err = rw.writeEntry(METADATA_FILE_NAME, dataBytes)
+	if err != nil {
+		return err
+	}
+	recordWrite(ctx, ARCHIVE_RW_TYPE, len(dataBytes))
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.closed = true
+	err = rw.writer.close()
+	if err != nil {
+		return err
+	}
+
+	err = os.Chmod(rw.tempPath, OBJECT_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(rw.archivePath); err == nil {
+		return fmt.Errorf("archive %s already exists", rw.archivePath)
+	}
+
+	return os.Rename(rw.tempPath, rw.archivePath)
+}
+
+// Summary is written next to the archive, as the archive is already closed
+// once the run is finished
+func (rw *ArchiveReaderWriter) WriteSummary(ctx context.Context,
+	dataBytes []byte) error {
+	return writeSummaryFile(filepath.Dir(rw.archivePath), dataBytes)
+}
+
+// Temporary file of the archive being written is removed, leaving any existing
+// archive untouched. Archive opened for reading is closed
+func (rw *ArchiveReaderWriter) CleanUp(ctx context.Context) error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	if rw.reader != nil {
+		return rw.reader.close()
+	}
+
+	if !rw.closed {
+		rw.closed = true
+		rw.writer.close()
+	}
+
+	err := os.Remove(rw.tempPath)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Objects can only be appended to the archive, so objects of other
+// ReaderWriter are always read and written again
+func (rw *ArchiveReaderWriter) ReuseObject(ctx context.Context,
+	source ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	return copyObject(ctx, source, rw, objectType, identifier)
+}
+
+// Helper function to get the directory inside the archive in which objects of
+// given type are stored
+func (rw *ArchiveReaderWriter) getObjectDirPath(
+	objectType metadata.NotionObjectType) (string, error) {
+	switch objectType {
+	case metadata.NotionObjectType_PAGE:
+		return rw.pageDirPath, nil
+	case metadata.NotionObjectType_DATABASE:
+		return rw.databaseDirPath, nil
+	case metadata.NotionObjectType_BLOCK:
+		return rw.blockDirPath, nil
+	case metadata.NotionObjectType_ASSET:
+		if rw.assetDirPath != "" {
+			return rw.assetDirPath, nil
+		}
+	case metadata.NotionObjectType_COMMENT:
+		if rw.commentDirPath != "" {
+			return rw.commentDirPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("no directory for object of type %s", objectType)
+}
+
+func (rw *ArchiveReaderWriter) WriteRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	dataBytes []byte) (DataIdentifier, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *ArchiveReaderWriter) writeRawObjectWithIdentifier(
+	ctx context.Context, objectType metadata.NotionObjectType,
+	identifier DataIdentifier, dataBytes []byte) error {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return err
+	}
+
+	return rw.writeObject(ctx, dataBytes, dirPath, identifier)
+}
+
+func (rw *ArchiveReaderWriter) ReadRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return rw.readEntry(dirPath, identifier)
+}
+
+func (rw *ArchiveReaderWriter) GetStorageConfig(ctx context.Context) (
+	*metadata.StorageConfig, error) {
+	archiveConfig := &metadata.StorageConfig_Archive{
+		Format:      rw.format,
+		DatabaseDir: rw.databaseDirPath,
+		PageDir:     rw.pageDirPath,
+		BlocksDir:   rw.blockDirPath,
+		AssetsDir:   rw.assetDirPath,
+		CommentsDir: rw.commentDirPath,
+	}
+
+	return &metadata.StorageConfig{
+		Config: &metadata.StorageConfig_Archive_{
+			Archive: archiveConfig,
+		},
+	}, nil
+}
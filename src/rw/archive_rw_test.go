@@ -0,0 +1,202 @@
+package rw_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetArchiveFormat(t *testing.T) {
+	assert.Equal(t, rw.TAR_GZ_FORMAT, rw.GetArchiveFormat("backup.tar.gz"))
+	assert.Equal(t, rw.TAR_GZ_FORMAT, rw.GetArchiveFormat("backup.TGZ"))
+	assert.Equal(t, rw.ZIP_FORMAT, rw.GetArchiveFormat("/some/backup.zip"))
+	assert.Equal(t, "", rw.GetArchiveFormat("metadata.pb"))
+}
+
+func TestArchiveReaderWriter(t *testing.T) {
+	database := &notionapi.Database{
+		Object: notionapi.ObjectTypeDatabase,
+		ID:     "database_id",
+	}
+	page := &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "page_id",
+	}
+	block := &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "block_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}
+
+	for _, format := range []string{rw.TAR_GZ_FORMAT, rw.ZIP_FORMAT} {
+		t.Run("Write and read "+format+" archive", func(t *testing.T) {
+			ctx := context.Background()
+			dir := t.TempDir()
+			writer, err := rw.GetArchiveReaderWriter(ctx, dir, false, format)
+			assert.Nil(t, err)
+
+			databaseId, err := writer.WriteDatabase(ctx, database)
+			assert.Nil(t, err)
+			pageId, err := writer.WritePage(ctx, page)
+			assert.Nil(t, err)
+			blockId, err := writer.WriteBlock(ctx, block)
+			assert.Nil(t, err)
+
+			_, err = writer.ReadPage(ctx, pageId)
+			assert.NotNil(t, err)
+
+			storageConfig, err := writer.GetStorageConfig(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, format, storageConfig.GetArchive().GetFormat())
+
+			err = writer.WriteMetaData(ctx, &metadata.MetaData{
+				StorageConfig: storageConfig,
+			})
+			assert.Nil(t, err)
+
+			_, err = writer.WritePage(ctx, page)
+			assert.NotNil(t, err)
+
+			archivePath := filepath.Join(dir, rw.ARCHIVE_FILE_NAME+"."+format)
+			checkFilePermissions(t, archivePath)
+
+			reader, metadataObj, err := rw.GetArchiveReaderWriterForArchive(ctx,
+				archivePath)
+			assert.Nil(t, err)
+			assert.Equal(t, format,
+				metadataObj.GetStorageConfig().GetArchive().GetFormat())
+
+			readDatabase, err := reader.ReadDatabase(ctx, databaseId)
+			assert.Nil(t, err)
+			assert.Equal(t, database.ID, readDatabase.ID)
+
+			readPage, err := reader.ReadPage(ctx, pageId)
+			assert.Nil(t, err)
+			assert.Equal(t, page.ID, readPage.ID)
+
+			readBlock, err := reader.ReadBlock(ctx, blockId)
+			assert.Nil(t, err)
+			assert.Equal(t, block.ID, readBlock.GetID())
+
+			_, err = reader.ReadBlock(ctx, "non_existing")
+			assert.NotNil(t, err)
+
+			_, err = reader.WriteBlock(ctx, block)
+			assert.NotNil(t, err)
+
+			assert.Nil(t, reader.CleanUp(ctx))
+		})
+	}
+
+	t.Run("CleanUp removes archive", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetArchiveReaderWriter(ctx, dir, false,
+			rw.TAR_GZ_FORMAT)
+		assert.Nil(t, err)
+
+		_, err = writer.WritePage(ctx, page)
+		assert.Nil(t, err)
+
+		err = writer.CleanUp(ctx)
+		assert.Nil(t, err)
+
+		_, err = os.Stat(filepath.Join(dir,
+			rw.ARCHIVE_FILE_NAME+"."+rw.TAR_GZ_FORMAT))
+		assert.True(t, os.IsNotExist(err))
+
+		entries, err := os.ReadDir(dir)
+		assert.Nil(t, err)
+		assert.Empty(t, entries)
+	})
+
+	t.Run("Existing archive is not overwritten", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		archivePath := filepath.Join(dir,
+			rw.ARCHIVE_FILE_NAME+"."+rw.TAR_GZ_FORMAT)
+
+		writer, err := rw.GetArchiveReaderWriter(ctx, dir, false,
+			rw.TAR_GZ_FORMAT)
+		assert.Nil(t, err)
+
+		// Archive is not created until the metadata is written
+		_, err = os.Stat(archivePath)
+		assert.True(t, os.IsNotExist(err))
+
+		storageConfig, err := writer.GetStorageConfig(ctx)
+		assert.Nil(t, err)
+		err = writer.WriteMetaData(ctx, &metadata.MetaData{
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(t, err)
+
+		writer, err = rw.GetArchiveReaderWriter(ctx, dir, false,
+			rw.TAR_GZ_FORMAT)
+		assert.Nil(t, writer)
+		assert.NotNil(t, err)
+
+		_, _, err = rw.GetArchiveReaderWriterForArchive(ctx, archivePath)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Read metadata and entries of tar.gz archive", func(t *testing.T) {
+		ctx := context.Background()
+		dir := t.TempDir()
+		writer, err := rw.GetArchiveReaderWriter(ctx, dir, false,
+			rw.TAR_GZ_FORMAT)
+		assert.Nil(t, err)
+
+		pageId, err := writer.WritePage(ctx, page)
+		assert.Nil(t, err)
+
+		storageConfig, err := writer.GetStorageConfig(ctx)
+		assert.Nil(t, err)
+		err = writer.WriteMetaData(ctx, &metadata.MetaData{
+			SnapshotId:    "snapshot_id",
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(t, err)
+
+		archivePath := filepath.Join(dir,
+			rw.ARCHIVE_FILE_NAME+"."+rw.TAR_GZ_FORMAT)
+		metadataObj, err := rw.ReadArchiveMetaData(archivePath)
+		assert.Nil(t, err)
+		assert.Equal(t, "snapshot_id", metadataObj.SnapshotId)
+
+		// Entries are read again after the archive is opened once more
+		for i := 0; i < 2; i++ {
+			reader, _, err := rw.GetArchiveReaderWriterForArchive(ctx,
+				archivePath)
+			assert.Nil(t, err)
+
+			readPage, err := reader.ReadPage(ctx, pageId)
+			assert.Nil(t, err)
+			assert.Equal(t, page.ID, readPage.ID)
+			assert.Nil(t, reader.CleanUp(ctx))
+		}
+	})
+
+	t.Run("Unsupported format", func(t *testing.T) {
+		writer, err := rw.GetArchiveReaderWriter(context.Background(),
+			t.TempDir(), false, "rar")
+		assert.Nil(t, writer)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Non existing archive", func(t *testing.T) {
+		reader, metadataObj, err := rw.GetArchiveReaderWriterForArchive(
+			context.Background(), filepath.Join(t.TempDir(), "backup.zip"))
+		assert.Nil(t, reader)
+		assert.Nil(t, metadataObj)
+		assert.NotNil(t, err)
+	})
+}
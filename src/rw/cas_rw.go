@@ -0,0 +1,623 @@
+package rw
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	OBJECTS_DIR_NAME     = "objects"
+	SNAPSHOTS_DIR_NAME   = "snapshots"
+	SNAPSHOT_NAME_FORMAT = "20060102T150405Z"
+
+	// Backups writing in the object pool and the garbage collection hold lock
+	// files in the locks directory of the pool, so that garbage collection
+	// never deletes the objects of a backup in progress
+	LOCKS_DIR_NAME           = "locks"
+	GC_LOCK_FILE_NAME        = "gc.lock"
+	BACKUP_LOCK_FILE_PATTERN = "backup-*.lock"
+
+	// Objects are spread across sub directories named with first characters of
+	// the hash to keep the directories small
+	OBJECT_SUB_DIR_LENGTH = 2
+)
+
+// Helper function to check if the identifier is a hex encoded SHA-256 hash.
+// Identifiers are used as file names, so any other identifier is rejected
+func isObjectHash(identifier string) bool {
+	if len(identifier) != 2*sha256.Size {
+		return false
+	}
+
+	_, err := hex.DecodeString(identifier)
+	return err == nil
+}
+
+// Check if the directory is an object pool created by
+// ContentAddressedReaderWriter
+func IsObjectPool(dir string) bool {
+	_, snapshotsErr := os.Stat(filepath.Join(dir, SNAPSHOTS_DIR_NAME))
+	_, objectsErr := os.Stat(filepath.Join(dir, OBJECTS_DIR_NAME))
+	return snapshotsErr == nil && objectsErr == nil
+}
+
+// Get the metadata file paths of all the snapshots of the object pool sorted
+// from oldest to newest
+func GetSnapshotMetadataPaths(poolPath string) ([]string, error) {
+	snapshotsDirPath := filepath.Join(poolPath, SNAPSHOTS_DIR_NAME)
+	entries, err := ioutil.ReadDir(snapshotsDirPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		metadataFilePath := filepath.Join(snapshotsDirPath, entry.Name(),
+			METADATA_FILE_NAME)
+		if _, err := os.Stat(metadataFilePath); err == nil {
+			paths = append(paths, metadataFilePath)
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// Lock the object pool for garbage collection. Error is returned if another
+// garbage collection or a backup is in progress in the pool. Returned function
+// releases the lock
+func LockObjectPool(poolPath string) (func() error, error) {
+	locksDirPath := filepath.Join(poolPath, LOCKS_DIR_NAME)
+	err := utils.CreateDirectory(locksDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Lock file is created before checking the backup locks, and backups create
+	// their lock files before checking this one. So at least one of them always
+	// sees the lock of the other
+	gcLockFilePath := filepath.Join(locksDirPath, GC_LOCK_FILE_NAME)
+	file, err := os.OpenFile(gcLockFilePath, os.O_CREATE|os.O_EXCL|os.O_WRONLY,
+		OBJECT_FILE_PERM)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, fmt.Errorf("garbage collection is already running in "+
+				"object pool %s. Remove %s if it is not running", poolPath,
+				gcLockFilePath)
+		}
+		return nil, err
+	}
+	file.Close()
+
+	unlock := func() error {
+		err := os.Remove(gcLockFilePath)
+		if err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	backupLockFilePaths, err := filepath.Glob(filepath.Join(locksDirPath,
+		BACKUP_LOCK_FILE_PATTERN))
+	if err == nil && len(backupLockFilePaths) != 0 {
+		err = fmt.Errorf("backup is in progress in object pool %s. Remove %s "+
+			"if it is not running", poolPath, backupLockFilePaths[0])
+	}
+
+	if err != nil {
+		unlock()
+		return nil, err
+	}
+
+	return unlock, nil
+}
+
+// Delete all the objects of the object pool which are not referenced. Hashes of
+// deleted objects are returned. Objects are only listed without deleting them
+// for dry run
+func CollectGarbage(ctx context.Context, poolPath string,
+	referencedHashes map[string]bool, dryRun bool) ([]string, error) {
+	log := zerolog.Ctx(ctx)
+	objectsDirPath := filepath.Join(poolPath, OBJECTS_DIR_NAME)
+	removedHashes := make([]string, 0)
+	err := filepath.Walk(objectsDirPath,
+		func(filePath string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			// Temporary files of objects being written are skipped
+			if info.IsDir() || !isObjectHash(info.Name()) ||
+				referencedHashes[info.Name()] {
+				return nil
+			}
+
+			if !dryRun {
+				err = os.Remove(filePath)
+				if err != nil {
+					return err
+				}
+			}
+
+			log.Debug().Str(logging.ExportPath, filePath).Msg(
+				"Unreferenced object removed")
+			removedHashes = append(removedHashes, info.Name())
+			return nil
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	return removedHashes, nil
+}
+
+// ContentAddressedReaderWriter stores the objects in an object pool shared by
+// many snapshots. Objects are identified by the SHA-256 hash of their JSON, so
+// identical objects of all the snapshots are stored only once. Metadata of
+// each snapshot is stored in its own directory inside snapshots directory of
+// the pool
+type ContentAddressedReaderWriter struct {
+	poolDirPath     string
+	objectsDirPath  string
+	snapshotDirPath string
+	lockFilePath    string
+}
+
+// Create the ReaderWriter writing objects in the object pool of given
+// directory
+func GetContentAddressedReaderWriter(ctx context.Context, poolPath string,
+	createDirIfNotExist bool) (ReaderWriter, error) {
+	err := utils.CheckIfDirExists(poolPath)
+	if err != nil {
+		if !createDirIfNotExist {
+			return nil, err
+		}
+
+		err = utils.CreateDirectory(poolPath)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	objectsDirPath := filepath.Join(poolPath, OBJECTS_DIR_NAME)
+	zerolog.Ctx(ctx).Info().Str(logging.ExportPath, objectsDirPath).Msg(
+		"Object pool path")
+
+	err = utils.CreateDirectory(objectsDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContentAddressedReaderWriter{
+		poolDirPath:    poolPath,
+		objectsDirPath: objectsDirPath,
+	}, nil
+}
+
+// Create the ReaderWriter to read the objects of the snapshot from the object
+// pool
+func GetContentAddressedReaderWriterForMetadata(ctx context.Context,
+	metadataFilePath string, data *metadata.MetaData) (ReaderWriter, error) {
+	casConfig := data.GetStorageConfig().GetContentAddressed()
+	if casConfig == nil {
+		return nil, fmt.Errorf("content addressed storage config not found in " +
+			"metadata")
+	}
+
+	baseDir, err := filepath.Abs(filepath.Dir(metadataFilePath))
+	if err != nil {
+		return nil, err
+	}
+
+	objectsDirPath := filepath.Join(baseDir, casConfig.ObjectsDir)
+	err = utils.CheckIfDirExists(objectsDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ContentAddressedReaderWriter{
+		poolDirPath:    filepath.Dir(objectsDirPath),
+		objectsDirPath: objectsDirPath,
+	}, nil
+}
+
+// Helper function to get the path of the object with given hash
+func (rw *ContentAddressedReaderWriter) getObjectPath(
+	identifier DataIdentifier) (string, error) {
+	hash := identifier.String()
+	if !isObjectHash(hash) {
+		return "", fmt.Errorf("invalid object hash: %s", hash)
+	}
+
+	return filepath.Join(rw.objectsDirPath, hash[:OBJECT_SUB_DIR_LENGTH],
+		hash), nil
+}
+
+// Helper function to lock the object pool for the backup on its first write.
+// Lock is held until the metadata is written or the backup is cleaned up
+func (rw *ContentAddressedReaderWriter) lockPool() error {
+	if rw.lockFilePath != "" {
+		return nil
+	}
+
+	locksDirPath := filepath.Join(rw.poolDirPath, LOCKS_DIR_NAME)
+	err := utils.CreateDirectory(locksDirPath)
+	if err != nil {
+		return err
+	}
+
+	file, err := ioutil.TempFile(locksDirPath, BACKUP_LOCK_FILE_PATTERN)
+	if err != nil {
+		return err
+	}
+	file.Close()
+	rw.lockFilePath = file.Name()
+
+	_, err = os.Stat(filepath.Join(locksDirPath, GC_LOCK_FILE_NAME))
+	if err == nil {
+		rw.unlockPool()
+		return fmt.Errorf("garbage collection is running in object pool %s",
+			rw.poolDirPath)
+	}
+
+	return nil
+}
+
+// Helper function to release the lock of the object pool held by the backup
+func (rw *ContentAddressedReaderWriter) unlockPool() error {
+	if rw.lockFilePath == "" {
+		return nil
+	}
+
+	err := os.Remove(rw.lockFilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	rw.lockFilePath = ""
+	return nil
+}
+
+// Helper function to store the object if an object with same hash does not
+// exist. Object is written to a temporary file and renamed, so an object
+// file is never partially written
+func (rw *ContentAddressedReaderWriter) writeBytes(ctx context.Context,
+	dataBytes []byte) (DataIdentifier, error) {
+	err := rw.lockPool()
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(dataBytes)
+	identifier := DataIdentifier(hex.EncodeToString(hash[:]))
+	filePath, err := rw.getObjectPath(identifier)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := os.Stat(filePath); err == nil {
+		return identifier, nil
+	}
+
+	dirPath := filepath.Dir(filePath)
+	err = utils.CreateDirectory(dirPath)
+	if err != nil {
+		return "", err
+	}
+
+	tmpFile, err := ioutil.TempFile(dirPath, ".tmp-")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.Write(dataBytes)
+	if err != nil {
+		tmpFile.Close()
+		return "", err
+	}
+
+	err = tmpFile.Close()
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Chmod(tmpFile.Name(), OBJECT_FILE_PERM)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.Rename(tmpFile.Name(), filePath)
+	if err != nil {
+		return "", err
+	}
+
+	recordWrite(ctx, CAS_RW_TYPE, len(dataBytes))
+	return identifier, nil
+}
+
+func (rw *ContentAddressedReaderWriter) writeData(ctx context.Context,
+	v interface{}) (DataIdentifier, error) {
+	dataBytes, err := json.Marshal(&v)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes)
+}
+
+// Helper function to read the object. Hash of the content is verified, so
+// corrupted objects are never returned
+func (rw *ContentAddressedReaderWriter) readBytes(ctx context.Context,
+	identifier DataIdentifier) ([]byte, error) {
+	filePath, err := rw.getObjectPath(identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	dataBytes, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256(dataBytes)
+	if hex.EncodeToString(hash[:]) != identifier.String() {
+		return nil, fmt.Errorf("hash of object %s does not match its content",
+			identifier)
+	}
+
+	return dataBytes, nil
+}
+
+func (rw *ContentAddressedReaderWriter) WriteDatabase(ctx context.Context,
+	database *notionapi.Database) (DataIdentifier, error) {
+	if database == nil {
+		return "", fmt.Errorf("nullptr received for database object")
+	}
+
+	return rw.writeData(ctx, database)
+}
+
+func (rw *ContentAddressedReaderWriter) ReadDatabase(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Database, error) {
+	dataBytes, err := rw.readBytes(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &notionapi.Database{}
+	err = json.Unmarshal(dataBytes, &database)
+	if err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+func (rw *ContentAddressedReaderWriter) WritePage(ctx context.Context,
+	page *notionapi.Page) (DataIdentifier, error) {
+	if page == nil {
+		return "", fmt.Errorf("nullptr received for page object")
+	}
+
+	return rw.writeData(ctx, page)
+}
+
+func (rw *ContentAddressedReaderWriter) ReadPage(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Page, error) {
+	dataBytes, err := rw.readBytes(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &notionapi.Page{}
+	err = json.Unmarshal(dataBytes, &page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (rw *ContentAddressedReaderWriter) WriteBlock(ctx context.Context,
+	block notionapi.Block) (DataIdentifier, error) {
+	if block == nil {
+		return "", fmt.Errorf("nullptr received for block object")
+	}
+
+	return rw.writeData(ctx, block)
+}
+
+func (rw *ContentAddressedReaderWriter) ReadBlock(ctx context.Context,
+	identifier DataIdentifier) (notionapi.Block, error) {
+	dataBytes, err := rw.readBytes(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(dataBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.DecodeBlockObject(response)
+}
+
+func (rw *ContentAddressedReaderWriter) WriteComment(ctx context.Context,
+	comment *notionapi.Comment) (DataIdentifier, error) {
+	if comment == nil {
+		return "", fmt.Errorf("nullptr received for comment object")
+	}
+
+	return rw.writeData(ctx, comment)
+}
+
+func (rw *ContentAddressedReaderWriter) ReadComment(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Comment, error) {
+	dataBytes, err := rw.readBytes(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &notionapi.Comment{}
+	err = json.Unmarshal(dataBytes, &comment)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (rw *ContentAddressedReaderWriter) WriteRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	dataBytes []byte) (DataIdentifier, error) {
+	return rw.writeBytes(ctx, dataBytes)
+}
+
+func (rw *ContentAddressedReaderWriter) ReadRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	return rw.readBytes(ctx, identifier)
+}
+
+// Metadata of the snapshot is written in a new directory inside snapshots
+// directory named with the current UTC time. Lock of the object pool is
+// released once the metadata is written
+func (rw *ContentAddressedReaderWriter) WriteMetaData(ctx context.Context,
+	metadata *metadata.MetaData) error {
+	err := rw.lockPool()
+	if err != nil {
+		return err
+	}
+
+	dataBytes, err := proto.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	snapshotsDirPath := filepath.Join(rw.poolDirPath, SNAPSHOTS_DIR_NAME)
+	err = utils.CreateDirectory(snapshotsDirPath)
+	if err != nil {
+		return err
+	}
+
+	name := time.Now().UTC().Format(SNAPSHOT_NAME_FORMAT)
+	snapshotDirPath := filepath.Join(snapshotsDirPath, name)
+	for i := 2; ; i++ {
+		err = os.Mkdir(snapshotDirPath, os.ModePerm)
+		if !os.IsExist(err) {
+			break
+		}
+		snapshotDirPath = filepath.Join(snapshotsDirPath,
+			fmt.Sprintf("%s-%d", name, i))
+	}
+
+	if err != nil {
+		return err
+	}
+	rw.snapshotDirPath = snapshotDirPath
+
+	path := filepath.Join(snapshotDirPath, METADATA_FILE_NAME)
+	zerolog.Ctx(ctx).Info().Str(logging.MetaDataFilePath, path).Msg(
+		"Writing Metadata file")
+	err = os.WriteFile(path, dataBytes, METADATA_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	recordWrite(ctx, CAS_RW_TYPE, len(dataBytes))
+	return rw.unlockPool()
+}
+
+// Summary is written next to the metadata file of the snapshot. Summary of the
+// run which did not take a snapshot is written in the pool directory instead
+func (rw *ContentAddressedReaderWriter) WriteSummary(ctx context.Context,
+	dataBytes []byte) error {
+	if rw.snapshotDirPath != "" {
+		return writeSummaryFile(rw.snapshotDirPath, dataBytes)
+	}
+
+	return writeSummaryFile(rw.poolDirPath, dataBytes)
+}
+
+// Snapshot directory is removed and the lock of the pool is released. Objects
+// added to the pool are kept as a concurrent backup may have reused them, and
+// are removed by garbage collection if no snapshot refers them
+func (rw *ContentAddressedReaderWriter) CleanUp(ctx context.Context) error {
+	var externalErr error
+	if rw.snapshotDirPath != "" {
+		err := os.RemoveAll(rw.snapshotDirPath)
+		if err != nil {
+			externalErr = err
+		}
+		rw.snapshotDirPath = ""
+	}
+
+	err := rw.unlockPool()
+	if err != nil {
+		externalErr = err
+	}
+
+	return externalErr
+}
+
+// Objects of the same object pool are reused with the same hash. Objects of
+// any other ReaderWriter are read and added to the pool
+func (rw *ContentAddressedReaderWriter) ReuseObject(ctx context.Context,
+	source ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	sourceRW, ok := source.(*ContentAddressedReaderWriter)
+	if !ok || sourceRW.objectsDirPath != rw.objectsDirPath {
+		return copyObject(ctx, source, rw, objectType, identifier)
+	}
+
+	err := rw.lockPool()
+	if err != nil {
+		return "", err
+	}
+
+	filePath, err := rw.getObjectPath(identifier)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = os.Stat(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	return identifier, nil
+}
+
+// Metadata file is stored two levels below the pool directory, so objects
+// directory is always at the same relative path from it
+func (rw *ContentAddressedReaderWriter) GetStorageConfig(ctx context.Context) (
+	*metadata.StorageConfig, error) {
+	return &metadata.StorageConfig{
+		Config: &metadata.StorageConfig_ContentAddressed_{
+			ContentAddressed: &metadata.StorageConfig_ContentAddressed{
+				ObjectsDir: filepath.Join("..", "..", OBJECTS_DIR_NAME),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,261 @@
+package rw_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/stretchr/testify/assert"
+)
+
+// Helper function to get the path of the object in the object pool
+func getPoolObjectPath(poolDir string, identifier rw.DataIdentifier) string {
+	hash := identifier.String()
+	return filepath.Join(poolDir, rw.OBJECTS_DIR_NAME,
+		hash[:rw.OBJECT_SUB_DIR_LENGTH], hash)
+}
+
+func TestContentAddressedReaderWriter(t *testing.T) {
+	ctx := context.Background()
+	page := &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "page_id",
+	}
+	block := &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "block_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}
+
+	poolDir := filepath.Join(t.TempDir(), "pool")
+	_, err := rw.GetContentAddressedReaderWriter(ctx, poolDir, false)
+	assert.NotNil(t, err)
+
+	writer, err := rw.GetContentAddressedReaderWriter(ctx, poolDir, true)
+	assert.Nil(t, err)
+
+	pageId, err := writer.WritePage(ctx, page)
+	assert.Nil(t, err)
+	blockId, err := writer.WriteBlock(ctx, block)
+	assert.Nil(t, err)
+	assert.Len(t, pageId.String(), 64)
+	assert.FileExists(t, getPoolObjectPath(poolDir, pageId))
+
+	// Identical object is stored only once
+	samePageId, err := writer.WritePage(ctx, &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "page_id",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, pageId, samePageId)
+
+	storageConfig, err := writer.GetStorageConfig(ctx)
+	assert.Nil(t, err)
+	err = writer.WriteMetaData(ctx, &metadata.MetaData{
+		StorageConfig: storageConfig,
+	})
+	assert.Nil(t, err)
+
+	snapshotPaths, err := rw.GetSnapshotMetadataPaths(poolDir)
+	assert.Nil(t, err)
+	assert.Len(t, snapshotPaths, 1)
+
+	reader, err := rw.GetContentAddressedReaderWriterForMetadata(ctx,
+		snapshotPaths[0], &metadata.MetaData{StorageConfig: storageConfig})
+	assert.Nil(t, err)
+
+	readPage, err := reader.ReadPage(ctx, pageId)
+	assert.Nil(t, err)
+	assert.Equal(t, page.ID, readPage.ID)
+
+	readBlock, err := reader.ReadBlock(ctx, blockId)
+	assert.Nil(t, err)
+	assert.Equal(t, block.ID, readBlock.GetID())
+
+	_, err = reader.ReadPage(ctx, "../../metadata.pb")
+	assert.NotNil(t, err)
+
+	t.Run("Second snapshot reuses objects", func(t *testing.T) {
+		newWriter, err := rw.GetContentAddressedReaderWriter(ctx, poolDir, false)
+		assert.Nil(t, err)
+
+		reusedId, err := newWriter.ReuseObject(ctx, reader,
+			metadata.NotionObjectType_PAGE, pageId)
+		assert.Nil(t, err)
+		assert.Equal(t, pageId, reusedId)
+
+		err = newWriter.WriteMetaData(ctx, &metadata.MetaData{
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(t, err)
+
+		snapshotPaths, err := rw.GetSnapshotMetadataPaths(poolDir)
+		assert.Nil(t, err)
+		assert.Len(t, snapshotPaths, 2)
+
+		// Clean up keeps the objects shared with other snapshots
+		err = newWriter.CleanUp(ctx)
+		assert.Nil(t, err)
+		assert.FileExists(t, getPoolObjectPath(poolDir, pageId))
+
+		snapshotPaths, err = rw.GetSnapshotMetadataPaths(poolDir)
+		assert.Nil(t, err)
+		assert.Len(t, snapshotPaths, 1)
+	})
+
+	t.Run("Snapshots of object pool", func(t *testing.T) {
+		assert.True(t, rw.IsObjectPool(poolDir))
+		snapshotPaths, err := rw.GetSnapshotPaths(poolDir)
+		assert.Nil(t, err)
+		assert.Len(t, snapshotPaths, 1)
+	})
+
+	t.Run("Garbage collection", func(t *testing.T) {
+		referencedHashes := map[string]bool{pageId.String(): true}
+		removedHashes, err := rw.CollectGarbage(ctx, poolDir, referencedHashes,
+			true)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{blockId.String()}, removedHashes)
+		assert.FileExists(t, getPoolObjectPath(poolDir, blockId))
+
+		removedHashes, err = rw.CollectGarbage(ctx, poolDir, referencedHashes,
+			false)
+		assert.Nil(t, err)
+		assert.Equal(t, []string{blockId.String()}, removedHashes)
+		assert.NoFileExists(t, getPoolObjectPath(poolDir, blockId))
+		assert.FileExists(t, getPoolObjectPath(poolDir, pageId))
+	})
+
+	t.Run("Garbage collection during backup", func(t *testing.T) {
+		backupWriter, err := rw.GetContentAddressedReaderWriter(ctx, poolDir,
+			false)
+		assert.Nil(t, err)
+
+		// Backup locks the object pool on its first write
+		_, err = backupWriter.WritePage(ctx, page)
+		assert.Nil(t, err)
+		_, err = rw.LockObjectPool(poolDir)
+		assert.NotNil(t, err)
+
+		err = backupWriter.CleanUp(ctx)
+		assert.Nil(t, err)
+		unlock, err := rw.LockObjectPool(poolDir)
+		assert.Nil(t, err)
+
+		// Backup and another garbage collection cannot start while locked
+		_, err = rw.LockObjectPool(poolDir)
+		assert.NotNil(t, err)
+		backupWriter, err = rw.GetContentAddressedReaderWriter(ctx, poolDir,
+			false)
+		assert.Nil(t, err)
+		_, err = backupWriter.WritePage(ctx, page)
+		assert.NotNil(t, err)
+
+		err = unlock()
+		assert.Nil(t, err)
+		_, err = backupWriter.WritePage(ctx, page)
+		assert.Nil(t, err)
+		err = backupWriter.WriteMetaData(ctx, &metadata.MetaData{})
+		assert.Nil(t, err)
+
+		unlock, err = rw.LockObjectPool(poolDir)
+		assert.Nil(t, err)
+		assert.Nil(t, unlock())
+	})
+
+	t.Run("Clean up during concurrent backup", func(t *testing.T) {
+		failedWriter, err := rw.GetContentAddressedReaderWriter(ctx, poolDir,
+			false)
+		assert.Nil(t, err)
+		newPageId, err := failedWriter.WritePage(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     "new_page_id",
+		})
+		assert.Nil(t, err)
+
+		// Second backup reuses the object added by the first backup
+		backupWriter, err := rw.GetContentAddressedReaderWriter(ctx, poolDir,
+			false)
+		assert.Nil(t, err)
+		samePageId, err := backupWriter.WritePage(ctx, &notionapi.Page{
+			Object: notionapi.ObjectTypePage,
+			ID:     "new_page_id",
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, newPageId, samePageId)
+
+		// Failure of the first backup keeps the object in the pool
+		err = failedWriter.CleanUp(ctx)
+		assert.Nil(t, err)
+		err = backupWriter.WriteMetaData(ctx, &metadata.MetaData{
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(t, err)
+		assert.FileExists(t, getPoolObjectPath(poolDir, newPageId))
+	})
+
+	t.Run("Corrupted object", func(t *testing.T) {
+		objectPath := getPoolObjectPath(poolDir, pageId)
+		err := os.Chmod(objectPath, 0600)
+		assert.Nil(t, err)
+		err = os.WriteFile(objectPath, []byte(`{"object":"page"}`), 0600)
+		assert.Nil(t, err)
+
+		_, err = reader.ReadPage(ctx, pageId)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestGetSnapshotPaths(t *testing.T) {
+	ctx := context.Background()
+	rootDir := t.TempDir()
+	writer, err := rw.GetFileReaderWriter(ctx, filepath.Join(rootDir,
+		"2022-05-01"), true)
+	assert.Nil(t, err)
+	err = writer.WriteMetaData(ctx, &metadata.MetaData{})
+	assert.Nil(t, err)
+
+	archiveWriter, err := rw.GetArchiveReaderWriter(ctx, rootDir, false,
+		rw.ZIP_FORMAT)
+	assert.Nil(t, err)
+	storageConfig, err := archiveWriter.GetStorageConfig(ctx)
+	assert.Nil(t, err)
+	err = archiveWriter.WriteMetaData(ctx, &metadata.MetaData{
+		StorageConfig: storageConfig,
+		SnapshotId:    "snapshot_id",
+	})
+	assert.Nil(t, err)
+
+	subDirArchiveWriter, err := rw.GetArchiveReaderWriter(ctx,
+		filepath.Join(rootDir, "2022-05-02"), true, rw.TAR_GZ_FORMAT)
+	assert.Nil(t, err)
+	storageConfig, err = subDirArchiveWriter.GetStorageConfig(ctx)
+	assert.Nil(t, err)
+	err = subDirArchiveWriter.WriteMetaData(ctx, &metadata.MetaData{
+		StorageConfig: storageConfig,
+	})
+	assert.Nil(t, err)
+
+	// Directories without metadata file or archive are not snapshots
+	err = os.Mkdir(filepath.Join(rootDir, "logs"), 0755)
+	assert.Nil(t, err)
+
+	assert.False(t, rw.IsObjectPool(rootDir))
+	snapshotPaths, err := rw.GetSnapshotPaths(rootDir)
+	assert.Nil(t, err)
+	assert.Len(t, snapshotPaths, 3)
+	assert.Equal(t, filepath.Join(rootDir, "2022-05-01", rw.METADATA_FILE_NAME),
+		snapshotPaths[0])
+	assert.Equal(t, filepath.Join(rootDir, "2022-05-02",
+		rw.ARCHIVE_FILE_NAME+"."+rw.TAR_GZ_FORMAT), snapshotPaths[1])
+
+	metadataObj, err := rw.ReadArchiveMetaData(snapshotPaths[2])
+	assert.Nil(t, err)
+	assert.Equal(t, "snapshot_id", metadataObj.SnapshotId)
+}
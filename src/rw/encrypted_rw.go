@@ -0,0 +1,509 @@
+package rw
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"golang.org/x/crypto/argon2"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	ENCRYPTION_ALGORITHM = "AES-256-GCM"
+	KDF_ARGON2ID         = "argon2id"
+	KDF_NONE             = "none"
+	KEY_LENGTH           = 32
+	SALT_LENGTH          = 16
+	ARGON2_TIME          = 3
+	ARGON2_MEMORY        = 64 * 1024
+	ARGON2_THREADS       = 4
+
+	// Additional data authenticated along with the encrypted metadata so that
+	// metadata and objects cannot be swapped
+	METADATA_ADDITIONAL_DATA = "METADATA"
+	FINGERPRINT_DATA         = "notionbackup key fingerprint"
+)
+
+var errWrongKey = fmt.Errorf("passphrase or key file does not match the " +
+	"key of the backup")
+
+// Secret from which the encryption key is obtained. Passphrase is stretched
+// into the key with argon2id while the key file contains the key itself
+type KeySource struct {
+	passphrase []byte
+	key        []byte
+}
+
+// Read the passphrase from the passphrase file. Trailing new line is ignored
+func GetKeySourceFromPassphraseFile(passphraseFile string) (*KeySource,
+	error) {
+	dataBytes, err := os.ReadFile(passphraseFile)
+	if err != nil {
+		return nil, err
+	}
+
+	passphrase := bytes.TrimRight(dataBytes, "\r\n")
+	if len(passphrase) == 0 {
+		return nil, fmt.Errorf("passphrase file %s is empty", passphraseFile)
+	}
+
+	return &KeySource{passphrase: passphrase}, nil
+}
+
+// Read the key from the key file. Key file contains either 32 raw bytes or 64
+// hex characters
+func GetKeySourceFromKeyFile(keyFile string) (*KeySource, error) {
+	dataBytes, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(dataBytes) == KEY_LENGTH {
+		return &KeySource{key: dataBytes}, nil
+	}
+
+	key, err := hex.DecodeString(string(bytes.TrimSpace(dataBytes)))
+	if err != nil || len(key) != KEY_LENGTH {
+		return nil, fmt.Errorf("key file %s must contain %d raw bytes or %d hex "+
+			"characters", keyFile, KEY_LENGTH, 2*KEY_LENGTH)
+	}
+
+	return &KeySource{key: key}, nil
+}
+
+// Key with which objects and metadata are encrypted along with the
+// parameters to derive it again
+type EncryptionKey struct {
+	key    []byte
+	config *metadata.EncryptionConfig
+}
+
+// Helper function to get the fingerprint of the key. Fingerprint is a MAC of
+// fixed data so that the hash of the key itself is never stored
+func getKeyFingerprint(key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(FINGERPRINT_DATA))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Helper function to derive the key from the passphrase with parameters of
+// the config
+func deriveKey(passphrase []byte, config *metadata.EncryptionConfig) []byte {
+	return argon2.IDKey(passphrase, config.Salt, config.Argon2Time,
+		config.Argon2Memory, uint8(config.Argon2Threads), KEY_LENGTH)
+}
+
+// Create the key for a new backup. Key is derived from the passphrase with a
+// new random salt
+func (s *KeySource) NewEncryptionKey() (*EncryptionKey, error) {
+	config := &metadata.EncryptionConfig{
+		Algorithm: ENCRYPTION_ALGORITHM,
+		Kdf:       KDF_NONE,
+	}
+
+	key := s.key
+	if s.passphrase != nil {
+		salt := make([]byte, SALT_LENGTH)
+		_, err := io.ReadFull(rand.Reader, salt)
+		if err != nil {
+			return nil, err
+		}
+
+		config.Kdf = KDF_ARGON2ID
+		config.Salt = salt
+		config.Argon2Time = ARGON2_TIME
+		config.Argon2Memory = ARGON2_MEMORY
+		config.Argon2Threads = ARGON2_THREADS
+		key = deriveKey(s.passphrase, config)
+	}
+
+	config.KeyFingerprint = getKeyFingerprint(key)
+	return &EncryptionKey{key: key, config: config}, nil
+}
+
+// Get the key of an existing backup encrypted with given config. Error is
+// returned if the key does not match the fingerprint of the config
+func (s *KeySource) GetEncryptionKey(
+	config *metadata.EncryptionConfig) (*EncryptionKey, error) {
+	if config.Algorithm != ENCRYPTION_ALGORITHM {
+		return nil, fmt.Errorf("unsupported encryption algorithm: %s",
+			config.Algorithm)
+	}
+
+	var key []byte
+	switch config.Kdf {
+	case KDF_ARGON2ID:
+		if s.passphrase == nil {
+			return nil, fmt.Errorf("backup is encrypted with a passphrase")
+		}
+		key = deriveKey(s.passphrase, config)
+	case KDF_NONE:
+		if s.key == nil {
+			return nil, fmt.Errorf("backup is encrypted with a key file")
+		}
+		key = s.key
+	default:
+		return nil, fmt.Errorf("unsupported key derivation function: %s",
+			config.Kdf)
+	}
+
+	if !hmac.Equal([]byte(getKeyFingerprint(key)),
+		[]byte(config.KeyFingerprint)) {
+		return nil, errWrongKey
+	}
+
+	return &EncryptionKey{key: key, config: config}, nil
+}
+
+func (k *EncryptionKey) GetFingerprint() string {
+	return k.config.KeyFingerprint
+}
+
+// Helper function to create the AEAD cipher of the key
+func (k *EncryptionKey) getAEAD() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(k.key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// Helper function to encrypt the data. Random nonce is prepended to the
+// encrypted data
+func encrypt(aead cipher.AEAD, plaintext []byte,
+	additionalData string) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize(),
+		aead.NonceSize()+len(plaintext)+aead.Overhead())
+	_, err := io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, []byte(additionalData)), nil
+}
+
+// Helper function to decrypt the data encrypted by encrypt
+func decrypt(aead cipher.AEAD, ciphertext []byte,
+	additionalData string) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+
+	nonce := ciphertext[:aead.NonceSize()]
+	plaintext, err := aead.Open(nil, nonce, ciphertext[aead.NonceSize():],
+		[]byte(additionalData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+// Decrypt the metadata of the encrypted backup. Metadata which is not
+// encrypted is returned as is
+func DecryptMetaData(key *EncryptionKey,
+	metadataObj *metadata.MetaData) (*metadata.MetaData, error) {
+	if metadataObj.GetEncryptionConfig() == nil {
+		return metadataObj, nil
+	}
+
+	aead, err := key.getAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	dataBytes, err := decrypt(aead, metadataObj.EncryptedMetadata,
+		METADATA_ADDITIONAL_DATA)
+	if err != nil {
+		return nil, err
+	}
+
+	decryptedMetadata := &metadata.MetaData{}
+	err = proto.Unmarshal(dataBytes, decryptedMetadata)
+	if err != nil {
+		return nil, err
+	}
+
+	return decryptedMetadata, nil
+}
+
+// EncryptedReaderWriter encrypts every object and the metadata with
+// AES-256-GCM before they are stored by the wrapped ReaderWriter. Metadata
+// file only contains the storage config, encryption config and identity of
+// the snapshot in plain text
+type EncryptedReaderWriter struct {
+	rwClient identifiedReaderWriter
+	key      *EncryptionKey
+	aead     cipher.AEAD
+}
+
+// Wrap the ReaderWriter to encrypt and decrypt the objects with the key
+func GetEncryptedReaderWriter(rwClient ReaderWriter,
+	key *EncryptionKey) (ReaderWriter, error) {
+	// Identifier of the object is decided before it is encrypted, as it is
+	// authenticated along with the object
+	rawRW, ok := rwClient.(identifiedReaderWriter)
+	if !ok {
+		return nil, fmt.Errorf("ReaderWriter does not support encryption")
+	}
+
+	aead, err := key.getAEAD()
+	if err != nil {
+		return nil, err
+	}
+
+	return &EncryptedReaderWriter{
+		rwClient: rawRW,
+		key:      key,
+		aead:     aead,
+	}, nil
+}
+
+// Get the key with which the objects are encrypted
+func (rw *EncryptedReaderWriter) GetEncryptionKey() *EncryptionKey {
+	return rw.key
+}
+
+func (rw *EncryptedReaderWriter) writeData(ctx context.Context, v interface{},
+	objectType metadata.NotionObjectType) (DataIdentifier, error) {
+	dataBytes, err := json.Marshal(&v)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, objectType)
+}
+
+// Helper function to get the additional data authenticated along with the
+// object. Object is bound to its type and identifier, so that objects cannot
+// be swapped with each other in the storage
+func getAdditionalData(objectType metadata.NotionObjectType,
+	identifier DataIdentifier) string {
+	return objectType.String() + "/" + identifier.String()
+}
+
+func (rw *EncryptedReaderWriter) writeBytes(ctx context.Context,
+	dataBytes []byte,
+	objectType metadata.NotionObjectType) (DataIdentifier, error) {
+	identifier := DataIdentifier(uuid.NewString())
+	encryptedBytes, err := encrypt(rw.aead, dataBytes,
+		getAdditionalData(objectType, identifier))
+	if err != nil {
+		return "", err
+	}
+
+	err = rw.rwClient.writeRawObjectWithIdentifier(ctx, objectType, identifier,
+		encryptedBytes)
+	if err != nil {
+		return "", err
+	}
+
+	return identifier, nil
+}
+
+func (rw *EncryptedReaderWriter) readBytes(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	encryptedBytes, err := rw.rwClient.ReadRawObject(ctx, objectType,
+		identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return decrypt(rw.aead, encryptedBytes,
+		getAdditionalData(objectType, identifier))
+}
+
+func (rw *EncryptedReaderWriter) WriteDatabase(ctx context.Context,
+	database *notionapi.Database) (DataIdentifier, error) {
+	if database == nil {
+		return "", fmt.Errorf("nullptr received for database object")
+	}
+
+	return rw.writeData(ctx, database, metadata.NotionObjectType_DATABASE)
+}
+
+func (rw *EncryptedReaderWriter) ReadDatabase(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Database, error) {
+	dataBytes, err := rw.readBytes(ctx, metadata.NotionObjectType_DATABASE,
+		identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	database := &notionapi.Database{}
+	err = json.Unmarshal(dataBytes, &database)
+	if err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+func (rw *EncryptedReaderWriter) WritePage(ctx context.Context,
+	page *notionapi.Page) (DataIdentifier, error) {
+	if page == nil {
+		return "", fmt.Errorf("nullptr received for page object")
+	}
+
+	return rw.writeData(ctx, page, metadata.NotionObjectType_PAGE)
+}
+
+func (rw *EncryptedReaderWriter) ReadPage(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Page, error) {
+	dataBytes, err := rw.readBytes(ctx, metadata.NotionObjectType_PAGE,
+		identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &notionapi.Page{}
+	err = json.Unmarshal(dataBytes, &page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (rw *EncryptedReaderWriter) WriteBlock(ctx context.Context,
+	block notionapi.Block) (DataIdentifier, error) {
+	if block == nil {
+		return "", fmt.Errorf("nullptr received for block object")
+	}
+
+	return rw.writeData(ctx, block, metadata.NotionObjectType_BLOCK)
+}
+
+func (rw *EncryptedReaderWriter) ReadBlock(ctx context.Context,
+	identifier DataIdentifier) (notionapi.Block, error) {
+	dataBytes, err := rw.readBytes(ctx, metadata.NotionObjectType_BLOCK,
+		identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(dataBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.DecodeBlockObject(response)
+}
+
+func (rw *EncryptedReaderWriter) WriteComment(ctx context.Context,
+	comment *notionapi.Comment) (DataIdentifier, error) {
+	if comment == nil {
+		return "", fmt.Errorf("nullptr received for comment object")
+	}
+
+	return rw.writeData(ctx, comment, metadata.NotionObjectType_COMMENT)
+}
+
+func (rw *EncryptedReaderWriter) ReadComment(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Comment, error) {
+	dataBytes, err := rw.readBytes(ctx, metadata.NotionObjectType_COMMENT,
+		identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &notionapi.Comment{}
+	err = json.Unmarshal(dataBytes, &comment)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// Metadata is encrypted and stored along with the storage config and the
+// encryption config required to decrypt it
+func (rw *EncryptedReaderWriter) WriteMetaData(ctx context.Context,
+	metadataObj *metadata.MetaData) error {
+	dataBytes, err := proto.Marshal(metadataObj)
+	if err != nil {
+		return err
+	}
+
+	encryptedBytes, err := encrypt(rw.aead, dataBytes,
+		METADATA_ADDITIONAL_DATA)
+	if err != nil {
+		return err
+	}
+
+	// Identity of the snapshot is kept in plain text so that snapshots can be
+	// pruned without the key
+	return rw.rwClient.WriteMetaData(ctx, &metadata.MetaData{
+		StorageConfig:     metadataObj.StorageConfig,
+		EncryptionConfig:  rw.key.config,
+		EncryptedMetadata: encryptedBytes,
+		SnapshotId:        metadataObj.SnapshotId,
+		CreatedAt:         metadataObj.CreatedAt,
+		ParentSnapshotId:  metadataObj.ParentSnapshotId,
+	})
+}
+
+// Summary holds only the totals of the run, so it is written in plain text
+// like the identity of the snapshot
+func (rw *EncryptedReaderWriter) WriteSummary(ctx context.Context,
+	dataBytes []byte) error {
+	summaryWriter, ok := rw.rwClient.(SummaryWriter)
+	if !ok {
+		return nil
+	}
+
+	return summaryWriter.WriteSummary(ctx, dataBytes)
+}
+
+// Raw objects of EncryptedReaderWriter are the serialized objects before
+// encryption, so that the objects can be copied between ReaderWriters using
+// different keys
+func (rw *EncryptedReaderWriter) WriteRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	dataBytes []byte) (DataIdentifier, error) {
+	return rw.writeBytes(ctx, dataBytes, objectType)
+}
+
+func (rw *EncryptedReaderWriter) ReadRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	return rw.readBytes(ctx, objectType, identifier)
+}
+
+func (rw *EncryptedReaderWriter) CleanUp(ctx context.Context) error {
+	return rw.rwClient.CleanUp(ctx)
+}
+
+// Objects of another EncryptedReaderWriter encrypted with the same key are
+// reused by the wrapped ReaderWriter without decrypting them. Objects of any
+// other ReaderWriter are read and encrypted again
+func (rw *EncryptedReaderWriter) ReuseObject(ctx context.Context,
+	source ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	sourceRW, ok := source.(*EncryptedReaderWriter)
+	if !ok || sourceRW.key.GetFingerprint() != rw.key.GetFingerprint() {
+		return copyObject(ctx, source, rw, objectType, identifier)
+	}
+
+	return rw.rwClient.ReuseObject(ctx, sourceRW.rwClient, objectType,
+		identifier)
+}
+
+func (rw *EncryptedReaderWriter) GetStorageConfig(ctx context.Context) (
+	*metadata.StorageConfig, error) {
+	return rw.rwClient.GetStorageConfig(ctx)
+}
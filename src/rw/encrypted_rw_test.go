@@ -0,0 +1,280 @@
+package rw_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+func writeSecretFile(t *testing.T, content string) string {
+	filePath := filepath.Join(t.TempDir(), "secret")
+	err := os.WriteFile(filePath, []byte(content), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return filePath
+}
+
+func TestGetKeySource(t *testing.T) {
+	t.Run("Passphrase file", func(t *testing.T) {
+		keySource, err := rw.GetKeySourceFromPassphraseFile(
+			writeSecretFile(t, "passphrase\n"))
+		assert.Nil(t, err)
+
+		key, err := keySource.NewEncryptionKey()
+		assert.Nil(t, err)
+		assert.NotEmpty(t, key.GetFingerprint())
+	})
+
+	t.Run("Empty passphrase file", func(t *testing.T) {
+		_, err := rw.GetKeySourceFromPassphraseFile(writeSecretFile(t, "\n"))
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Hex key file", func(t *testing.T) {
+		keySource, err := rw.GetKeySourceFromKeyFile(writeSecretFile(t,
+			strings.Repeat("ab", rw.KEY_LENGTH)+"\n"))
+		assert.Nil(t, err)
+
+		key, err := keySource.NewEncryptionKey()
+		assert.Nil(t, err)
+
+		// Same key file always gives the same key
+		sameKey, err := keySource.NewEncryptionKey()
+		assert.Nil(t, err)
+		assert.Equal(t, key.GetFingerprint(), sameKey.GetFingerprint())
+	})
+
+	t.Run("Raw key file", func(t *testing.T) {
+		_, err := rw.GetKeySourceFromKeyFile(writeSecretFile(t,
+			strings.Repeat("k", rw.KEY_LENGTH)))
+		assert.Nil(t, err)
+	})
+
+	t.Run("Invalid key file", func(t *testing.T) {
+		_, err := rw.GetKeySourceFromKeyFile(writeSecretFile(t, "short"))
+		assert.NotNil(t, err)
+	})
+}
+
+// Helper function to read the metadata file as stored
+func readMetadataFile(t *testing.T,
+	metadataFilePath string) *metadata.MetaData {
+	dataBytes, err := os.ReadFile(metadataFilePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadataObj := &metadata.MetaData{}
+	err = proto.Unmarshal(dataBytes, metadataObj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return metadataObj
+}
+
+func TestEncryptedReaderWriter(t *testing.T) {
+	ctx := context.Background()
+	page := &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "secret_page_id",
+	}
+	block := &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "secret_block_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}
+
+	keySource, err := rw.GetKeySourceFromPassphraseFile(
+		writeSecretFile(t, "passphrase"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := keySource.NewEncryptionKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	fileRW, err := rw.GetFileReaderWriter(ctx, dir, false)
+	assert.Nil(t, err)
+	writer, err := rw.GetEncryptedReaderWriter(fileRW, key)
+	assert.Nil(t, err)
+
+	pageId, err := writer.WritePage(ctx, page)
+	assert.Nil(t, err)
+	blockId, err := writer.WriteBlock(ctx, block)
+	assert.Nil(t, err)
+
+	// Objects are not stored in plain text
+	dataBytes, err := os.ReadFile(filepath.Join(dir, rw.PAGE_DIR_NAME,
+		pageId.String()))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(dataBytes), "secret_page_id")
+
+	storageConfig, err := writer.GetStorageConfig(ctx)
+	assert.Nil(t, err)
+	err = writer.WriteMetaData(ctx, &metadata.MetaData{
+		StorageConfig:      storageConfig,
+		ParentSnapshotPath: "/secret/path",
+		SnapshotId:         "snapshot_id",
+	})
+	assert.Nil(t, err)
+
+	metadataFilePath := filepath.Join(dir, rw.METADATA_FILE_NAME)
+	storedMetadata := readMetadataFile(t, metadataFilePath)
+	assert.Empty(t, storedMetadata.ParentSnapshotPath)
+	assert.Equal(t, "snapshot_id", storedMetadata.SnapshotId)
+	assert.Equal(t, rw.KDF_ARGON2ID, storedMetadata.EncryptionConfig.Kdf)
+	assert.Equal(t, key.GetFingerprint(),
+		storedMetadata.EncryptionConfig.KeyFingerprint)
+
+	t.Run("Decrypt with same passphrase", func(t *testing.T) {
+		readKey, err := keySource.GetEncryptionKey(
+			storedMetadata.EncryptionConfig)
+		assert.Nil(t, err)
+
+		decryptedMetadata, err := rw.DecryptMetaData(readKey, storedMetadata)
+		assert.Nil(t, err)
+		assert.Equal(t, "/secret/path", decryptedMetadata.ParentSnapshotPath)
+
+		fileReader, err := rw.GetFileReaderWriterForMetadata(ctx,
+			metadataFilePath, storedMetadata)
+		assert.Nil(t, err)
+		reader, err := rw.GetEncryptedReaderWriter(fileReader, readKey)
+		assert.Nil(t, err)
+
+		readPage, err := reader.ReadPage(ctx, pageId)
+		assert.Nil(t, err)
+		assert.Equal(t, page.ID, readPage.ID)
+
+		// Raw object is the serialized object before encryption
+		rawBytes, err := reader.(rw.RawReaderWriter).ReadRawObject(ctx,
+			metadata.NotionObjectType_PAGE, pageId)
+		assert.Nil(t, err)
+		checksum, err := rw.GetObjectChecksum(page)
+		assert.Nil(t, err)
+		assert.Equal(t, checksum, rw.GetChecksum(rawBytes))
+
+		readBlock, err := reader.ReadBlock(ctx, blockId)
+		assert.Nil(t, err)
+		assert.Equal(t, block.ID, readBlock.GetID())
+
+		// Objects are reused as is for the same key and encrypted again for a
+		// different key
+		newDir := t.TempDir()
+		newFileRW, err := rw.GetFileReaderWriter(ctx, newDir, false)
+		assert.Nil(t, err)
+		newWriter, err := rw.GetEncryptedReaderWriter(newFileRW, readKey)
+		assert.Nil(t, err)
+		reusedId, err := newWriter.ReuseObject(ctx, reader,
+			metadata.NotionObjectType_PAGE, pageId)
+		assert.Nil(t, err)
+		assert.Equal(t, pageId, reusedId)
+
+		newReader, err := rw.GetEncryptedReaderWriter(newFileRW, readKey)
+		assert.Nil(t, err)
+		readPage, err = newReader.ReadPage(ctx, reusedId)
+		assert.Nil(t, err)
+		assert.Equal(t, page.ID, readPage.ID)
+
+		otherKey, err := keySource.NewEncryptionKey()
+		assert.Nil(t, err)
+		otherWriter, err := rw.GetEncryptedReaderWriter(newFileRW, otherKey)
+		assert.Nil(t, err)
+		copiedId, err := otherWriter.ReuseObject(ctx, reader,
+			metadata.NotionObjectType_BLOCK, blockId)
+		assert.Nil(t, err)
+		assert.NotEqual(t, blockId, copiedId)
+
+		otherReader, err := rw.GetEncryptedReaderWriter(newFileRW, otherKey)
+		assert.Nil(t, err)
+		readBlock, err = otherReader.ReadBlock(ctx, copiedId)
+		assert.Nil(t, err)
+		assert.Equal(t, block.ID, readBlock.GetID())
+	})
+
+	t.Run("Wrong passphrase", func(t *testing.T) {
+		wrongSource, err := rw.GetKeySourceFromPassphraseFile(
+			writeSecretFile(t, "wrong"))
+		assert.Nil(t, err)
+
+		_, err = wrongSource.GetEncryptionKey(storedMetadata.EncryptionConfig)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Key file for passphrase encrypted backup", func(t *testing.T) {
+		keyFileSource, err := rw.GetKeySourceFromKeyFile(writeSecretFile(t,
+			strings.Repeat("ab", rw.KEY_LENGTH)))
+		assert.Nil(t, err)
+
+		_, err = keyFileSource.GetEncryptionKey(storedMetadata.EncryptionConfig)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Tampered object", func(t *testing.T) {
+		fileReader, err := rw.GetFileReaderWriterForMetadata(ctx,
+			metadataFilePath, storedMetadata)
+		assert.Nil(t, err)
+		reader, err := rw.GetEncryptedReaderWriter(fileReader, key)
+		assert.Nil(t, err)
+
+		// Page object moved to blocks fails authentication
+		dataBytes, err := os.ReadFile(filepath.Join(dir, rw.PAGE_DIR_NAME,
+			pageId.String()))
+		assert.Nil(t, err)
+		err = os.WriteFile(filepath.Join(dir, rw.BLOCK_DIR_NAME,
+			pageId.String()), dataBytes, 0600)
+		assert.Nil(t, err)
+
+		_, err = reader.ReadBlock(ctx, pageId)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "decrypt")
+	})
+
+	t.Run("Swapped objects", func(t *testing.T) {
+		fileRW, err := rw.GetFileReaderWriter(ctx, t.TempDir(), false)
+		assert.Nil(t, err)
+		writer, err := rw.GetEncryptedReaderWriter(fileRW, key)
+		assert.Nil(t, err)
+
+		firstId, err := writer.WriteBlock(ctx, block)
+		assert.Nil(t, err)
+		secondId, err := writer.WriteBlock(ctx, &notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     "other_block_id",
+				Type:   notionapi.BlockTypeParagraph,
+			},
+		})
+		assert.Nil(t, err)
+
+		// Block stored in place of another block fails authentication
+		rawRW := fileRW.(rw.RawReaderWriter)
+		dataBytes, err := rawRW.ReadRawObject(ctx,
+			metadata.NotionObjectType_BLOCK, secondId)
+		assert.Nil(t, err)
+		copiedId, err := rawRW.WriteRawObject(ctx,
+			metadata.NotionObjectType_BLOCK, dataBytes)
+		assert.Nil(t, err)
+
+		_, err = writer.ReadBlock(ctx, copiedId)
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "decrypt")
+
+		readBlock, err := writer.ReadBlock(ctx, firstId)
+		assert.Nil(t, err)
+		assert.Equal(t, block.ID, readBlock.GetID())
+	})
+}
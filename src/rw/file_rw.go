@@ -20,6 +20,8 @@ const (
 	DATABASE_DIR_NAME  = "databases"
 	PAGE_DIR_NAME      = "pages"
 	BLOCK_DIR_NAME     = "blocks"
+	ASSET_DIR_NAME     = "assets"
+	COMMENT_DIR_NAME   = "comments"
 	OBJECT_FILE_PERM   = 0400
 	METADATA_FILE_PERM = 0644
 	METADATA_FILE_NAME = "metadata.pb"
@@ -30,6 +32,8 @@ type FileReaderWriter struct {
 	databaseDirPath string
 	pageDirPath     string
 	blockDirPath    string
+	assetDirPath    string
+	commentDirPath  string
 	filePathList    []string
 }
 
@@ -60,6 +64,14 @@ func GetFileReaderWriter(ctx context.Context, basePath string,
 	log.Info().Str(logging.ExportPath, blockDirPath).Msg(
 		"Block objects backup path")
 
+	assetDirPath := filepath.Join(basePath, ASSET_DIR_NAME)
+	log.Info().Str(logging.ExportPath, assetDirPath).Msg(
+		"Assets backup path")
+
+	commentDirPath := filepath.Join(basePath, COMMENT_DIR_NAME)
+	log.Info().Str(logging.ExportPath, commentDirPath).Msg(
+		"Comment objects backup path")
+
 	err = utils.CreateDirectory(databaseDirPath)
 	if err != nil {
 		return nil, err
@@ -75,11 +87,23 @@ func GetFileReaderWriter(ctx context.Context, basePath string,
 		return nil, err
 	}
 
+	err = utils.CreateDirectory(assetDirPath)
+	if err != nil {
+		return nil, err
+	}
+
+	err = utils.CreateDirectory(commentDirPath)
+	if err != nil {
+		return nil, err
+	}
+
 	return &FileReaderWriter{
 		baseDirPath:     basePath,
 		databaseDirPath: databaseDirPath,
 		pageDirPath:     pageDirPath,
 		blockDirPath:    blockDirPath,
+		assetDirPath:    assetDirPath,
+		commentDirPath:  commentDirPath,
 		filePathList:    make([]string, 0),
 	}, nil
 }
@@ -113,31 +137,72 @@ func GetFileReaderWriterForMetadata(ctx context.Context,
 		return nil, err
 	}
 
+	// Backups taken by older versions do not have the assets directory
+	assetDir := ""
+	if data.StorageConfig.GetLocal().AssetsDir != "" {
+		assetDir = filepath.Join(absBasePath,
+			data.StorageConfig.GetLocal().AssetsDir)
+		err = utils.CheckIfDirExists(assetDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Backups taken by older versions do not have the comments directory
+	commentDir := ""
+	if data.StorageConfig.GetLocal().CommentsDir != "" {
+		commentDir = filepath.Join(absBasePath,
+			data.StorageConfig.GetLocal().CommentsDir)
+		err = utils.CheckIfDirExists(commentDir)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return &FileReaderWriter{
 		baseDirPath:     absBasePath,
 		databaseDirPath: databaseDir,
 		pageDirPath:     pageDir,
 		blockDirPath:    blockDir,
+		assetDirPath:    assetDir,
+		commentDirPath:  commentDir,
 		filePathList:    make([]string, 0),
 	}, nil
 }
 
 func (rw *FileReaderWriter) writeData(ctx context.Context, v interface{},
 	dirPath string) (DataIdentifier, error) {
-	dataIdentifier := uuid.NewString()
-	filePath := filepath.Join(dirPath, dataIdentifier)
 	dataBytes, err := json.Marshal(&v)
 	if err != nil {
 		return "", err
 	}
 
-	err = os.WriteFile(filePath, dataBytes, OBJECT_FILE_PERM)
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *FileReaderWriter) writeBytes(ctx context.Context, dataBytes []byte,
+	dirPath string) (DataIdentifier, error) {
+	dataIdentifier := DataIdentifier(uuid.NewString())
+	err := rw.writeFile(ctx, dataBytes, dirPath, dataIdentifier)
 	if err != nil {
 		return "", err
 	}
 
+	return dataIdentifier, nil
+}
+
+// Helper function to write the object in the directory with given identifier
+func (rw *FileReaderWriter) writeFile(ctx context.Context, dataBytes []byte,
+	dirPath string, identifier DataIdentifier) error {
+	filePath := filepath.Join(dirPath, identifier.String())
+	err := os.WriteFile(filePath, dataBytes, OBJECT_FILE_PERM)
+	if err != nil {
+		return err
+	}
+
+	recordWrite(ctx, FILE_RW_TYPE, len(dataBytes))
 	rw.filePathList = append(rw.filePathList, filePath)
-	return DataIdentifier(dataIdentifier), nil
+	return nil
 }
 
 func (rw *FileReaderWriter) readData(ctx context.Context, filePath string,
@@ -221,6 +286,36 @@ func (rw *FileReaderWriter) ReadBlock(ctx context.Context,
 	return utils.DecodeBlockObject(response)
 }
 
+func (rw *FileReaderWriter) WriteComment(ctx context.Context,
+	comment *notionapi.Comment) (DataIdentifier, error) {
+	if comment == nil {
+		return "", fmt.Errorf("nullptr received for comment object")
+	}
+
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeData(ctx, comment, dirPath)
+}
+
+func (rw *FileReaderWriter) ReadComment(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Comment, error) {
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &notionapi.Comment{}
+	err = rw.readData(ctx, filepath.Join(dirPath, identifier.String()),
+		&comment)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
 func (rw *FileReaderWriter) CleanUp(ctx context.Context) error {
 	var externalErr error
 	externalErr = nil
@@ -250,15 +345,129 @@ func (rw *FileReaderWriter) WriteMetaData(ctx context.Context,
 		return err
 	}
 
+	recordWrite(ctx, FILE_RW_TYPE, len(dataBytes))
 	return nil
 }
 
+// Summary is written next to the metadata file
+func (rw *FileReaderWriter) WriteSummary(ctx context.Context,
+	dataBytes []byte) error {
+	return writeSummaryFile(rw.baseDirPath, dataBytes)
+}
+
+// Helper function to get the directory in which objects of given type are
+// stored
+func (rw *FileReaderWriter) getObjectDirPath(
+	objectType metadata.NotionObjectType) (string, error) {
+	switch objectType {
+	case metadata.NotionObjectType_PAGE:
+		return rw.pageDirPath, nil
+	case metadata.NotionObjectType_DATABASE:
+		return rw.databaseDirPath, nil
+	case metadata.NotionObjectType_BLOCK:
+		return rw.blockDirPath, nil
+	case metadata.NotionObjectType_ASSET:
+		if rw.assetDirPath != "" {
+			return rw.assetDirPath, nil
+		}
+	case metadata.NotionObjectType_COMMENT:
+		if rw.commentDirPath != "" {
+			return rw.commentDirPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("no directory for object of type %s", objectType)
+}
+
+// Objects stored by another FileReaderWriter are hard linked (or copied if
+// linking is not possible) into this directory with the same identifier. Objects
+// from any other ReaderWriter are read and written again
+func (rw *FileReaderWriter) ReuseObject(ctx context.Context,
+	source ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	sourceRW, ok := source.(*FileReaderWriter)
+	if !ok {
+		return copyObject(ctx, source, rw, objectType, identifier)
+	}
+
+	sourceDirPath, err := sourceRW.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	sourcePath := filepath.Join(sourceDirPath, identifier.String())
+	filePath := filepath.Join(dirPath, identifier.String())
+
+	// Object already exists when backup is taken in the same directory as of
+	// the previous snapshot
+	if _, err := os.Stat(filePath); err == nil {
+		return identifier, nil
+	}
+
+	err = os.Link(sourcePath, filePath)
+	if err != nil {
+		dataBytes, err := os.ReadFile(sourcePath)
+		if err != nil {
+			return "", err
+		}
+
+		err = os.WriteFile(filePath, dataBytes, OBJECT_FILE_PERM)
+		if err != nil {
+			return "", err
+		}
+		recordWrite(ctx, FILE_RW_TYPE, len(dataBytes))
+	}
+
+	rw.filePathList = append(rw.filePathList, filePath)
+	return identifier, nil
+}
+
+func (rw *FileReaderWriter) WriteRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	dataBytes []byte) (DataIdentifier, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *FileReaderWriter) writeRawObjectWithIdentifier(ctx context.Context,
+	objectType metadata.NotionObjectType, identifier DataIdentifier,
+	dataBytes []byte) error {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return err
+	}
+
+	return rw.writeFile(ctx, dataBytes, dirPath, identifier)
+}
+
+func (rw *FileReaderWriter) ReadRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filepath.Join(dirPath, identifier.String()))
+}
+
 func (rw *FileReaderWriter) GetStorageConfig(ctx context.Context) (
 	*metadata.StorageConfig, error) {
 	localConfig := &metadata.StorageConfig_Local{
 		DatabaseDir: DATABASE_DIR_NAME,
 		PageDir:     PAGE_DIR_NAME,
 		BlocksDir:   BLOCK_DIR_NAME,
+		AssetsDir:   ASSET_DIR_NAME,
+		CommentsDir: COMMENT_DIR_NAME,
 	}
 
 	return &metadata.StorageConfig{
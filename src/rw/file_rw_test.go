@@ -2,6 +2,8 @@ package rw_test
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -11,6 +13,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/jomei/notionapi"
 	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/metrics"
 	"github.com/shivaji17/notionbackup/src/rw"
 	"github.com/shivaji17/notionbackup/src/utils"
 	"github.com/stretchr/testify/assert"
@@ -745,6 +748,196 @@ func TestCleanUp(t *testing.T) {
 	}
 }
 
+func TestReuseObject(t *testing.T) {
+	block := &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "some_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}
+
+	t.Run("Reuse object of another directory", func(t *testing.T) {
+		sourceRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+		destinationRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+
+		id, err := sourceRW.WriteBlock(context.Background(), block)
+		assert.Nil(t, err)
+
+		reusedId, err := destinationRW.ReuseObject(context.Background(), sourceRW,
+			metadata.NotionObjectType_BLOCK, id)
+		assert.Nil(t, err)
+		assert.Equal(t, id, reusedId)
+
+		reusedBlock, err := destinationRW.ReadBlock(context.Background(),
+			reusedId)
+		assert.Nil(t, err)
+		assert.Equal(t, block.ID, reusedBlock.GetID())
+
+		// Cleanup of destination must not remove object of source
+		err = destinationRW.CleanUp(context.Background())
+		assert.Nil(t, err)
+		_, err = sourceRW.ReadBlock(context.Background(), id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Reuse object of same directory", func(t *testing.T) {
+		dir := t.TempDir()
+		sourceRW, err := rw.GetFileReaderWriter(context.Background(), dir, true)
+		assert.Nil(t, err)
+		destinationRW, err := rw.GetFileReaderWriter(context.Background(), dir,
+			true)
+		assert.Nil(t, err)
+
+		id, err := sourceRW.WriteBlock(context.Background(), block)
+		assert.Nil(t, err)
+
+		reusedId, err := destinationRW.ReuseObject(context.Background(), sourceRW,
+			metadata.NotionObjectType_BLOCK, id)
+		assert.Nil(t, err)
+		assert.Equal(t, id, reusedId)
+
+		err = destinationRW.CleanUp(context.Background())
+		assert.Nil(t, err)
+		_, err = sourceRW.ReadBlock(context.Background(), id)
+		assert.Nil(t, err)
+	})
+
+	t.Run("Non existing object", func(t *testing.T) {
+		sourceRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+		destinationRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+
+		reusedId, err := destinationRW.ReuseObject(context.Background(), sourceRW,
+			metadata.NotionObjectType_PAGE, rw.DataIdentifier(uuid.NewString()))
+		assert.NotNil(t, err)
+		assert.Empty(t, reusedId)
+	})
+}
+
+func TestAsset(t *testing.T) {
+	dataBytes := []byte("\x89PNG image bytes")
+
+	t.Run("Write and read asset", func(t *testing.T) {
+		dir := t.TempDir()
+		filerw, err := rw.GetFileReaderWriter(context.Background(), dir, true)
+		assert.Nil(t, err)
+
+		id, err := rw.WriteAsset(context.Background(), filerw, dataBytes)
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(dir, rw.ASSET_DIR_NAME, id.String()))
+
+		readBytes, err := rw.ReadAsset(context.Background(), filerw, id)
+		assert.Nil(t, err)
+		assert.Equal(t, dataBytes, readBytes)
+	})
+
+	t.Run("Reuse asset of another directory", func(t *testing.T) {
+		sourceRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+		destinationRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(t, err)
+
+		id, err := rw.WriteAsset(context.Background(), sourceRW, dataBytes)
+		assert.Nil(t, err)
+
+		reusedId, err := destinationRW.ReuseObject(context.Background(), sourceRW,
+			metadata.NotionObjectType_ASSET, id)
+		assert.Nil(t, err)
+
+		readBytes, err := rw.ReadAsset(context.Background(), destinationRW,
+			reusedId)
+		assert.Nil(t, err)
+		assert.Equal(t, dataBytes, readBytes)
+	})
+
+	t.Run("Backup without assets directory", func(t *testing.T) {
+		metadataObj := &metadata.MetaData{
+			StorageConfig: &metadata.StorageConfig{
+				Config: &metadata.StorageConfig_Local_{
+					Local: &metadata.StorageConfig_Local{
+						BlocksDir:   rw.BLOCK_DIR_NAME,
+						PageDir:     rw.PAGE_DIR_NAME,
+						DatabaseDir: rw.DATABASE_DIR_NAME,
+					},
+				},
+			},
+		}
+		path := createDirs(t, t.TempDir(), true, true, true, metadataObj)
+
+		filerw, err := rw.GetFileReaderWriterForMetadata(context.Background(),
+			path, metadataObj)
+		assert.Nil(t, err)
+
+		_, err = rw.ReadAsset(context.Background(), filerw,
+			rw.DataIdentifier(uuid.NewString()))
+		assert.NotNil(t, err)
+	})
+}
+
+func TestComment(t *testing.T) {
+	comment := &notionapi.Comment{
+		Object:       notionapi.ObjectTypeComment,
+		ID:           notionapi.ObjectID(uuid.NewString()),
+		DiscussionID: notionapi.DiscussionID(uuid.NewString()),
+		RichText: []notionapi.RichText{{
+			Type:      notionapi.ObjectTypeText,
+			Text:      &notionapi.Text{Content: "comment"},
+			PlainText: "comment",
+		}},
+	}
+
+	t.Run("Write and read comment", func(t *testing.T) {
+		dir := t.TempDir()
+		filerw, err := rw.GetFileReaderWriter(context.Background(), dir, true)
+		assert.Nil(t, err)
+
+		id, err := filerw.WriteComment(context.Background(), comment)
+		assert.Nil(t, err)
+		assert.FileExists(t, filepath.Join(dir, rw.COMMENT_DIR_NAME,
+			id.String()))
+
+		readComment, err := filerw.ReadComment(context.Background(), id)
+		assert.Nil(t, err)
+		assert.Equal(t, comment, readComment)
+
+		_, err = filerw.WriteComment(context.Background(), nil)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Backup without comments directory", func(t *testing.T) {
+		metadataObj := &metadata.MetaData{
+			StorageConfig: &metadata.StorageConfig{
+				Config: &metadata.StorageConfig_Local_{
+					Local: &metadata.StorageConfig_Local{
+						BlocksDir:   rw.BLOCK_DIR_NAME,
+						PageDir:     rw.PAGE_DIR_NAME,
+						DatabaseDir: rw.DATABASE_DIR_NAME,
+					},
+				},
+			},
+		}
+		path := createDirs(t, t.TempDir(), true, true, true, metadataObj)
+
+		filerw, err := rw.GetFileReaderWriterForMetadata(context.Background(),
+			path, metadataObj)
+		assert.Nil(t, err)
+
+		_, err = filerw.ReadComment(context.Background(),
+			rw.DataIdentifier(uuid.NewString()))
+		assert.NotNil(t, err)
+	})
+}
+
 func TestWriteMetaData(t *testing.T) {
 	t.Run("File write successful", func(t *testing.T) {
 		filerw, err := rw.GetFileReaderWriter(context.Background(),
@@ -754,6 +947,43 @@ func TestWriteMetaData(t *testing.T) {
 
 		err = filerw.WriteMetaData(context.Background(), &metadata.MetaData{})
 		assert.Nil(t, err)
+		assert.NoFileExists(t, filepath.Join(TESTDATAPATH, rw.SUMMARY_FILE_NAME))
+	})
+
+	t.Run("Summary of the run", func(t *testing.T) {
+		dir := t.TempDir()
+		recorder := metrics.GetMetrics().GetRecorder(metrics.BACKUP, "")
+		ctx := recorder.WithContext(context.Background())
+		filerw, err := rw.GetFileReaderWriter(ctx, dir, false)
+		assert.Nil(t, err)
+
+		recorder.SetSnapshotId("id")
+		recorder.OnFinish(func(summary *metrics.Summary) {
+			assert.Nil(t, rw.WriteSummary(ctx, filerw, summary))
+		})
+
+		_, err = filerw.WritePage(ctx, &notionapi.Page{ID: "page_id"})
+		assert.Nil(t, err)
+		err = filerw.WriteMetaData(ctx, &metadata.MetaData{SnapshotId: "id"})
+		assert.Nil(t, err)
+
+		// Summary is only written once the run is finished
+		summaryPath := filepath.Join(dir, rw.SUMMARY_FILE_NAME)
+		assert.NoFileExists(t, summaryPath)
+		recorder.Finish(errors.New("failed"))
+
+		dataBytes, err := os.ReadFile(summaryPath)
+		assert.Nil(t, err)
+		summary := &metrics.Summary{}
+		err = json.Unmarshal(dataBytes, summary)
+		assert.Nil(t, err)
+		assert.Equal(t, "id", summary.SnapshotId)
+		assert.Equal(t, metrics.BACKUP, summary.Operation)
+		assert.Equal(t, metrics.FAILURE_OUTCOME, summary.Outcome)
+		assert.Equal(t, "failed", summary.Error)
+		assert.NotNil(t, summary.FinishedAt)
+		assert.Equal(t, int64(2), summary.ObjectsWritten[rw.FILE_RW_TYPE])
+		assert.Greater(t, summary.BytesStored[rw.FILE_RW_TYPE], int64(0))
 	})
 
 	// TODO: Add negative test cases
@@ -771,6 +1001,8 @@ func TestFillStorageConfig(t *testing.T) {
 				BlocksDir:   rw.BLOCK_DIR_NAME,
 				PageDir:     rw.PAGE_DIR_NAME,
 				DatabaseDir: rw.DATABASE_DIR_NAME,
+				AssetsDir:   rw.ASSET_DIR_NAME,
+				CommentsDir: rw.COMMENT_DIR_NAME,
 			},
 		},
 	}
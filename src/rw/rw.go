@@ -2,9 +2,31 @@ package rw
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
 
 	"github.com/jomei/notionapi"
 	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/metrics"
+	"github.com/shivaji17/notionbackup/src/progress"
+)
+
+const (
+	// Types of the ReaderWriters with which the written objects are recorded
+	FILE_RW_TYPE    = "file"
+	ARCHIVE_RW_TYPE = "archive"
+	CAS_RW_TYPE     = "content_addressed"
+	S3_RW_TYPE      = "s3"
+
+	// Summary of the run is written next to the metadata of the snapshot once
+	// the run is finished
+	SUMMARY_FILE_NAME = "summary.json"
 )
 
 type DataIdentifier string
@@ -21,6 +43,218 @@ type ReaderWriter interface {
 	ReadPage(context.Context, DataIdentifier) (*notionapi.Page, error)
 	WriteBlock(context.Context, notionapi.Block) (DataIdentifier, error)
 	ReadBlock(context.Context, DataIdentifier) (notionapi.Block, error)
+	WriteComment(context.Context, *notionapi.Comment) (DataIdentifier, error)
+	ReadComment(context.Context, DataIdentifier) (*notionapi.Comment, error)
 	WriteMetaData(context.Context, *metadata.MetaData) error
 	CleanUp(context.Context) error
+
+	// Make the object of given type stored by another ReaderWriter with given
+	// identifier available in this ReaderWriter. Returns the identifier with
+	// which the object can be read from this ReaderWriter
+	ReuseObject(context.Context, ReaderWriter, metadata.NotionObjectType,
+		DataIdentifier) (DataIdentifier, error)
+}
+
+// RawReaderWriter reads and writes the serialized objects as is, so that
+// decorators like EncryptedReaderWriter can transform the objects before they
+// are stored and objects can be copied and verified without decoding them
+type RawReaderWriter interface {
+	ReaderWriter
+	WriteRawObject(context.Context, metadata.NotionObjectType,
+		[]byte) (DataIdentifier, error)
+	ReadRawObject(context.Context, metadata.NotionObjectType,
+		DataIdentifier) ([]byte, error)
+}
+
+// RawReaderWriter which stores the serialized object with the identifier
+// given by the caller instead of deciding it
+type identifiedReaderWriter interface {
+	RawReaderWriter
+	writeRawObjectWithIdentifier(context.Context, metadata.NotionObjectType,
+		DataIdentifier, []byte) error
+}
+
+// Get the hex encoded SHA-256 checksum of the serialized object
+func GetChecksum(dataBytes []byte) string {
+	hash := sha256.Sum256(dataBytes)
+	return hex.EncodeToString(hash[:])
+}
+
+// Get the checksum of the object as serialized by the ReaderWriters before
+// storing it
+func GetObjectChecksum(v interface{}) (string, error) {
+	dataBytes, err := json.Marshal(&v)
+	if err != nil {
+		return "", err
+	}
+
+	return GetChecksum(dataBytes), nil
+}
+
+// Helper function to record the object of the size written by the
+// ReaderWriter of the type
+func recordWrite(ctx context.Context, rwType string, size int) {
+	progress.Ctx(ctx).AddBytesWritten(size)
+	metrics.Ctx(ctx).AddObjectWritten(rwType, size)
+}
+
+// SummaryWriter is implemented by the ReaderWriters which can write the
+// summary of the run along with the snapshot, whether the run failed or not
+type SummaryWriter interface {
+	WriteSummary(context.Context, []byte) error
+}
+
+// Write the summary of the run with the ReaderWriter if it supports writing
+// summaries
+func WriteSummary(ctx context.Context, readerWriter ReaderWriter,
+	summary *metrics.Summary) error {
+	summaryWriter, ok := readerWriter.(SummaryWriter)
+	if !ok || summary == nil {
+		return nil
+	}
+
+	dataBytes, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return summaryWriter.WriteSummary(ctx, dataBytes)
+}
+
+// Helper function to write the summary to the file in the directory. Nothing
+// is written if the directory of the failed snapshot has been removed
+func writeSummaryFile(dirPath string, dataBytes []byte) error {
+	if _, err := os.Stat(dirPath); os.IsNotExist(err) {
+		return nil
+	}
+
+	return os.WriteFile(filepath.Join(dirPath, SUMMARY_FILE_NAME), dataBytes,
+		METADATA_FILE_PERM)
+}
+
+// Helper function to copy the object from source ReaderWriter to destination
+// ReaderWriter by reading and writing it again. Serialized object is copied as
+// is if both ReaderWriters implement RawReaderWriter, and keeps its identifier
+// if destination can store it with the given identifier. Otherwise identifier
+// of the copied object is decided by destination ReaderWriter
+func copyObject(ctx context.Context, source ReaderWriter,
+	destination ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	rawSource, isSourceRaw := source.(RawReaderWriter)
+	rawDestination, isDestinationRaw := destination.(RawReaderWriter)
+	if isSourceRaw && isDestinationRaw {
+		dataBytes, err := rawSource.ReadRawObject(ctx, objectType, identifier)
+		if err != nil {
+			return "", err
+		}
+
+		// Encrypted objects are authenticated along with their identifier, so
+		// they can only be read with the same identifier
+		identifiedRW, ok := destination.(identifiedReaderWriter)
+		if !ok {
+			return rawDestination.WriteRawObject(ctx, objectType, dataBytes)
+		}
+
+		err = identifiedRW.writeRawObjectWithIdentifier(ctx, objectType,
+			identifier, dataBytes)
+		if err != nil {
+			return "", err
+		}
+		return identifier, nil
+	}
+
+	switch objectType {
+	case metadata.NotionObjectType_PAGE:
+		page, err := source.ReadPage(ctx, identifier)
+		if err != nil {
+			return "", err
+		}
+		return destination.WritePage(ctx, page)
+	case metadata.NotionObjectType_DATABASE:
+		database, err := source.ReadDatabase(ctx, identifier)
+		if err != nil {
+			return "", err
+		}
+		return destination.WriteDatabase(ctx, database)
+	case metadata.NotionObjectType_BLOCK:
+		block, err := source.ReadBlock(ctx, identifier)
+		if err != nil {
+			return "", err
+		}
+		return destination.WriteBlock(ctx, block)
+	case metadata.NotionObjectType_COMMENT:
+		comment, err := source.ReadComment(ctx, identifier)
+		if err != nil {
+			return "", err
+		}
+		return destination.WriteComment(ctx, comment)
+	}
+
+	return "", fmt.Errorf("cannot copy object of type %s", objectType)
+}
+
+// Store the downloaded asset in the assets area of the ReaderWriter. Assets
+// are stored as is, without serializing them
+func WriteAsset(ctx context.Context, rwClient ReaderWriter,
+	dataBytes []byte) (DataIdentifier, error) {
+	rawRW, ok := rwClient.(RawReaderWriter)
+	if !ok {
+		return "", fmt.Errorf("ReaderWriter does not support storing assets")
+	}
+
+	return rawRW.WriteRawObject(ctx, metadata.NotionObjectType_ASSET, dataBytes)
+}
+
+// Read the asset stored with given identifier from the assets area of the
+// ReaderWriter
+func ReadAsset(ctx context.Context, rwClient ReaderWriter,
+	identifier DataIdentifier) ([]byte, error) {
+	rawRW, ok := rwClient.(RawReaderWriter)
+	if !ok {
+		return nil, fmt.Errorf("ReaderWriter does not support reading assets")
+	}
+
+	return rawRW.ReadRawObject(ctx, metadata.NotionObjectType_ASSET, identifier)
+}
+
+// Get the metadata file paths and archive paths of all the snapshots in the
+// root directory sorted by name. Snapshots are the directories containing a
+// metadata file or an archive and the archives in the root directory, or the
+// snapshots of the object pool if the root directory is an object pool
+func GetSnapshotPaths(rootDir string) ([]string, error) {
+	if IsObjectPool(rootDir) {
+		return GetSnapshotMetadataPaths(rootDir)
+	}
+
+	entries, err := ioutil.ReadDir(rootDir)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		entryPath := filepath.Join(rootDir, entry.Name())
+		if !entry.IsDir() {
+			if GetArchiveFormat(entry.Name()) != "" {
+				paths = append(paths, entryPath)
+			}
+			continue
+		}
+
+		metadataFilePath := filepath.Join(entryPath, METADATA_FILE_NAME)
+		if _, err := os.Stat(metadataFilePath); err == nil {
+			paths = append(paths, metadataFilePath)
+			continue
+		}
+
+		for _, format := range []string{TAR_GZ_FORMAT, ZIP_FORMAT} {
+			archivePath := filepath.Join(entryPath, ARCHIVE_FILE_NAME+"."+format)
+			if _, err := os.Stat(archivePath); err == nil {
+				paths = append(paths, archivePath)
+			}
+		}
+	}
+
+	sort.Strings(paths)
+	return paths, nil
 }
@@ -0,0 +1,656 @@
+package rw
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/utils"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	S3_URL_SCHEME         = "s3"
+	DEFAULT_S3_ENDPOINT   = "s3.amazonaws.com"
+	OBJECT_CONTENT_TYPE   = "application/json"
+	METADATA_CONTENT_TYPE = "application/octet-stream"
+	SUMMARY_CONTENT_TYPE  = "application/json"
+)
+
+// Configuration to connect to the bucket of S3 compatible object storage.
+// Credentials are read from AWS or MinIO environment variables and AWS
+// credentials file if access key is not provided
+type S3Config struct {
+	// Endpoint with or without scheme. Plain HTTP is used for http:// scheme
+	Endpoint  string
+	Region    string
+	Bucket    string
+	Prefix    string
+	AccessKey string
+	SecretKey string
+
+	// Use plain HTTP instead of HTTPS
+	Insecure bool
+}
+
+// Check if the path is an S3 URL of the form s3://bucket/prefix
+func IsS3URL(rawURL string) bool {
+	return strings.HasPrefix(rawURL, S3_URL_SCHEME+"://")
+}
+
+// Parse the S3 URL of the form s3://bucket/prefix into bucket and prefix.
+// Trailing metadata file name is removed from the prefix, so URL of the
+// metadata object can also be used
+func ParseS3URL(rawURL string) (string, string, error) {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "", "", err
+	}
+
+	if parsedURL.Scheme != S3_URL_SCHEME || parsedURL.Host == "" {
+		return "", "", fmt.Errorf("invalid S3 URL: %s", rawURL)
+	}
+
+	prefix := strings.Trim(parsedURL.Path, "/")
+	if path.Base(prefix) == METADATA_FILE_NAME {
+		prefix = strings.TrimSuffix(strings.TrimSuffix(prefix,
+			METADATA_FILE_NAME), "/")
+	}
+
+	return parsedURL.Host, prefix, nil
+}
+
+// Helper function to get the endpoint without scheme and whether it should be
+// accessed over HTTPS
+func parseEndpoint(config *S3Config) (string, bool) {
+	endpoint := config.Endpoint
+	secure := !config.Insecure
+	if endpoint == "" {
+		return DEFAULT_S3_ENDPOINT, secure
+	}
+
+	if strings.HasPrefix(endpoint, "http://") {
+		endpoint = strings.TrimPrefix(endpoint, "http://")
+		secure = false
+	} else {
+		endpoint = strings.TrimPrefix(endpoint, "https://")
+	}
+
+	return strings.TrimSuffix(endpoint, "/"), secure
+}
+
+// Helper function to create the client of object storage
+func getS3Client(config *S3Config) (*minio.Client, string, error) {
+	if config.Bucket == "" {
+		return nil, "", fmt.Errorf("bucket name not provided")
+	}
+
+	providers := make([]credentials.Provider, 0)
+	if config.AccessKey != "" {
+		providers = append(providers, &credentials.Static{
+			Value: credentials.Value{
+				AccessKeyID:     config.AccessKey,
+				SecretAccessKey: config.SecretKey,
+				SignerType:      credentials.SignatureV4,
+			},
+		})
+	}
+	providers = append(providers, &credentials.EnvAWS{}, &credentials.EnvMinio{},
+		&credentials.FileAWSCredentials{})
+
+	endpoint, secure := parseEndpoint(config)
+	client, err := minio.New(endpoint, &minio.Options{
+		Creds:  credentials.NewChainCredentials(providers),
+		Secure: secure,
+		Region: config.Region,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	return client, endpoint, nil
+}
+
+// S3ReaderWriter stores all the objects and the metadata file as objects in a
+// bucket of S3 compatible object storage like AWS S3, MinIO or Ceph. Keys of
+// the objects are the identifiers prefixed by the prefix and the directory of
+// the object type
+type S3ReaderWriter struct {
+	client          *minio.Client
+	endpoint        string
+	region          string
+	bucket          string
+	prefix          string
+	databaseDirPath string
+	pageDirPath     string
+	blockDirPath    string
+	assetDirPath    string
+	commentDirPath  string
+	objectKeyList   []string
+
+	// Keys of written objects are recorded by concurrent writers
+	mutex sync.Mutex
+}
+
+// Create the ReaderWriter writing objects in the bucket under given prefix
+func GetS3ReaderWriter(ctx context.Context, config *S3Config) (ReaderWriter,
+	error) {
+	client, endpoint, err := getS3Client(config)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("bucket %s does not exist", config.Bucket)
+	}
+
+	prefix := strings.Trim(config.Prefix, "/")
+	zerolog.Ctx(ctx).Info().Str(logging.ExportPath,
+		S3_URL_SCHEME+"://"+path.Join(config.Bucket, prefix)).Msg(
+		"S3 backup path")
+
+	rw := &S3ReaderWriter{
+		client:          client,
+		endpoint:        endpoint,
+		region:          config.Region,
+		bucket:          config.Bucket,
+		prefix:          prefix,
+		databaseDirPath: DATABASE_DIR_NAME,
+		pageDirPath:     PAGE_DIR_NAME,
+		blockDirPath:    BLOCK_DIR_NAME,
+		assetDirPath:    ASSET_DIR_NAME,
+		commentDirPath:  COMMENT_DIR_NAME,
+		objectKeyList:   make([]string, 0),
+	}
+
+	// Snapshot under the prefix is never overwritten, as it may be the only
+	// copy of an earlier snapshot or the parent of this snapshot
+	err = rw.checkMetaDataNotExists(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return rw, nil
+}
+
+// Read the metadata stored in the bucket under given prefix and create the
+// ReaderWriter to read the objects of the backup
+func GetS3ReaderWriterForMetadata(ctx context.Context,
+	config *S3Config) (ReaderWriter, *metadata.MetaData, error) {
+	client, endpoint, err := getS3Client(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rw := &S3ReaderWriter{
+		client:   client,
+		endpoint: endpoint,
+		region:   config.Region,
+		bucket:   config.Bucket,
+		prefix:   strings.Trim(config.Prefix, "/"),
+	}
+
+	metadataKey := rw.getObjectKey(METADATA_FILE_NAME)
+	zerolog.Ctx(ctx).Info().Str(logging.MetaDataFilePath, metadataKey).Msg(
+		"Reading Metadata object")
+	dataBytes, err := rw.getObject(ctx, metadataKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metadataObj := &metadata.MetaData{}
+	err = proto.Unmarshal(dataBytes, metadataObj)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s3Config := metadataObj.GetStorageConfig().GetS3()
+	if s3Config == nil {
+		return nil, nil, fmt.Errorf("S3 storage config not found in metadata")
+	}
+
+	rw.databaseDirPath = s3Config.DatabaseDir
+	rw.pageDirPath = s3Config.PageDir
+	rw.blockDirPath = s3Config.BlocksDir
+	rw.assetDirPath = s3Config.AssetsDir
+	rw.commentDirPath = s3Config.CommentsDir
+	return rw, metadataObj, nil
+}
+
+// Helper function to get the key of the object with the prefix
+func (rw *S3ReaderWriter) getObjectKey(elem ...string) string {
+	return path.Join(append([]string{rw.prefix}, elem...)...)
+}
+
+// Helper function to check that the metadata object does not exist under the
+// prefix
+func (rw *S3ReaderWriter) checkMetaDataNotExists(ctx context.Context) error {
+	metadataKey := rw.getObjectKey(METADATA_FILE_NAME)
+	_, err := rw.client.StatObject(ctx, rw.bucket, metadataKey,
+		minio.StatObjectOptions{})
+	if err == nil {
+		return fmt.Errorf("metadata object %s already exists in bucket %s",
+			metadataKey, rw.bucket)
+	}
+
+	if minio.ToErrorResponse(err).Code == "NoSuchKey" {
+		return nil
+	}
+	return err
+}
+
+// Helper function to upload the object and record its key for clean up
+func (rw *S3ReaderWriter) putObject(ctx context.Context, key string,
+	dataBytes []byte, contentType string) error {
+	_, err := rw.client.PutObject(ctx, rw.bucket, key,
+		bytes.NewReader(dataBytes), int64(len(dataBytes)),
+		minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		return err
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.objectKeyList = append(rw.objectKeyList, key)
+	return nil
+}
+
+// Helper function to download the object
+func (rw *S3ReaderWriter) getObject(ctx context.Context,
+	key string) ([]byte, error) {
+	object, err := rw.client.GetObject(ctx, rw.bucket, key,
+		minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer object.Close()
+
+	return ioutil.ReadAll(object)
+}
+
+func (rw *S3ReaderWriter) writeData(ctx context.Context, v interface{},
+	dirPath string) (DataIdentifier, error) {
+	dataBytes, err := json.Marshal(&v)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *S3ReaderWriter) writeBytes(ctx context.Context, dataBytes []byte,
+	dirPath string) (DataIdentifier, error) {
+	dataIdentifier := DataIdentifier(uuid.NewString())
+	err := rw.writeObject(ctx, dataBytes, dirPath, dataIdentifier)
+	if err != nil {
+		return "", err
+	}
+
+	return dataIdentifier, nil
+}
+
+// Helper function to upload the object under the directory with given
+// identifier
+func (rw *S3ReaderWriter) writeObject(ctx context.Context, dataBytes []byte,
+	dirPath string, identifier DataIdentifier) error {
+	err := rw.putObject(ctx, rw.getObjectKey(dirPath, identifier.String()),
+		dataBytes, OBJECT_CONTENT_TYPE)
+	if err != nil {
+		return err
+	}
+
+	recordWrite(ctx, S3_RW_TYPE, len(dataBytes))
+	return nil
+}
+
+func (rw *S3ReaderWriter) readData(ctx context.Context, dirPath string,
+	identifier DataIdentifier, v interface{}) error {
+	dataBytes, err := rw.getObject(ctx, rw.getObjectKey(dirPath,
+		identifier.String()))
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(dataBytes, v)
+}
+
+func (rw *S3ReaderWriter) WriteDatabase(ctx context.Context,
+	database *notionapi.Database) (DataIdentifier, error) {
+	if database == nil {
+		return "", fmt.Errorf("nullptr received for database object")
+	}
+
+	return rw.writeData(ctx, database, rw.databaseDirPath)
+}
+
+func (rw *S3ReaderWriter) ReadDatabase(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Database, error) {
+	database := &notionapi.Database{}
+	err := rw.readData(ctx, rw.databaseDirPath, identifier, &database)
+	if err != nil {
+		return nil, err
+	}
+	return database, nil
+}
+
+func (rw *S3ReaderWriter) WritePage(ctx context.Context,
+	page *notionapi.Page) (DataIdentifier, error) {
+	if page == nil {
+		return "", fmt.Errorf("nullptr received for page object")
+	}
+
+	return rw.writeData(ctx, page, rw.pageDirPath)
+}
+
+func (rw *S3ReaderWriter) ReadPage(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Page, error) {
+	page := &notionapi.Page{}
+	err := rw.readData(ctx, rw.pageDirPath, identifier, &page)
+	if err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+func (rw *S3ReaderWriter) WriteBlock(ctx context.Context,
+	block notionapi.Block) (DataIdentifier, error) {
+	if block == nil {
+		return "", fmt.Errorf("nullptr received for block object")
+	}
+
+	return rw.writeData(ctx, block, rw.blockDirPath)
+}
+
+func (rw *S3ReaderWriter) ReadBlock(ctx context.Context,
+	identifier DataIdentifier) (notionapi.Block, error) {
+	dataBytes, err := rw.getObject(ctx, rw.getObjectKey(rw.blockDirPath,
+		identifier.String()))
+	if err != nil {
+		return nil, err
+	}
+
+	var response map[string]interface{}
+	err = json.Unmarshal(dataBytes, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.DecodeBlockObject(response)
+}
+
+func (rw *S3ReaderWriter) WriteComment(ctx context.Context,
+	comment *notionapi.Comment) (DataIdentifier, error) {
+	if comment == nil {
+		return "", fmt.Errorf("nullptr received for comment object")
+	}
+
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeData(ctx, comment, dirPath)
+}
+
+func (rw *S3ReaderWriter) ReadComment(ctx context.Context,
+	identifier DataIdentifier) (*notionapi.Comment, error) {
+	dirPath, err := rw.getObjectDirPath(metadata.NotionObjectType_COMMENT)
+	if err != nil {
+		return nil, err
+	}
+
+	comment := &notionapi.Comment{}
+	err = rw.readData(ctx, dirPath, identifier, &comment)
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+func (rw *S3ReaderWriter) WriteMetaData(ctx context.Context,
+	metadata *metadata.MetaData) error {
+	dataBytes, err := proto.Marshal(metadata)
+	if err != nil {
+		return err
+	}
+
+	err = rw.checkMetaDataNotExists(ctx)
+	if err != nil {
+		return err
+	}
+
+	metadataKey := rw.getObjectKey(METADATA_FILE_NAME)
+	zerolog.Ctx(ctx).Info().Str(logging.MetaDataFilePath, metadataKey).Msg(
+		"Writing Metadata object")
+	err = rw.putObject(ctx, metadataKey, dataBytes, METADATA_CONTENT_TYPE)
+	if err != nil {
+		return err
+	}
+
+	recordWrite(ctx, S3_RW_TYPE, len(dataBytes))
+	return nil
+}
+
+// Summary is written next to the metadata object under the prefix
+func (rw *S3ReaderWriter) WriteSummary(ctx context.Context,
+	dataBytes []byte) error {
+	return rw.putObject(ctx, rw.getObjectKey(SUMMARY_FILE_NAME), dataBytes,
+		SUMMARY_CONTENT_TYPE)
+}
+
+// Remove all the objects written by this ReaderWriter
+func (rw *S3ReaderWriter) CleanUp(ctx context.Context) error {
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+
+	for _, key := range rw.objectKeyList {
+		err := rw.client.RemoveObject(ctx, rw.bucket, key,
+			minio.RemoveObjectOptions{})
+		if err != nil {
+			return err
+		}
+	}
+
+	rw.objectKeyList = make([]string, 0)
+	return nil
+}
+
+// Helper function to get the directory of the object type
+func (rw *S3ReaderWriter) getObjectDirPath(
+	objectType metadata.NotionObjectType) (string, error) {
+	switch objectType {
+	case metadata.NotionObjectType_PAGE:
+		return rw.pageDirPath, nil
+	case metadata.NotionObjectType_DATABASE:
+		return rw.databaseDirPath, nil
+	case metadata.NotionObjectType_BLOCK:
+		return rw.blockDirPath, nil
+	case metadata.NotionObjectType_ASSET:
+		if rw.assetDirPath != "" {
+			return rw.assetDirPath, nil
+		}
+	case metadata.NotionObjectType_COMMENT:
+		if rw.commentDirPath != "" {
+			return rw.commentDirPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("no directory for object of type %s", objectType)
+}
+
+func (rw *S3ReaderWriter) WriteRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	dataBytes []byte) (DataIdentifier, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	return rw.writeBytes(ctx, dataBytes, dirPath)
+}
+
+func (rw *S3ReaderWriter) writeRawObjectWithIdentifier(ctx context.Context,
+	objectType metadata.NotionObjectType, identifier DataIdentifier,
+	dataBytes []byte) error {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return err
+	}
+
+	return rw.writeObject(ctx, dataBytes, dirPath, identifier)
+}
+
+func (rw *S3ReaderWriter) ReadRawObject(ctx context.Context,
+	objectType metadata.NotionObjectType,
+	identifier DataIdentifier) ([]byte, error) {
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return rw.getObject(ctx, rw.getObjectKey(dirPath, identifier.String()))
+}
+
+// Objects of another S3ReaderWriter on the same endpoint are copied on the
+// server side without downloading them. Objects already stored at the same
+// key are reused as is. Objects of other ReaderWriters are read and written
+// again
+func (rw *S3ReaderWriter) ReuseObject(ctx context.Context,
+	source ReaderWriter, objectType metadata.NotionObjectType,
+	identifier DataIdentifier) (DataIdentifier, error) {
+	sourceRW, ok := source.(*S3ReaderWriter)
+	if !ok || sourceRW.endpoint != rw.endpoint {
+		return copyObject(ctx, source, rw, objectType, identifier)
+	}
+
+	sourceDirPath, err := sourceRW.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	dirPath, err := rw.getObjectDirPath(objectType)
+	if err != nil {
+		return "", err
+	}
+
+	sourceKey := sourceRW.getObjectKey(sourceDirPath, identifier.String())
+	key := rw.getObjectKey(dirPath, identifier.String())
+	if sourceRW.bucket == rw.bucket && sourceKey == key {
+		return identifier, nil
+	}
+
+	_, err = rw.client.CopyObject(ctx,
+		minio.CopyDestOptions{Bucket: rw.bucket, Object: key},
+		minio.CopySrcOptions{Bucket: sourceRW.bucket, Object: sourceKey})
+	if err != nil {
+		return "", err
+	}
+
+	rw.mutex.Lock()
+	defer rw.mutex.Unlock()
+	rw.objectKeyList = append(rw.objectKeyList, key)
+	return identifier, nil
+}
+
+func (rw *S3ReaderWriter) GetStorageConfig(ctx context.Context) (
+	*metadata.StorageConfig, error) {
+	s3Config := &metadata.StorageConfig_S3{
+		Endpoint:    rw.endpoint,
+		Bucket:      rw.bucket,
+		Prefix:      rw.prefix,
+		Region:      rw.region,
+		DatabaseDir: rw.databaseDirPath,
+		PageDir:     rw.pageDirPath,
+		BlocksDir:   rw.blockDirPath,
+		AssetsDir:   rw.assetDirPath,
+		CommentsDir: rw.commentDirPath,
+	}
+
+	return &metadata.StorageConfig{
+		Config: &metadata.StorageConfig_S3_{
+			S3: s3Config,
+		},
+	}, nil
+}
+
+// S3AssetUploader uploads the assets of the backup to a bucket of S3
+// compatible object storage so that Notion can fetch them as external files.
+// Bucket must allow anonymous read access to the uploaded objects
+type S3AssetUploader struct {
+	client   *minio.Client
+	endpoint string
+	secure   bool
+	bucket   string
+	prefix   string
+}
+
+// Create the uploader uploading the assets in the bucket under given prefix
+func GetS3AssetUploader(ctx context.Context,
+	config *S3Config) (*S3AssetUploader, error) {
+	client, endpoint, err := getS3Client(config)
+	if err != nil {
+		return nil, err
+	}
+
+	found, err := client.BucketExists(ctx, config.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, fmt.Errorf("bucket %s does not exist", config.Bucket)
+	}
+
+	_, secure := parseEndpoint(config)
+	return &S3AssetUploader{
+		client:   client,
+		endpoint: endpoint,
+		secure:   secure,
+		bucket:   config.Bucket,
+		prefix:   strings.Trim(config.Prefix, "/"),
+	}, nil
+}
+
+// Upload the asset with a key derived from its checksum, so uploading the
+// same asset again overwrites the object with the same content. Returns the
+// URL of the object
+func (u *S3AssetUploader) UploadAsset(ctx context.Context,
+	asset *metadata.Asset, dataBytes []byte) (string, error) {
+	key := path.Join(u.prefix, ASSET_DIR_NAME, asset.Checksum, asset.FileName)
+	_, err := u.client.PutObject(ctx, u.bucket, key,
+		bytes.NewReader(dataBytes), int64(len(dataBytes)),
+		minio.PutObjectOptions{ContentType: asset.ContentType})
+	if err != nil {
+		return "", err
+	}
+
+	scheme := "https"
+	if !u.secure {
+		scheme = "http"
+	}
+
+	objectUrl := url.URL{
+		Scheme: scheme,
+		Host:   u.endpoint,
+		Path:   "/" + path.Join(u.bucket, key),
+	}
+	return objectUrl.String(), nil
+}
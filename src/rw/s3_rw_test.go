@@ -0,0 +1,329 @@
+package rw_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/stretchr/testify/assert"
+)
+
+const notFoundResponse = `<?xml version="1.0" encoding="UTF-8"?>` +
+	`<Error><Code>NoSuchKey</Code><Message>Not found</Message></Error>`
+
+const copyObjectResponse = `<?xml version="1.0" encoding="UTF-8"?>` +
+	`<CopyObjectResult><LastModified>2022-05-01T00:00:00.000Z</LastModified>` +
+	`<ETag>"etag"</ETag></CopyObjectResult>`
+
+// Helper function to decode the payload of request signed with streaming
+// signature. Payload is sent in chunks of form
+// "<hex size>;chunk-signature=<signature>\r\n<data>\r\n" ending with chunk of
+// size 0
+func readChunkedPayload(body io.Reader) ([]byte, error) {
+	reader := bufio.NewReader(body)
+	data := make([]byte, 0)
+	for {
+		header, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		sizeHex := strings.SplitN(strings.TrimSpace(header), ";", 2)[0]
+		size, err := strconv.ParseInt(sizeHex, 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		if size == 0 {
+			return data, nil
+		}
+
+		chunk := make([]byte, size+2)
+		_, err = io.ReadFull(reader, chunk)
+		if err != nil {
+			return nil, err
+		}
+		data = append(data, chunk[:size]...)
+	}
+}
+
+// In-process fake of S3 compatible object storage supporting path style
+// requests for the operations used by S3ReaderWriter. Requests are not
+// authenticated
+type fakeS3 struct {
+	bucket  string
+	objects map[string][]byte
+	mutex   sync.Mutex
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 2)
+	if parts[0] != f.bucket {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if len(parts) == 1 || parts[1] == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	key := parts[1]
+	switch r.Method {
+	case http.MethodPut:
+		if copySource := r.Header.Get("X-Amz-Copy-Source"); copySource != "" {
+			source, _ := url.PathUnescape(copySource)
+			sourceParts := strings.SplitN(strings.TrimPrefix(source, "/"), "/", 2)
+			data, found := f.objects[sourceParts[1]]
+			if !found {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(notFoundResponse))
+				return
+			}
+
+			f.objects[key] = data
+			w.Write([]byte(copyObjectResponse))
+			return
+		}
+
+		var data []byte
+		var err error
+		if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), "STREAMING-") {
+			data, err = readChunkedPayload(r.Body)
+		} else {
+			data, err = ioutil.ReadAll(r.Body)
+		}
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		f.objects[key] = data
+		w.Header().Set("ETag", `"etag"`)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		data, found := f.objects[key]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(notFoundResponse))
+			return
+		}
+
+		w.Header().Set("ETag", `"etag"`)
+		w.Header().Set("Last-Modified", "Sun, 01 May 2022 00:00:00 GMT")
+		w.Write(data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func getFakeS3Config(t *testing.T, prefix string) (*rw.S3Config, *fakeS3) {
+	fake := &fakeS3{
+		bucket:  "backups",
+		objects: make(map[string][]byte),
+	}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return &rw.S3Config{
+		Endpoint:  server.URL,
+		Region:    "us-east-1",
+		Bucket:    "backups",
+		Prefix:    prefix,
+		AccessKey: "access",
+		SecretKey: "secret",
+	}, fake
+}
+
+func TestParseS3URL(t *testing.T) {
+	tests := []struct {
+		url            string
+		expectedBucket string
+		expectedPrefix string
+		wantErr        bool
+	}{
+		{"s3://bucket/some/prefix", "bucket", "some/prefix", false},
+		{"s3://bucket/some/prefix/", "bucket", "some/prefix", false},
+		{"s3://bucket/some/prefix/metadata.pb", "bucket", "some/prefix", false},
+		{"s3://bucket/metadata.pb", "bucket", "", false},
+		{"s3://bucket", "bucket", "", false},
+		{"s3:///prefix", "", "", true},
+		{"https://bucket/prefix", "", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.url, func(t *testing.T) {
+			assert.Equal(t, strings.HasPrefix(test.url, "s3://"),
+				rw.IsS3URL(test.url))
+			bucket, prefix, err := rw.ParseS3URL(test.url)
+			if test.wantErr {
+				assert.NotNil(t, err)
+				return
+			}
+
+			assert.Nil(t, err)
+			assert.Equal(t, test.expectedBucket, bucket)
+			assert.Equal(t, test.expectedPrefix, prefix)
+		})
+	}
+}
+
+func TestS3ReaderWriter(t *testing.T) {
+	ctx := context.Background()
+	page := &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "page_id",
+	}
+	block := &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "block_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}
+
+	t.Run("Bucket does not exist", func(t *testing.T) {
+		config, _ := getFakeS3Config(t, "backup")
+		config.Bucket = "unknown"
+		_, err := rw.GetS3ReaderWriter(ctx, config)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Write and read objects", func(t *testing.T) {
+		config, fake := getFakeS3Config(t, "/backup/")
+		writer, err := rw.GetS3ReaderWriter(ctx, config)
+		assert.Nil(t, err)
+
+		pageId, err := writer.WritePage(ctx, page)
+		assert.Nil(t, err)
+		assert.Contains(t, fake.objects, "backup/pages/"+pageId.String())
+		blockId, err := writer.WriteBlock(ctx, block)
+		assert.Nil(t, err)
+
+		storageConfig, err := writer.GetStorageConfig(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "backups", storageConfig.GetS3().GetBucket())
+		assert.Equal(t, "backup", storageConfig.GetS3().GetPrefix())
+
+		err = writer.WriteMetaData(ctx, &metadata.MetaData{
+			StorageConfig: storageConfig,
+		})
+		assert.Nil(t, err)
+		assert.Contains(t, fake.objects, "backup/metadata.pb")
+
+		reader, metadataObj, err := rw.GetS3ReaderWriterForMetadata(ctx, config)
+		assert.Nil(t, err)
+		assert.NotNil(t, metadataObj.GetStorageConfig().GetS3())
+
+		readPage, err := reader.ReadPage(ctx, pageId)
+		assert.Nil(t, err)
+		assert.Equal(t, page.ID, readPage.ID)
+
+		readBlock, err := reader.ReadBlock(ctx, blockId)
+		assert.Nil(t, err)
+		assert.Equal(t, block.ID, readBlock.GetID())
+
+		_, err = reader.ReadPage(ctx, "unknown")
+		assert.NotNil(t, err)
+
+		// Objects of the previous backup are copied on server side
+		config.Prefix = "backup2"
+		newWriter, err := rw.GetS3ReaderWriter(ctx, config)
+		assert.Nil(t, err)
+		reusedId, err := newWriter.ReuseObject(ctx, reader,
+			metadata.NotionObjectType_PAGE, pageId)
+		assert.Nil(t, err)
+		assert.Equal(t, pageId, reusedId)
+		assert.Contains(t, fake.objects, "backup2/pages/"+pageId.String())
+
+		err = newWriter.CleanUp(ctx)
+		assert.Nil(t, err)
+		assert.NotContains(t, fake.objects, "backup2/pages/"+pageId.String())
+		assert.Contains(t, fake.objects, "backup/pages/"+pageId.String())
+
+		err = writer.CleanUp(ctx)
+		assert.Nil(t, err)
+		assert.Empty(t, fake.objects)
+	})
+
+	t.Run("Existing snapshot", func(t *testing.T) {
+		config, fake := getFakeS3Config(t, "backup")
+		writer, err := rw.GetS3ReaderWriter(ctx, config)
+		assert.Nil(t, err)
+		otherWriter, err := rw.GetS3ReaderWriter(ctx, config)
+		assert.Nil(t, err)
+
+		err = writer.WriteMetaData(ctx, &metadata.MetaData{
+			SnapshotId: "snapshot_id",
+		})
+		assert.Nil(t, err)
+		metadataBytes := fake.objects["backup/metadata.pb"]
+
+		// Snapshot written in the meantime is neither overwritten nor removed
+		err = otherWriter.WriteMetaData(ctx, &metadata.MetaData{})
+		assert.NotNil(t, err)
+		err = otherWriter.CleanUp(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, metadataBytes, fake.objects["backup/metadata.pb"])
+
+		_, err = rw.GetS3ReaderWriter(ctx, config)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("Metadata not found", func(t *testing.T) {
+		config, _ := getFakeS3Config(t, "backup")
+		_, _, err := rw.GetS3ReaderWriterForMetadata(ctx, config)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestS3AssetUploader(t *testing.T) {
+	ctx := context.Background()
+	s3Config, fake := getFakeS3Config(t, "restore")
+	dataBytes := []byte("image bytes")
+	asset := &metadata.Asset{
+		StorageIdentifier: "asset_id",
+		FileName:          "cover.png",
+		ContentType:       "image/png",
+		Checksum:          rw.GetChecksum(dataBytes),
+	}
+
+	uploader, err := rw.GetS3AssetUploader(ctx, s3Config)
+	assert.Nil(t, err)
+
+	assetUrl, err := uploader.UploadAsset(ctx, asset, dataBytes)
+	assert.Nil(t, err)
+	assert.Equal(t, s3Config.Endpoint+"/backups/restore/assets/"+
+		asset.Checksum+"/cover.png", assetUrl)
+	assert.Equal(t, dataBytes,
+		fake.objects["restore/assets/"+asset.Checksum+"/cover.png"])
+
+	// Notion fetches the uploaded asset anonymously
+	rsp, err := http.Get(assetUrl)
+	assert.Nil(t, err)
+	defer rsp.Body.Close()
+	body, err := ioutil.ReadAll(rsp.Body)
+	assert.Nil(t, err)
+	assert.Equal(t, dataBytes, body)
+
+	s3Config.Bucket = "missing"
+	_, err = rw.GetS3AssetUploader(ctx, s3Config)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,147 @@
+package builder
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+const (
+	ASSET_DOWNLOAD_TIMEOUT = 5 * time.Minute
+	DEFAULT_CONTENT_TYPE   = "application/octet-stream"
+)
+
+// Helper function to get the client with which the assets are downloaded
+func getHTTPClient(request *TreeBuilderRequest) *http.Client {
+	if request.HTTPClient != nil {
+		return request.HTTPClient
+	}
+
+	return &http.Client{Timeout: ASSET_DOWNLOAD_TIMEOUT}
+}
+
+// Download the file hosted by Notion. Returns the content of the file along
+// with its content type
+func (builderObj *ExportTreeBuilder) downloadFile(ctx context.Context,
+	fileUrl string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fileUrl, nil)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rsp, err := builderObj.httpClient.Do(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rsp.Body.Close()
+
+	if rsp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("failed to download file: %s", rsp.Status)
+	}
+
+	dataBytes, err := ioutil.ReadAll(rsp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	contentType := rsp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = DEFAULT_CONTENT_TYPE
+	}
+
+	return dataBytes, contentType, nil
+}
+
+// Store the asset of the previous snapshot in this snapshot. Files hosted by
+// Notion never change once uploaded, so the asset is reused without
+// downloading it again
+func (builderObj *ExportTreeBuilder) reuseAsset(ctx context.Context,
+	previousAsset *metadata.Asset) (*metadata.Asset, error) {
+	identifier, err := builderObj.rw.ReuseObject(ctx, builderObj.previousRW,
+		metadata.NotionObjectType_ASSET,
+		rw.DataIdentifier(previousAsset.StorageIdentifier))
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata.Asset{
+		StorageIdentifier: identifier.String(),
+		FileName:          previousAsset.FileName,
+		ContentType:       previousAsset.ContentType,
+		Checksum:          previousAsset.Checksum,
+	}, nil
+}
+
+// Store the downloaded file in the assets area of the ReaderWriter
+func (builderObj *ExportTreeBuilder) writeAsset(ctx context.Context,
+	fileUrl string, dataBytes []byte, contentType string) (*metadata.Asset,
+	error) {
+	identifier, err := rw.WriteAsset(ctx, builderObj.rw, dataBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata.Asset{
+		StorageIdentifier: identifier.String(),
+		FileName:          utils.GetAssetFileName(fileUrl),
+		ContentType:       contentType,
+		Checksum:          rw.GetChecksum(dataBytes),
+	}, nil
+}
+
+// Download the files hosted by Notion which are referenced by the object.
+// Files are downloaded as soon as the object is fetched as their URLs expire
+// an hour later. Files which cannot be downloaded are skipped, restore falls
+// back to the configured URL for them
+func (builderObj *ExportTreeBuilder) addAssets(ctx context.Context,
+	object interface{}) error {
+	if !builderObj.request.DownloadAssets {
+		return nil
+	}
+
+	log := zerolog.Ctx(ctx)
+	fileUrls, err := utils.GetNotionHostedFileUrls(object)
+	if err != nil {
+		return err
+	}
+
+	for _, fileUrl := range fileUrls {
+		key := utils.GetAssetKey(fileUrl)
+		if _, found := builderObj.assets[key]; found {
+			continue
+		}
+
+		var asset *metadata.Asset
+		if previousAsset, found := builderObj.previousAssets[key]; found {
+			asset, err = builderObj.reuseAsset(ctx, previousAsset)
+			if err != nil {
+				return err
+			}
+		} else {
+			log.Debug().Msgf("Downloading asset %s", key)
+			dataBytes, contentType, err := builderObj.downloadFile(ctx, fileUrl)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Failed to download asset %s. Skipping...",
+					key)
+				continue
+			}
+
+			asset, err = builderObj.writeAsset(ctx, fileUrl, dataBytes,
+				contentType)
+			if err != nil {
+				return err
+			}
+		}
+
+		builderObj.assets[key] = asset
+	}
+
+	return nil
+}
@@ -0,0 +1,121 @@
+package builder
+
+import (
+	"context"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+)
+
+// Check if the comments of the block are fetched along with the block. Comments
+// of child pages are fetched with the page itself and databases cannot have
+// comments
+func hasComments(block notionapi.Block) bool {
+	return block.GetType() != notionapi.BlockTypeChildPage &&
+		block.GetType() != notionapi.BlockTypeChildDatabase
+}
+
+// Fetch all the comments of the given page or block. This function does not
+// modify any state of the builder and hence can be called from workers
+func (builderObj *ExportTreeBuilder) fetchComments(ctx context.Context,
+	objectId string) ([]notionapi.Comment, error) {
+	log := zerolog.Ctx(ctx).With().Str(logging.BlockUUID, objectId).Logger()
+	log.Debug().Msg("Fetching comments")
+
+	result := []notionapi.Comment{}
+	cursor := notionapi.Cursor("")
+	for {
+		var comments []notionapi.Comment
+		var err error
+		comments, cursor, err = builderObj.notionClient.GetComments(ctx,
+			notionclient.BlockID(objectId), cursor)
+
+		if err != nil {
+			log.Error().Err(err).Msg(logging.CommentsFetchErr)
+			return nil, err
+		}
+
+		result = append(result, comments...)
+		if cursor == "" {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+// Fetch the comments of the given page, if pageId is not empty, and of the
+// given blocks. Returns the comments mapped with the ID of the commented object.
+// Nothing is fetched unless comments are requested
+func (builderObj *ExportTreeBuilder) fetchAllComments(ctx context.Context,
+	pageId string, blocks []notionapi.Block) (map[string][]notionapi.Comment,
+	error) {
+	if !builderObj.request.BackupComments {
+		return nil, nil
+	}
+
+	objectIds := []string{}
+	if pageId != "" {
+		objectIds = append(objectIds, pageId)
+	}
+
+	for _, block := range blocks {
+		if hasComments(block) && !builderObj.filter.isBlockExcluded(block) {
+			objectIds = append(objectIds, block.GetID().String())
+		}
+	}
+
+	result := make(map[string][]notionapi.Comment)
+	for _, objectId := range objectIds {
+		comments, err := builderObj.fetchComments(ctx, objectId)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(comments) != 0 {
+			result[objectId] = comments
+		}
+	}
+
+	return result, nil
+}
+
+// Create comment nodes for the given comments and add them to the node of the
+// commented page or block i.e. parentNode
+func (builderObj *ExportTreeBuilder) addComments(ctx context.Context,
+	parentNode *node.Node, comments []notionapi.Comment) error {
+	for i := range comments {
+		commentNode, err := node.CreateCommentNode(ctx, &comments[i],
+			builderObj.rw)
+		if err != nil {
+			zerolog.Ctx(ctx).Error().Err(err).Str(logging.CommentUUID,
+				comments[i].ID.String()).Msg(logging.CommentNodeCreateErr)
+			return err
+		}
+
+		parentNode.AddChild(commentNode)
+	}
+
+	return nil
+}
+
+// Fetch the comments of the page or block whose node is reused from previous
+// snapshot. Adding a comment does not change the last edited time of the
+// object, so comments are always fetched again
+func (builderObj *ExportTreeBuilder) fetchAndAddComments(ctx context.Context,
+	parentNode *node.Node) error {
+	if !builderObj.request.BackupComments {
+		return nil
+	}
+
+	comments, err := builderObj.fetchComments(ctx,
+		parentNode.GetNotionObjectId())
+	if err != nil {
+		return err
+	}
+
+	return builderObj.addComments(ctx, parentNode, comments)
+}
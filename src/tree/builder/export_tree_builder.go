@@ -3,17 +3,26 @@ package builder
 import (
 	"context"
 	"fmt"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/jomei/notionapi"
 	"github.com/rs/zerolog"
 	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
 	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/shivaji17/notionbackup/src/rw"
 	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/iterator"
 	"github.com/shivaji17/notionbackup/src/tree/node"
 	"github.com/shivaji17/notionbackup/src/utils"
 )
 
+// Precision of the last edited time of the objects returned by Notion API
+const LAST_EDITED_TIME_PRECISION = time.Minute
+
 type ExportTreeBuilder struct {
 	notionClient               notionclient.NotionClient
 	rw                         rw.ReaderWriter
@@ -25,6 +34,14 @@ type ExportTreeBuilder struct {
 	nodeStack                  stack
 	request                    *TreeBuilderRequest
 	objectId2NodeMap           map[string]*node.Node
+	concurrency                int
+	previousRW                 rw.ReaderWriter
+	previousNodeMap            map[string]*node.Node
+	previousCreatedAt          time.Time
+	httpClient                 *http.Client
+	assets                     map[string]*metadata.Asset
+	previousAssets             map[string]*metadata.Asset
+	filter                     *objectFilter
 }
 
 func GetExportTreebuilder(ctx context.Context,
@@ -41,7 +58,278 @@ func GetExportTreebuilder(ctx context.Context,
 		nodeStack:                  make(stack, 0),
 		request:                    request,
 		objectId2NodeMap:           make(map[string]*node.Node),
+		concurrency:                request.Concurrency,
+		previousRW:                 request.PreviousReaderWriter,
+		previousNodeMap:            getPreviousNodeMap(request.PreviousTree),
+		previousCreatedAt:          getPreviousCreatedAt(request.PreviousTree),
+		httpClient:                 getHTTPClient(request),
+		assets:                     make(map[string]*metadata.Asset),
+		previousAssets:             getPreviousAssets(request.PreviousTree),
+		filter:                     getObjectFilter(request.Filter),
+	}
+}
+
+// Index the page and database nodes of the previous snapshot with their notion
+// object IDs
+func getPreviousNodeMap(previousTree *tree.Tree) map[string]*node.Node {
+	if previousTree == nil {
+		return nil
+	}
+
+	previousNodeMap := make(map[string]*node.Node)
+	iter := iterator.GetTreeIterator(previousTree.RootNode)
+	for {
+		nodeObj, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if nodeObj.GetNodeType() == node.PAGE ||
+			nodeObj.GetNodeType() == node.DATABASE {
+			previousNodeMap[nodeObj.GetNotionObjectId()] = nodeObj
+		}
+	}
+
+	return previousNodeMap
+}
+
+// Get the time at which the previous snapshot was taken
+func getPreviousCreatedAt(previousTree *tree.Tree) time.Time {
+	if previousTree == nil {
+		return time.Time{}
+	}
+
+	return previousTree.CreatedAt
+}
+
+// Get the assets of the previous snapshot
+func getPreviousAssets(previousTree *tree.Tree) map[string]*metadata.Asset {
+	if previousTree == nil {
+		return nil
+	}
+
+	return previousTree.Assets
+}
+
+// Get the node of previous snapshot for the given notion object if the object
+// was not edited after previous snapshot was taken. Notion rounds the last
+// edited time down to the minute, so the object edited within the minute in
+// which previous snapshot was taken may have changed after it was fetched
+// without changing its last edited time. Such object is fetched again
+func (builderObj *ExportTreeBuilder) getUnchangedPreviousNode(
+	notionObjectId string, lastEditedTime time.Time) *node.Node {
+	previousNode, found := builderObj.previousNodeMap[notionObjectId]
+	if !found {
+		return nil
+	}
+
+	if previousNode.GetLastEditedTime().IsZero() ||
+		!previousNode.GetLastEditedTime().Equal(lastEditedTime) {
+		return nil
+	}
+
+	if !builderObj.previousCreatedAt.IsZero() &&
+		lastEditedTime.Add(LAST_EDITED_TIME_PRECISION).After(
+			builderObj.previousCreatedAt) {
+		return nil
+	}
+
+	return previousNode
+}
+
+// Create a node for the object stored by previous snapshot
+func (builderObj *ExportTreeBuilder) reuseNode(ctx context.Context,
+	previousNode *node.Node, objectType metadata.NotionObjectType) (*node.Node,
+	error) {
+	identifier, err := builderObj.rw.ReuseObject(ctx, builderObj.previousRW,
+		objectType, previousNode.GetStorageIdentifier())
+	if err != nil {
+		return nil, err
+	}
+
+	return node.CopyNode(previousNode, identifier)
+}
+
+// Create page node. Page object stored by previous snapshot is reused if page
+// is unchanged
+func (builderObj *ExportTreeBuilder) createPageNode(ctx context.Context,
+	page *notionapi.Page) (*node.Node, error) {
+	tracker := progress.Ctx(ctx)
+	tracker.Add(progress.PAGES, progress.DISCOVERED, 1)
+	err := builderObj.addAssets(ctx, page)
+	if err != nil {
+		return nil, err
+	}
+
+	var pageNode *node.Node
+	previousNode := builderObj.getUnchangedPreviousNode(page.ID.String(),
+		page.LastEditedTime)
+	if previousNode != nil {
+		pageNode, err = builderObj.reuseNode(ctx, previousNode,
+			metadata.NotionObjectType_PAGE)
+	} else {
+		pageNode, err = node.CreatePageNode(ctx, page, builderObj.rw)
+	}
+
+	if err == nil {
+		tracker.Add(progress.PAGES, progress.WRITTEN, 1)
+	}
+	return pageNode, err
+}
+
+// Create database node. Database object stored by previous snapshot is reused
+// if database is unchanged
+func (builderObj *ExportTreeBuilder) createDatabaseNode(ctx context.Context,
+	database *notionapi.Database) (*node.Node, error) {
+	tracker := progress.Ctx(ctx)
+	tracker.Add(progress.DATABASES, progress.DISCOVERED, 1)
+	err := builderObj.addAssets(ctx, database)
+	if err != nil {
+		return nil, err
+	}
+
+	var databaseNode *node.Node
+	previousNode := builderObj.getUnchangedPreviousNode(database.ID.String(),
+		database.LastEditedTime)
+	if previousNode != nil {
+		databaseNode, err = builderObj.reuseNode(ctx, previousNode,
+			metadata.NotionObjectType_DATABASE)
+	} else {
+		databaseNode, err = node.CreateDatabaseNode(ctx, database, builderObj.rw)
+	}
+
+	if err == nil {
+		tracker.Add(progress.DATABASES, progress.WRITTEN, 1)
+	}
+	return databaseNode, err
+}
+
+// Helper function to count the node whose children are fetched or reused
+func markFetched(ctx context.Context, nodeObj *node.Node) {
+	switch nodeObj.GetNodeType() {
+	case node.PAGE:
+		progress.Ctx(ctx).Add(progress.PAGES, progress.FETCHED, 1)
+	case node.DATABASE:
+		progress.Ctx(ctx).Add(progress.DATABASES, progress.FETCHED, 1)
+	case node.BLOCK:
+		progress.Ctx(ctx).Add(progress.BLOCKS, progress.FETCHED, 1)
+	}
+}
+
+// Add the given block node of previous snapshot and its subtree to the given
+// node i.e. parentNode. Child pages and databases are not reused as they can be
+// edited without changing the block, they are added the same way as fetched
+// child page and database blocks
+func (builderObj *ExportTreeBuilder) reuseBlock(ctx context.Context,
+	parentNode *node.Node, previousNode *node.Node) error {
+	log := zerolog.Ctx(ctx).With().Str(logging.BlockUUID,
+		previousNode.GetNotionObjectId()).Logger()
+	block, err := builderObj.previousRW.ReadBlock(ctx,
+		previousNode.GetStorageIdentifier())
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to read block from previous snapshot")
+		return err
+	}
+
+	// Previous snapshot may have been taken with different filters
+	if builderObj.filter.isChildBlockExcluded(ctx, parentNode, block) {
+		return nil
+	}
+
+	tracker := progress.Ctx(ctx)
+	tracker.Add(progress.BLOCKS, progress.DISCOVERED, 1)
+
+	err = builderObj.addAssets(ctx, block)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to store the assets of the block")
+		return err
+	}
+
+	blockNode, err := builderObj.reuseNode(ctx, previousNode,
+		metadata.NotionObjectType_BLOCK)
+	if err != nil {
+		log.Error().Err(err).Msg(logging.BlockNodeCreateErr)
+		return err
+	}
+
+	// Children of the block are reused along with it
+	tracker.Add(progress.BLOCKS, progress.WRITTEN, 1)
+	tracker.Add(progress.BLOCKS, progress.FETCHED, 1)
+	parentNode.AddChild(blockNode)
+
+	if block.GetType() == notionapi.BlockTypeChildDatabase {
+		return builderObj.addDatabase(ctx, blockNode, block.GetID().String())
+	}
+
+	if block.GetType() == notionapi.BlockTypeChildPage {
+		return builderObj.addPage(ctx, blockNode, block.GetID().String())
+	}
+
+	err = builderObj.fetchAndAddComments(ctx, blockNode)
+	if err != nil {
+		return err
+	}
+
+	iter := iterator.GetChildIterator(previousNode)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		// Comments are fetched again instead of being reused
+		if childNode.GetNodeType() == node.COMMENT {
+			continue
+		}
+
+		err = builderObj.reuseBlock(ctx, blockNode, childNode)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Add the blocks of the page from previous snapshot if the page is unchanged
+// since previous snapshot was taken. Returns false if blocks cannot be reused
+// and needs to be fetched
+func (builderObj *ExportTreeBuilder) addPreviousPageChildren(
+	ctx context.Context, pageNode *node.Node) (bool, error) {
+	previousNode := builderObj.getUnchangedPreviousNode(
+		pageNode.GetNotionObjectId(), pageNode.GetLastEditedTime())
+	if previousNode == nil || previousNode.GetNodeType() != node.PAGE {
+		return false, nil
+	}
+
+	log := zerolog.Ctx(ctx).With().Str(logging.PageUUID,
+		pageNode.GetNotionObjectId()).Logger()
+	log.Debug().Msg("Page is unchanged. Reusing Page blocks from previous " +
+		"snapshot")
+
+	err := builderObj.fetchAndAddComments(ctx, pageNode)
+	if err != nil {
+		return true, err
+	}
+
+	iter := iterator.GetChildIterator(previousNode)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if childNode.GetNodeType() == node.COMMENT {
+			continue
+		}
+
+		err = builderObj.reuseBlock(ctx, pageNode, childNode)
+		if err != nil {
+			return true, err
+		}
 	}
+
+	return true, nil
 }
 
 // Check if Parent type is workspace
@@ -129,6 +417,10 @@ func (builderObj *ExportTreeBuilder) addPage(ctx context.Context,
 	log := zerolog.Ctx(ctx).With().Str(logging.PageUUID, pageId).Logger()
 	var pageNode *node.Node
 
+	if builderObj.filter.isIdExcluded(ctx, parentNode, pageId) {
+		return nil
+	}
+
 	if nodeObj, found := builderObj.pageId2PageNodeMap[pageId]; found {
 		pageNode = nodeObj
 		delete(builderObj.pageId2PageNodeMap, pageId)
@@ -142,7 +434,13 @@ func (builderObj *ExportTreeBuilder) addPage(ctx context.Context,
 			log.Error().Err(err).Msg(logging.PageFetchErr)
 			return err
 		}
-		nodeObj, err := node.CreatePageNode(ctx, page, builderObj.rw)
+
+		if builderObj.filter.isTitleExcluded(ctx, pageId,
+			utils.GetPageTitle(page)) {
+			return nil
+		}
+
+		nodeObj, err := builderObj.createPageNode(ctx, page)
 		if err != nil {
 			log.Error().Err(err).Msg(logging.PageNodeCreateErr)
 			return err
@@ -157,12 +455,13 @@ func (builderObj *ExportTreeBuilder) addPage(ctx context.Context,
 	return nil
 }
 
-// Query all the blocks of the page and add them to given node i.e. parentNode
-func (builderObj *ExportTreeBuilder) queryAndAddPageChildren(
-	ctx context.Context, parentNode *node.Node, pageId string) error {
+// Fetch all the blocks of the given page
+func (builderObj *ExportTreeBuilder) fetchPageBlocks(ctx context.Context,
+	pageId string) ([]notionapi.Block, error) {
 	log := zerolog.Ctx(ctx).With().Str(logging.PageUUID, pageId).Logger()
 	log.Debug().Msg("Fetching Page blocks")
 
+	result := []notionapi.Block{}
 	cursor := notionapi.Cursor("")
 	for {
 		var blocks []notionapi.Block
@@ -172,22 +471,42 @@ func (builderObj *ExportTreeBuilder) queryAndAddPageChildren(
 
 		if err != nil {
 			log.Error().Err(err).Msg(logging.PageBlocksFetchErr)
-			return err
-		}
-
-		for _, block := range blocks {
-			err = builderObj.addBlock(ctx, parentNode, block)
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
 
+		result = append(result, blocks...)
 		if cursor == "" {
 			break
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// Query all the blocks of the page and add them to given node i.e. parentNode
+func (builderObj *ExportTreeBuilder) queryAndAddPageChildren(
+	ctx context.Context, parentNode *node.Node, pageId string) error {
+	if reused, err := builderObj.addPreviousPageChildren(ctx,
+		parentNode); reused {
+		return err
+	}
+
+	blocks, err := builderObj.fetchPageBlocks(ctx, pageId)
+	if err != nil {
+		return err
+	}
+
+	comments, err := builderObj.fetchAllComments(ctx, pageId, blocks)
+	if err != nil {
+		return err
+	}
+
+	err = builderObj.addComments(ctx, parentNode, comments[pageId])
+	if err != nil {
+		return err
+	}
+
+	return builderObj.addBlocks(ctx, parentNode, blocks, comments)
 }
 
 // Create node object for given database and add it's children to created
@@ -197,6 +516,10 @@ func (builderObj *ExportTreeBuilder) addDatabase(ctx context.Context,
 	log := zerolog.Ctx(ctx).With().Str(logging.DatabaseUUID, databaseId).Logger()
 	var databaseNode *node.Node
 
+	if builderObj.filter.isIdExcluded(ctx, parentNode, databaseId) {
+		return nil
+	}
+
 	if nodeObj, found := builderObj.
 		databaseId2DatabaseNodeMap[databaseId]; found {
 		databaseNode = nodeObj
@@ -213,7 +536,12 @@ func (builderObj *ExportTreeBuilder) addDatabase(ctx context.Context,
 			return err
 		}
 
-		nodeObj, err := node.CreateDatabaseNode(ctx, database, builderObj.rw)
+		if builderObj.filter.isTitleExcluded(ctx, databaseId,
+			utils.GetDatabaseTitle(database)) {
+			return nil
+		}
+
+		nodeObj, err := builderObj.createDatabaseNode(ctx, database)
 		if err != nil {
 			log.Error().Err(err).Msg(logging.DatabaseNodeCreateErr)
 			return err
@@ -228,26 +556,36 @@ func (builderObj *ExportTreeBuilder) addDatabase(ctx context.Context,
 	return nil
 }
 
-// Query all the pages of the given database and add them to the given node i.e
-// parentNode
-func (builderObj *ExportTreeBuilder) queryAndAddDatabaseChildren(
-	ctx context.Context, parentNode *node.Node, databaseId string) error {
-	log := zerolog.Ctx(ctx).With().Str(logging.DatabaseUUID, databaseId).Logger()
+// Add the pages of the given database which were cached while fetching all
+// the pages from workspace. Returns false if no pages were cached for the
+// database
+func (builderObj *ExportTreeBuilder) addCachedDatabasePages(
+	ctx context.Context, parentNode *node.Node, databaseId string) (bool,
+	error) {
+	pageIdList, found := builderObj.databaseId2PageListMap[databaseId]
+	if !found {
+		return false, nil
+	}
 
-	if pageIdList, found := builderObj.databaseId2PageListMap[databaseId]; found {
-		for _, pageId := range pageIdList {
-			err := builderObj.addPage(ctx, parentNode, pageId)
+	for _, pageId := range pageIdList {
+		err := builderObj.addPage(ctx, parentNode, pageId)
 
-			if err != nil {
-				return err
-			}
+		if err != nil {
+			return true, err
 		}
-
-		delete(builderObj.databaseId2PageListMap, databaseId)
-		return nil
 	}
 
+	delete(builderObj.databaseId2PageListMap, databaseId)
+	return true, nil
+}
+
+// Fetch all the pages of the given database
+func (builderObj *ExportTreeBuilder) fetchDatabasePages(ctx context.Context,
+	databaseId string) ([]notionapi.Page, error) {
+	log := zerolog.Ctx(ctx).With().Str(logging.DatabaseUUID, databaseId).Logger()
 	log.Debug().Msg("Fetching Database pages")
+
+	result := []notionapi.Page{}
 	cursor := notionapi.Cursor("")
 	for {
 		var pages []notionapi.Page
@@ -257,45 +595,83 @@ func (builderObj *ExportTreeBuilder) queryAndAddDatabaseChildren(
 
 		if err != nil {
 			log.Error().Err(err).Msg(logging.DatabasePagesFetchErr)
-			return err
+			return nil, err
 		}
 
-		for _, page := range pages {
+		result = append(result, pages...)
+		if cursor == "" {
+			break
+		}
+	}
 
-			if foundNode := builderObj.getNode(page.ID.String()); foundNode != nil {
-				err = builderObj.restructureTree(ctx, foundNode, parentNode)
-				if err != nil {
-					log.Error().Err(err).Str(logging.PageUUID, page.ID.String()).
-						Msg("Failed to restructure the tree for database page node")
-					return err
-				}
-			}
+	return result, nil
+}
+
+// Create page nodes for the given pages of database and add them to the given
+// node i.e parentNode
+func (builderObj *ExportTreeBuilder) addDatabasePages(ctx context.Context,
+	parentNode *node.Node, pages []notionapi.Page) error {
+	log := zerolog.Ctx(ctx).With().Str(logging.DatabaseUUID,
+		parentNode.GetNotionObjectId()).Logger()
 
-			pageNode, err := node.CreatePageNode(ctx, &page, builderObj.rw)
+	for _, page := range pages {
+		if builderObj.filter.isPageExcluded(ctx, parentNode, &page) {
+			continue
+		}
+
+		if foundNode := builderObj.getNode(page.ID.String()); foundNode != nil {
+			err := builderObj.restructureTree(ctx, foundNode, parentNode)
 			if err != nil {
 				log.Error().Err(err).Str(logging.PageUUID, page.ID.String()).
-					Msg(logging.PageNodeCreateErr)
+					Msg("Failed to restructure the tree for database page node")
 				return err
 			}
-
-			builderObj.objectId2NodeMap[pageNode.GetNotionObjectId()] = pageNode
-			parentNode.AddChild(pageNode)
-			builderObj.nodeStack.Push(pageNode)
 		}
 
-		if cursor == "" {
-			break
+		pageNode, err := builderObj.createPageNode(ctx, &page)
+		if err != nil {
+			log.Error().Err(err).Str(logging.PageUUID, page.ID.String()).
+				Msg(logging.PageNodeCreateErr)
+			return err
 		}
+
+		builderObj.objectId2NodeMap[pageNode.GetNotionObjectId()] = pageNode
+		parentNode.AddChild(pageNode)
+		builderObj.nodeStack.Push(pageNode)
 	}
 
 	return nil
 }
 
-// Create node object for given block and add it's children to created
-// block node object
+// Query all the pages of the given database and add them to the given node i.e
+// parentNode
+func (builderObj *ExportTreeBuilder) queryAndAddDatabaseChildren(
+	ctx context.Context, parentNode *node.Node, databaseId string) error {
+	if found, err := builderObj.addCachedDatabasePages(ctx, parentNode,
+		databaseId); found {
+		return err
+	}
+
+	pages, err := builderObj.fetchDatabasePages(ctx, databaseId)
+	if err != nil {
+		return err
+	}
+
+	return builderObj.addDatabasePages(ctx, parentNode, pages)
+}
+
+// Create node object for given block and add it's comments and children to
+// created block node object
 func (builderObj *ExportTreeBuilder) addBlock(ctx context.Context,
-	parentNode *node.Node, block notionapi.Block) error {
+	parentNode *node.Node, block notionapi.Block,
+	comments []notionapi.Comment) error {
+	if builderObj.filter.isChildBlockExcluded(ctx, parentNode, block) {
+		return nil
+	}
+
 	log := zerolog.Ctx(ctx)
+	tracker := progress.Ctx(ctx)
+	tracker.Add(progress.BLOCKS, progress.DISCOVERED, 1)
 	blockNode, err := node.CreateBlockNode(ctx, block, builderObj.rw)
 
 	if err != nil {
@@ -303,31 +679,66 @@ func (builderObj *ExportTreeBuilder) addBlock(ctx context.Context,
 			Msg(logging.BlockNodeCreateErr)
 		return err
 	}
+	tracker.Add(progress.BLOCKS, progress.WRITTEN, 1)
+
+	err = builderObj.addAssets(ctx, block)
+	if err != nil {
+		log.Error().Err(err).Str(logging.BlockUUID, block.GetID().String()).
+			Msg("Failed to store the assets of the block")
+		return err
+	}
 
 	parentNode.AddChild(blockNode)
 
+	// Children of child page and database blocks are added to the page and
+	// database nodes instead
 	if block.GetType() == notionapi.BlockTypeChildDatabase {
+		markFetched(ctx, blockNode)
 		return builderObj.addDatabase(ctx, blockNode, block.GetID().String())
 	}
 
 	if block.GetType() == notionapi.BlockTypeChildPage {
+		markFetched(ctx, blockNode)
 		return builderObj.addPage(ctx, blockNode, block.GetID().String())
 	}
 
+	err = builderObj.addComments(ctx, blockNode, comments)
+	if err != nil {
+		return err
+	}
+
 	if block.GetHasChildren() {
 		builderObj.nodeStack.Push(blockNode)
+	} else {
+		markFetched(ctx, blockNode)
 	}
 
 	return nil
 }
 
-// Query all the child blocks of the given block and add them to the given node
-// i.e. parentNode
-func (builderObj *ExportTreeBuilder) queryAndAddBlockChildren(
-	ctx context.Context, parentNode *node.Node, blockId string) error {
+// Create node objects for given blocks and add them to the given node i.e.
+// parentNode. Comments of the blocks are mapped with the block IDs
+func (builderObj *ExportTreeBuilder) addBlocks(ctx context.Context,
+	parentNode *node.Node, blocks []notionapi.Block,
+	comments map[string][]notionapi.Comment) error {
+	for _, block := range blocks {
+		err := builderObj.addBlock(ctx, parentNode, block,
+			comments[block.GetID().String()])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Fetch all the child blocks of the given block
+func (builderObj *ExportTreeBuilder) fetchChildBlocks(ctx context.Context,
+	blockId string) ([]notionapi.Block, error) {
 	log := zerolog.Ctx(ctx).With().Str(logging.BlockUUID, blockId).Logger()
 	log.Debug().Msg("Fetching child blocks")
 
+	result := []notionapi.Block{}
 	cursor := notionapi.Cursor("")
 	for {
 		var blocks []notionapi.Block
@@ -337,22 +748,33 @@ func (builderObj *ExportTreeBuilder) queryAndAddBlockChildren(
 
 		if err != nil {
 			log.Error().Err(err).Msg(logging.ChildBlockFetchErr)
-			return err
-		}
-
-		for _, block := range blocks {
-			err = builderObj.addBlock(ctx, parentNode, block)
-			if err != nil {
-				return err
-			}
+			return nil, err
 		}
 
+		result = append(result, blocks...)
 		if cursor.String() == "" {
 			break
 		}
 	}
 
-	return nil
+	return result, nil
+}
+
+// Query all the child blocks of the given block and add them to the given node
+// i.e. parentNode
+func (builderObj *ExportTreeBuilder) queryAndAddBlockChildren(
+	ctx context.Context, parentNode *node.Node, blockId string) error {
+	blocks, err := builderObj.fetchChildBlocks(ctx, blockId)
+	if err != nil {
+		return err
+	}
+
+	comments, err := builderObj.fetchAllComments(ctx, "", blocks)
+	if err != nil {
+		return err
+	}
+
+	return builderObj.addBlocks(ctx, parentNode, blocks, comments)
 }
 
 // This function will fetch all the pages. Pages which belong to workspace will
@@ -374,8 +796,14 @@ func (builderObj *ExportTreeBuilder) addWorkspacePages(ctx context.Context,
 		}
 
 		for _, page := range pages {
+			// Depth of the cached pages is checked once they are added to their
+			// parents
+			if builderObj.filter.isPageExcluded(ctx, parentNode, &page) {
+				continue
+			}
+
 			if builderObj.isParentWorkspace(&page.Parent) {
-				pageNode, err := node.CreatePageNode(ctx, &page, builderObj.rw)
+				pageNode, err := builderObj.createPageNode(ctx, &page)
 				if err != nil {
 					log.Error().Err(err).Str(logging.PageUUID, page.ID.String()).
 						Msg(logging.PageNodeCreateErr)
@@ -388,7 +816,7 @@ func (builderObj *ExportTreeBuilder) addWorkspacePages(ctx context.Context,
 			}
 
 			// cache for later use
-			pageNode, err := node.CreatePageNode(ctx, &page, builderObj.rw)
+			pageNode, err := builderObj.createPageNode(ctx, &page)
 			if err != nil {
 				log.Error().Err(err).Str(logging.PageUUID, page.ID.String()).
 					Msg(logging.PageNodeCreateErr)
@@ -425,9 +853,12 @@ func (builderObj *ExportTreeBuilder) addWorkspaceDatabases(ctx context.Context,
 		}
 
 		for _, database := range databases {
+			if builderObj.filter.isDatabaseExcluded(ctx, parentNode, &database) {
+				continue
+			}
+
 			if builderObj.isParentWorkspace(&database.Parent) {
-				databaseNode, err := node.CreateDatabaseNode(
-					ctx, &database, builderObj.rw)
+				databaseNode, err := builderObj.createDatabaseNode(ctx, &database)
 				if err != nil {
 					log.Error().Err(err).Str(logging.DatabaseUUID, database.ID.String()).
 						Msg(logging.DatabaseNodeCreateErr)
@@ -439,8 +870,7 @@ func (builderObj *ExportTreeBuilder) addWorkspaceDatabases(ctx context.Context,
 			}
 
 			// cache for later use
-			databaseNode, err := node.CreateDatabaseNode(
-				ctx, &database, builderObj.rw)
+			databaseNode, err := builderObj.createDatabaseNode(ctx, &database)
 			if err != nil {
 				log.Error().Err(err).Str(logging.DatabaseUUID, database.ID.String()).
 					Msg(logging.DatabaseNodeCreateErr)
@@ -482,11 +912,178 @@ func (builderObj *ExportTreeBuilder) buildTreeUntilStackEmpty(
 		if err != nil {
 			return err
 		}
+		markFetched(ctx, object)
 	}
 
 	return nil
 }
 
+// Result of fetching the children of a node by a worker
+type fetchResult struct {
+	nodeObj  *node.Node
+	blocks   []notionapi.Block
+	pages    []notionapi.Page
+	comments map[string][]notionapi.Comment
+	err      error
+}
+
+// Fetch the children of the given node. This function does not modify the
+// tree or any state of the builder and hence can be called from multiple
+// workers at the same time
+func (builderObj *ExportTreeBuilder) fetchChildren(ctx context.Context,
+	nodeObj *node.Node) *fetchResult {
+	result := &fetchResult{nodeObj: nodeObj}
+	switch nodeObj.GetNodeType() {
+	case node.PAGE:
+		result.blocks, result.err = builderObj.fetchPageBlocks(ctx,
+			nodeObj.GetNotionObjectId())
+		if result.err == nil {
+			result.comments, result.err = builderObj.fetchAllComments(ctx,
+				nodeObj.GetNotionObjectId(), result.blocks)
+		}
+	case node.DATABASE:
+		result.pages, result.err = builderObj.fetchDatabasePages(ctx,
+			nodeObj.GetNotionObjectId())
+	case node.BLOCK:
+		result.blocks, result.err = builderObj.fetchChildBlocks(ctx,
+			nodeObj.GetNotionObjectId())
+		if result.err == nil {
+			result.comments, result.err = builderObj.fetchAllComments(ctx, "",
+				result.blocks)
+		}
+	}
+
+	return result
+}
+
+// Add the children fetched by a worker to the tree
+func (builderObj *ExportTreeBuilder) addFetchedChildren(ctx context.Context,
+	result *fetchResult) error {
+	if result.err != nil {
+		return result.err
+	}
+
+	if result.nodeObj.GetNodeType() == node.DATABASE {
+		return builderObj.addDatabasePages(ctx, result.nodeObj, result.pages)
+	}
+
+	if result.nodeObj.GetNodeType() == node.PAGE {
+		err := builderObj.addComments(ctx, result.nodeObj,
+			result.comments[result.nodeObj.GetNotionObjectId()])
+		if err != nil {
+			return err
+		}
+	}
+
+	return builderObj.addBlocks(ctx, result.nodeObj, result.blocks,
+		result.comments)
+}
+
+// Same as buildTreeUntilStackEmpty but children of nodes are fetched by a pool
+// of workers. Only the fetching is done in parallel, the fetched children are
+// added to the tree by the calling goroutine so that the tree, maps of the
+// builder and the ReaderWriter are never accessed concurrently. Since all the
+// children of a node are fetched by a single worker, sibling order remains same
+// as in the sequential build. Workers fetch with their own context which is
+// cancelled as soon as the build fails, so that in-flight requests are aborted
+// and no further requests are sent
+func (builderObj *ExportTreeBuilder) buildTreeConcurrently(
+	ctx context.Context) error {
+	log := zerolog.Ctx(ctx)
+	log.Debug().Msgf("Building tree with %d workers", builderObj.concurrency)
+
+	jobs := make(chan *node.Node)
+	// At most 'concurrency' jobs are in flight, so workers never block while
+	// sending the results
+	results := make(chan *fetchResult, builderObj.concurrency)
+
+	fetchCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	for i := 0; i < builderObj.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for nodeObj := range jobs {
+				if fetchCtx.Err() != nil {
+					results <- &fetchResult{nodeObj: nodeObj, err: fetchCtx.Err()}
+					continue
+				}
+				results <- builderObj.fetchChildren(fetchCtx, nodeObj)
+			}
+		}()
+	}
+
+	defer func() {
+		cancel()
+		close(jobs)
+		wg.Wait()
+	}()
+
+	inFlight := 0
+	var buildErr error
+	for {
+		for buildErr == nil && inFlight < builderObj.concurrency {
+			object, err := builderObj.nodeStack.Pop()
+			if err == errStackEmpty {
+				break
+			}
+
+			// Pages of database which are already cached and blocks of unchanged
+			// pages do not need to be fetched
+			var found bool
+			if object.GetNodeType() == node.DATABASE {
+				found, buildErr = builderObj.addCachedDatabasePages(ctx, object,
+					object.GetNotionObjectId())
+			} else if object.GetNodeType() == node.PAGE {
+				found, buildErr = builderObj.addPreviousPageChildren(ctx, object)
+			}
+
+			if found {
+				if buildErr == nil {
+					markFetched(ctx, object)
+				} else {
+					cancel()
+				}
+				continue
+			}
+
+			jobs <- object
+			inFlight++
+		}
+
+		if inFlight == 0 {
+			break
+		}
+
+		result := <-results
+		inFlight--
+
+		// Once an error occurred, wait for the cancelled in-flight jobs to
+		// finish and ignore their results
+		if buildErr == nil {
+			buildErr = builderObj.addFetchedChildren(ctx, result)
+			if buildErr != nil {
+				cancel()
+			} else {
+				markFetched(ctx, result.nodeObj)
+			}
+		}
+	}
+
+	return buildErr
+}
+
+// Build the tree for the nodes present in stack either sequentially or
+// concurrently depending on the requested concurrency
+func (builderObj *ExportTreeBuilder) buildTreeFromStack(
+	ctx context.Context) error {
+	if builderObj.concurrency > 1 {
+		return builderObj.buildTreeConcurrently(ctx)
+	}
+
+	return builderObj.buildTreeUntilStackEmpty(ctx)
+}
+
 // This function will build the tree for whole workspace depending on Databases
 // and Pages the token has access to
 func (builderObj *ExportTreeBuilder) buildTreeForWorkspace(
@@ -503,7 +1100,7 @@ func (builderObj *ExportTreeBuilder) buildTreeForWorkspace(
 		return err
 	}
 
-	err = builderObj.buildTreeUntilStackEmpty(ctx)
+	err = builderObj.buildTreeFromStack(ctx)
 	if err != nil {
 		return err
 	}
@@ -538,7 +1135,7 @@ func (builderObj *ExportTreeBuilder) buildTreeForGivenObjectIds(
 		}
 	}
 
-	err := builderObj.buildTreeUntilStackEmpty(ctx)
+	err := builderObj.buildTreeFromStack(ctx)
 	if err != nil {
 		return err
 	}
@@ -555,6 +1152,7 @@ func (builderObj *ExportTreeBuilder) BuildTree(ctx context.Context) (*tree.Tree,
 	if builderObj.rootNode != nil {
 		return &tree.Tree{
 			RootNode: builderObj.rootNode,
+			Assets:   builderObj.assets,
 		}, nil
 	}
 
@@ -587,5 +1185,6 @@ func (builderObj *ExportTreeBuilder) BuildTree(ctx context.Context) (*tree.Tree,
 	log.Debug().Msg("Successfully built export tree")
 	return &tree.Tree{
 		RootNode: builderObj.rootNode,
+		Assets:   builderObj.assets,
 	}, nil
 }
@@ -0,0 +1,223 @@
+package builder
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/utils"
+)
+
+// Types of the Blocks known to Notion API
+var knownBlockTypes = map[notionapi.BlockType]bool{
+	notionapi.BlockTypeParagraph:        true,
+	notionapi.BlockTypeHeading1:         true,
+	notionapi.BlockTypeHeading2:         true,
+	notionapi.BlockTypeHeading3:         true,
+	notionapi.BlockTypeBulletedListItem: true,
+	notionapi.BlockTypeNumberedListItem: true,
+	notionapi.BlockTypeToDo:             true,
+	notionapi.BlockTypeToggle:           true,
+	notionapi.BlockTypeChildPage:        true,
+	notionapi.BlockTypeChildDatabase:    true,
+	notionapi.BlockTypeEmbed:            true,
+	notionapi.BlockTypeImage:            true,
+	notionapi.BlockTypeVideo:            true,
+	notionapi.BlockTypeFile:             true,
+	notionapi.BlockTypePdf:              true,
+	notionapi.BlockTypeBookmark:         true,
+	notionapi.BlockTypeCode:             true,
+	notionapi.BlockTypeDivider:          true,
+	notionapi.BlockCallout:              true,
+	notionapi.BlockQuote:                true,
+	notionapi.BlockTypeTableOfContents:  true,
+	notionapi.BlockTypeEquation:         true,
+	notionapi.BlockTypeBreadcrumb:       true,
+	notionapi.BlockTypeColumn:           true,
+	notionapi.BlockTypeColumnList:       true,
+	notionapi.BlockTypeLinkPreview:      true,
+	notionapi.BlockTypeLinkToPage:       true,
+	notionapi.BlockTypeTemplate:         true,
+	notionapi.BlockTypeSyncedBlock:      true,
+	notionapi.BlockTypeTableBlock:       true,
+	notionapi.BlockTypeTableRowBlock:    true,
+	notionapi.BlockTypeUnsupported:      true,
+}
+
+// Check if the Block type is known to Notion API
+func IsKnownBlockType(blockType string) bool {
+	return knownBlockTypes[notionapi.BlockType(blockType)]
+}
+
+// Filter of the objects included in the backup. Excluded Pages, Databases and
+// Blocks are not written and their children are never fetched
+type Filter struct {
+	// Notion IDs of the Pages and Databases excluded along with their children
+	ExcludePageIds     []string
+	ExcludeDatabaseIds []string
+
+	// Pages and Databases whose title matches the expression are excluded along
+	// with their children
+	ExcludeTitleRegex *regexp.Regexp
+
+	// Maximum depth of nested Pages and Databases, the objects backed up
+	// directly being at depth 1. Zero means no limit
+	MaxDepth int
+
+	// Types of the excluded Blocks, e.g. embed and link_preview
+	ExcludeBlockTypes []string
+}
+
+// Check if the filter excludes any object
+func (f *Filter) IsEmpty() bool {
+	return f == nil || (len(f.ExcludePageIds) == 0 &&
+		len(f.ExcludeDatabaseIds) == 0 && f.ExcludeTitleRegex == nil &&
+		f.MaxDepth <= 0 && len(f.ExcludeBlockTypes) == 0)
+}
+
+// Get the filter as recorded in the metadata of the backup. Nil is returned for
+// empty filter
+func (f *Filter) ToProto() *metadata.BackupFilter {
+	if f.IsEmpty() {
+		return nil
+	}
+
+	filter := &metadata.BackupFilter{
+		ExcludePageIds:     f.ExcludePageIds,
+		ExcludeDatabaseIds: f.ExcludeDatabaseIds,
+		MaxDepth:           int32(f.MaxDepth),
+		ExcludeBlockTypes:  f.ExcludeBlockTypes,
+	}
+
+	if f.ExcludeTitleRegex != nil {
+		filter.ExcludeTitleRegex = f.ExcludeTitleRegex.String()
+	}
+
+	return filter
+}
+
+// Filter compiled for the lookups done while building the tree
+type objectFilter struct {
+	excludedIds        map[string]bool
+	titleRegex         *regexp.Regexp
+	maxDepth           int
+	excludedBlockTypes map[notionapi.BlockType]bool
+}
+
+func getObjectFilter(filter *Filter) *objectFilter {
+	objFilter := &objectFilter{
+		excludedIds:        make(map[string]bool),
+		excludedBlockTypes: make(map[notionapi.BlockType]bool),
+	}
+
+	if filter == nil {
+		return objFilter
+	}
+
+	for _, ids := range [][]string{filter.ExcludePageIds,
+		filter.ExcludeDatabaseIds} {
+		for _, id := range ids {
+			objFilter.excludedIds[utils.NormalizeNotionId(id)] = true
+		}
+	}
+
+	for _, blockType := range filter.ExcludeBlockTypes {
+		objFilter.excludedBlockTypes[notionapi.BlockType(blockType)] = true
+	}
+
+	objFilter.titleRegex = filter.ExcludeTitleRegex
+	objFilter.maxDepth = filter.MaxDepth
+	return objFilter
+}
+
+// Get the depth of the Page or Database added to the given node i.e.
+// parentNode. Blocks do not add to the depth
+func getChildDepth(parentNode *node.Node) int {
+	depth := 1
+	for nodeObj := parentNode; nodeObj != nil; nodeObj = nodeObj.GetParentNode() {
+		if nodeObj.GetNodeType() == node.PAGE ||
+			nodeObj.GetNodeType() == node.DATABASE {
+			depth++
+		}
+	}
+
+	return depth
+}
+
+// Check if the Page or Database with given ID added to the given node i.e.
+// parentNode is excluded before fetching it
+func (f *objectFilter) isIdExcluded(ctx context.Context, parentNode *node.Node,
+	notionObjectId string) bool {
+	log := zerolog.Ctx(ctx)
+	if f.excludedIds[utils.NormalizeNotionId(notionObjectId)] {
+		log.Debug().Msgf("Excluding %s as it is excluded by ID", notionObjectId)
+		return true
+	}
+
+	if f.maxDepth > 0 && getChildDepth(parentNode) > f.maxDepth {
+		log.Debug().Msgf("Excluding %s as it is deeper than maximum depth %d",
+			notionObjectId, f.maxDepth)
+		return true
+	}
+
+	return false
+}
+
+// Check if the Page or Database with given title is excluded. Excluded object
+// is remembered so that it is not fetched again when found in another parent
+func (f *objectFilter) isTitleExcluded(ctx context.Context,
+	notionObjectId string, title string) bool {
+	if f.titleRegex == nil || !f.titleRegex.MatchString(title) {
+		return false
+	}
+
+	zerolog.Ctx(ctx).Debug().Msgf("Excluding %s as its title '%s' matches the "+
+		"title filter", notionObjectId, title)
+	f.excludedIds[utils.NormalizeNotionId(notionObjectId)] = true
+	return true
+}
+
+// Check if the Page added to the given node i.e. parentNode is excluded
+func (f *objectFilter) isPageExcluded(ctx context.Context,
+	parentNode *node.Node, page *notionapi.Page) bool {
+	return f.isIdExcluded(ctx, parentNode, page.ID.String()) ||
+		f.isTitleExcluded(ctx, page.ID.String(), utils.GetPageTitle(page))
+}
+
+// Check if the Database added to the given node i.e. parentNode is excluded
+func (f *objectFilter) isDatabaseExcluded(ctx context.Context,
+	parentNode *node.Node, database *notionapi.Database) bool {
+	return f.isIdExcluded(ctx, parentNode, database.ID.String()) ||
+		f.isTitleExcluded(ctx, database.ID.String(),
+			utils.GetDatabaseTitle(database))
+}
+
+func (f *objectFilter) isBlockExcluded(block notionapi.Block) bool {
+	return f.excludedBlockTypes[block.GetType()]
+}
+
+// Check if the Block added to the given node i.e. parentNode is excluded. Child
+// page and database blocks are excluded along with their Pages and Databases,
+// so that the blocks are not left without them
+func (f *objectFilter) isChildBlockExcluded(ctx context.Context,
+	parentNode *node.Node, block notionapi.Block) bool {
+	if f.isBlockExcluded(block) {
+		return true
+	}
+
+	switch childBlock := block.(type) {
+	case *notionapi.ChildPageBlock:
+		return f.isIdExcluded(ctx, parentNode, childBlock.ID.String()) ||
+			f.isTitleExcluded(ctx, childBlock.ID.String(),
+				childBlock.ChildPage.Title)
+	case *notionapi.ChildDatabaseBlock:
+		return f.isIdExcluded(ctx, parentNode, childBlock.ID.String()) ||
+			f.isTitleExcluded(ctx, childBlock.ID.String(),
+				childBlock.ChildDatabase.Title)
+	}
+
+	return false
+}
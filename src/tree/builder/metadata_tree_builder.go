@@ -82,7 +82,13 @@ func (builder *MetadataTreeBuilder) BuildTree(ctx context.Context) (*tree.Tree,
 		return nil, fmt.Errorf("root node does not exist")
 	}
 
-	return &tree.Tree{
+	treeObj := &tree.Tree{
 		RootNode: rootNode,
-	}, nil
+		Assets:   builder.metadataCfg.AssetMap,
+	}
+	if builder.metadataCfg.CreatedAt != nil {
+		treeObj.CreatedAt = builder.metadataCfg.CreatedAt.AsTime()
+	}
+
+	return treeObj, nil
 }
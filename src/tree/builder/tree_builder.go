@@ -3,7 +3,9 @@ package builder
 import (
 	"context"
 	"fmt"
+	"net/http"
 
+	"github.com/shivaji17/notionbackup/src/rw"
 	"github.com/shivaji17/notionbackup/src/tree"
 	"github.com/shivaji17/notionbackup/src/tree/node"
 )
@@ -11,6 +13,33 @@ import (
 type TreeBuilderRequest struct {
 	PageIdList     []string
 	DatabaseIdList []string
+
+	// Number of workers fetching the children of nodes in parallel. Values less
+	// than or equal to 1 build the tree sequentially
+	Concurrency int
+
+	// Tree and ReaderWriter of the previous snapshot. If set, objects which are
+	// not edited after the previous snapshot was taken are reused instead of
+	// being fetched and written again
+	PreviousTree         *tree.Tree
+	PreviousReaderWriter rw.ReaderWriter
+
+	// Download the files hosted by Notion, like images and page covers, into
+	// the assets area of the ReaderWriter. URLs of such files expire an hour
+	// after they are fetched, so backups without assets lose the files
+	DownloadAssets bool
+
+	// Client with which the assets are downloaded. Client with a timeout of
+	// ASSET_DOWNLOAD_TIMEOUT is used if not set
+	HTTPClient *http.Client
+
+	// Fetch the comments of the pages and blocks. Comments are fetched with a
+	// separate request for every page and block and require the integration
+	// to have the capability to read comments
+	BackupComments bool
+
+	// Pages, Databases and Blocks excluded from the backup
+	Filter *Filter
 }
 
 type TreeBuilder interface {
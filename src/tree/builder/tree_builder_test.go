@@ -7,7 +7,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jomei/notionapi"
@@ -15,6 +21,7 @@ import (
 	"github.com/shivaji17/notionbackup/src/metadata"
 	"github.com/shivaji17/notionbackup/src/mocks"
 	"github.com/shivaji17/notionbackup/src/notionclient"
+	"github.com/shivaji17/notionbackup/src/progress"
 	"github.com/shivaji17/notionbackup/src/rw"
 	"github.com/shivaji17/notionbackup/src/tree"
 	"github.com/shivaji17/notionbackup/src/tree/builder"
@@ -23,6 +30,7 @@ import (
 	"github.com/shivaji17/notionbackup/src/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
@@ -324,7 +332,7 @@ func (c *mocker) mockNotionClientFunctions() {
 
 		for databaseId, pages := range c.databaseId2PageList {
 			if len(pages) == 0 {
-				c.mockedNotionClient.On("GetDatabasePages", context.Background(),
+				c.mockedNotionClient.On("GetDatabasePages", mock.Anything,
 					notionclient.DatabaseID(databaseId), EMPTY_CURSOR).
 					Return(pages, EMPTY_CURSOR, nil)
 			}
@@ -345,18 +353,18 @@ func (c *mocker) mockNotionClientFunctions() {
 
 		for databaseId, pages := range c.databaseId2PageList {
 			if len(pages) <= 1 {
-				c.mockedNotionClient.On("GetDatabasePages", context.Background(),
+				c.mockedNotionClient.On("GetDatabasePages", mock.Anything,
 					notionclient.DatabaseID(databaseId), EMPTY_CURSOR).
 					Return(pages, EMPTY_CURSOR, nil)
 				continue
 			}
 
 			index := len(pages) / 2
-			c.mockedNotionClient.On("GetDatabasePages", context.Background(),
+			c.mockedNotionClient.On("GetDatabasePages", mock.Anything,
 				notionclient.DatabaseID(databaseId), EMPTY_CURSOR).
 				Return(pages[:index], cursor, nil)
 
-			c.mockedNotionClient.On("GetDatabasePages", context.Background(),
+			c.mockedNotionClient.On("GetDatabasePages", mock.Anything,
 				notionclient.DatabaseID(databaseId), cursor).
 				Return(pages[index:], EMPTY_CURSOR, nil)
 		}
@@ -365,7 +373,7 @@ func (c *mocker) mockNotionClientFunctions() {
 
 	for pageId, blocks := range c.pageId2BlockList {
 		if len(blocks) <= 1 {
-			c.mockedNotionClient.On("GetPageBlocks", context.Background(),
+			c.mockedNotionClient.On("GetPageBlocks", mock.Anything,
 				notionclient.PageID(pageId), EMPTY_CURSOR).
 				Return(blocks, EMPTY_CURSOR, nil)
 
@@ -373,29 +381,29 @@ func (c *mocker) mockNotionClientFunctions() {
 		}
 
 		index := len(blocks) / 2
-		c.mockedNotionClient.On("GetPageBlocks", context.Background(),
+		c.mockedNotionClient.On("GetPageBlocks", mock.Anything,
 			notionclient.PageID(pageId), EMPTY_CURSOR).
 			Return(blocks[:index], cursor, nil)
 
-		c.mockedNotionClient.On("GetPageBlocks", context.Background(),
+		c.mockedNotionClient.On("GetPageBlocks", mock.Anything,
 			notionclient.PageID(pageId), cursor).
 			Return(blocks[index:], EMPTY_CURSOR, nil)
 	}
 
 	for blockId, blocks := range c.blockId2BlockList {
 		if len(blocks) <= 1 {
-			c.mockedNotionClient.On("GetChildBlocksOfBlock", context.Background(),
+			c.mockedNotionClient.On("GetChildBlocksOfBlock", mock.Anything,
 				notionclient.BlockID(blockId), EMPTY_CURSOR).
 				Return(blocks, EMPTY_CURSOR, nil)
 			continue
 		}
 
 		index := len(blocks) / 2
-		c.mockedNotionClient.On("GetChildBlocksOfBlock", context.Background(),
+		c.mockedNotionClient.On("GetChildBlocksOfBlock", mock.Anything,
 			notionclient.BlockID(blockId), EMPTY_CURSOR).
 			Return(blocks[:index], cursor, nil)
 
-		c.mockedNotionClient.On("GetChildBlocksOfBlock", context.Background(),
+		c.mockedNotionClient.On("GetChildBlocksOfBlock", mock.Anything,
 			notionclient.BlockID(blockId), cursor).
 			Return(blocks[index:], EMPTY_CURSOR, nil)
 	}
@@ -437,6 +445,34 @@ func createNotionObjectMappingFromTree(
 	return objectMapping
 }
 
+// Creates mapping of each node to its children IDs in the order of siblings
+func createOrderedNotionObjectMappingFromTree(
+	treeObj *tree.Tree) map[string][]string {
+	objectMapping := make(map[string][]string)
+	treeIter := iterator.GetTreeIterator(treeObj.RootNode)
+	nodeList := []*node.Node{treeObj.RootNode}
+	for {
+		obj, err := treeIter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+		nodeList = append(nodeList, obj)
+	}
+
+	for _, obj := range nodeList {
+		childIter := iterator.GetChildIterator(obj)
+		for {
+			childObj, err := childIter.Next()
+			if err == iterator.ErrDone {
+				break
+			}
+			objectMapping[obj.GetNotionObjectId()] = append(
+				objectMapping[obj.GetNotionObjectId()], childObj.GetNotionObjectId())
+		}
+	}
+	return objectMapping
+}
+
 // ExportTreebuilder tester
 func TestExportTreeBuilder(t *testing.T) {
 	assert := assert.New(t)
@@ -628,7 +664,7 @@ func TestExportTreeBuilder(t *testing.T) {
 			}, nil)
 
 		mockedNotionClient.On(
-			"GetPageBlocks", context.Background(),
+			"GetPageBlocks", mock.Anything,
 			notionclient.PageID("36dac6ee-76e9-4c99-94a9-b0989be3f624"),
 			EMPTY_CURSOR).
 			Return([]notionapi.Block{}, EMPTY_CURSOR, errGeneric)
@@ -711,7 +747,7 @@ func TestExportTreeBuilder(t *testing.T) {
 			}, nil)
 
 		mockedNotionClient.On(
-			"GetDatabasePages", context.Background(),
+			"GetDatabasePages", mock.Anything,
 			notionclient.DatabaseID("36dac6ee-76e9-4c99-94a9-b0989be3f624"),
 			EMPTY_CURSOR).
 			Return([]notionapi.Page{}, EMPTY_CURSOR, errGeneric)
@@ -753,7 +789,7 @@ func TestExportTreeBuilder(t *testing.T) {
 		}
 
 		mockedNotionClient.On(
-			"GetPageBlocks", context.Background(),
+			"GetPageBlocks", mock.Anything,
 			notionclient.PageID("36dac6ee-76e9-4c99-94a9-b0989be3f624"),
 			EMPTY_CURSOR).
 			Return([]notionapi.Block{block}, EMPTY_CURSOR, nil)
@@ -795,13 +831,13 @@ func TestExportTreeBuilder(t *testing.T) {
 		}
 
 		mockedNotionClient.On(
-			"GetPageBlocks", context.Background(),
+			"GetPageBlocks", mock.Anything,
 			notionclient.PageID("36dac6ee-76e9-4c99-94a9-b0989be3f624"),
 			EMPTY_CURSOR).
 			Return([]notionapi.Block{block}, EMPTY_CURSOR, nil)
 
 		mockedNotionClient.On(
-			"GetChildBlocksOfBlock", context.Background(),
+			"GetChildBlocksOfBlock", mock.Anything,
 			notionclient.BlockID(blockId), EMPTY_CURSOR).
 			Return([]notionapi.Block{}, EMPTY_CURSOR, errGeneric)
 
@@ -1082,3 +1118,789 @@ func TestMetaDataTreeBuilder(t *testing.T) {
 		assert.NotNil(err)
 	})
 }
+
+// Tree built concurrently must be same as the tree built sequentially
+func TestConcurrentExportTreeBuilder(t *testing.T) {
+	assert := assert.New(t)
+
+	tests := []struct {
+		name     string
+		filePath string
+		request  builder.TreeBuilderRequest
+	}{
+		{
+			name:     "Build tree for whole workspace",
+			filePath: WORKSPACE_TREE,
+			request:  builder.TreeBuilderRequest{},
+		},
+		{
+			name:     "Build tree for given page and database",
+			filePath: SPECIFIC_PAGE_DATABASE_TREE,
+			request: builder.TreeBuilderRequest{
+				PageIdList:     []string{"05034203-2870-4bc8-b1f9-22c0ae6e56ba"},
+				DatabaseIdList: []string{"5ed2d97a-510a-4756-b113-cc28c7a30fd7"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockedRW := mocks.NewReaderWriter(t)
+			mockedNotionClient := mocks.NewNotionClient(t)
+
+			// mock all ReaderWriter functions
+			mockWritePage(mockedRW, mock.Anything, nil)
+			mockWriteDatabase(mockedRW, mock.Anything, nil)
+			mockWriteBlock(mockedRW, mock.Anything, nil)
+
+			// mock all required NotionClient functions
+			mockerObj := getMocker(mockedNotionClient)
+			mockerObj.createMappings(t, test.filePath)
+			mockerObj.mockNotionClientFunctions()
+
+			sequentialRequest := test.request
+			sequentialBuilder := builder.GetExportTreebuilder(context.Background(),
+				mockedNotionClient, mockedRW, &sequentialRequest)
+			sequentialTree, err := sequentialBuilder.BuildTree(context.Background())
+			assert.Nil(err)
+			assert.NotNil(sequentialTree)
+
+			concurrentRequest := test.request
+			concurrentRequest.Concurrency = 4
+			concurrentBuilder := builder.GetExportTreebuilder(context.Background(),
+				mockedNotionClient, mockedRW, &concurrentRequest)
+			concurrentTree, err := concurrentBuilder.BuildTree(context.Background())
+			assert.Nil(err)
+			assert.NotNil(concurrentTree)
+
+			assert.Equal(mockerObj.objectIdMapping,
+				createNotionObjectMappingFromTree(concurrentTree))
+			assert.Equal(createOrderedNotionObjectMappingFromTree(sequentialTree),
+				createOrderedNotionObjectMappingFromTree(concurrentTree))
+		})
+	}
+
+	t.Run("Error while fetching blocks of given page", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+
+		mockWritePage(mockedRW, mock.Anything, nil)
+		mockedRW.On("CleanUp", context.Background()).Return(nil)
+
+		pageIds := []string{uuid.NewString(), uuid.NewString(), uuid.NewString()}
+		for i, pageId := range pageIds {
+			mockedNotionClient.On("GetPageByID", context.Background(),
+				notionclient.PageID(pageId)).
+				Return(&notionapi.Page{ID: notionapi.ObjectID(pageId)}, nil)
+
+			var err error
+			if i == 1 {
+				err = errGeneric
+			}
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(pageId), EMPTY_CURSOR).
+				Return([]notionapi.Block{}, EMPTY_CURSOR, err).Maybe()
+		}
+
+		treeBuilder := builder.GetExportTreebuilder(context.Background(),
+			mockedNotionClient, mockedRW, &builder.TreeBuilderRequest{
+				PageIdList:  pageIds,
+				Concurrency: 2,
+			})
+
+		tree, err := treeBuilder.BuildTree(context.Background())
+		assert.NotNil(err)
+		assert.Nil(tree)
+	})
+
+	t.Run("Fetching is cancelled after error", func(t *testing.T) {
+		mockedRW := mocks.NewReaderWriter(t)
+		mockedNotionClient := mocks.NewNotionClient(t)
+
+		mockWritePage(mockedRW, mock.Anything, nil)
+		mockedRW.On("CleanUp", context.Background()).Return(nil)
+
+		// Blocks of second page are fetched until the request is cancelled
+		pageIds := []string{uuid.NewString(), uuid.NewString()}
+		for _, pageId := range pageIds {
+			mockedNotionClient.On("GetPageByID", context.Background(),
+				notionclient.PageID(pageId)).
+				Return(&notionapi.Page{ID: notionapi.ObjectID(pageId)}, nil)
+		}
+
+		mockedNotionClient.On("GetPageBlocks", mock.Anything,
+			notionclient.PageID(pageIds[0]), EMPTY_CURSOR).
+			Return([]notionapi.Block{}, EMPTY_CURSOR, errGeneric)
+
+		cancelled := false
+		mockedNotionClient.On("GetPageBlocks", mock.Anything,
+			notionclient.PageID(pageIds[1]), EMPTY_CURSOR).
+			Run(func(args mock.Arguments) {
+				select {
+				case <-args.Get(0).(context.Context).Done():
+					cancelled = true
+				case <-time.After(10 * time.Second):
+				}
+			}).Return([]notionapi.Block{}, EMPTY_CURSOR, context.Canceled)
+
+		treeBuilder := builder.GetExportTreebuilder(context.Background(),
+			mockedNotionClient, mockedRW, &builder.TreeBuilderRequest{
+				PageIdList:  pageIds,
+				Concurrency: 2,
+			})
+
+		tree, err := treeBuilder.BuildTree(context.Background())
+		assert.Equal(errGeneric, err)
+		assert.Nil(tree)
+		assert.True(cancelled)
+	})
+}
+
+// Create tree of previous snapshot having a page with single paragraph block
+func createPreviousSnapshotTree(t *testing.T, pageId string, blockId string,
+	lastEditedTime time.Time, createdAt time.Time) *tree.Tree {
+	rootNode := node.CreateRootNode()
+	pageNode, err := node.CreateNode(&metadata.NotionObject{
+		Uuid:              uuid.NewString(),
+		Type:              metadata.NotionObjectType_PAGE,
+		StorageIdentifier: pageId,
+		NotionObjectId:    pageId,
+		LastEditedTime:    timestamppb.New(lastEditedTime),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockNode, err := node.CreateNode(&metadata.NotionObject{
+		Uuid:              uuid.NewString(),
+		Type:              metadata.NotionObjectType_BLOCK,
+		StorageIdentifier: blockId,
+		NotionObjectId:    blockId,
+		LastEditedTime:    timestamppb.New(lastEditedTime),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootNode.AddChild(pageNode)
+	pageNode.AddChild(blockNode)
+	return &tree.Tree{RootNode: rootNode, CreatedAt: createdAt}
+}
+
+func TestIncrementalExportTreeBuilder(t *testing.T) {
+	assert := assert.New(t)
+	pageId := uuid.NewString()
+	blockId := uuid.NewString()
+	previousEditedTime := time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC)
+	previousCreatedAt := previousEditedTime.Add(time.Hour)
+
+	tests := []struct {
+		name           string
+		lastEditedTime time.Time
+		createdAt      time.Time
+		concurrency    int
+		reused         bool
+	}{
+		{
+			name:           "Unchanged page is reused",
+			lastEditedTime: previousEditedTime,
+			createdAt:      previousCreatedAt,
+			concurrency:    1,
+			reused:         true,
+		},
+		{
+			name:           "Unchanged page is reused with concurrency",
+			lastEditedTime: previousEditedTime,
+			createdAt:      previousCreatedAt,
+			concurrency:    4,
+			reused:         true,
+		},
+		{
+			name:           "Edited page is fetched again",
+			lastEditedTime: previousEditedTime.Add(time.Minute),
+			createdAt:      previousCreatedAt,
+			concurrency:    1,
+			reused:         false,
+		},
+		{
+			name:           "Edited page is fetched again with concurrency",
+			lastEditedTime: previousEditedTime.Add(time.Minute),
+			createdAt:      previousCreatedAt,
+			concurrency:    4,
+			reused:         false,
+		},
+		{
+			// Page may have been edited again in the same minute after it was
+			// fetched by the previous snapshot
+			name:           "Page edited while previous snapshot was taken",
+			lastEditedTime: previousEditedTime,
+			createdAt:      previousEditedTime.Add(30 * time.Second),
+			concurrency:    1,
+			reused:         false,
+		},
+		{
+			name:           "Unchanged page of older snapshot is reused",
+			lastEditedTime: previousEditedTime,
+			concurrency:    1,
+			reused:         true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mockedRW := mocks.NewReaderWriter(t)
+			previousRW := mocks.NewReaderWriter(t)
+			mockedNotionClient := mocks.NewNotionClient(t)
+
+			page := &notionapi.Page{
+				ID:             notionapi.ObjectID(pageId),
+				LastEditedTime: test.lastEditedTime,
+			}
+			mockedNotionClient.On("GetPageByID", context.Background(),
+				notionclient.PageID(pageId)).Return(page, nil)
+
+			if test.reused {
+				mockedRW.On("ReuseObject", context.Background(), previousRW,
+					metadata.NotionObjectType_PAGE, rw.DataIdentifier(pageId)).
+					Return(rw.DataIdentifier(pageId), nil)
+				mockedRW.On("ReuseObject", context.Background(), previousRW,
+					metadata.NotionObjectType_BLOCK, rw.DataIdentifier(blockId)).
+					Return(rw.DataIdentifier(blockId), nil)
+				previousRW.On("ReadBlock", context.Background(),
+					rw.DataIdentifier(blockId)).
+					Return(&notionapi.ParagraphBlock{
+						BasicBlock: notionapi.BasicBlock{
+							ID:   notionapi.BlockID(blockId),
+							Type: notionapi.BlockTypeParagraph,
+						},
+					}, nil)
+			} else {
+				mockWritePage(mockedRW, page, nil)
+				mockedNotionClient.On("GetPageBlocks", mock.Anything,
+					notionclient.PageID(pageId), EMPTY_CURSOR).
+					Return([]notionapi.Block{}, EMPTY_CURSOR, nil)
+			}
+
+			treeBuilder := builder.GetExportTreebuilder(context.Background(),
+				mockedNotionClient, mockedRW, &builder.TreeBuilderRequest{
+					PageIdList:  []string{pageId},
+					Concurrency: test.concurrency,
+					PreviousTree: createPreviousSnapshotTree(t, pageId, blockId,
+						previousEditedTime, test.createdAt),
+					PreviousReaderWriter: previousRW,
+				})
+
+			tree, err := treeBuilder.BuildTree(context.Background())
+			assert.Nil(err)
+			assert.NotNil(tree)
+
+			pageNode := tree.RootNode.GetChildNode()
+			assert.NotNil(pageNode)
+			assert.Equal(pageId, pageNode.GetNotionObjectId())
+			assert.True(test.lastEditedTime.Equal(pageNode.GetLastEditedTime()))
+			if test.reused {
+				assert.NotNil(pageNode.GetChildNode())
+				assert.Equal(blockId, pageNode.GetChildNode().GetNotionObjectId())
+			} else {
+				assert.Nil(pageNode.GetChildNode())
+			}
+		})
+	}
+}
+
+// Helper function to create the image block of the file hosted by Notion
+func getImageBlock(blockId string, fileUrl string) *notionapi.ImageBlock {
+	return &notionapi.ImageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(blockId),
+			Type:   notionapi.BlockTypeImage,
+		},
+		Image: notionapi.Image{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{URL: fileUrl},
+		},
+	}
+}
+
+func TestExportTreeBuilderAssets(t *testing.T) {
+	assert := assert.New(t)
+	var requestCount int32
+	server := httptest.NewServer(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&requestCount, 1)
+			if r.URL.Path == "/files/expired.png" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+
+			w.Header().Set("Content-Type", "image/png")
+			w.Write([]byte("content of " + r.URL.Path))
+		}))
+	defer server.Close()
+
+	pageId := uuid.NewString()
+	page := &notionapi.Page{
+		Object:         notionapi.ObjectTypePage,
+		ID:             notionapi.ObjectID(pageId),
+		LastEditedTime: time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC),
+		Cover: &notionapi.Image{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{
+				URL: server.URL + "/files/cover.png?X-Amz-Signature=1",
+			},
+		},
+		Icon: &notionapi.Icon{
+			Type:     notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{URL: server.URL + "/external.png"},
+		},
+	}
+	blocks := []notionapi.Block{
+		getImageBlock(uuid.NewString(),
+			server.URL+"/files/diagram.png?X-Amz-Signature=2"),
+		getImageBlock(uuid.NewString(),
+			server.URL+"/files/cover.png?X-Amz-Signature=3"),
+		getImageBlock(uuid.NewString(),
+			server.URL+"/files/expired.png?X-Amz-Signature=4"),
+	}
+
+	mockedNotionClient := mocks.NewNotionClient(t)
+	mockedNotionClient.On("GetPageByID", context.Background(),
+		notionclient.PageID(pageId)).Return(page, nil)
+	mockedNotionClient.On("GetPageBlocks", mock.Anything,
+		notionclient.PageID(pageId), EMPTY_CURSOR).
+		Return(blocks, EMPTY_CURSOR, nil).Once()
+
+	fileRW, err := rw.GetFileReaderWriter(context.Background(), t.TempDir(),
+		true)
+	assert.Nil(err)
+
+	treeBuilder := builder.GetExportTreebuilder(context.Background(),
+		mockedNotionClient, fileRW, &builder.TreeBuilderRequest{
+			PageIdList:     []string{pageId},
+			DownloadAssets: true,
+		})
+	tree, err := treeBuilder.BuildTree(context.Background())
+	assert.Nil(err)
+
+	// Expired file is skipped and the same file is downloaded only once
+	assert.Equal(int32(3), atomic.LoadInt32(&requestCount))
+	assert.Len(tree.Assets, 2)
+	for _, name := range []string{"cover.png", "diagram.png"} {
+		asset := tree.Assets[server.URL+"/files/"+name]
+		if !assert.NotNil(asset) {
+			continue
+		}
+
+		dataBytes, err := rw.ReadAsset(context.Background(), fileRW,
+			rw.DataIdentifier(asset.StorageIdentifier))
+		assert.Nil(err)
+		assert.Equal("content of /files/"+name, string(dataBytes))
+		assert.Equal(name, asset.FileName)
+		assert.Equal("image/png", asset.ContentType)
+		assert.Equal(rw.GetChecksum(dataBytes), asset.Checksum)
+	}
+
+	t.Run("Assets of unchanged objects are reused", func(t *testing.T) {
+		incrementalRW, err := rw.GetFileReaderWriter(context.Background(),
+			t.TempDir(), true)
+		assert.Nil(err)
+
+		treeBuilder := builder.GetExportTreebuilder(context.Background(),
+			mockedNotionClient, incrementalRW, &builder.TreeBuilderRequest{
+				PageIdList:           []string{pageId},
+				DownloadAssets:       true,
+				PreviousTree:         tree,
+				PreviousReaderWriter: fileRW,
+			})
+		incrementalTree, err := treeBuilder.BuildTree(context.Background())
+		assert.Nil(err)
+
+		// Only the expired file is requested again
+		assert.Equal(int32(4), atomic.LoadInt32(&requestCount))
+		assert.Len(incrementalTree.Assets, 2)
+		for key, asset := range incrementalTree.Assets {
+			assert.Equal(tree.Assets[key].Checksum, asset.Checksum)
+			_, err := rw.ReadAsset(context.Background(), incrementalRW,
+				rw.DataIdentifier(asset.StorageIdentifier))
+			assert.Nil(err)
+		}
+	})
+}
+
+func getComment(pageId string, discussionId string,
+	content string) notionapi.Comment {
+	return notionapi.Comment{
+		Object:       notionapi.ObjectTypeComment,
+		ID:           notionapi.ObjectID(uuid.NewString()),
+		DiscussionID: notionapi.DiscussionID(discussionId),
+		CreatedTime:  time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC),
+		Parent: notionapi.Parent{
+			Type:   notionapi.ParentTypePageID,
+			PageID: notionapi.PageID(pageId),
+		},
+		RichText: []notionapi.RichText{{
+			Type:      notionapi.ObjectTypeText,
+			Text:      &notionapi.Text{Content: content},
+			PlainText: content,
+		}},
+	}
+}
+
+// Helper function to get the contents of the comments added to the node
+func getCommentContents(t *testing.T, rwClient rw.ReaderWriter,
+	nodeObj *node.Node) []string {
+	contents := []string{}
+	iter := iterator.GetChildIterator(nodeObj)
+	for {
+		childNode, err := iter.Next()
+		if err == iterator.ErrDone {
+			break
+		}
+
+		if childNode.GetNodeType() != node.COMMENT {
+			continue
+		}
+
+		comment, err := rwClient.ReadComment(context.Background(),
+			childNode.GetStorageIdentifier())
+		assert.Nil(t, err)
+		assert.Equal(t, comment.ID.String(), childNode.GetNotionObjectId())
+		contents = append(contents, utils.GetPlainText(comment.RichText))
+	}
+	return contents
+}
+
+func TestExportTreeBuilderComments(t *testing.T) {
+	pageId := uuid.NewString()
+	page := &notionapi.Page{
+		Object:         notionapi.ObjectTypePage,
+		ID:             notionapi.ObjectID(pageId),
+		LastEditedTime: time.Date(2022, 5, 1, 10, 30, 0, 0, time.UTC),
+	}
+	blockId := uuid.NewString()
+	childBlockId := uuid.NewString()
+	childPageId := uuid.NewString()
+	blocks := []notionapi.Block{
+		&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object:      notionapi.ObjectTypeBlock,
+				ID:          notionapi.BlockID(blockId),
+				Type:        notionapi.BlockTypeParagraph,
+				HasChildren: true,
+			},
+		},
+		&notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(childPageId),
+				Type:   notionapi.BlockTypeChildPage,
+			},
+		},
+	}
+	childBlocks := []notionapi.Block{
+		&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(childBlockId),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+		},
+	}
+	childPage := &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     notionapi.ObjectID(childPageId),
+	}
+
+	discussionId := uuid.NewString()
+	// Blocks of unchanged pages are reused without fetching them
+	mockClient := func(t *testing.T, reused bool) *mocks.NotionClient {
+		mockedNotionClient := mocks.NewNotionClient(t)
+		mockedNotionClient.On("GetPageByID", context.Background(),
+			notionclient.PageID(pageId)).Return(page, nil)
+		mockedNotionClient.On("GetPageByID", context.Background(),
+			notionclient.PageID(childPageId)).Return(childPage, nil)
+		mockedNotionClient.On("GetPageBlocks", mock.Anything,
+			notionclient.PageID(childPageId), EMPTY_CURSOR).
+			Return([]notionapi.Block{}, EMPTY_CURSOR, nil)
+		if !reused {
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(pageId), EMPTY_CURSOR).
+				Return(blocks, EMPTY_CURSOR, nil)
+			mockedNotionClient.On("GetChildBlocksOfBlock", mock.Anything,
+				notionclient.BlockID(blockId), EMPTY_CURSOR).
+				Return(childBlocks, EMPTY_CURSOR, nil)
+		}
+		mockedNotionClient.On("GetComments", mock.Anything,
+			notionclient.BlockID(pageId), EMPTY_CURSOR).
+			Return([]notionapi.Comment{
+				getComment(pageId, discussionId, "first"),
+			}, notionapi.Cursor("next"), nil).Once()
+		mockedNotionClient.On("GetComments", mock.Anything,
+			notionclient.BlockID(pageId), notionapi.Cursor("next")).
+			Return([]notionapi.Comment{
+				getComment(pageId, discussionId, "reply"),
+			}, EMPTY_CURSOR, nil).Once()
+		mockedNotionClient.On("GetComments", mock.Anything,
+			notionclient.BlockID(blockId), EMPTY_CURSOR).
+			Return([]notionapi.Comment{
+				getComment(pageId, uuid.NewString(), "on block"),
+			}, EMPTY_CURSOR, nil).Once()
+		mockedNotionClient.On("GetComments", mock.Anything,
+			notionclient.BlockID(childBlockId), EMPTY_CURSOR).
+			Return([]notionapi.Comment{}, EMPTY_CURSOR, nil).Once()
+		mockedNotionClient.On("GetComments", mock.Anything,
+			notionclient.BlockID(childPageId), EMPTY_CURSOR).
+			Return([]notionapi.Comment{}, EMPTY_CURSOR, nil).Once()
+		return mockedNotionClient
+	}
+
+	assertComments := func(t *testing.T, rwClient rw.ReaderWriter,
+		treeObj *tree.Tree) {
+		pageNode := treeObj.RootNode.GetChildNode()
+		assert.Equal(t, []string{"first", "reply"},
+			getCommentContents(t, rwClient, pageNode))
+
+		// Comments are added before the children of the block
+		blockNode := pageNode.GetChildNode().GetSiblingNode().GetSiblingNode()
+		assert.Equal(t, blockId, blockNode.GetNotionObjectId())
+		assert.Equal(t, []string{"on block"},
+			getCommentContents(t, rwClient, blockNode))
+		assert.Equal(t, node.COMMENT, blockNode.GetChildNode().GetNodeType())
+		assert.Equal(t, childBlockId,
+			blockNode.GetChildNode().GetSiblingNode().GetNotionObjectId())
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("Concurrency %d", concurrency), func(t *testing.T) {
+			fileRW, err := rw.GetFileReaderWriter(context.Background(),
+				t.TempDir(), true)
+			assert.Nil(t, err)
+
+			treeBuilder := builder.GetExportTreebuilder(context.Background(),
+				mockClient(t, false), fileRW, &builder.TreeBuilderRequest{
+					PageIdList:     []string{pageId},
+					Concurrency:    concurrency,
+					BackupComments: true,
+				})
+			treeObj, err := treeBuilder.BuildTree(context.Background())
+			assert.Nil(t, err)
+			assertComments(t, fileRW, treeObj)
+
+			t.Run("Comments of unchanged page are fetched again",
+				func(t *testing.T) {
+					incrementalRW, err := rw.GetFileReaderWriter(
+						context.Background(), t.TempDir(), true)
+					assert.Nil(t, err)
+
+					treeBuilder := builder.GetExportTreebuilder(context.Background(),
+						mockClient(t, true), incrementalRW, &builder.TreeBuilderRequest{
+							PageIdList:           []string{pageId},
+							Concurrency:          concurrency,
+							BackupComments:       true,
+							PreviousTree:         treeObj,
+							PreviousReaderWriter: fileRW,
+						})
+					incrementalTree, err := treeBuilder.BuildTree(context.Background())
+					assert.Nil(t, err)
+					assertComments(t, incrementalRW, incrementalTree)
+				})
+		})
+	}
+}
+
+func TestExportTreeBuilderProgress(t *testing.T) {
+	pageId := uuid.NewString()
+	blockId := uuid.NewString()
+	childPageId := uuid.NewString()
+	blocks := []notionapi.Block{
+		&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object:      notionapi.ObjectTypeBlock,
+				ID:          notionapi.BlockID(blockId),
+				Type:        notionapi.BlockTypeParagraph,
+				HasChildren: true,
+			},
+		},
+		&notionapi.ChildPageBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(childPageId),
+				Type:   notionapi.BlockTypeChildPage,
+			},
+		},
+	}
+	childBlocks := []notionapi.Block{
+		&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+		},
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("Concurrency %d", concurrency), func(t *testing.T) {
+			mockedNotionClient := mocks.NewNotionClient(t)
+			mockedNotionClient.On("GetPageByID", mock.Anything,
+				notionclient.PageID(pageId)).Return(&notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID(pageId),
+			}, nil)
+			mockedNotionClient.On("GetPageByID", mock.Anything,
+				notionclient.PageID(childPageId)).Return(&notionapi.Page{
+				Object: notionapi.ObjectTypePage,
+				ID:     notionapi.ObjectID(childPageId),
+			}, nil)
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(pageId), EMPTY_CURSOR).
+				Return(blocks, EMPTY_CURSOR, nil)
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(childPageId), EMPTY_CURSOR).
+				Return([]notionapi.Block{}, EMPTY_CURSOR, nil)
+			mockedNotionClient.On("GetChildBlocksOfBlock", mock.Anything,
+				notionclient.BlockID(blockId), EMPTY_CURSOR).
+				Return(childBlocks, EMPTY_CURSOR, nil)
+
+			fileRW, err := rw.GetFileReaderWriter(context.Background(),
+				t.TempDir(), true)
+			assert.Nil(t, err)
+
+			tracker := progress.GetTracker(progress.BACKUP)
+			ctx := tracker.WithContext(context.Background())
+			treeBuilder := builder.GetExportTreebuilder(ctx, mockedNotionClient,
+				fileRW, &builder.TreeBuilderRequest{
+					PageIdList:  []string{pageId},
+					Concurrency: concurrency,
+				})
+			_, err = treeBuilder.BuildTree(ctx)
+			assert.Nil(t, err)
+
+			status := tracker.GetStatus()
+			assert.Equal(t, progress.ObjectCounts{Discovered: 2, Fetched: 2,
+				Written: 2}, status.Pages)
+			assert.Equal(t, progress.ObjectCounts{Discovered: 3, Fetched: 3,
+				Written: 3}, status.Blocks)
+			assert.Equal(t, int64(5), status.Done)
+			assert.Equal(t, int64(5), status.Total)
+			assert.Greater(t, status.BytesWritten, int64(0))
+		})
+	}
+}
+
+// Helper function to create child page block with the title
+func getChildPageBlock(pageId string, title string) *notionapi.ChildPageBlock {
+	block := &notionapi.ChildPageBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     notionapi.BlockID(pageId),
+			Type:   notionapi.BlockTypeChildPage,
+		},
+	}
+	block.ChildPage.Title = title
+	return block
+}
+
+func TestExportTreeBuilderFilter(t *testing.T) {
+	pageId := uuid.NewString()
+	paragraphId := uuid.NewString()
+	excludedPageId := uuid.NewString()
+	archivedPageId := uuid.NewString()
+	childPageId := uuid.NewString()
+	deepPageId := uuid.NewString()
+
+	blocks := []notionapi.Block{
+		&notionapi.ParagraphBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(paragraphId),
+				Type:   notionapi.BlockTypeParagraph,
+			},
+		},
+		&notionapi.EmbedBlock{
+			BasicBlock: notionapi.BasicBlock{
+				Object: notionapi.ObjectTypeBlock,
+				ID:     notionapi.BlockID(uuid.NewString()),
+				Type:   notionapi.BlockTypeEmbed,
+			},
+		},
+		getChildPageBlock(excludedPageId, "Excluded"),
+		getChildPageBlock(archivedPageId, "Archive 2022"),
+		getChildPageBlock(childPageId, "Notes"),
+	}
+
+	filter := &builder.Filter{
+		ExcludePageIds:    []string{strings.ReplaceAll(excludedPageId, "-", "")},
+		ExcludeTitleRegex: regexp.MustCompile("^Archive"),
+		MaxDepth:          2,
+		ExcludeBlockTypes: []string{string(notionapi.BlockTypeEmbed)},
+	}
+
+	for _, concurrency := range []int{1, 3} {
+		t.Run(fmt.Sprintf("Concurrency %d", concurrency), func(t *testing.T) {
+			// Excluded pages are never fetched, which is asserted by the mock
+			mockedNotionClient := mocks.NewNotionClient(t)
+			for _, id := range []string{pageId, childPageId} {
+				mockedNotionClient.On("GetPageByID", mock.Anything,
+					notionclient.PageID(id)).Return(&notionapi.Page{
+					Object: notionapi.ObjectTypePage,
+					ID:     notionapi.ObjectID(id),
+				}, nil)
+			}
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(pageId), EMPTY_CURSOR).
+				Return(blocks, EMPTY_CURSOR, nil)
+			mockedNotionClient.On("GetPageBlocks", mock.Anything,
+				notionclient.PageID(childPageId), EMPTY_CURSOR).
+				Return([]notionapi.Block{getChildPageBlock(deepPageId, "Deep")},
+					EMPTY_CURSOR, nil)
+
+			fileRW, err := rw.GetFileReaderWriter(context.Background(),
+				t.TempDir(), true)
+			assert.Nil(t, err)
+
+			treeBuilder := builder.GetExportTreebuilder(context.Background(),
+				mockedNotionClient, fileRW, &builder.TreeBuilderRequest{
+					PageIdList:  []string{pageId},
+					Concurrency: concurrency,
+					Filter:      filter,
+				})
+			treeObj, err := treeBuilder.BuildTree(context.Background())
+			assert.Nil(t, err)
+
+			mapping := createOrderedNotionObjectMappingFromTree(treeObj)
+			assert.Equal(t, []string{paragraphId, childPageId}, mapping[pageId])
+			assert.Equal(t, []string{childPageId}, mapping[childPageId])
+		})
+	}
+}
+
+func TestFilter(t *testing.T) {
+	t.Run("Empty filter", func(t *testing.T) {
+		var filter *builder.Filter
+		assert.True(t, filter.IsEmpty())
+		assert.Nil(t, filter.ToProto())
+		assert.True(t, (&builder.Filter{}).IsEmpty())
+	})
+
+	t.Run("Filter recorded in metadata", func(t *testing.T) {
+		filter := &builder.Filter{
+			ExcludeDatabaseIds: []string{uuid.NewString()},
+			ExcludeTitleRegex:  regexp.MustCompile("^Archive"),
+			MaxDepth:           3,
+		}
+		assert.False(t, filter.IsEmpty())
+
+		filterProto := filter.ToProto()
+		assert.Equal(t, filter.ExcludeDatabaseIds,
+			filterProto.ExcludeDatabaseIds)
+		assert.Equal(t, "^Archive", filterProto.ExcludeTitleRegex)
+		assert.Equal(t, int32(3), filterProto.MaxDepth)
+	})
+
+	t.Run("Known block types", func(t *testing.T) {
+		assert.True(t, builder.IsKnownBlockType("link_preview"))
+		assert.False(t, builder.IsKnownBlockType("link-preview"))
+	})
+}
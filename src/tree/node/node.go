@@ -3,6 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jomei/notionapi"
@@ -23,6 +24,7 @@ const (
 	PAGE     NodeType = "PAGE"
 	DATABASE NodeType = "DATABASE"
 	BLOCK    NodeType = "BLOCK"
+	COMMENT  NodeType = "COMMENT"
 )
 
 type Node struct {
@@ -30,6 +32,8 @@ type Node struct {
 	nodeType          NodeType
 	storageIdentifier rw.DataIdentifier
 	notionObjectId    string
+	lastEditedTime    time.Time
+	checksum          string
 
 	// Using N-ary tree implementation
 	// https://www.interviewbit.com/blog/n-ary-tree/
@@ -42,7 +46,8 @@ type Node struct {
 
 // Helper function to create node with given NodeType
 func createNode(id NodeID, nodeType NodeType,
-	storageIdentifier rw.DataIdentifier, notionObjectId string) (*Node, error) {
+	storageIdentifier rw.DataIdentifier, notionObjectId string,
+	lastEditedTime time.Time, checksum string) (*Node, error) {
 	return &Node{
 		id:                id,
 		nodeType:          nodeType,
@@ -50,9 +55,16 @@ func createNode(id NodeID, nodeType NodeType,
 		child:             nil,
 		storageIdentifier: storageIdentifier,
 		notionObjectId:    notionObjectId,
+		lastEditedTime:    lastEditedTime,
+		checksum:          checksum,
 	}, nil
 }
 
+// Helper function to get the checksum of the object written by ReaderWriter
+func getChecksum(v interface{}) (string, error) {
+	return rw.GetObjectChecksum(v)
+}
+
 // Create database node
 func CreateDatabaseNode(ctx context.Context, database *notionapi.Database,
 	rw rw.ReaderWriter) (*Node, error) {
@@ -61,8 +73,13 @@ func CreateDatabaseNode(ctx context.Context, database *notionapi.Database,
 		return nil, err
 	}
 
+	checksum, err := getChecksum(database)
+	if err != nil {
+		return nil, err
+	}
+
 	return createNode(NodeID(uuid.New().String()), DATABASE, storageIdentifier,
-		database.ID.String())
+		database.ID.String(), database.LastEditedTime, checksum)
 }
 
 // Create page node
@@ -73,8 +90,13 @@ func CreatePageNode(ctx context.Context, page *notionapi.Page,
 		return nil, err
 	}
 
+	checksum, err := getChecksum(page)
+	if err != nil {
+		return nil, err
+	}
+
 	return createNode(NodeID(uuid.New().String()), PAGE, storageIdentifier,
-		page.ID.String())
+		page.ID.String(), page.LastEditedTime, checksum)
 }
 
 // Create block node
@@ -85,8 +107,50 @@ func CreateBlockNode(ctx context.Context, block notionapi.Block,
 		return nil, err
 	}
 
+	checksum, err := getChecksum(block)
+	if err != nil {
+		return nil, err
+	}
+
+	var lastEditedTime time.Time
+	if block.GetLastEditedTime() != nil {
+		lastEditedTime = *block.GetLastEditedTime()
+	}
+
 	return createNode(NodeID(uuid.New().String()), BLOCK, storageIdentifier,
-		block.GetID().String())
+		block.GetID().String(), lastEditedTime, checksum)
+}
+
+// Create comment node. Comment node is a child of the node of the commented
+// page or block
+func CreateCommentNode(ctx context.Context, comment *notionapi.Comment,
+	rw rw.ReaderWriter) (*Node, error) {
+	storageIdentifier, err := rw.WriteComment(ctx, comment)
+	if err != nil {
+		return nil, err
+	}
+
+	checksum, err := getChecksum(comment)
+	if err != nil {
+		return nil, err
+	}
+
+	return createNode(NodeID(uuid.New().String()), COMMENT, storageIdentifier,
+		comment.ID.String(), comment.LastEditedTime, checksum)
+}
+
+// Create a new node having same type, notion object ID, last edited time and
+// checksum as given node of another tree and whose object is stored with given storage
+// identifier. Parent, child and siblings of the node are not copied
+func CopyNode(nodeObj *Node, storageIdentifier rw.DataIdentifier) (*Node,
+	error) {
+	if nodeObj.nodeType == ROOT || nodeObj.nodeType == UNKNOWN {
+		return nil, fmt.Errorf("cannot copy node of type %s", nodeObj.nodeType)
+	}
+
+	return createNode(NodeID(uuid.New().String()), nodeObj.nodeType,
+		storageIdentifier, nodeObj.notionObjectId, nodeObj.lastEditedTime,
+		nodeObj.checksum)
 }
 
 // Special node which will act as a root node for a tree
@@ -114,6 +178,8 @@ func CreateNode(obj *metadata.NotionObject) (*Node, error) {
 		nodeType = DATABASE
 	case metadata.NotionObjectType_PAGE:
 		nodeType = PAGE
+	case metadata.NotionObjectType_COMMENT:
+		nodeType = COMMENT
 	default:
 		nodeType = UNKNOWN
 	}
@@ -138,11 +204,18 @@ func CreateNode(obj *metadata.NotionObject) (*Node, error) {
 		}
 	}
 
+	var lastEditedTime time.Time
+	if obj.LastEditedTime != nil {
+		lastEditedTime = obj.LastEditedTime.AsTime()
+	}
+
 	return &Node{
 		id:                NodeID(obj.Uuid),
 		nodeType:          nodeType,
 		storageIdentifier: rw.DataIdentifier(obj.StorageIdentifier),
 		notionObjectId:    obj.NotionObjectId,
+		lastEditedTime:    lastEditedTime,
+		checksum:          obj.Checksum,
 		sibling:           nil,
 		child:             nil,
 		parent:            nil,
@@ -186,6 +259,14 @@ func (nodeObj *Node) GetParentNode() *Node {
 	return nodeObj.parent
 }
 
+func (nodeObj *Node) GetLastEditedTime() time.Time {
+	return nodeObj.lastEditedTime
+}
+
+func (nodeObj *Node) GetChecksum() string {
+	return nodeObj.checksum
+}
+
 // Adding a child to current node
 func (nodeObj *Node) AddChild(childNode *Node) {
 	childNode.parent = nodeObj
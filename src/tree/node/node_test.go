@@ -80,15 +80,25 @@ func TestCreateNodeForAllTypes(t *testing.T) {
 			blockNode, err3 := node.CreateBlockNode(
 				context.Background(), block, mockedRW)
 
+			comment := &notionapi.Comment{
+				ID: notionapi.ObjectID(test.notionObjectId),
+			}
+			mockedRW.On("WriteComment", context.Background(), comment).
+				Return(test.storageIdentifier, test.err)
+			commentNode, err4 := node.CreateCommentNode(
+				context.Background(), comment, mockedRW)
+
 			mockedRW.AssertExpectations(t)
 
 			if test.wantErr {
 				assert.Nil(databaseNode)
 				assert.Nil(pageNode)
 				assert.Nil(blockNode)
+				assert.Nil(commentNode)
 				assert.NotNil(err1)
 				assert.NotNil(err2)
 				assert.NotNil(err3)
+				assert.NotNil(err4)
 			} else {
 				expectedIdentifier := test.storageIdentifier
 				// Assert DatabaseNode
@@ -127,6 +137,14 @@ func TestCreateNodeForAllTypes(t *testing.T) {
 				assert.Nil(blockNode.GetParentNode())
 				assert.Equal(string(blockNode.GetID()), blockNode.GetID().String())
 				assert.Nil(err3)
+
+				// Assert CommentNode
+				assert.NotNil(commentNode)
+				assert.Equal(expectedIdentifier, commentNode.GetStorageIdentifier())
+				assert.Equal(node.NodeType(node.COMMENT), commentNode.GetNodeType())
+				assert.Equal(test.notionObjectId, commentNode.GetNotionObjectId())
+				assert.Nil(commentNode.GetParentNode())
+				assert.Nil(err4)
 			}
 		})
 	}
@@ -1,9 +1,20 @@
 package tree
 
 import (
+	"time"
+
+	"github.com/shivaji17/notionbackup/src/metadata"
 	"github.com/shivaji17/notionbackup/src/tree/node"
 )
 
 type Tree struct {
 	RootNode *node.Node
+
+	// Files hosted by Notion which were downloaded while taking the backup,
+	// keyed by the URL of the file without query parameters
+	Assets map[string]*metadata.Asset
+
+	// Time at which the snapshot of the tree was taken. Zero for trees which
+	// are not read from a snapshot or read from snapshots of older versions
+	CreatedAt time.Time
 }
@@ -2,12 +2,29 @@ package utils
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
 
+	"github.com/google/uuid"
 	"github.com/jomei/notionapi"
 )
 
+const (
+	UNTITLED             = "Untitled"
+	MAX_FILE_NAME_LENGTH = 100
+
+	// Type of the file objects hosted by Notion. URLs of such files expire an
+	// hour after the object is fetched
+	NOTION_HOSTED_FILE_TYPE = string(notionapi.FileTypeFile)
+)
+
 func ParsePageJsonString(jsonBytes []byte) (*notionapi.Page, error) {
 	page := &notionapi.Page{}
 	err := json.Unmarshal(jsonBytes, &page)
@@ -145,3 +162,165 @@ func GetUniqueValues(strList []string) []string {
 	}
 	return result
 }
+
+// Normalize the Notion ID so that the IDs written with or without dashes can
+// be compared
+func NormalizeNotionId(id string) string {
+	return strings.ToLower(strings.ReplaceAll(id, "-", ""))
+}
+
+// Notion ID at the end of the path of the URLs copied from Notion app, e.g.
+// https://www.notion.so/workspace/Page-Title-0503420328704bc8b1f922c0ae6e56ba
+var notionUrlIdRegex = regexp.MustCompile(`([0-9a-fA-F]{32})$`)
+
+// Parse the Notion ID from the ID, with or without dashes, or from the URL of
+// the Page or Database copied from Notion app. ID is returned with dashes
+func ParseNotionId(idOrUrl string) (string, error) {
+	if id, err := uuid.Parse(idOrUrl); err == nil {
+		return id.String(), nil
+	}
+
+	parsedUrl, err := url.Parse(idOrUrl)
+	if err != nil || parsedUrl.Host == "" {
+		return "", fmt.Errorf("invalid Notion ID or URL: %s", idOrUrl)
+	}
+
+	// View of the database in the query of the URL is ignored
+	match := notionUrlIdRegex.FindString(strings.TrimSuffix(parsedUrl.Path, "/"))
+	if match == "" {
+		return "", fmt.Errorf("notion ID not found in URL: %s", idOrUrl)
+	}
+
+	return uuid.MustParse(match).String(), nil
+}
+
+// Get the plain text of the rich text
+func GetPlainText(richTexts []notionapi.RichText) string {
+	var builder strings.Builder
+	for _, richText := range richTexts {
+		builder.WriteString(richText.PlainText)
+	}
+
+	return builder.String()
+}
+
+// Get the title of the page from its title property
+func GetPageTitle(page *notionapi.Page) string {
+	for _, property := range page.Properties {
+		if titleProperty, ok := property.(*notionapi.TitleProperty); ok {
+			return GetPlainText(titleProperty.Title)
+		}
+	}
+
+	return ""
+}
+
+// Get the title of the database
+func GetDatabaseTitle(database *notionapi.Database) string {
+	return GetPlainText(database.Title)
+}
+
+// Make the title usable as a file name by replacing the characters not allowed
+// in file names. Empty titles are replaced with UNTITLED
+func SanitizeFileName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || strings.ContainsRune(`/\:*?"<>|`, r) {
+			return '_'
+		}
+		return r
+	}, title)
+
+	runes := []rune(strings.TrimSpace(name))
+	if len(runes) > MAX_FILE_NAME_LENGTH {
+		runes = runes[:MAX_FILE_NAME_LENGTH]
+	}
+
+	name = strings.Trim(string(runes), " .")
+	if name == "" {
+		return UNTITLED
+	}
+
+	return name
+}
+
+// Get the key identifying the file hosted by Notion. Query parameters of the
+// URL are signed every time the object is fetched, so they are removed
+func GetAssetKey(fileUrl string) string {
+	parsedUrl, err := url.Parse(fileUrl)
+	if err != nil {
+		return fileUrl
+	}
+
+	parsedUrl.RawQuery = ""
+	parsedUrl.Fragment = ""
+	return parsedUrl.String()
+}
+
+// Get the name of the file hosted by Notion from its URL
+func GetAssetFileName(fileUrl string) string {
+	parsedUrl, err := url.Parse(fileUrl)
+	if err != nil {
+		return UNTITLED
+	}
+
+	return SanitizeFileName(path.Base(parsedUrl.Path))
+}
+
+// Call visit for every file object hosted by Notion inside the decoded JSON
+// value along with the URL of the file. File object can be modified by visit
+func WalkNotionHostedFiles(value interface{},
+	visit func(map[string]interface{}, string) error) error {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if v["type"] == NOTION_HOSTED_FILE_TYPE {
+			file, _ := v[NOTION_HOSTED_FILE_TYPE].(map[string]interface{})
+			if fileUrl, ok := file["url"].(string); ok && fileUrl != "" {
+				return visit(v, fileUrl)
+			}
+		}
+
+		for _, child := range v {
+			err := WalkNotionHostedFiles(child, visit)
+			if err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range v {
+			err := WalkNotionHostedFiles(child, visit)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Get the unique URLs of the files hosted by Notion which are referenced by
+// the object. Icons, covers, file blocks and files properties are included
+func GetNotionHostedFileUrls(object interface{}) ([]string, error) {
+	dataBytes, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var decoded interface{}
+	err = json.Unmarshal(dataBytes, &decoded)
+	if err != nil {
+		return nil, err
+	}
+
+	fileUrls := []string{}
+	err = WalkNotionHostedFiles(decoded, func(_ map[string]interface{},
+		fileUrl string) error {
+		fileUrls = append(fileUrls, fileUrl)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(fileUrls)
+	return GetUniqueValues(fileUrls), nil
+}
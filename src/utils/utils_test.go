@@ -3,6 +3,7 @@ package utils_test
 import (
 	"encoding/json"
 	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/jomei/notionapi"
@@ -272,3 +273,91 @@ func TestGetUniqueValues(t *testing.T) {
 	output := utils.GetUniqueValues(input)
 	assert.Equal(t, expectedOutput, output)
 }
+
+func TestSanitizeFileName(t *testing.T) {
+	tests := []struct {
+		title    string
+		expected string
+	}{
+		{title: "Some title", expected: "Some title"},
+		{title: "a/b\\c:d*e?f\"g<h>i|j", expected: "a_b_c_d_e_f_g_h_i_j"},
+		{title: "  ..title..  ", expected: "title"},
+		{title: "", expected: utils.UNTITLED},
+		{title: strings.Repeat("a", 150), expected: strings.Repeat("a", 100)},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, utils.SanitizeFileName(test.title))
+	}
+}
+
+func TestGetNotionHostedFileUrls(t *testing.T) {
+	fileUrl := "https://files.notion.so/space/file%20name.pdf"
+	page := &notionapi.Page{
+		Cover: &notionapi.Image{
+			Type:     notionapi.FileTypeExternal,
+			External: &notionapi.FileObject{URL: "https://example.com/a.png"},
+		},
+		Icon: &notionapi.Icon{
+			Type: notionapi.FileTypeFile,
+			File: &notionapi.FileObject{URL: fileUrl + "?X-Amz=1"},
+		},
+		Properties: notionapi.Properties{
+			"Files": &notionapi.FilesProperty{
+				Type: notionapi.PropertyTypeFiles,
+				Files: []notionapi.File{{
+					Name: "file name.pdf",
+					Type: notionapi.FileTypeFile,
+					File: &notionapi.FileObject{URL: fileUrl + "?X-Amz=2"},
+				}, {
+					Name: "file name.pdf",
+					Type: notionapi.FileTypeFile,
+					File: &notionapi.FileObject{URL: fileUrl + "?X-Amz=1"},
+				}},
+			},
+		},
+	}
+
+	fileUrls, err := utils.GetNotionHostedFileUrls(page)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{fileUrl + "?X-Amz=1",
+		fileUrl + "?X-Amz=2"}, fileUrls)
+
+	for _, url := range fileUrls {
+		assert.Equal(t, fileUrl, utils.GetAssetKey(url))
+		assert.Equal(t, "file name.pdf", utils.GetAssetFileName(url))
+	}
+}
+
+func TestParseNotionId(t *testing.T) {
+	id := "05034203-2870-4bc8-b1f9-22c0ae6e56ba"
+	tests := []struct {
+		name    string
+		idOrUrl string
+		isValid bool
+	}{
+		{name: "ID", idOrUrl: id, isValid: true},
+		{name: "ID without dashes", idOrUrl: "0503420328704bc8b1f922c0ae6e56ba",
+			isValid: true},
+		{name: "Page URL", idOrUrl: "https://www.notion.so/workspace/" +
+			"Page-Title-0503420328704bc8b1f922c0ae6e56ba?pvs=4", isValid: true},
+		{name: "Database URL", idOrUrl: "https://www.notion.so/workspace/" +
+			"0503420328704bc8b1f922c0ae6e56ba?v=9cd00ee963e54dadb0aad76f2ecc36d1",
+			isValid: true},
+		{name: "Invalid ID", idOrUrl: "0503420328704bc8", isValid: false},
+		{name: "URL without ID", idOrUrl: "https://www.notion.so/workspace",
+			isValid: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parsedId, err := utils.ParseNotionId(test.idOrUrl)
+			if test.isValid {
+				assert.Nil(t, err)
+				assert.Equal(t, id, parsedId)
+			} else {
+				assert.NotNil(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,361 @@
+package verifier
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/rs/zerolog"
+	"github.com/shivaji17/notionbackup/src/logging"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+)
+
+type Check string
+
+const (
+	ROOT_CHECK      Check = "root"
+	STRUCTURE_CHECK Check = "structure"
+	OBJECT_CHECK    Check = "object"
+	CHECKSUM_CHECK  Check = "checksum"
+	ASSET_CHECK     Check = "asset"
+)
+
+// Problem found in the backup by one of the checks
+type Issue struct {
+	Check   Check  `json:"check"`
+	Uuid    string `json:"uuid,omitempty"`
+	Message string `json:"message"`
+}
+
+// Result of the verification of the backup
+type Report struct {
+	MetadataFilePath string  `json:"metadata_file_path"`
+	Valid            bool    `json:"valid"`
+	ObjectCount      int     `json:"object_count"`
+	VerifiedObjects  int     `json:"verified_objects"`
+	MissingChecksums int     `json:"missing_checksums"`
+	AssetCount       int     `json:"asset_count"`
+	VerifiedAssets   int     `json:"verified_assets"`
+	Issues           []Issue `json:"issues"`
+}
+
+// Verifier checks that the backup is complete and consistent without
+// restoring it
+type Verifier struct {
+	rwClient    rw.ReaderWriter
+	metadataObj *metadata.MetaData
+	report      *Report
+}
+
+func GetVerifier(rwClient rw.ReaderWriter,
+	metadataObj *metadata.MetaData) *Verifier {
+	return &Verifier{
+		rwClient:    rwClient,
+		metadataObj: metadataObj,
+	}
+}
+
+func (v *Verifier) addIssue(check Check, nodeUuid string, format string,
+	args ...interface{}) {
+	v.report.Issues = append(v.report.Issues, Issue{
+		Check:   check,
+		Uuid:    nodeUuid,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// Helper function to get the keys of the map in a stable order so that the
+// report is same for the same backup
+func getSortedKeys(objectMap map[string]*metadata.NotionObject) []string {
+	keys := make([]string, 0, len(objectMap))
+	for key := range objectMap {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Check that there is exactly one ROOT object and it is stored with Nil UUID
+func (v *Verifier) verifyRoot() {
+	rootCount := 0
+	for _, nodeUuid := range getSortedKeys(v.metadataObj.NotionObjectMap) {
+		notionObj := v.metadataObj.NotionObjectMap[nodeUuid]
+		if notionObj.Type != metadata.NotionObjectType_ROOT {
+			continue
+		}
+
+		rootCount++
+		if nodeUuid != uuid.Nil.String() {
+			v.addIssue(ROOT_CHECK, nodeUuid, "root object does not have nil uuid")
+		}
+	}
+
+	if rootCount != 1 {
+		v.addIssue(ROOT_CHECK, "", "found %d root objects instead of 1",
+			rootCount)
+	}
+}
+
+// Helper function to visit the children of the node depth first and report
+// the cycles found
+func (v *Verifier) visit(nodeUuid string, state map[string]int) {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state[nodeUuid] = visiting
+	childList := v.metadataObj.ParentUuid_2ChildrenUuidMap[nodeUuid]
+	for _, childUuid := range childList.GetChildrenUuidList() {
+		switch state[childUuid] {
+		case visiting:
+			v.addIssue(STRUCTURE_CHECK, childUuid,
+				"cycle found through parent '%s'", nodeUuid)
+		case visited:
+			// Object with more than one parent is reported while mapping the
+			// parents
+		default:
+			v.visit(childUuid, state)
+		}
+	}
+	state[nodeUuid] = visited
+}
+
+// Check that the children map only refers to existing objects, every object
+// has a single parent and every object can be reached from the root object
+// without any cycle
+func (v *Verifier) verifyStructure() {
+	parentMap := make(map[string]string)
+	childrenMap := v.metadataObj.ParentUuid_2ChildrenUuidMap
+	parentUuids := make([]string, 0, len(childrenMap))
+	for parentUuid := range childrenMap {
+		parentUuids = append(parentUuids, parentUuid)
+	}
+	sort.Strings(parentUuids)
+
+	for _, parentUuid := range parentUuids {
+		if _, found := v.metadataObj.NotionObjectMap[parentUuid]; !found {
+			v.addIssue(STRUCTURE_CHECK, parentUuid,
+				"parent object does not exist")
+		}
+
+		for _, childUuid := range childrenMap[parentUuid].ChildrenUuidList {
+			if _, found := v.metadataObj.NotionObjectMap[childUuid]; !found {
+				v.addIssue(STRUCTURE_CHECK, childUuid,
+					"child object of parent '%s' does not exist", parentUuid)
+			}
+
+			if childUuid == uuid.Nil.String() {
+				v.addIssue(STRUCTURE_CHECK, childUuid,
+					"root object is a child of '%s'", parentUuid)
+			}
+
+			if otherParent, found := parentMap[childUuid]; found {
+				v.addIssue(STRUCTURE_CHECK, childUuid,
+					"object has more than one parent: '%s' and '%s'", otherParent,
+					parentUuid)
+				continue
+			}
+			parentMap[childUuid] = parentUuid
+		}
+	}
+
+	state := make(map[string]int)
+	if _, found := v.metadataObj.NotionObjectMap[uuid.Nil.String()]; found {
+		v.visit(uuid.Nil.String(), state)
+	}
+
+	for _, nodeUuid := range getSortedKeys(v.metadataObj.NotionObjectMap) {
+		if state[nodeUuid] != 0 {
+			continue
+		}
+
+		// Cycles among orphans are only reachable from the orphans
+		v.addIssue(STRUCTURE_CHECK, nodeUuid,
+			"object is not reachable from the root object")
+		v.visit(nodeUuid, state)
+	}
+}
+
+// Helper function to read the object and check that it decodes to the type
+// and notion object ID recorded in metadata. Returns the decoded object
+func (v *Verifier) readObject(ctx context.Context,
+	notionObj *metadata.NotionObject) (interface{}, error) {
+	identifier := rw.DataIdentifier(notionObj.StorageIdentifier)
+	var objectType notionapi.ObjectType
+	var notionObjectId string
+	var object interface{}
+
+	switch notionObj.Type {
+	case metadata.NotionObjectType_PAGE:
+		page, err := v.rwClient.ReadPage(ctx, identifier)
+		if err != nil {
+			return nil, err
+		}
+		objectType, notionObjectId, object = page.Object, page.ID.String(), page
+		if objectType != notionapi.ObjectTypePage {
+			return nil, fmt.Errorf("object of type '%s' stored as page",
+				objectType)
+		}
+	case metadata.NotionObjectType_DATABASE:
+		database, err := v.rwClient.ReadDatabase(ctx, identifier)
+		if err != nil {
+			return nil, err
+		}
+		objectType, notionObjectId, object = database.Object,
+			database.ID.String(), database
+		if objectType != notionapi.ObjectTypeDatabase {
+			return nil, fmt.Errorf("object of type '%s' stored as database",
+				objectType)
+		}
+	case metadata.NotionObjectType_BLOCK:
+		block, err := v.rwClient.ReadBlock(ctx, identifier)
+		if err != nil {
+			return nil, err
+		}
+		objectType, notionObjectId, object = block.GetObject(),
+			block.GetID().String(), block
+		if objectType != notionapi.ObjectTypeBlock {
+			return nil, fmt.Errorf("object of type '%s' stored as block",
+				objectType)
+		}
+	case metadata.NotionObjectType_COMMENT:
+		comment, err := v.rwClient.ReadComment(ctx, identifier)
+		if err != nil {
+			return nil, err
+		}
+		objectType, notionObjectId, object = comment.Object,
+			comment.ID.String(), comment
+		if objectType != notionapi.ObjectTypeComment {
+			return nil, fmt.Errorf("object of type '%s' stored as comment",
+				objectType)
+		}
+	default:
+		return nil, fmt.Errorf("unknown notion object type: %s", notionObj.Type)
+	}
+
+	if notionObjectId != notionObj.NotionObjectId {
+		return nil, fmt.Errorf("object has notion object id '%s' instead of '%s'",
+			notionObjectId, notionObj.NotionObjectId)
+	}
+
+	return object, nil
+}
+
+// Helper function to get the checksum of the stored object. Serialized object
+// is read as is if ReaderWriter supports it
+func (v *Verifier) getChecksum(ctx context.Context,
+	notionObj *metadata.NotionObject, object interface{}) (string, error) {
+	rawRW, ok := v.rwClient.(rw.RawReaderWriter)
+	if !ok {
+		return rw.GetObjectChecksum(object)
+	}
+
+	dataBytes, err := rawRW.ReadRawObject(ctx, notionObj.Type,
+		rw.DataIdentifier(notionObj.StorageIdentifier))
+	if err != nil {
+		return "", err
+	}
+
+	return rw.GetChecksum(dataBytes), nil
+}
+
+// Check that every object can be read and matches its checksum
+func (v *Verifier) verifyObjects(ctx context.Context) {
+	log := zerolog.Ctx(ctx)
+	for _, nodeUuid := range getSortedKeys(v.metadataObj.NotionObjectMap) {
+		notionObj := v.metadataObj.NotionObjectMap[nodeUuid]
+		if notionObj.Uuid != nodeUuid {
+			v.addIssue(OBJECT_CHECK, nodeUuid, "object is stored with uuid '%s'",
+				notionObj.Uuid)
+		}
+
+		if notionObj.Type == metadata.NotionObjectType_ROOT {
+			continue
+		}
+
+		if notionObj.StorageIdentifier == "" {
+			v.addIssue(OBJECT_CHECK, nodeUuid, "object has no storage identifier")
+			continue
+		}
+
+		object, err := v.readObject(ctx, notionObj)
+		if err != nil {
+			log.Debug().Err(err).Str(logging.NodeID, nodeUuid).Msg(
+				"Failed to read the object")
+			v.addIssue(OBJECT_CHECK, nodeUuid, "%v", err)
+			continue
+		}
+
+		if notionObj.Checksum == "" {
+			v.report.MissingChecksums++
+			v.report.VerifiedObjects++
+			continue
+		}
+
+		checksum, err := v.getChecksum(ctx, notionObj, object)
+		if err != nil {
+			v.addIssue(CHECKSUM_CHECK, nodeUuid, "%v", err)
+			continue
+		}
+
+		if checksum != notionObj.Checksum {
+			v.addIssue(CHECKSUM_CHECK, nodeUuid,
+				"checksum '%s' does not match recorded checksum '%s'", checksum,
+				notionObj.Checksum)
+			continue
+		}
+
+		v.report.VerifiedObjects++
+	}
+}
+
+// Check that every asset can be read and matches its checksum
+func (v *Verifier) verifyAssets(ctx context.Context) {
+	keys := make([]string, 0, len(v.metadataObj.AssetMap))
+	for key := range v.metadataObj.AssetMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		asset := v.metadataObj.AssetMap[key]
+		dataBytes, err := rw.ReadAsset(ctx, v.rwClient,
+			rw.DataIdentifier(asset.StorageIdentifier))
+		if err != nil {
+			v.addIssue(ASSET_CHECK, "", "asset %s: %v", key, err)
+			continue
+		}
+
+		checksum := rw.GetChecksum(dataBytes)
+		if checksum != asset.Checksum {
+			v.addIssue(ASSET_CHECK, "", "asset %s: checksum '%s' does not match "+
+				"recorded checksum '%s'", key, checksum, asset.Checksum)
+			continue
+		}
+
+		v.report.VerifiedAssets++
+	}
+}
+
+// Run all the checks on the backup. Problems found are reported as issues of
+// the report instead of stopping the verification
+func (v *Verifier) Verify(ctx context.Context) *Report {
+	v.report = &Report{
+		ObjectCount: len(v.metadataObj.NotionObjectMap),
+		AssetCount:  len(v.metadataObj.AssetMap),
+		Issues:      []Issue{},
+	}
+
+	v.verifyRoot()
+	v.verifyStructure()
+	v.verifyObjects(ctx)
+	v.verifyAssets(ctx)
+
+	v.report.Valid = len(v.report.Issues) == 0
+	return v.report
+}
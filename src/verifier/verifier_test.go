@@ -0,0 +1,227 @@
+package verifier_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jomei/notionapi"
+	"github.com/shivaji17/notionbackup/src/exporter"
+	"github.com/shivaji17/notionbackup/src/metadata"
+	"github.com/shivaji17/notionbackup/src/rw"
+	"github.com/shivaji17/notionbackup/src/tree"
+	"github.com/shivaji17/notionbackup/src/tree/node"
+	"github.com/shivaji17/notionbackup/src/verifier"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/proto"
+)
+
+type testBackup struct {
+	dir         string
+	rwClient    rw.ReaderWriter
+	metadataObj *metadata.MetaData
+	pageNode    *node.Node
+	blockNode   *node.Node
+}
+
+// Helper function to write a backup having a page with a block and a database
+func createBackup(t *testing.T) *testBackup {
+	ctx := context.Background()
+	dir := t.TempDir()
+	rwClient, err := rw.GetFileReaderWriter(ctx, dir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pageNode, err := node.CreatePageNode(ctx, &notionapi.Page{
+		Object: notionapi.ObjectTypePage,
+		ID:     "page_id",
+	}, rwClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blockNode, err := node.CreateBlockNode(ctx, &notionapi.ParagraphBlock{
+		BasicBlock: notionapi.BasicBlock{
+			Object: notionapi.ObjectTypeBlock,
+			ID:     "block_id",
+			Type:   notionapi.BlockTypeParagraph,
+		},
+	}, rwClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	databaseNode, err := node.CreateDatabaseNode(ctx, &notionapi.Database{
+		Object: notionapi.ObjectTypeDatabase,
+		ID:     "database_id",
+	}, rwClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rootNode := node.CreateRootNode()
+	rootNode.AddChild(pageNode)
+	rootNode.AddChild(databaseNode)
+	pageNode.AddChild(blockNode)
+
+	metadataObj, err := exporter.CreateMetadata(ctx,
+		&tree.Tree{RootNode: rootNode})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &testBackup{
+		dir:         dir,
+		rwClient:    rwClient,
+		metadataObj: metadataObj,
+		pageNode:    pageNode,
+		blockNode:   blockNode,
+	}
+}
+
+// Helper function to get the checks of all the issues of the report
+func getIssueChecks(report *verifier.Report) []verifier.Check {
+	checks := []verifier.Check{}
+	for _, issue := range report.Issues {
+		checks = append(checks, issue.Check)
+	}
+	return checks
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Valid backup", func(t *testing.T) {
+		backup := createBackup(t)
+		pageObj := backup.metadataObj.NotionObjectMap[backup.pageNode.GetID().
+			String()]
+		assert.Len(t, pageObj.Checksum, 64)
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Issues)
+		assert.Equal(t, 4, report.ObjectCount)
+		assert.Equal(t, 3, report.VerifiedObjects)
+		assert.Equal(t, 0, report.MissingChecksums)
+	})
+
+	t.Run("Backup without checksums", func(t *testing.T) {
+		backup := createBackup(t)
+		for _, notionObj := range backup.metadataObj.NotionObjectMap {
+			notionObj.Checksum = ""
+		}
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.True(t, report.Valid)
+		assert.Equal(t, 3, report.MissingChecksums)
+	})
+
+	t.Run("Missing object", func(t *testing.T) {
+		backup := createBackup(t)
+		err := os.Remove(filepath.Join(backup.dir, rw.BLOCK_DIR_NAME,
+			backup.blockNode.GetStorageIdentifier().String()))
+		assert.Nil(t, err)
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []verifier.Check{verifier.OBJECT_CHECK},
+			getIssueChecks(report))
+		assert.Equal(t, backup.blockNode.GetID().String(), report.Issues[0].Uuid)
+	})
+
+	t.Run("Object of wrong type", func(t *testing.T) {
+		backup := createBackup(t)
+		blockObj := backup.metadataObj.NotionObjectMap[backup.blockNode.GetID().
+			String()]
+		blockObj.Type = metadata.NotionObjectType_PAGE
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []verifier.Check{verifier.OBJECT_CHECK},
+			getIssueChecks(report))
+	})
+
+	t.Run("Checksum mismatch", func(t *testing.T) {
+		backup := createBackup(t)
+		pageObj := backup.metadataObj.NotionObjectMap[backup.pageNode.GetID().
+			String()]
+		pageObj.Checksum = rw.GetChecksum([]byte("other"))
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []verifier.Check{verifier.CHECKSUM_CHECK},
+			getIssueChecks(report))
+	})
+
+	t.Run("Orphan object", func(t *testing.T) {
+		backup := createBackup(t)
+		delete(backup.metadataObj.ParentUuid_2ChildrenUuidMap,
+			backup.pageNode.GetID().String())
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []verifier.Check{verifier.STRUCTURE_CHECK},
+			getIssueChecks(report))
+		assert.Equal(t, backup.blockNode.GetID().String(), report.Issues[0].Uuid)
+	})
+
+	t.Run("Cycle", func(t *testing.T) {
+		backup := createBackup(t)
+		backup.metadataObj.ParentUuid_2ChildrenUuidMap[backup.blockNode.GetID().
+			String()] = &metadata.ChildrenNotionObjectUuids{
+			ChildrenUuidList: []string{backup.pageNode.GetID().String()},
+		}
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Contains(t, getIssueChecks(report), verifier.STRUCTURE_CHECK)
+		assert.Contains(t, report.Issues[len(report.Issues)-1].Message, "cycle")
+	})
+
+	t.Run("Child does not exist", func(t *testing.T) {
+		backup := createBackup(t)
+		childList := backup.metadataObj.ParentUuid_2ChildrenUuidMap[backup.
+			pageNode.GetID().String()]
+		childList.ChildrenUuidList = append(childList.ChildrenUuidList,
+			uuid.NewString())
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, []verifier.Check{verifier.STRUCTURE_CHECK},
+			getIssueChecks(report))
+	})
+
+	t.Run("Missing root", func(t *testing.T) {
+		backup := createBackup(t)
+		delete(backup.metadataObj.NotionObjectMap, uuid.Nil.String())
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Equal(t, verifier.ROOT_CHECK, report.Issues[0].Check)
+	})
+
+	t.Run("More than one root", func(t *testing.T) {
+		backup := createBackup(t)
+		otherRoot := proto.Clone(backup.metadataObj.NotionObjectMap[uuid.Nil.
+			String()]).(*metadata.NotionObject)
+		otherRoot.Uuid = uuid.NewString()
+		backup.metadataObj.NotionObjectMap[otherRoot.Uuid] = otherRoot
+
+		report := verifier.GetVerifier(backup.rwClient, backup.metadataObj).
+			Verify(ctx)
+		assert.False(t, report.Valid)
+		assert.Contains(t, getIssueChecks(report), verifier.ROOT_CHECK)
+	})
+}